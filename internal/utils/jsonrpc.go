@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type JSONRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+	ID      int         `json:"id"`
+}
+
+type JSONRPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *JSONRPCError   `json:"error"`
+	ID      int             `json:"id"`
+}
+
+// JSONRPCError is the error object returned by JSON-RPC endpoints.
+// Data holds the raw APING exception (e.g. {"APINGException": {...}, "exceptionname": "APINGException"}).
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("json-rpc error: %d - message: %s - data: %s", e.Code, e.Message, e.Data)
+}
+
+// SendJSONRPCRequest sends a JSON-RPC request to the betfair servers and returns the raw result.
+// If the server replies with a JSON-RPC error, a *JSONRPCError is returned.
+func SendJSONRPCRequest(httpClient *http.Client, appKey string, sessionToken string, url string, method string, params interface{}) (json.RawMessage, error) {
+	reqBytes, err := json.Marshal(JSONRPCRequest{JSONRPC: "2.0", Method: method, Params: params, ID: 1})
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling request %w", err)
+	}
+
+	respBody, err := SendRequest(httpClient, "POST", appKey, sessionToken, url, bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := JSONRPCResponse{}
+	err = json.Unmarshal(respBody, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("error while unmarshalling response %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+
+	return resp.Result, nil
+}
@@ -0,0 +1,65 @@
+package accounts
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gustavooferreira/betfair/internal/utils"
+	"github.com/gustavooferreira/betfair/pkg/aping"
+)
+
+type AccountsAPI struct {
+	aping.BetfairAPI
+}
+
+func NewAccountsAPI(bapi aping.BetfairAPI) AccountsAPI {
+	accountsAPI := AccountsAPI{bapi}
+	return accountsAPI
+}
+
+// call marshals the params, sends them to the operation endpoint and unmarshals the response into result.
+func (a AccountsAPI) call(operation string, params interface{}, result interface{}) error {
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error while marshalling request %w", err)
+	}
+
+	payload := bytes.NewBuffer(paramsBytes)
+	response, err := a.sendRequest(apiEndpoint+operation+"/", payload)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(response, result)
+	if err != nil {
+		return fmt.Errorf("error while unmarshalling response %w", err)
+	}
+
+	return nil
+}
+
+func (a AccountsAPI) sendRequest(url string, body io.Reader) ([]byte, error) {
+
+	respBody, err := utils.SendRequest(a.HttpClient, "POST", a.AppKey, a.SessionToken, url, body)
+
+	// Encapsulate error here!
+	if errB, ok := err.(*utils.BetfairAPIError); ok {
+		bapie := BetfairAPIError{}
+		err = json.Unmarshal([]byte(errB.Body), &bapie)
+		if err != nil {
+			return nil, fmt.Errorf("error while unmarshalling AccountAPINGException response %w", err)
+		}
+
+		return nil, &AccountsAPIError{
+			ErrorCode:    bapie.Detail.AccountAPINGException.ErrorCode,
+			ErrorDetails: bapie.Detail.AccountAPINGException.ErrorDetails,
+			RequestUUID:  bapie.Detail.AccountAPINGException.RequestUUID,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return respBody, nil
+}
@@ -0,0 +1,22 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package accounts
+
+// ContainerGetAccountFunds holds the parameters of the getAccountFunds operation.
+type ContainerGetAccountFunds struct {
+	Wallet *Wallet `json:"wallet,omitempty"`
+}
+
+// ContainerGetAccountStatement holds the parameters of the getAccountStatement operation.
+type ContainerGetAccountStatement struct {
+	Locale        string       `json:"locale,omitempty"`
+	FromRecord    *int         `json:"fromRecord,omitempty"`
+	RecordCount   *int         `json:"recordCount,omitempty"`
+	ItemDateRange *TimeRange   `json:"itemDateRange,omitempty"`
+	IncludeItem   *IncludeItem `json:"includeItem,omitempty"`
+	Wallet        *Wallet      `json:"wallet,omitempty"`
+}
+
+// ContainerListCurrencyRates holds the parameters of the listCurrencyRates operation.
+type ContainerListCurrencyRates struct {
+	FromCurrency string `json:"fromCurrency,omitempty"`
+}
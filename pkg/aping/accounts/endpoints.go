@@ -1 +1,40 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package accounts
+
+const apiEndpoint = "https://api.betfair.com/exchange/account/rest/v1.0/"
+
+const (
+	getAccountFundsOperation     = "getAccountFunds"
+	getAccountDetailsOperation   = "getAccountDetails"
+	getAccountStatementOperation = "getAccountStatement"
+	listCurrencyRatesOperation   = "listCurrencyRates"
+)
+
+// GetAccountFunds returns the available to bet amount, exposure and commission information.
+func (a AccountsAPI) GetAccountFunds(c ContainerGetAccountFunds) (AccountFundsResponse, error) {
+	var result AccountFundsResponse
+	err := a.call(getAccountFundsOperation, c, &result)
+	return result, err
+}
+
+// GetAccountDetails returns the details relating your account, including your discount rate and Betfair point balance.
+func (a AccountsAPI) GetAccountDetails() (AccountDetailsResponse, error) {
+	var result AccountDetailsResponse
+	err := a.call(getAccountDetailsOperation, struct{}{}, &result)
+	return result, err
+}
+
+// GetAccountStatement returns the account statement.
+// By default the service will return all available data for the last 90 days.
+func (a AccountsAPI) GetAccountStatement(c ContainerGetAccountStatement) (AccountStatementReport, error) {
+	var result AccountStatementReport
+	err := a.call(getAccountStatementOperation, c, &result)
+	return result, err
+}
+
+// ListCurrencyRates returns a list of currency rates based on given currency.
+func (a AccountsAPI) ListCurrencyRates(c ContainerListCurrencyRates) ([]CurrencyRate, error) {
+	var result []CurrencyRate
+	err := a.call(listCurrencyRatesOperation, c, &result)
+	return result, err
+}
@@ -0,0 +1,82 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package accounts
+
+import (
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"time"
+)
+
+// AccountFundsResponse is the response of getAccountFunds.
+type AccountFundsResponse struct {
+	AvailableToBetBalance *decimal.Money `json:"availableToBetBalance,omitempty"`
+	Exposure              *decimal.Money `json:"exposure,omitempty"`
+	RetainedCommission    *decimal.Money `json:"retainedCommission,omitempty"`
+	ExposureLimit         *decimal.Money `json:"exposureLimit,omitempty"`
+	DiscountRate          *float64       `json:"discountRate,omitempty"`
+	PointsBalance         *int           `json:"pointsBalance,omitempty"`
+	Wallet                *Wallet        `json:"wallet,omitempty"`
+}
+
+// AccountDetailsResponse is the response of getAccountDetails.
+type AccountDetailsResponse struct {
+	CurrencyCode  string   `json:"currencyCode,omitempty"`
+	FirstName     string   `json:"firstName,omitempty"`
+	LastName      string   `json:"lastName,omitempty"`
+	LocaleCode    string   `json:"localeCode,omitempty"`
+	Region        string   `json:"region,omitempty"`
+	Timezone      string   `json:"timezone,omitempty"`
+	DiscountRate  *float64 `json:"discountRate,omitempty"`
+	PointsBalance *int     `json:"pointsBalance,omitempty"`
+	CountryCode   string   `json:"countryCode,omitempty"`
+}
+
+// AccountStatementReport is the response of getAccountStatement.
+type AccountStatementReport struct {
+	AccountStatement []StatementItem `json:"accountStatement,omitempty"`
+	MoreAvailable    *bool           `json:"moreAvailable,omitempty"`
+}
+
+// StatementItem is a single account statement entry.
+type StatementItem struct {
+	RefID         string               `json:"refId,omitempty"`
+	ItemDate      *time.Time           `json:"itemDate,omitempty"`
+	Amount        *decimal.Money       `json:"amount,omitempty"`
+	Balance       *decimal.Money       `json:"balance,omitempty"`
+	ItemClass     *ItemClass           `json:"itemClass,omitempty"`
+	ItemClassData map[string]string    `json:"itemClassData,omitempty"`
+	LegacyData    *StatementLegacyData `json:"legacyData,omitempty"`
+}
+
+// StatementLegacyData holds the statement details of exchange bets.
+type StatementLegacyData struct {
+	AvgPrice        *decimal.Price `json:"avgPrice,omitempty"`
+	BetSize         *decimal.Money `json:"betSize,omitempty"`
+	BetType         string         `json:"betType,omitempty"`
+	BetCategoryType string         `json:"betCategoryType,omitempty"`
+	CommissionRate  string         `json:"commissionRate,omitempty"`
+	EventID         *int64         `json:"eventId,omitempty"`
+	EventTypeID     *int64         `json:"eventTypeId,omitempty"`
+	FullMarketName  string         `json:"fullMarketName,omitempty"`
+	GrossBetAmount  *decimal.Money `json:"grossBetAmount,omitempty"`
+	MarketName      string         `json:"marketName,omitempty"`
+	MarketType      string         `json:"marketType,omitempty"`
+	PlacedDate      *time.Time     `json:"placedDate,omitempty"`
+	SelectionID     *int64         `json:"selectionId,omitempty"`
+	SelectionName   string         `json:"selectionName,omitempty"`
+	StartDate       *time.Time     `json:"startDate,omitempty"`
+	TransactionType string         `json:"transactionType,omitempty"`
+	TransactionID   *int64         `json:"transactionId,omitempty"`
+	WinLose         string         `json:"winLose,omitempty"`
+}
+
+// CurrencyRate holds the exchange rate of a currency against GBP.
+type CurrencyRate struct {
+	CurrencyCode string   `json:"currencyCode,omitempty"`
+	Rate         *float64 `json:"rate,omitempty"`
+}
+
+// TimeRange defines a time window.
+type TimeRange struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
@@ -0,0 +1,30 @@
+package accounts
+
+import "fmt"
+
+type BetfairAPIError struct {
+	Detail      BetfairDetailError `json:"detail"`
+	FaultCode   string             `json:"faultCode"`
+	FaultString string             `json:"faultstring"`
+}
+
+type BetfairDetailError struct {
+	AccountAPINGException AccountAPINGException `json:"AccountAPINGException"`
+	ExceptionName         string                `json:"exceptionname"`
+}
+
+type AccountAPINGException struct {
+	ErrorCode    AccountAPINGExceptionCode `json:"errorCode"`
+	ErrorDetails string                    `json:"errorDetails"`
+	RequestUUID  string                    `json:"requestUUID"`
+}
+
+type AccountsAPIError struct {
+	ErrorCode    AccountAPINGExceptionCode
+	ErrorDetails string
+	RequestUUID  string
+}
+
+func (e *AccountsAPIError) Error() string {
+	return fmt.Sprintf("Betfair Accounts APING error: %s - Details: %s - RequestUUID: %s", e.ErrorCode, e.ErrorDetails, e.RequestUUID)
+}
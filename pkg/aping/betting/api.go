@@ -0,0 +1,80 @@
+package betting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gustavooferreira/betfair/internal/utils"
+	"github.com/gustavooferreira/betfair/pkg/aping"
+)
+
+// DefaultEndpoint is the betfair Betting API (REST) endpoint
+const DefaultEndpoint = apiEndpoint
+
+type BettingAPI struct {
+	aping.BetfairAPI
+	// Endpoint is the URL of the Betting API, the operation name is appended to it.
+	// Defaults to DefaultEndpoint, set it to use another server (e.g. the emulator).
+	Endpoint string
+	// PriceLadder, when set, is used by PlaceOrders and ReplaceOrders to validate the order prices
+	// against the market's price ladder before sending the request.
+	// Use ClassicPriceLadder to validate every market against the CLASSIC ladder.
+	PriceLadder PriceLadderFunc
+}
+
+func NewBettingAPI(bapi aping.BetfairAPI) BettingAPI {
+	bettingAPI := BettingAPI{BetfairAPI: bapi, Endpoint: DefaultEndpoint}
+	return bettingAPI
+}
+
+// call marshals the params, sends them to the operation endpoint and unmarshals the response into result.
+func (b BettingAPI) call(operation string, params interface{}, result interface{}) error {
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("error while marshalling request %w", err)
+	}
+
+	payload := bytes.NewBuffer(paramsBytes)
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	response, err := b.sendRequest(endpoint+operation+"/", payload)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(response, result)
+	if err != nil {
+		return fmt.Errorf("error while unmarshalling response %w", err)
+	}
+
+	return nil
+}
+
+func (b BettingAPI) sendRequest(url string, body io.Reader) ([]byte, error) {
+
+	respBody, err := utils.SendRequest(b.HttpClient, "POST", b.AppKey, b.SessionToken, url, body)
+
+	// Encapsulate error here!
+	if errB, ok := err.(*utils.BetfairAPIError); ok {
+		bapie := BetfairAPIError{}
+		err = json.Unmarshal([]byte(errB.Body), &bapie)
+		if err != nil {
+			return nil, fmt.Errorf("error while unmarshalling APINGException response %w", err)
+		}
+
+		return nil, &BettingAPIError{
+			ErrorCode:    bapie.Detail.APINGException.ErrorCode,
+			ErrorDetails: bapie.Detail.APINGException.ErrorDetails,
+			RequestUUID:  bapie.Detail.APINGException.RequestUUID,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return respBody, nil
+}
@@ -1,45 +1,163 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package betting
 
+import (
+	"time"
+)
+
+// ContainerListEventTypes holds the parameters of the listEventTypes operation.
+type ContainerListEventTypes struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListCompetitions holds the parameters of the listCompetitions operation.
+type ContainerListCompetitions struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListTimeRanges holds the parameters of the listTimeRanges operation.
+type ContainerListTimeRanges struct {
+	Filter      MarketFilter    `json:"filter"`
+	Granularity TimeGranularity `json:"granularity"`
+}
+
+// ContainerListEvents holds the parameters of the listEvents operation.
+type ContainerListEvents struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListMarketTypes holds the parameters of the listMarketTypes operation.
+type ContainerListMarketTypes struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListCountries holds the parameters of the listCountries operation.
+type ContainerListCountries struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListVenues holds the parameters of the listVenues operation.
+type ContainerListVenues struct {
+	Filter MarketFilter `json:"filter"`
+	Locale string       `json:"locale,omitempty"`
+}
+
+// ContainerListMarketCatalogue holds the parameters of the listMarketCatalogue operation.
 type ContainerListMarketCatalogue struct {
 	Filter           MarketFilter       `json:"filter"`
 	MarketProjection []MarketProjection `json:"marketProjection,omitempty"`
 	Sort             *MarketSort        `json:"sort,omitempty"`
-	MaxResults       uint               `json:"maxResults"`
-	Locale           *string            `json:"locale,omitempty"`
+	MaxResults       int                `json:"maxResults"`
+	Locale           string             `json:"locale,omitempty"`
 }
 
+// ContainerListMarketBook holds the parameters of the listMarketBook operation.
 type ContainerListMarketBook struct {
-	MarketIDs       []string        `json:"marketIds"`
-	PriceProjection PriceProjection `json:"priceProjection"`
+	MarketIDs                     []string         `json:"marketIds"`
+	PriceProjection               *PriceProjection `json:"priceProjection,omitempty"`
+	OrderProjection               *OrderProjection `json:"orderProjection,omitempty"`
+	MatchProjection               *MatchProjection `json:"matchProjection,omitempty"`
+	IncludeOverallPosition        *bool            `json:"includeOverallPosition,omitempty"`
+	PartitionMatchedByStrategyRef *bool            `json:"partitionMatchedByStrategyRef,omitempty"`
+	CustomerStrategyRefs          []string         `json:"customerStrategyRefs,omitempty"`
+	CurrencyCode                  string           `json:"currencyCode,omitempty"`
+	Locale                        string           `json:"locale,omitempty"`
+	MatchedSince                  *time.Time       `json:"matchedSince,omitempty"`
+	BetIDs                        []string         `json:"betIds,omitempty"`
 }
 
-type ContainerPlaceOrders struct {
-	MarketID     string             `json:"marketId"`
-	Instructions []PlaceInstruction `json:"instructions"`
-	// CustomerStrategyRef - Max of 15 characters
-	CustomerStrategyRef string `json:"customerStrategyRef"`
+// ContainerListRunnerBook holds the parameters of the listRunnerBook operation.
+type ContainerListRunnerBook struct {
+	MarketID                      string           `json:"marketId"`
+	SelectionID                   int64            `json:"selectionId"`
+	Handicap                      *float64         `json:"handicap,omitempty"`
+	PriceProjection               *PriceProjection `json:"priceProjection,omitempty"`
+	OrderProjection               *OrderProjection `json:"orderProjection,omitempty"`
+	MatchProjection               *MatchProjection `json:"matchProjection,omitempty"`
+	IncludeOverallPosition        *bool            `json:"includeOverallPosition,omitempty"`
+	PartitionMatchedByStrategyRef *bool            `json:"partitionMatchedByStrategyRef,omitempty"`
+	CustomerStrategyRefs          []string         `json:"customerStrategyRefs,omitempty"`
+	CurrencyCode                  string           `json:"currencyCode,omitempty"`
+	Locale                        string           `json:"locale,omitempty"`
+	MatchedSince                  *time.Time       `json:"matchedSince,omitempty"`
+	BetIDs                        []string         `json:"betIds,omitempty"`
 }
 
-type ContainerReplaceOrders struct {
-	MarketID     string               `json:"marketId"`
-	Instructions []ReplaceInstruction `json:"instructions"`
+// ContainerListMarketProfitAndLoss holds the parameters of the listMarketProfitAndLoss operation.
+type ContainerListMarketProfitAndLoss struct {
+	MarketIDs          []string `json:"marketIds"`
+	IncludeSettledBets *bool    `json:"includeSettledBets,omitempty"`
+	IncludeBSPBets     *bool    `json:"includeBspBets,omitempty"`
+	NetOfCommission    *bool    `json:"netOfCommission,omitempty"`
 }
 
-type ContainerCancelOrders struct {
-	MarketID     string              `json:"marketId"`
-	Instructions []CancelInstruction `json:"instructions"`
+// ContainerListCurrentOrders holds the parameters of the listCurrentOrders operation.
+type ContainerListCurrentOrders struct {
+	BetIDs               []string         `json:"betIds,omitempty"`
+	MarketIDs            []string         `json:"marketIds,omitempty"`
+	OrderProjection      *OrderProjection `json:"orderProjection,omitempty"`
+	CustomerOrderRefs    []string         `json:"customerOrderRefs,omitempty"`
+	CustomerStrategyRefs []string         `json:"customerStrategyRefs,omitempty"`
+	DateRange            *TimeRange       `json:"dateRange,omitempty"`
+	OrderBy              *OrderBy         `json:"orderBy,omitempty"`
+	SortDir              *SortDir         `json:"sortDir,omitempty"`
+	FromRecord           *int             `json:"fromRecord,omitempty"`
+	RecordCount          *int             `json:"recordCount,omitempty"`
 }
 
+// ContainerListClearedOrders holds the parameters of the listClearedOrders operation.
 type ContainerListClearedOrders struct {
 	BetStatus              BetStatus  `json:"betStatus"`
-	GroupBy                *GroupBy   `json:"groupBy"`
+	EventTypeIDs           []string   `json:"eventTypeIds,omitempty"`
+	EventIDs               []string   `json:"eventIds,omitempty"`
+	MarketIDs              []string   `json:"marketIds,omitempty"`
+	RunnerIDs              []RunnerID `json:"runnerIds,omitempty"`
+	BetIDs                 []string   `json:"betIds,omitempty"`
+	CustomerOrderRefs      []string   `json:"customerOrderRefs,omitempty"`
 	CustomerStrategyRefs   []string   `json:"customerStrategyRefs,omitempty"`
+	Side                   *Side      `json:"side,omitempty"`
 	SettledDateRange       *TimeRange `json:"settledDateRange,omitempty"`
-	IncludeItemDescription bool       `json:"includeItemDescription"`
-	FromRecord             uint       `json:"fromRecord"`
-	RecordCount            uint       `json:"recordCount"`
+	GroupBy                *GroupBy   `json:"groupBy,omitempty"`
+	IncludeItemDescription *bool      `json:"includeItemDescription,omitempty"`
+	Locale                 string     `json:"locale,omitempty"`
+	FromRecord             *int       `json:"fromRecord,omitempty"`
+	RecordCount            *int       `json:"recordCount,omitempty"`
 }
 
-type ContainerListCurrentOrders struct {
-	BetIDs []string `json:"betIds,omitempty"`
+// ContainerPlaceOrders holds the parameters of the placeOrders operation.
+type ContainerPlaceOrders struct {
+	MarketID            string             `json:"marketId"`
+	Instructions        []PlaceInstruction `json:"instructions"`
+	CustomerRef         string             `json:"customerRef,omitempty"`
+	MarketVersion       *MarketVersion     `json:"marketVersion,omitempty"`
+	CustomerStrategyRef string             `json:"customerStrategyRef,omitempty"` // Max of 15 characters
+	Async               *bool              `json:"async,omitempty"`
+}
+
+// ContainerCancelOrders holds the parameters of the cancelOrders operation.
+type ContainerCancelOrders struct {
+	MarketID     string              `json:"marketId,omitempty"`
+	Instructions []CancelInstruction `json:"instructions,omitempty"`
+	CustomerRef  string              `json:"customerRef,omitempty"`
+}
+
+// ContainerReplaceOrders holds the parameters of the replaceOrders operation.
+type ContainerReplaceOrders struct {
+	MarketID      string               `json:"marketId"`
+	Instructions  []ReplaceInstruction `json:"instructions"`
+	CustomerRef   string               `json:"customerRef,omitempty"`
+	MarketVersion *MarketVersion       `json:"marketVersion,omitempty"`
+	Async         *bool                `json:"async,omitempty"`
+}
+
+// ContainerUpdateOrders holds the parameters of the updateOrders operation.
+type ContainerUpdateOrders struct {
+	MarketID     string              `json:"marketId"`
+	Instructions []UpdateInstruction `json:"instructions"`
+	CustomerRef  string              `json:"customerRef,omitempty"`
 }
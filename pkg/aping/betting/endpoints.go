@@ -1,219 +1,154 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package betting
 
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-
-	"github.com/gustavooferreira/betfair/internal/utils"
-	"github.com/gustavooferreira/betfair/pkg/aping"
-)
-
-const ukBettingEndpoint = "https://api.betfair.com/exchange/betting/rest/v1.0/"
+const apiEndpoint = "https://api.betfair.com/exchange/betting/rest/v1.0/"
 
 const (
-	listMarketCatalogueEndpoint = ukBettingEndpoint + "listMarketCatalogue/"
-	listMarketBookEndpoint      = ukBettingEndpoint + "listMarketBook/"
-	placeOrdersEndpoint         = ukBettingEndpoint + "placeOrders/"
-	replaceOrdersEndpoint       = ukBettingEndpoint + "replaceOrders/"
-	cancelOrdersEndpoint        = ukBettingEndpoint + "cancelOrders/"
-	listClearedOrdersEndpoint   = ukBettingEndpoint + "listClearedOrders/"
-	listCurrentOrdersEndpoint   = ukBettingEndpoint + "listCurrentOrders/"
+	listEventTypesOperation          = "listEventTypes"
+	listCompetitionsOperation        = "listCompetitions"
+	listTimeRangesOperation          = "listTimeRanges"
+	listEventsOperation              = "listEvents"
+	listMarketTypesOperation         = "listMarketTypes"
+	listCountriesOperation           = "listCountries"
+	listVenuesOperation              = "listVenues"
+	listMarketCatalogueOperation     = "listMarketCatalogue"
+	listMarketBookOperation          = "listMarketBook"
+	listRunnerBookOperation          = "listRunnerBook"
+	listMarketProfitAndLossOperation = "listMarketProfitAndLoss"
+	listCurrentOrdersOperation       = "listCurrentOrders"
+	listClearedOrdersOperation       = "listClearedOrders"
+	placeOrdersOperation             = "placeOrders"
+	cancelOrdersOperation            = "cancelOrders"
+	replaceOrdersOperation           = "replaceOrders"
+	updateOrdersOperation            = "updateOrders"
 )
 
-type BettingAPI struct {
-	aping.BetfairAPI
+// ListEventTypes returns a list of Event Types (i.e. Sports) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListEventTypes(c ContainerListEventTypes) ([]EventTypeResult, error) {
+	var result []EventTypeResult
+	err := b.call(listEventTypesOperation, c, &result)
+	return result, err
 }
 
-func NewBettingAPI(bapi aping.BetfairAPI) BettingAPI {
-	bettingAPI := BettingAPI{bapi}
-	return bettingAPI
+// ListCompetitions returns a list of Competitions (i.e., World Cup 2013) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListCompetitions(c ContainerListCompetitions) ([]CompetitionResult, error) {
+	var result []CompetitionResult
+	err := b.call(listCompetitionsOperation, c, &result)
+	return result, err
 }
 
-// ListMarketCatalogue lists the market catalogue.
-// Note: listMarketCatalogue does not return markets that are CLOSED.
-func (b BettingAPI) ListMarketCatalogue(lrc ContainerListMarketCatalogue) ([]MarketCatalogue, error) {
-	lrcBytes, err := json.Marshal(lrc)
-	if err != nil {
-		return nil, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(lrcBytes)
-	response, err := b.sendRequest(listMarketCatalogueEndpoint, payload)
-	if err != nil {
-		return nil, err
-	}
-
-	mcs := []MarketCatalogue{}
-	err = json.Unmarshal(response, &mcs)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshalling response %w", err)
-	}
-
-	return mcs, nil
+// ListTimeRanges returns a list of time ranges in the granularity specified in the request (i.e. 3PM to 4PM, Aug 14th to Aug 15th) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListTimeRanges(c ContainerListTimeRanges) ([]TimeRangeResult, error) {
+	var result []TimeRangeResult
+	err := b.call(listTimeRangesOperation, c, &result)
+	return result, err
 }
 
-// ListMarketBook lists dynamic data about markets.
-// Calls to listMarketBook should be made up to a maximum of 5 times per second to a single marketId.
-func (b BettingAPI) ListMarketBook(clmb ContainerListMarketBook) ([]MarketBook, error) {
-	clmbBytes, err := json.Marshal(clmb)
-	if err != nil {
-		return nil, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(clmbBytes)
-	response, err := b.sendRequest(listMarketBookEndpoint, payload)
-	if err != nil {
-		return nil, err
-	}
-
-	mbs := []MarketBook{}
-	err = json.Unmarshal(response, &mbs)
-	if err != nil {
-		return nil, fmt.Errorf("error while unmarshalling response %w", err)
-	}
-
-	return mbs, nil
+// ListEvents returns a list of Events (i.e, Reading vs. Man United) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListEvents(c ContainerListEvents) ([]EventResult, error) {
+	var result []EventResult
+	err := b.call(listEventsOperation, c, &result)
+	return result, err
 }
 
-// PlaceOrders puts back/lay bets on the market.
-func (b BettingAPI) PlaceOrders(cpo ContainerPlaceOrders) (PlaceExecutionReport, error) {
-	per := PlaceExecutionReport{}
-
-	cpoBytes, err := json.Marshal(cpo)
-	if err != nil {
-		return PlaceExecutionReport{}, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(cpoBytes)
-	response, err := b.sendRequest(placeOrdersEndpoint, payload)
-	if err != nil {
-		return per, err
-	}
-
-	err = json.Unmarshal(response, &per)
-	if err != nil {
-		return per, fmt.Errorf("error while unmarshalling response %w", err)
-	}
-
-	return per, nil
+// ListMarketTypes returns a list of market types (i.e. MATCH_ODDS, NEXT_GOAL) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListMarketTypes(c ContainerListMarketTypes) ([]MarketTypeResult, error) {
+	var result []MarketTypeResult
+	err := b.call(listMarketTypesOperation, c, &result)
+	return result, err
 }
 
-// ReplaceOrders cancels bets followed by putting new bets on the market.
-func (b BettingAPI) ReplaceOrders(cro ContainerReplaceOrders) (ReplaceExecutionReport, error) {
-	rer := ReplaceExecutionReport{}
-
-	croBytes, err := json.Marshal(cro)
-	if err != nil {
-		return rer, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(croBytes)
-	response, err := b.sendRequest(replaceOrdersEndpoint, payload)
-	if err != nil {
-		return rer, err
-	}
-
-	err = json.Unmarshal(response, &rer)
-	if err != nil {
-		return ReplaceExecutionReport{}, fmt.Errorf("error while unmarshalling response %w", err)
-	}
+// ListCountries returns a list of Countries associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListCountries(c ContainerListCountries) ([]CountryCodeResult, error) {
+	var result []CountryCodeResult
+	err := b.call(listCountriesOperation, c, &result)
+	return result, err
+}
 
-	return rer, nil
+// ListVenues returns a list of Venues (i.e. Cheltenham, Ascot) associated with the markets selected by the MarketFilter.
+func (b BettingAPI) ListVenues(c ContainerListVenues) ([]VenueResult, error) {
+	var result []VenueResult
+	err := b.call(listVenuesOperation, c, &result)
+	return result, err
 }
 
-// CancelOrders cancels bets on the market.
-func (b BettingAPI) CancelOrders(cco ContainerCancelOrders) (CancelExecutionReport, error) {
-	cer := CancelExecutionReport{}
+// ListMarketCatalogue returns a list of information about published (ACTIVE/SUSPENDED) markets that does not change (or changes very rarely).
+// Note: listMarketCatalogue does not return markets that are CLOSED.
+func (b BettingAPI) ListMarketCatalogue(c ContainerListMarketCatalogue) ([]MarketCatalogue, error) {
+	var result []MarketCatalogue
+	err := b.call(listMarketCatalogueOperation, c, &result)
+	return result, err
+}
 
-	ccoBytes, err := json.Marshal(cco)
-	if err != nil {
-		return cer, fmt.Errorf("error while marshalling request %w", err)
-	}
+// ListMarketBook returns a list of dynamic data about markets.
+// Calls to listMarketBook should be made up to a maximum of 5 times per second to a single marketId.
+func (b BettingAPI) ListMarketBook(c ContainerListMarketBook) ([]MarketBook, error) {
+	var result []MarketBook
+	err := b.call(listMarketBookOperation, c, &result)
+	return result, err
+}
 
-	payload := bytes.NewBuffer(ccoBytes)
-	response, err := b.sendRequest(cancelOrdersEndpoint, payload)
-	if err != nil {
-		return cer, err
-	}
+// ListRunnerBook returns a list of dynamic data about a market and a specified runner.
+func (b BettingAPI) ListRunnerBook(c ContainerListRunnerBook) ([]MarketBook, error) {
+	var result []MarketBook
+	err := b.call(listRunnerBookOperation, c, &result)
+	return result, err
+}
 
-	err = json.Unmarshal(response, &cer)
-	if err != nil {
-		return cer, fmt.Errorf("error while unmarshalling response %w", err)
-	}
+// ListMarketProfitAndLoss retrieves profit and loss for a given list of OPEN markets.
+func (b BettingAPI) ListMarketProfitAndLoss(c ContainerListMarketProfitAndLoss) ([]MarketProfitAndLoss, error) {
+	var result []MarketProfitAndLoss
+	err := b.call(listMarketProfitAndLossOperation, c, &result)
+	return result, err
+}
 
-	return cer, nil
+// ListCurrentOrders returns a list of your current orders.
+func (b BettingAPI) ListCurrentOrders(c ContainerListCurrentOrders) (CurrentOrderSummaryReport, error) {
+	var result CurrentOrderSummaryReport
+	err := b.call(listCurrentOrdersOperation, c, &result)
+	return result, err
 }
 
 // ListClearedOrders returns a list of settled bets based on the bet status, ordered by settled date.
 // To retrieve more than 1000 records, you need to make use of the fromRecord and recordCount parameters.
 // By default the service will return all available data for the last 90 days.
-func (b BettingAPI) ListClearedOrders(clco ContainerListClearedOrders) (ClearedOrderSummaryReport, error) {
-	cosr := ClearedOrderSummaryReport{}
-
-	clcoBytes, err := json.Marshal(clco)
-	if err != nil {
-		return cosr, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(clcoBytes)
-	response, err := b.sendRequest(listClearedOrdersEndpoint, payload)
-	if err != nil {
-		return cosr, err
-	}
-
-	err = json.Unmarshal(response, &cosr)
-	if err != nil {
-		return cosr, fmt.Errorf("error while unmarshalling response %w", err)
-	}
-
-	return cosr, nil
+func (b BettingAPI) ListClearedOrders(c ContainerListClearedOrders) (ClearedOrderSummaryReport, error) {
+	var result ClearedOrderSummaryReport
+	err := b.call(listClearedOrdersOperation, c, &result)
+	return result, err
 }
 
-// ListClearedOrders returns a list of settled bets based on the bet status, ordered by settled date.
-func (b BettingAPI) ListCurrentOrders(clco ContainerListCurrentOrders) (CurrentOrderSummaryReport, error) {
-	cosr := CurrentOrderSummaryReport{}
-
-	clcoBytes, err := json.Marshal(clco)
-	if err != nil {
-		return cosr, fmt.Errorf("error while marshalling request %w", err)
-	}
-
-	payload := bytes.NewBuffer(clcoBytes)
-	response, err := b.sendRequest(listCurrentOrdersEndpoint, payload)
-	if err != nil {
-		return cosr, err
-	}
-
-	err = json.Unmarshal(response, &cosr)
-	if err != nil {
-		return cosr, fmt.Errorf("error while unmarshalling response %w", err)
+// PlaceOrders places new orders into a market.
+func (b BettingAPI) PlaceOrders(c ContainerPlaceOrders) (PlaceExecutionReport, error) {
+	var result PlaceExecutionReport
+	if err := b.validatePlaceOrders(c); err != nil {
+		return result, err
 	}
-
-	return cosr, nil
+	err := b.call(placeOrdersOperation, c, &result)
+	return result, err
 }
 
-func (b BettingAPI) sendRequest(url string, body io.Reader) ([]byte, error) {
-
-	respBody, err := utils.SendRequest(b.HttpClient, "POST", b.AppKey, b.SessionToken, url, body)
-
-	// Encapsulate error here!
-	if errB, ok := err.(*utils.BetfairAPIError); ok {
-		bapie := BetfairAPIError{}
-		err = json.Unmarshal([]byte(errB.Body), &bapie)
-		if err != nil {
-			return nil, fmt.Errorf("error while unmarshalling APINGException response %w", err)
-		}
+// CancelOrders cancels all bets, all bets on a market, or fully or partially cancels particular orders on a market.
+func (b BettingAPI) CancelOrders(c ContainerCancelOrders) (CancelExecutionReport, error) {
+	var result CancelExecutionReport
+	err := b.call(cancelOrdersOperation, c, &result)
+	return result, err
+}
 
-		return nil, &BettingAPIError{
-			ErrorCode:    bapie.Detail.APINGException.ErrorCode,
-			ErrorDetails: bapie.Detail.APINGException.ErrorDetails,
-			RequestUUID:  bapie.Detail.APINGException.RequestUUID,
-		}
-	} else if err != nil {
-		return nil, err
-	}
+// ReplaceOrders cancels bets followed by putting new bets on the market.
+// This operation is logically a bulk cancel followed by a bulk place.
+func (b BettingAPI) ReplaceOrders(c ContainerReplaceOrders) (ReplaceExecutionReport, error) {
+	var result ReplaceExecutionReport
+	if err := b.validateReplaceOrders(c); err != nil {
+		return result, err
+	}
+	err := b.call(replaceOrdersOperation, c, &result)
+	return result, err
+}
 
-	return respBody, nil
+// UpdateOrders updates non-exposure changing fields.
+func (b BettingAPI) UpdateOrders(c ContainerUpdateOrders) (UpdateExecutionReport, error) {
+	var result UpdateExecutionReport
+	err := b.call(updateOrdersOperation, c, &result)
+	return result, err
 }
@@ -11,7 +11,7 @@ import (
 
 func TestAPI(t *testing.T) {
 
-	bs := BettingAPI{aping.BetfairAPI{AppKey: "", SessionToken: ""}}
+	bs := NewBettingAPI(aping.BetfairAPI{AppKey: "", SessionToken: ""})
 
 	from := time.Date(2019, 8, 21, 0, 0, 0, 0, time.UTC)
 	to := time.Date(2019, 8, 21, 23, 55, 0, 0, time.UTC)
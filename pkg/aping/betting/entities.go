@@ -1,12 +1,15 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package betting
 
 import (
+	"github.com/gustavooferreira/betfair/pkg/decimal"
 	"time"
 )
 
+// MarketFilter restricts the markets returned by the listing operations.
 type MarketFilter struct {
 	TextQuery          string              `json:"textQuery,omitempty"`
-	ExchangeIDs        []string            `json:"exchangeIds,omitempty"` //NOTE: Deprecated
+	ExchangeIDs        []string            `json:"exchangeIds,omitempty"` // Deprecated
 	EventTypeIDs       []string            `json:"eventTypeIds,omitempty"`
 	EventIDs           []string            `json:"eventIds,omitempty"`
 	CompetitionIDs     []string            `json:"competitionIds,omitempty"`
@@ -23,217 +26,484 @@ type MarketFilter struct {
 	RaceTypes          []string            `json:"raceTypes,omitempty"`
 }
 
-type TimeRange struct {
-	From *time.Time `json:"from,omitempty"`
-	To   *time.Time `json:"to,omitempty"`
+// MarketCatalogue holds information about a market.
+type MarketCatalogue struct {
+	MarketID        string             `json:"marketId"`
+	MarketName      string             `json:"marketName"`
+	MarketStartTime *time.Time         `json:"marketStartTime,omitempty"`
+	Description     *MarketDescription `json:"description,omitempty"`
+	TotalMatched    *decimal.Money     `json:"totalMatched,omitempty"`
+	Runners         []RunnerCatalog    `json:"runners,omitempty"`
+	EventType       *EventType         `json:"eventType,omitempty"`
+	Competition     *Competition       `json:"competition,omitempty"`
+	Event           *Event             `json:"event,omitempty"`
 }
 
-type MarketCatalogue struct {
-	MarketID        string     `json:"marketId"`
-	MarketName      string     `json:"marketName"`
-	MarketStartTime *time.Time `json:"marketStartTime"`
-	// Description     string          `json:"description"`
-	TotalMatched float64         `json:"totalMatched"`
-	Runners      []RunnerCatalog `json:"runners"`
-	// EventType    string          `json:"eventType"`
-	// Competition  string          `json:"competition"`
-	Event Event `json:"event"`
+// MarketBook holds the dynamic data in a market.
+type MarketBook struct {
+	MarketID              string              `json:"marketId"`
+	IsMarketDataDelayed   bool                `json:"isMarketDataDelayed"`
+	Status                *MarketStatus       `json:"status,omitempty"`
+	BetDelay              *int                `json:"betDelay,omitempty"`
+	BSPReconciled         *bool               `json:"bspReconciled,omitempty"`
+	Complete              *bool               `json:"complete,omitempty"`
+	InPlay                *bool               `json:"inplay,omitempty"`
+	NumberOfWinners       *int                `json:"numberOfWinners,omitempty"`
+	NumberOfRunners       *int                `json:"numberOfRunners,omitempty"`
+	NumberOfActiveRunners *int                `json:"numberOfActiveRunners,omitempty"`
+	LastMatchTime         *time.Time          `json:"lastMatchTime,omitempty"`
+	TotalMatched          *decimal.Money      `json:"totalMatched,omitempty"`
+	TotalAvailable        *decimal.Money      `json:"totalAvailable,omitempty"`
+	CrossMatching         *bool               `json:"crossMatching,omitempty"`
+	RunnersVoidable       *bool               `json:"runnersVoidable,omitempty"`
+	Version               *int64              `json:"version,omitempty"`
+	Runners               []Runner            `json:"runners,omitempty"`
+	KeyLineDescription    *KeyLineDescription `json:"keyLineDescription,omitempty"`
 }
 
+// RunnerCatalog holds information about the runners (selections) in a market.
 type RunnerCatalog struct {
-	SelectionID  uint              `json:"selectionId"`
+	SelectionID  int64             `json:"selectionId"`
 	RunnerName   string            `json:"runnerName"`
 	Handicap     float64           `json:"handicap"`
-	SortPriority uint              `json:"sortPriority"`
-	Metadata     map[string]string `json:"metadata"`
+	SortPriority int               `json:"sortPriority"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
 }
 
+// Runner holds the dynamic data about runners in a market.
+type Runner struct {
+	SelectionID       int64              `json:"selectionId"`
+	Handicap          float64            `json:"handicap"`
+	Status            RunnerStatus       `json:"status"`
+	AdjustmentFactor  *float64           `json:"adjustmentFactor,omitempty"`
+	LastPriceTraded   *decimal.Price     `json:"lastPriceTraded,omitempty"`
+	TotalMatched      *decimal.Money     `json:"totalMatched,omitempty"`
+	RemovalDate       *time.Time         `json:"removalDate,omitempty"`
+	SP                *StartingPrices    `json:"sp,omitempty"`
+	Ex                *ExchangePrices    `json:"ex,omitempty"`
+	Orders            []Order            `json:"orders,omitempty"`
+	Matches           []Match            `json:"matches,omitempty"`
+	MatchesByStrategy map[string]Matches `json:"matchesByStrategy,omitempty"`
+}
+
+// StartingPrices holds information about the Betfair Starting Price.
+type StartingPrices struct {
+	NearPrice         *decimal.Price `json:"nearPrice,omitempty"`
+	FarPrice          *decimal.Price `json:"farPrice,omitempty"`
+	BackStakeTaken    []PriceSize    `json:"backStakeTaken,omitempty"`
+	LayLiabilityTaken []PriceSize    `json:"layLiabilityTaken,omitempty"`
+	ActualSP          *decimal.Price `json:"actualSP,omitempty"`
+}
+
+// ExchangePrices holds the prices available on the exchange.
+type ExchangePrices struct {
+	AvailableToBack []PriceSize `json:"availableToBack,omitempty"`
+	AvailableToLay  []PriceSize `json:"availableToLay,omitempty"`
+	TradedVolume    []PriceSize `json:"tradedVolume,omitempty"`
+}
+
+// Event holds information about an event.
 type Event struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	CountryCode string     `json:"countryCode"`
-	Timezone    string     `json:"timezone"`
-	Venue       string     `json:"venue"`
-	OpenDate    *time.Time `json:"openDate"`
+	ID          string     `json:"id,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	CountryCode string     `json:"countryCode,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	Venue       string     `json:"venue,omitempty"`
+	OpenDate    *time.Time `json:"openDate,omitempty"`
 }
 
-type BetfairAPIError struct {
-	Detail      BetfairDetailError `json:"detail"`
-	FaultCode   string             `json:"faultCode"`
-	FaultString string             `json:"faultstring"`
+// EventResult holds an event and the number of markets associated with it.
+type EventResult struct {
+	Event       *Event `json:"event,omitempty"`
+	MarketCount *int   `json:"marketCount,omitempty"`
 }
 
-type BetfairDetailError struct {
-	APINGException APINGException `json:"APINGException"`
-	ExceptionName  string         `json:"exceptionname"`
+// Competition holds information about a competition.
+type Competition struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
 }
 
-type APINGException struct {
-	ErrorCode    APINGExceptionCode `json:"errorCode"`
-	ErrorDetails string             `json:"errorDetails"`
-	RequestUUID  string             `json:"requestUUID"`
+// CompetitionResult holds a competition and the number of markets associated with it.
+type CompetitionResult struct {
+	Competition       *Competition `json:"competition,omitempty"`
+	MarketCount       *int         `json:"marketCount,omitempty"`
+	CompetitionRegion string       `json:"competitionRegion,omitempty"`
 }
 
-type MarketBook struct {
-	MarketID              string       `json:"marketId"`
-	Status                MarketStatus `json:"status"`
-	InPlay                bool         `json:"inplay"`
-	NumberOfWinners       uint         `json:"numberOfWinners"`
-	NumberOfRunners       uint         `json:"numberOfRunners"`
-	NumberOfActiveRunners uint         `json:"numberOfActiveRunners"`
-	LastMatchTime         time.Time    `json:"lastMatchTime"`
-	TotalMatched          float64      `json:"totalMatched"`
-	TotalAvailable        float64      `json:"totalAvailable"`
-	Version               uint         `json:"version"`
-	Runners               []Runner     `json:"runners"`
+// EventType holds information about an event type (sport).
+type EventType struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
 }
 
-type Runner struct {
-	SelectionID     uint           `json:"selectionId"`
-	Status          RunnerStatus   `json:"status"`
-	LastPriceTraded float64        `json:"lastPriceTraded"`
-	TotalMatched    float64        `json:"totalMatched"`
-	Ex              ExchangePrices `json:"ex"`
+// EventTypeResult holds an event type and the number of markets associated with it.
+type EventTypeResult struct {
+	EventType   *EventType `json:"eventType,omitempty"`
+	MarketCount *int       `json:"marketCount,omitempty"`
 }
 
-type ExchangePrices struct {
-	AvailableToBack []PriceSize `json:"availableToBack"`
-	AvailableToLay  []PriceSize `json:"availableToLay"`
-	TradedVolume    []PriceSize `json:"tradedVolume"`
+// MarketTypeResult holds a market type and the number of markets associated with it.
+type MarketTypeResult struct {
+	MarketType  string `json:"marketType,omitempty"`
+	MarketCount *int   `json:"marketCount,omitempty"`
 }
 
-type PriceSize struct {
-	Price float64 `json:"price"`
-	Size  float64 `json:"size"`
+// CountryCodeResult holds a country code and the number of markets associated with it.
+type CountryCodeResult struct {
+	CountryCode string `json:"countryCode,omitempty"`
+	MarketCount *int   `json:"marketCount,omitempty"`
 }
 
-type PlaceInstruction struct {
-	OrderType        OrderType  `json:"orderType"`
-	SelectionID      uint       `json:"selectionId"`
-	Side             Side       `json:"side"`
-	LimitOrder       LimitOrder `json:"limitOrder"`
-	CustomerOrderRef string     `json:"customerOrderRef"`
+// VenueResult holds a venue and the number of markets associated with it.
+type VenueResult struct {
+	Venue       string `json:"venue,omitempty"`
+	MarketCount *int   `json:"marketCount,omitempty"`
 }
 
-type LimitOrder struct {
-	Size            string          `json:"size"`
-	Price           string          `json:"price"`
-	PersistenceType PersistenceType `json:"persistenceType,omitempty"`
+// TimeRange defines a time window.
+type TimeRange struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
 }
 
-type PlaceExecutionReport struct {
-	Status             ExecutionReportStatus    `json:"status"`
-	ErrorCode          ExecutionReportErrorCode `json:"errorCode"`
-	MarketID           string                   `json:"marketId"`
-	InstructionReports []PlaceInstructionReport `json:"instructionReports"`
+// TimeRangeResult holds a time range and the number of markets associated with it.
+type TimeRangeResult struct {
+	TimeRange   *TimeRange `json:"timeRange,omitempty"`
+	MarketCount *int       `json:"marketCount,omitempty"`
 }
 
-type PlaceInstructionReport struct {
-	Status              InstructionReportStatus     `json:"status"`
-	ErrorCode           *InstructionReportErrorCode `json:"errorCode"`
-	OrderStatus         OrderStatus                 `json:"orderStatus"`
-	Instruction         PlaceInstructionR           `json:"instruction"`
-	BetID               *string                     `json:"betId"`
-	AveragePriceMatched *float64                    `json:"averagePriceMatched"`
-	SizeMatched         *float64                    `json:"sizeMatched"`
+// Order holds information about an order on a runner.
+type Order struct {
+	BetID               string          `json:"betId"`
+	OrderType           OrderType       `json:"orderType"`
+	Status              OrderStatus     `json:"status"`
+	PersistenceType     PersistenceType `json:"persistenceType"`
+	Side                Side            `json:"side"`
+	Price               decimal.Price   `json:"price"`
+	Size                decimal.Money   `json:"size"`
+	BSPLiability        decimal.Money   `json:"bspLiability"`
+	PlacedDate          time.Time       `json:"placedDate"`
+	AvgPriceMatched     *decimal.Price  `json:"avgPriceMatched,omitempty"`
+	SizeMatched         *decimal.Money  `json:"sizeMatched,omitempty"`
+	SizeRemaining       *decimal.Money  `json:"sizeRemaining,omitempty"`
+	SizeLapsed          *decimal.Money  `json:"sizeLapsed,omitempty"`
+	SizeCancelled       *decimal.Money  `json:"sizeCancelled,omitempty"`
+	SizeVoided          *decimal.Money  `json:"sizeVoided,omitempty"`
+	CustomerOrderRef    string          `json:"customerOrderRef,omitempty"`
+	CustomerStrategyRef string          `json:"customerStrategyRef,omitempty"`
 }
 
-type PlaceInstructionR struct {
-	OrderType   OrderType   `json:"orderType"`
-	SelectionID uint        `json:"selectionId"`
-	Side        Side        `json:"side"`
-	LimitOrder  LimitOrderR `json:"limitOrder"`
+// Match represents a fill (or rollup of fills) on an order.
+type Match struct {
+	BetID     string        `json:"betId,omitempty"`
+	MatchID   string        `json:"matchId,omitempty"`
+	Side      Side          `json:"side"`
+	Price     decimal.Price `json:"price"`
+	Size      decimal.Money `json:"size"`
+	MatchDate *time.Time    `json:"matchDate,omitempty"`
 }
 
-type LimitOrderR struct {
-	Size            float64         `json:"size"`
-	Price           float64         `json:"price"`
-	PersistenceType PersistenceType `json:"persistenceType,omitempty"`
+// Matches is a wrapper for a list of matches.
+type Matches struct {
+	Matches []Match `json:"matches,omitempty"`
 }
 
-type ReplaceInstruction struct {
-	BetID    string  `json:"betId"`
-	NewPrice float64 `json:"newPrice"`
+// MarketDescription holds the market definition.
+type MarketDescription struct {
+	PersistenceEnabled     bool                    `json:"persistenceEnabled"`
+	BSPMarket              bool                    `json:"bspMarket"`
+	MarketTime             time.Time               `json:"marketTime"`
+	SuspendTime            time.Time               `json:"suspendTime"`
+	SettleTime             *time.Time              `json:"settleTime,omitempty"`
+	BettingType            MarketBettingType       `json:"bettingType"`
+	TurnInPlayEnabled      bool                    `json:"turnInPlayEnabled"`
+	MarketType             string                  `json:"marketType"`
+	Regulator              string                  `json:"regulator"`
+	MarketBaseRate         float64                 `json:"marketBaseRate"`
+	DiscountAllowed        bool                    `json:"discountAllowed"`
+	Wallet                 string                  `json:"wallet,omitempty"`
+	Rules                  string                  `json:"rules,omitempty"`
+	RulesHasDate           *bool                   `json:"rulesHasDate,omitempty"`
+	EachWayDivisor         *float64                `json:"eachWayDivisor,omitempty"`
+	Clarifications         string                  `json:"clarifications,omitempty"`
+	LineRangeInfo          *MarketLineRangeInfo    `json:"lineRangeInfo,omitempty"`
+	RaceType               string                  `json:"raceType,omitempty"`
+	PriceLadderDescription *PriceLadderDescription `json:"priceLadderDescription,omitempty"`
 }
 
-type ReplaceExecutionReport struct {
-	Status             ExecutionReportStatus      `json:"status"`
-	ErrorCode          ExecutionReportErrorCode   `json:"errorCode"`
-	MarketID           string                     `json:"marketId"`
-	InstructionReports []ReplaceInstructionReport `json:"instructionReports"`
+// MarketLineRangeInfo holds the range information for LINE markets.
+type MarketLineRangeInfo struct {
+	MaxUnitValue float64 `json:"maxUnitValue"`
+	MinUnitValue float64 `json:"minUnitValue"`
+	Interval     float64 `json:"interval"`
+	MarketUnit   string  `json:"marketUnit"`
 }
 
-type ReplaceInstructionReport struct {
-	Status    InstructionReportStatus     `json:"status"`
-	ErrorCode *InstructionReportErrorCode `json:"errorCode"`
+// PriceLadderDescription describes the price ladder used by a market.
+type PriceLadderDescription struct {
+	Type PriceLadderType `json:"type"`
 }
 
-type CancelInstruction struct {
-	BetID         string   `json:"betId"`
-	SizeReduction *float64 `json:"sizeReduction"`
+// PriceSize holds a price and the size available or traded at that price.
+type PriceSize struct {
+	Price decimal.Price `json:"price"`
+	Size  decimal.Money `json:"size"`
 }
 
-type CancelExecutionReport struct {
-	Status             ExecutionReportStatus     `json:"status"`
-	ErrorCode          ExecutionReportErrorCode  `json:"errorCode"`
-	MarketID           string                    `json:"marketId"`
-	InstructionReports []CancelInstructionReport `json:"instructionReports"`
+// KeyLineDescription holds the current set of key line selections.
+type KeyLineDescription struct {
+	KeyLine []KeyLineSelection `json:"keyLine"`
 }
 
-type CancelInstructionReport struct {
-	Status        InstructionReportStatus     `json:"status"`
-	ErrorCode     *InstructionReportErrorCode `json:"errorCode"`
-	Instruction   CancelInstruction           `json:"instruction"`
-	SizeCancelled float64                     `json:"sizeCancelled"`
+// KeyLineSelection identifies a selection that is part of the key line.
+type KeyLineSelection struct {
+	SelectionID int64   `json:"selectionId"`
+	Handicap    float64 `json:"handicap"`
 }
 
+// ClearedOrderSummaryReport is the response of listClearedOrders.
 type ClearedOrderSummaryReport struct {
 	ClearedOrders []ClearedOrderSummary `json:"clearedOrders"`
 	MoreAvailable bool                  `json:"moreAvailable"`
 }
 
+// ClearedOrderSummary holds a summary of a settled order.
 type ClearedOrderSummary struct {
-	EventID             string          `json:"eventId"`
-	MarketID            string          `json:"marketId"`
-	SelectionID         uint            `json:"selectionId"`
-	BetID               string          `json:"betId"`
-	PlacedDate          *time.Time      `json:"placedDate"`
-	Side                Side            `json:"side"`
-	ItemDescription     ItemDescription `json:"itemDescription"`
-	BetOutcome          string          `json:"betOutcome"`
-	PriceRequested      float64         `json:"priceRequested"`
-	SettledDate         *time.Time      `json:"settledDate"`
-	LastMatchedDate     *time.Time      `json:"lastMatchedDate"`
-	BetCount            uint            `json:"betCount"`
-	Commission          float64         `json:"commission"`
-	PriceMatched        float64         `json:"priceMatched"`
-	PriceReduced        bool            `json:"priceReduced"`
-	SizeSettled         float64         `json:"sizeSettled"`
-	Profit              float64         `json:"profit"`
-	CustomerStrategyRef string          `json:"customerStrategyRef"`
+	EventTypeID         string           `json:"eventTypeId,omitempty"`
+	EventID             string           `json:"eventId,omitempty"`
+	MarketID            string           `json:"marketId,omitempty"`
+	SelectionID         *int64           `json:"selectionId,omitempty"`
+	Handicap            *float64         `json:"handicap,omitempty"`
+	BetID               string           `json:"betId,omitempty"`
+	PlacedDate          *time.Time       `json:"placedDate,omitempty"`
+	PersistenceType     *PersistenceType `json:"persistenceType,omitempty"`
+	OrderType           *OrderType       `json:"orderType,omitempty"`
+	Side                *Side            `json:"side,omitempty"`
+	ItemDescription     *ItemDescription `json:"itemDescription,omitempty"`
+	BetOutcome          string           `json:"betOutcome,omitempty"`
+	PriceRequested      *decimal.Price   `json:"priceRequested,omitempty"`
+	SettledDate         *time.Time       `json:"settledDate,omitempty"`
+	LastMatchedDate     *time.Time       `json:"lastMatchedDate,omitempty"`
+	BetCount            *int             `json:"betCount,omitempty"`
+	Commission          *decimal.Money   `json:"commission,omitempty"`
+	PriceMatched        *decimal.Price   `json:"priceMatched,omitempty"`
+	PriceReduced        *bool            `json:"priceReduced,omitempty"`
+	SizeSettled         *decimal.Money   `json:"sizeSettled,omitempty"`
+	Profit              *decimal.Money   `json:"profit,omitempty"`
+	SizeCancelled       *decimal.Money   `json:"sizeCancelled,omitempty"`
+	CustomerOrderRef    string           `json:"customerOrderRef,omitempty"`
+	CustomerStrategyRef string           `json:"customerStrategyRef,omitempty"`
 }
 
+// ItemDescription holds a human readable description of a settled order.
 type ItemDescription struct {
-	EventTypeDesc   string     `json:"eventTypeDesc"`
-	EventDesc       string     `json:"eventDesc"`
-	MarketDesc      string     `json:"marketDesc"`
-	MarketType      string     `json:"marketType"`
-	MarketStartTime *time.Time `json:"marketStartTime"`
-	RunnerDesc      string     `json:"runnerDesc"`
-	NumberOfWinners uint       `json:"numberOfWinners"`
-	EachWayDivisor  float64    `json:"eachWayDivisor"`
+	EventTypeDesc   string     `json:"eventTypeDesc,omitempty"`
+	EventDesc       string     `json:"eventDesc,omitempty"`
+	MarketDesc      string     `json:"marketDesc,omitempty"`
+	MarketType      string     `json:"marketType,omitempty"`
+	MarketStartTime *time.Time `json:"marketStartTime,omitempty"`
+	RunnerDesc      string     `json:"runnerDesc,omitempty"`
+	NumberOfWinners *int       `json:"numberOfWinners,omitempty"`
+	EachWayDivisor  *float64   `json:"eachWayDivisor,omitempty"`
 }
 
+// RunnerID uniquely identifies a runner in a market.
+type RunnerID struct {
+	MarketID    string   `json:"marketId"`
+	SelectionID int64    `json:"selectionId"`
+	Handicap    *float64 `json:"handicap,omitempty"`
+}
+
+// CurrentOrderSummaryReport is the response of listCurrentOrders.
 type CurrentOrderSummaryReport struct {
 	CurrentOrders []CurrentOrderSummary `json:"currentOrders"`
 	MoreAvailable bool                  `json:"moreAvailable"`
 }
 
+// CurrentOrderSummary holds a summary of a current order.
 type CurrentOrderSummary struct {
-	BetID  string      `json:"betId"`
-	Status OrderStatus `json:"status"`
+	BetID               string          `json:"betId"`
+	MarketID            string          `json:"marketId"`
+	SelectionID         int64           `json:"selectionId"`
+	Handicap            float64         `json:"handicap"`
+	PriceSize           PriceSize       `json:"priceSize"`
+	BSPLiability        decimal.Money   `json:"bspLiability"`
+	Side                Side            `json:"side"`
+	Status              OrderStatus     `json:"status"`
+	PersistenceType     PersistenceType `json:"persistenceType"`
+	OrderType           OrderType       `json:"orderType"`
+	PlacedDate          time.Time       `json:"placedDate"`
+	MatchedDate         *time.Time      `json:"matchedDate,omitempty"`
+	AveragePriceMatched *decimal.Price  `json:"averagePriceMatched,omitempty"`
+	SizeMatched         *decimal.Money  `json:"sizeMatched,omitempty"`
+	SizeRemaining       *decimal.Money  `json:"sizeRemaining,omitempty"`
+	SizeLapsed          *decimal.Money  `json:"sizeLapsed,omitempty"`
+	SizeCancelled       *decimal.Money  `json:"sizeCancelled,omitempty"`
+	SizeVoided          *decimal.Money  `json:"sizeVoided,omitempty"`
+	RegulatorAuthCode   string          `json:"regulatorAuthCode,omitempty"`
+	RegulatorCode       string          `json:"regulatorCode,omitempty"`
+	CustomerOrderRef    string          `json:"customerOrderRef,omitempty"`
+	CustomerStrategyRef string          `json:"customerStrategyRef,omitempty"`
+}
+
+// PlaceInstruction describes a single order to be placed.
+type PlaceInstruction struct {
+	OrderType          OrderType           `json:"orderType"`
+	SelectionID        int64               `json:"selectionId"`
+	Handicap           *float64            `json:"handicap,omitempty"`
+	Side               Side                `json:"side"`
+	LimitOrder         *LimitOrder         `json:"limitOrder,omitempty"`
+	LimitOnCloseOrder  *LimitOnCloseOrder  `json:"limitOnCloseOrder,omitempty"`
+	MarketOnCloseOrder *MarketOnCloseOrder `json:"marketOnCloseOrder,omitempty"`
+	CustomerOrderRef   string              `json:"customerOrderRef,omitempty"`
+}
+
+// PlaceExecutionReport is the response of placeOrders.
+type PlaceExecutionReport struct {
+	CustomerRef        string                    `json:"customerRef,omitempty"`
+	Status             ExecutionReportStatus     `json:"status"`
+	ErrorCode          *ExecutionReportErrorCode `json:"errorCode,omitempty"`
+	MarketID           string                    `json:"marketId,omitempty"`
+	InstructionReports []PlaceInstructionReport  `json:"instructionReports,omitempty"`
+}
+
+// LimitOrder places a new LIMIT order (simple exchange bet for immediate execution).
+type LimitOrder struct {
+	Size            decimal.Money   `json:"size"`
+	Price           decimal.Price   `json:"price"`
+	PersistenceType PersistenceType `json:"persistenceType"`
+	TimeInForce     *TimeInForce    `json:"timeInForce,omitempty"`
+	MinFillSize     *decimal.Money  `json:"minFillSize,omitempty"`
+	BetTargetType   *BetTargetType  `json:"betTargetType,omitempty"`
+	BetTargetSize   *decimal.Money  `json:"betTargetSize,omitempty"`
+}
+
+// LimitOnCloseOrder places a new LIMIT_ON_CLOSE bet.
+type LimitOnCloseOrder struct {
+	Liability decimal.Money `json:"liability"`
+	Price     decimal.Price `json:"price"`
+}
+
+// MarketOnCloseOrder places a new MARKET_ON_CLOSE bet.
+type MarketOnCloseOrder struct {
+	Liability decimal.Money `json:"liability"`
+}
+
+// PlaceInstructionReport reports the outcome of a single PlaceInstruction.
+type PlaceInstructionReport struct {
+	Status              InstructionReportStatus     `json:"status"`
+	ErrorCode           *InstructionReportErrorCode `json:"errorCode,omitempty"`
+	OrderStatus         *OrderStatus                `json:"orderStatus,omitempty"`
+	Instruction         PlaceInstruction            `json:"instruction"`
+	BetID               string                      `json:"betId,omitempty"`
+	PlacedDate          *time.Time                  `json:"placedDate,omitempty"`
+	AveragePriceMatched *decimal.Price              `json:"averagePriceMatched,omitempty"`
+	SizeMatched         *decimal.Money              `json:"sizeMatched,omitempty"`
+}
+
+// CancelInstruction describes a full or partial cancellation of an order.
+type CancelInstruction struct {
+	BetID         string         `json:"betId"`
+	SizeReduction *decimal.Money `json:"sizeReduction,omitempty"`
+}
+
+// CancelExecutionReport is the response of cancelOrders.
+type CancelExecutionReport struct {
+	CustomerRef        string                    `json:"customerRef,omitempty"`
+	Status             ExecutionReportStatus     `json:"status"`
+	ErrorCode          *ExecutionReportErrorCode `json:"errorCode,omitempty"`
+	MarketID           string                    `json:"marketId,omitempty"`
+	InstructionReports []CancelInstructionReport `json:"instructionReports,omitempty"`
+}
+
+// CancelInstructionReport reports the outcome of a single CancelInstruction.
+type CancelInstructionReport struct {
+	Status        InstructionReportStatus     `json:"status"`
+	ErrorCode     *InstructionReportErrorCode `json:"errorCode,omitempty"`
+	Instruction   *CancelInstruction          `json:"instruction,omitempty"`
+	SizeCancelled decimal.Money               `json:"sizeCancelled"`
+	CancelledDate *time.Time                  `json:"cancelledDate,omitempty"`
 }
 
+// ReplaceInstruction describes an order to be cancelled and placed again at a new price.
+type ReplaceInstruction struct {
+	BetID    string        `json:"betId"`
+	NewPrice decimal.Price `json:"newPrice"`
+}
+
+// ReplaceExecutionReport is the response of replaceOrders.
+type ReplaceExecutionReport struct {
+	CustomerRef        string                     `json:"customerRef,omitempty"`
+	Status             ExecutionReportStatus      `json:"status"`
+	ErrorCode          *ExecutionReportErrorCode  `json:"errorCode,omitempty"`
+	MarketID           string                     `json:"marketId,omitempty"`
+	InstructionReports []ReplaceInstructionReport `json:"instructionReports,omitempty"`
+}
+
+// ReplaceInstructionReport reports the outcome of a single ReplaceInstruction.
+type ReplaceInstructionReport struct {
+	Status                  InstructionReportStatus     `json:"status"`
+	ErrorCode               *InstructionReportErrorCode `json:"errorCode,omitempty"`
+	CancelInstructionReport *CancelInstructionReport    `json:"cancelInstructionReport,omitempty"`
+	PlaceInstructionReport  *PlaceInstructionReport     `json:"placeInstructionReport,omitempty"`
+}
+
+// UpdateInstruction describes a change to the persistence type of an order.
+type UpdateInstruction struct {
+	BetID              string          `json:"betId"`
+	NewPersistenceType PersistenceType `json:"newPersistenceType"`
+}
+
+// UpdateExecutionReport is the response of updateOrders.
+type UpdateExecutionReport struct {
+	CustomerRef        string                    `json:"customerRef,omitempty"`
+	Status             ExecutionReportStatus     `json:"status"`
+	ErrorCode          *ExecutionReportErrorCode `json:"errorCode,omitempty"`
+	MarketID           string                    `json:"marketId,omitempty"`
+	InstructionReports []UpdateInstructionReport `json:"instructionReports,omitempty"`
+}
+
+// UpdateInstructionReport reports the outcome of a single UpdateInstruction.
+type UpdateInstructionReport struct {
+	Status      InstructionReportStatus     `json:"status"`
+	ErrorCode   *InstructionReportErrorCode `json:"errorCode,omitempty"`
+	Instruction UpdateInstruction           `json:"instruction"`
+}
+
+// PriceProjection selects the price data returned by listMarketBook.
 type PriceProjection struct {
-	PriceData []PriceData `json:"priceData"`
+	PriceData             []PriceData            `json:"priceData,omitempty"`
+	ExBestOffersOverrides *ExBestOffersOverrides `json:"exBestOffersOverrides,omitempty"`
+	Virtualise            *bool                  `json:"virtualise,omitempty"`
+	RolloverStakes        *bool                  `json:"rolloverStakes,omitempty"`
+}
+
+// ExBestOffersOverrides overrides the defaults used when EX_BEST_OFFERS is selected.
+type ExBestOffersOverrides struct {
+	BestPricesDepth          *int           `json:"bestPricesDepth,omitempty"`
+	RollupModel              *RollupModel   `json:"rollupModel,omitempty"`
+	RollupLimit              *int           `json:"rollupLimit,omitempty"`
+	RollupLiabilityThreshold *decimal.Money `json:"rollupLiabilityThreshold,omitempty"`
+	RollupLiabilityFactor    *int           `json:"rollupLiabilityFactor,omitempty"`
+}
+
+// MarketProfitAndLoss holds the profit and loss of a market.
+type MarketProfitAndLoss struct {
+	MarketID          string                `json:"marketId,omitempty"`
+	CommissionApplied *decimal.Money        `json:"commissionApplied,omitempty"`
+	ProfitAndLosses   []RunnerProfitAndLoss `json:"profitAndLosses,omitempty"`
+}
+
+// RunnerProfitAndLoss holds the profit and loss of a runner.
+type RunnerProfitAndLoss struct {
+	SelectionID *int64         `json:"selectionId,omitempty"`
+	IfWin       *decimal.Money `json:"ifWin,omitempty"`
+	IfLose      *decimal.Money `json:"ifLose,omitempty"`
+	IfPlace     *decimal.Money `json:"ifPlace,omitempty"`
+}
+
+// MarketVersion makes an order only executable if the market version matches.
+type MarketVersion struct {
+	Version *int64 `json:"version,omitempty"`
 }
@@ -5,13 +5,86 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
+// tolerantEnums sets on/off the tolerant mode when unmarshalling enums (0 - False | 1 - True)
+var tolerantEnums uint32
+
+// SetTolerantEnums turns on/off the tolerant mode when unmarshalling enums.
+// By default, unmarshalling an unknown enum value fails.
+// In tolerant mode, unknown values are decoded into an "Unknown" enum value (IsUnknown returns true)
+// that preserves the raw string, which is returned by String() and used when marshalling it back.
+// Up to maxUnknownEnums raw strings are kept per enum type, the unknown values received after that are all decoded
+// into the same value, whose String() is empty and which can't be marshalled.
+func SetTolerantEnums(tolerant bool) {
+	if tolerant {
+		atomic.StoreUint32(&tolerantEnums, 1)
+	} else {
+		atomic.StoreUint32(&tolerantEnums, 0)
+	}
+}
+
+// maxUnknownEnums is the maximum number of unknown raw strings kept per enum type
+const maxUnknownEnums = 64
+
+// unknownEnumOverflow is the value given to the unknown raw strings received once the registry is full
+const unknownEnumOverflow = -(maxUnknownEnums + 1)
+
+// unknownEnumsRegistry maps the unknown raw strings of an enum type to negative enum values and back.
+// It's thread safe!
+type unknownEnumsRegistry struct {
+	mu       sync.RWMutex
+	toValue  map[string]int
+	toString map[int]string
+}
+
+func newUnknownEnumsRegistry() *unknownEnumsRegistry {
+	return &unknownEnumsRegistry{toValue: map[string]int{}, toString: map[int]string{}}
+}
+
+// register returns the enum value assigned to the raw string, assigning a new one if needed.
+// Returns unknownEnumOverflow when the registry is full.
+func (uer *unknownEnumsRegistry) register(raw string) int {
+	uer.mu.Lock()
+	defer uer.mu.Unlock()
+
+	value, ok := uer.toValue[raw]
+	if !ok {
+		if len(uer.toValue) >= maxUnknownEnums {
+			return unknownEnumOverflow
+		}
+
+		value = -(len(uer.toValue) + 1)
+		uer.toValue[raw] = value
+		uer.toString[value] = raw
+	}
+
+	return value
+}
+
+// lookup returns the raw string assigned to an unknown enum value.
+// The raw string of unknownEnumOverflow is empty.
+func (uer *unknownEnumsRegistry) lookup(value int) (string, bool) {
+	if value == unknownEnumOverflow {
+		return "", true
+	}
+
+	uer.mu.RLock()
+	defer uer.mu.RUnlock()
+
+	raw, ok := uer.toString[value]
+	return raw, ok
+}
+
 // MarketProjection ENUM
 
 type MarketProjection int
 
+// marketProjectionUnknown keeps track of the MarketProjection values received that are not known by this library
+var marketProjectionUnknown = newUnknownEnumsRegistry()
+
 const (
 	MarketProjection_Competition MarketProjection = iota + 1
 	MarketProjection_Event
@@ -23,7 +96,12 @@ const (
 )
 
 func (mp MarketProjection) String() string {
-	return marketProjectionToString[mp]
+	if elem, ok := marketProjectionToString[mp]; ok {
+		return elem
+	}
+
+	raw, _ := marketProjectionUnknown.lookup(int(mp))
+	return raw
 }
 
 var marketProjectionToString = map[MarketProjection]string{
@@ -46,14 +124,67 @@ var marketProjectionToEnum = map[string]MarketProjection{
 	"RUNNER_METADATA":    MarketProjection_RunnerMetadata,
 }
 
+// MarketProjectionValues returns all the known values of the MarketProjection enum
+func MarketProjectionValues() []MarketProjection {
+	return []MarketProjection{
+		MarketProjection_Competition,
+		MarketProjection_Event,
+		MarketProjection_EventType,
+		MarketProjection_MarketStartTime,
+		MarketProjection_MarketDescription,
+		MarketProjection_RunnerDescription,
+		MarketProjection_RunnerMetadata,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (mp MarketProjection) IsValid() bool {
+	_, ok := marketProjectionToString[mp]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (mp MarketProjection) IsUnknown() bool {
+	_, ok := marketProjectionUnknown.lookup(int(mp))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (mp MarketProjection) MarshalText() ([]byte, error) {
+	elem, ok := marketProjectionToString[mp]
+	if !ok {
+		elem, ok = marketProjectionUnknown.lookup(int(mp))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MarketProjection enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (mp *MarketProjection) UnmarshalText(data []byte) error {
+	result, ok := marketProjectionToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MarketProjection enum value")
+		}
+		result = MarketProjection(marketProjectionUnknown.register(string(data)))
+	}
+
+	*mp = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (mp MarketProjection) MarshalJSON() ([]byte, error) {
-	elem, ok := marketProjectionToString[mp]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := mp.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MarketProjection enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -64,19 +195,16 @@ func (mp *MarketProjection) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := marketProjectionToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MarketProjection enum value")
-	}
-
-	*mp = result
-	return nil
+	return mp.UnmarshalText([]byte(j))
 }
 
 // PriceData ENUM
 
 type PriceData int
 
+// priceDataUnknown keeps track of the PriceData values received that are not known by this library
+var priceDataUnknown = newUnknownEnumsRegistry()
+
 const (
 	PriceData_SpAvailable PriceData = iota + 1
 	PriceData_SpTraded
@@ -86,7 +214,12 @@ const (
 )
 
 func (pd PriceData) String() string {
-	return priceDataToString[pd]
+	if elem, ok := priceDataToString[pd]; ok {
+		return elem
+	}
+
+	raw, _ := priceDataUnknown.lookup(int(pd))
+	return raw
 }
 
 var priceDataToString = map[PriceData]string{
@@ -105,14 +238,65 @@ var priceDataToEnum = map[string]PriceData{
 	"EX_TRADED":      PriceData_ExTraded,
 }
 
+// PriceDataValues returns all the known values of the PriceData enum
+func PriceDataValues() []PriceData {
+	return []PriceData{
+		PriceData_SpAvailable,
+		PriceData_SpTraded,
+		PriceData_ExBestOffers,
+		PriceData_ExAllOffers,
+		PriceData_ExTraded,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (pd PriceData) IsValid() bool {
+	_, ok := priceDataToString[pd]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (pd PriceData) IsUnknown() bool {
+	_, ok := priceDataUnknown.lookup(int(pd))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (pd PriceData) MarshalText() ([]byte, error) {
+	elem, ok := priceDataToString[pd]
+	if !ok {
+		elem, ok = priceDataUnknown.lookup(int(pd))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PriceData enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (pd *PriceData) UnmarshalText(data []byte) error {
+	result, ok := priceDataToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PriceData enum value")
+		}
+		result = PriceData(priceDataUnknown.register(string(data)))
+	}
+
+	*pd = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (pd PriceData) MarshalJSON() ([]byte, error) {
-	elem, ok := priceDataToString[pd]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := pd.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PriceData enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -123,19 +307,16 @@ func (pd *PriceData) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := priceDataToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PriceData enum value")
-	}
-
-	*pd = result
-	return nil
+	return pd.UnmarshalText([]byte(j))
 }
 
 // MatchProjection ENUM
 
 type MatchProjection int
 
+// matchProjectionUnknown keeps track of the MatchProjection values received that are not known by this library
+var matchProjectionUnknown = newUnknownEnumsRegistry()
+
 const (
 	MatchProjection_NoRollup MatchProjection = iota + 1
 	MatchProjection_RolledUpByPrice
@@ -143,7 +324,12 @@ const (
 )
 
 func (mp MatchProjection) String() string {
-	return matchProjectionToString[mp]
+	if elem, ok := matchProjectionToString[mp]; ok {
+		return elem
+	}
+
+	raw, _ := matchProjectionUnknown.lookup(int(mp))
+	return raw
 }
 
 var matchProjectionToString = map[MatchProjection]string{
@@ -158,14 +344,63 @@ var matchProjectionToEnum = map[string]MatchProjection{
 	"ROLLED_UP_BY_AVG_PRICE": MatchProjection_RolledUpByAvgPrice,
 }
 
+// MatchProjectionValues returns all the known values of the MatchProjection enum
+func MatchProjectionValues() []MatchProjection {
+	return []MatchProjection{
+		MatchProjection_NoRollup,
+		MatchProjection_RolledUpByPrice,
+		MatchProjection_RolledUpByAvgPrice,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (mp MatchProjection) IsValid() bool {
+	_, ok := matchProjectionToString[mp]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (mp MatchProjection) IsUnknown() bool {
+	_, ok := matchProjectionUnknown.lookup(int(mp))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (mp MatchProjection) MarshalText() ([]byte, error) {
+	elem, ok := matchProjectionToString[mp]
+	if !ok {
+		elem, ok = matchProjectionUnknown.lookup(int(mp))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MatchProjection enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (mp *MatchProjection) UnmarshalText(data []byte) error {
+	result, ok := matchProjectionToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MatchProjection enum value")
+		}
+		result = MatchProjection(matchProjectionUnknown.register(string(data)))
+	}
+
+	*mp = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (mp MatchProjection) MarshalJSON() ([]byte, error) {
-	elem, ok := matchProjectionToString[mp]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := mp.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MatchProjection enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -176,19 +411,16 @@ func (mp *MatchProjection) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := matchProjectionToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MatchProjection enum value")
-	}
-
-	*mp = result
-	return nil
+	return mp.UnmarshalText([]byte(j))
 }
 
 // OrderProjection ENUM
 
 type OrderProjection int
 
+// orderProjectionUnknown keeps track of the OrderProjection values received that are not known by this library
+var orderProjectionUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderProjection_All OrderProjection = iota + 1
 	OrderProjection_Executable
@@ -196,7 +428,12 @@ const (
 )
 
 func (op OrderProjection) String() string {
-	return orderProjectionToString[op]
+	if elem, ok := orderProjectionToString[op]; ok {
+		return elem
+	}
+
+	raw, _ := orderProjectionUnknown.lookup(int(op))
+	return raw
 }
 
 var orderProjectionToString = map[OrderProjection]string{
@@ -211,14 +448,63 @@ var orderProjectionToEnum = map[string]OrderProjection{
 	"EXECUTION_COMPLETE": OrderProjection_ExecutionComplete,
 }
 
+// OrderProjectionValues returns all the known values of the OrderProjection enum
+func OrderProjectionValues() []OrderProjection {
+	return []OrderProjection{
+		OrderProjection_All,
+		OrderProjection_Executable,
+		OrderProjection_ExecutionComplete,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (op OrderProjection) IsValid() bool {
+	_, ok := orderProjectionToString[op]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (op OrderProjection) IsUnknown() bool {
+	_, ok := orderProjectionUnknown.lookup(int(op))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (op OrderProjection) MarshalText() ([]byte, error) {
+	elem, ok := orderProjectionToString[op]
+	if !ok {
+		elem, ok = orderProjectionUnknown.lookup(int(op))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderProjection enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (op *OrderProjection) UnmarshalText(data []byte) error {
+	result, ok := orderProjectionToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderProjection enum value")
+		}
+		result = OrderProjection(orderProjectionUnknown.register(string(data)))
+	}
+
+	*op = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (op OrderProjection) MarshalJSON() ([]byte, error) {
-	elem, ok := orderProjectionToString[op]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := op.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderProjection enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -229,19 +515,16 @@ func (op *OrderProjection) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderProjectionToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderProjection enum value")
-	}
-
-	*op = result
-	return nil
+	return op.UnmarshalText([]byte(j))
 }
 
 // MarketStatus ENUM
 
 type MarketStatus int
 
+// marketStatusUnknown keeps track of the MarketStatus values received that are not known by this library
+var marketStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	MarketStatus_Inactive MarketStatus = iota + 1
 	MarketStatus_Open
@@ -250,7 +533,12 @@ const (
 )
 
 func (ms MarketStatus) String() string {
-	return marketStatusToString[ms]
+	if elem, ok := marketStatusToString[ms]; ok {
+		return elem
+	}
+
+	raw, _ := marketStatusUnknown.lookup(int(ms))
+	return raw
 }
 
 var marketStatusToString = map[MarketStatus]string{
@@ -267,14 +555,64 @@ var marketStatusToEnum = map[string]MarketStatus{
 	"CLOSED":    MarketStatus_Closed,
 }
 
+// MarketStatusValues returns all the known values of the MarketStatus enum
+func MarketStatusValues() []MarketStatus {
+	return []MarketStatus{
+		MarketStatus_Inactive,
+		MarketStatus_Open,
+		MarketStatus_Suspended,
+		MarketStatus_Closed,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ms MarketStatus) IsValid() bool {
+	_, ok := marketStatusToString[ms]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ms MarketStatus) IsUnknown() bool {
+	_, ok := marketStatusUnknown.lookup(int(ms))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ms MarketStatus) MarshalText() ([]byte, error) {
+	elem, ok := marketStatusToString[ms]
+	if !ok {
+		elem, ok = marketStatusUnknown.lookup(int(ms))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MarketStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ms *MarketStatus) UnmarshalText(data []byte) error {
+	result, ok := marketStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MarketStatus enum value")
+		}
+		result = MarketStatus(marketStatusUnknown.register(string(data)))
+	}
+
+	*ms = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ms MarketStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := marketStatusToString[ms]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ms.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MarketStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -285,19 +623,16 @@ func (ms *MarketStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := marketStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MarketStatus enum value")
-	}
-
-	*ms = result
-	return nil
+	return ms.UnmarshalText([]byte(j))
 }
 
 // RunnerStatus ENUM
 
 type RunnerStatus int
 
+// runnerStatusUnknown keeps track of the RunnerStatus values received that are not known by this library
+var runnerStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	RunnerStatus_Active RunnerStatus = iota + 1
 	RunnerStatus_Winner
@@ -309,7 +644,12 @@ const (
 )
 
 func (rs RunnerStatus) String() string {
-	return runnerStatusToString[rs]
+	if elem, ok := runnerStatusToString[rs]; ok {
+		return elem
+	}
+
+	raw, _ := runnerStatusUnknown.lookup(int(rs))
+	return raw
 }
 
 var runnerStatusToString = map[RunnerStatus]string{
@@ -332,14 +672,67 @@ var runnerStatusToEnum = map[string]RunnerStatus{
 	"HIDDEN":         RunnerStatus_Hidden,
 }
 
+// RunnerStatusValues returns all the known values of the RunnerStatus enum
+func RunnerStatusValues() []RunnerStatus {
+	return []RunnerStatus{
+		RunnerStatus_Active,
+		RunnerStatus_Winner,
+		RunnerStatus_Loser,
+		RunnerStatus_Placed,
+		RunnerStatus_RemovedVacant,
+		RunnerStatus_Removed,
+		RunnerStatus_Hidden,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (rs RunnerStatus) IsValid() bool {
+	_, ok := runnerStatusToString[rs]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (rs RunnerStatus) IsUnknown() bool {
+	_, ok := runnerStatusUnknown.lookup(int(rs))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (rs RunnerStatus) MarshalText() ([]byte, error) {
+	elem, ok := runnerStatusToString[rs]
+	if !ok {
+		elem, ok = runnerStatusUnknown.lookup(int(rs))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal RunnerStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (rs *RunnerStatus) UnmarshalText(data []byte) error {
+	result, ok := runnerStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching RunnerStatus enum value")
+		}
+		result = RunnerStatus(runnerStatusUnknown.register(string(data)))
+	}
+
+	*rs = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (rs RunnerStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := runnerStatusToString[rs]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := rs.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal RunnerStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -350,19 +743,16 @@ func (rs *RunnerStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := runnerStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching RunnerStatus enum value")
-	}
-
-	*rs = result
-	return nil
+	return rs.UnmarshalText([]byte(j))
 }
 
 // TimeGranularity ENUM
 
 type TimeGranularity int
 
+// timeGranularityUnknown keeps track of the TimeGranularity values received that are not known by this library
+var timeGranularityUnknown = newUnknownEnumsRegistry()
+
 const (
 	TimeGranularity_Days TimeGranularity = iota + 1
 	TimeGranularity_Hours
@@ -370,7 +760,12 @@ const (
 )
 
 func (tg TimeGranularity) String() string {
-	return timeGranularityToString[tg]
+	if elem, ok := timeGranularityToString[tg]; ok {
+		return elem
+	}
+
+	raw, _ := timeGranularityUnknown.lookup(int(tg))
+	return raw
 }
 
 var timeGranularityToString = map[TimeGranularity]string{
@@ -385,14 +780,63 @@ var timeGranularityToEnum = map[string]TimeGranularity{
 	"MINUTES": TimeGranularity_Minutes,
 }
 
+// TimeGranularityValues returns all the known values of the TimeGranularity enum
+func TimeGranularityValues() []TimeGranularity {
+	return []TimeGranularity{
+		TimeGranularity_Days,
+		TimeGranularity_Hours,
+		TimeGranularity_Minutes,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (tg TimeGranularity) IsValid() bool {
+	_, ok := timeGranularityToString[tg]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (tg TimeGranularity) IsUnknown() bool {
+	_, ok := timeGranularityUnknown.lookup(int(tg))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (tg TimeGranularity) MarshalText() ([]byte, error) {
+	elem, ok := timeGranularityToString[tg]
+	if !ok {
+		elem, ok = timeGranularityUnknown.lookup(int(tg))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal TimeGranularity enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (tg *TimeGranularity) UnmarshalText(data []byte) error {
+	result, ok := timeGranularityToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching TimeGranularity enum value")
+		}
+		result = TimeGranularity(timeGranularityUnknown.register(string(data)))
+	}
+
+	*tg = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (tg TimeGranularity) MarshalJSON() ([]byte, error) {
-	elem, ok := timeGranularityToString[tg]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := tg.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal TimeGranularity enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -403,26 +847,28 @@ func (tg *TimeGranularity) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := timeGranularityToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching TimeGranularity enum value")
-	}
-
-	*tg = result
-	return nil
+	return tg.UnmarshalText([]byte(j))
 }
 
 // Side ENUM
 
 type Side int
 
+// sideUnknown keeps track of the Side values received that are not known by this library
+var sideUnknown = newUnknownEnumsRegistry()
+
 const (
 	Side_Back Side = iota + 1
 	Side_Lay
 )
 
 func (s Side) String() string {
-	return sideToString[s]
+	if elem, ok := sideToString[s]; ok {
+		return elem
+	}
+
+	raw, _ := sideUnknown.lookup(int(s))
+	return raw
 }
 
 var sideToString = map[Side]string{
@@ -435,14 +881,62 @@ var sideToEnum = map[string]Side{
 	"LAY":  Side_Lay,
 }
 
+// SideValues returns all the known values of the Side enum
+func SideValues() []Side {
+	return []Side{
+		Side_Back,
+		Side_Lay,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (s Side) IsValid() bool {
+	_, ok := sideToString[s]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (s Side) IsUnknown() bool {
+	_, ok := sideUnknown.lookup(int(s))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (s Side) MarshalText() ([]byte, error) {
+	elem, ok := sideToString[s]
+	if !ok {
+		elem, ok = sideUnknown.lookup(int(s))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal Side enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (s *Side) UnmarshalText(data []byte) error {
+	result, ok := sideToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching Side enum value")
+		}
+		result = Side(sideUnknown.register(string(data)))
+	}
+
+	*s = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (s Side) MarshalJSON() ([]byte, error) {
-	elem, ok := sideToString[s]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := s.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal Side enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -453,19 +947,16 @@ func (s *Side) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := sideToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching Side enum value")
-	}
-
-	*s = result
-	return nil
+	return s.UnmarshalText([]byte(j))
 }
 
 // OrderStatus ENUM
 
 type OrderStatus int
 
+// orderStatusUnknown keeps track of the OrderStatus values received that are not known by this library
+var orderStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderStatus_Pending OrderStatus = iota + 1
 	OrderStatus_ExecutionComplete
@@ -474,7 +965,12 @@ const (
 )
 
 func (os OrderStatus) String() string {
-	return orderStatusToString[os]
+	if elem, ok := orderStatusToString[os]; ok {
+		return elem
+	}
+
+	raw, _ := orderStatusUnknown.lookup(int(os))
+	return raw
 }
 
 var orderStatusToString = map[OrderStatus]string{
@@ -491,14 +987,64 @@ var orderStatusToEnum = map[string]OrderStatus{
 	"EXPIRED":            OrderStatus_Expired,
 }
 
+// OrderStatusValues returns all the known values of the OrderStatus enum
+func OrderStatusValues() []OrderStatus {
+	return []OrderStatus{
+		OrderStatus_Pending,
+		OrderStatus_ExecutionComplete,
+		OrderStatus_Executable,
+		OrderStatus_Expired,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (os OrderStatus) IsValid() bool {
+	_, ok := orderStatusToString[os]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (os OrderStatus) IsUnknown() bool {
+	_, ok := orderStatusUnknown.lookup(int(os))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (os OrderStatus) MarshalText() ([]byte, error) {
+	elem, ok := orderStatusToString[os]
+	if !ok {
+		elem, ok = orderStatusUnknown.lookup(int(os))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (os *OrderStatus) UnmarshalText(data []byte) error {
+	result, ok := orderStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderStatus enum value")
+		}
+		result = OrderStatus(orderStatusUnknown.register(string(data)))
+	}
+
+	*os = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (os OrderStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := orderStatusToString[os]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := os.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -509,19 +1055,16 @@ func (os *OrderStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderStatus enum value")
-	}
-
-	*os = result
-	return nil
+	return os.UnmarshalText([]byte(j))
 }
 
 // OrderBy ENUM
 
 type OrderBy int
 
+// orderByUnknown keeps track of the OrderBy values received that are not known by this library
+var orderByUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderBy_ByBet OrderBy = iota + 1
 	OrderBy_ByMarket
@@ -532,7 +1075,12 @@ const (
 )
 
 func (ob OrderBy) String() string {
-	return orderByToString[ob]
+	if elem, ok := orderByToString[ob]; ok {
+		return elem
+	}
+
+	raw, _ := orderByUnknown.lookup(int(ob))
+	return raw
 }
 
 var orderByToString = map[OrderBy]string{
@@ -553,14 +1101,66 @@ var orderByToEnum = map[string]OrderBy{
 	"BY_VOID_TIME":    OrderBy_ByVoidTime,
 }
 
+// OrderByValues returns all the known values of the OrderBy enum
+func OrderByValues() []OrderBy {
+	return []OrderBy{
+		OrderBy_ByBet,
+		OrderBy_ByMarket,
+		OrderBy_ByMatchTime,
+		OrderBy_ByPlaceTime,
+		OrderBy_BySettledTime,
+		OrderBy_ByVoidTime,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ob OrderBy) IsValid() bool {
+	_, ok := orderByToString[ob]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ob OrderBy) IsUnknown() bool {
+	_, ok := orderByUnknown.lookup(int(ob))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ob OrderBy) MarshalText() ([]byte, error) {
+	elem, ok := orderByToString[ob]
+	if !ok {
+		elem, ok = orderByUnknown.lookup(int(ob))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderBy enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ob *OrderBy) UnmarshalText(data []byte) error {
+	result, ok := orderByToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderBy enum value")
+		}
+		result = OrderBy(orderByUnknown.register(string(data)))
+	}
+
+	*ob = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ob OrderBy) MarshalJSON() ([]byte, error) {
-	elem, ok := orderByToString[ob]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ob.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderBy enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -571,26 +1171,28 @@ func (ob *OrderBy) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderByToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderBy enum value")
-	}
-
-	*ob = result
-	return nil
+	return ob.UnmarshalText([]byte(j))
 }
 
 // SortDir ENUM
 
 type SortDir int
 
+// sortDirUnknown keeps track of the SortDir values received that are not known by this library
+var sortDirUnknown = newUnknownEnumsRegistry()
+
 const (
 	SortDir_EarliestToLatest SortDir = iota + 1
 	SortDir_LatestToEarliest
 )
 
 func (sd SortDir) String() string {
-	return sortDirToString[sd]
+	if elem, ok := sortDirToString[sd]; ok {
+		return elem
+	}
+
+	raw, _ := sortDirUnknown.lookup(int(sd))
+	return raw
 }
 
 var sortDirToString = map[SortDir]string{
@@ -598,19 +1200,67 @@ var sortDirToString = map[SortDir]string{
 	SortDir_LatestToEarliest: "LATEST_TO_EARLIEST",
 }
 
-var sortDirToEnum = map[string]SortDir{
-	"EARLIEST_TO_LATEST": SortDir_EarliestToLatest,
-	"LATEST_TO_EARLIEST": SortDir_LatestToEarliest,
+var sortDirToEnum = map[string]SortDir{
+	"EARLIEST_TO_LATEST": SortDir_EarliestToLatest,
+	"LATEST_TO_EARLIEST": SortDir_LatestToEarliest,
+}
+
+// SortDirValues returns all the known values of the SortDir enum
+func SortDirValues() []SortDir {
+	return []SortDir{
+		SortDir_EarliestToLatest,
+		SortDir_LatestToEarliest,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (sd SortDir) IsValid() bool {
+	_, ok := sortDirToString[sd]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (sd SortDir) IsUnknown() bool {
+	_, ok := sortDirUnknown.lookup(int(sd))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (sd SortDir) MarshalText() ([]byte, error) {
+	elem, ok := sortDirToString[sd]
+	if !ok {
+		elem, ok = sortDirUnknown.lookup(int(sd))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal SortDir enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (sd *SortDir) UnmarshalText(data []byte) error {
+	result, ok := sortDirToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching SortDir enum value")
+		}
+		result = SortDir(sortDirUnknown.register(string(data)))
+	}
+
+	*sd = result
+	return nil
 }
 
 // MarshalJSON marshals the enum as a quoted json string
 func (sd SortDir) MarshalJSON() ([]byte, error) {
-	elem, ok := sortDirToString[sd]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := sd.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal SortDir enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -621,19 +1271,16 @@ func (sd *SortDir) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := sortDirToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching SortDir enum value")
-	}
-
-	*sd = result
-	return nil
+	return sd.UnmarshalText([]byte(j))
 }
 
 // OrderType ENUM
 
 type OrderType int
 
+// orderTypeUnknown keeps track of the OrderType values received that are not known by this library
+var orderTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderType_Limit OrderType = iota + 1
 	OrderType_LimitOnClose
@@ -641,7 +1288,12 @@ const (
 )
 
 func (ot OrderType) String() string {
-	return orderTypeToString[ot]
+	if elem, ok := orderTypeToString[ot]; ok {
+		return elem
+	}
+
+	raw, _ := orderTypeUnknown.lookup(int(ot))
+	return raw
 }
 
 var orderTypeToString = map[OrderType]string{
@@ -656,14 +1308,63 @@ var orderTypeToEnum = map[string]OrderType{
 	"MARKET_ON_CLOSE": OrderType_MarketOnClose,
 }
 
+// OrderTypeValues returns all the known values of the OrderType enum
+func OrderTypeValues() []OrderType {
+	return []OrderType{
+		OrderType_Limit,
+		OrderType_LimitOnClose,
+		OrderType_MarketOnClose,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ot OrderType) IsValid() bool {
+	_, ok := orderTypeToString[ot]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ot OrderType) IsUnknown() bool {
+	_, ok := orderTypeUnknown.lookup(int(ot))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ot OrderType) MarshalText() ([]byte, error) {
+	elem, ok := orderTypeToString[ot]
+	if !ok {
+		elem, ok = orderTypeUnknown.lookup(int(ot))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ot *OrderType) UnmarshalText(data []byte) error {
+	result, ok := orderTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderType enum value")
+		}
+		result = OrderType(orderTypeUnknown.register(string(data)))
+	}
+
+	*ot = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ot OrderType) MarshalJSON() ([]byte, error) {
-	elem, ok := orderTypeToString[ot]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ot.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -674,19 +1375,16 @@ func (ot *OrderType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderType enum value")
-	}
-
-	*ot = result
-	return nil
+	return ot.UnmarshalText([]byte(j))
 }
 
 // MarketSort ENUM
 
 type MarketSort int
 
+// marketSortUnknown keeps track of the MarketSort values received that are not known by this library
+var marketSortUnknown = newUnknownEnumsRegistry()
+
 const (
 	MarketSort_MinimumTraded MarketSort = iota + 1
 	MarketSort_MaximumTraded
@@ -697,7 +1395,12 @@ const (
 )
 
 func (ms MarketSort) String() string {
-	return marketSortToString[ms]
+	if elem, ok := marketSortToString[ms]; ok {
+		return elem
+	}
+
+	raw, _ := marketSortUnknown.lookup(int(ms))
+	return raw
 }
 
 var marketSortToString = map[MarketSort]string{
@@ -718,14 +1421,66 @@ var marketSortToEnum = map[string]MarketSort{
 	"LAST_TO_START":     MarketSort_LastToStart,
 }
 
+// MarketSortValues returns all the known values of the MarketSort enum
+func MarketSortValues() []MarketSort {
+	return []MarketSort{
+		MarketSort_MinimumTraded,
+		MarketSort_MaximumTraded,
+		MarketSort_MinimumAvailable,
+		MarketSort_MaximumAvailable,
+		MarketSort_FirstToStart,
+		MarketSort_LastToStart,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ms MarketSort) IsValid() bool {
+	_, ok := marketSortToString[ms]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ms MarketSort) IsUnknown() bool {
+	_, ok := marketSortUnknown.lookup(int(ms))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ms MarketSort) MarshalText() ([]byte, error) {
+	elem, ok := marketSortToString[ms]
+	if !ok {
+		elem, ok = marketSortUnknown.lookup(int(ms))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MarketSort enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ms *MarketSort) UnmarshalText(data []byte) error {
+	result, ok := marketSortToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MarketSort enum value")
+		}
+		result = MarketSort(marketSortUnknown.register(string(data)))
+	}
+
+	*ms = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ms MarketSort) MarshalJSON() ([]byte, error) {
-	elem, ok := marketSortToString[ms]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ms.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MarketSort enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -736,19 +1491,16 @@ func (ms *MarketSort) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := marketSortToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MarketSort enum value")
-	}
-
-	*ms = result
-	return nil
+	return ms.UnmarshalText([]byte(j))
 }
 
 // MarketBettingType ENUM
 
 type MarketBettingType int
 
+// marketBettingTypeUnknown keeps track of the MarketBettingType values received that are not known by this library
+var marketBettingTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	MarketBettingType_Odds MarketBettingType = iota + 1
 	MarketBettingType_Line
@@ -759,7 +1511,12 @@ const (
 )
 
 func (mbt MarketBettingType) String() string {
-	return marketBettingTypeToString[mbt]
+	if elem, ok := marketBettingTypeToString[mbt]; ok {
+		return elem
+	}
+
+	raw, _ := marketBettingTypeUnknown.lookup(int(mbt))
+	return raw
 }
 
 var marketBettingTypeToString = map[MarketBettingType]string{
@@ -780,14 +1537,66 @@ var marketBettingTypeToEnum = map[string]MarketBettingType{
 	"FIXED_ODDS":                 MarketBettingType_FixedOdds,
 }
 
+// MarketBettingTypeValues returns all the known values of the MarketBettingType enum
+func MarketBettingTypeValues() []MarketBettingType {
+	return []MarketBettingType{
+		MarketBettingType_Odds,
+		MarketBettingType_Line,
+		MarketBettingType_Range,
+		MarketBettingType_AsianHandicapDoubleLine,
+		MarketBettingType_AsianHandicapSingleLine,
+		MarketBettingType_FixedOdds,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (mbt MarketBettingType) IsValid() bool {
+	_, ok := marketBettingTypeToString[mbt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (mbt MarketBettingType) IsUnknown() bool {
+	_, ok := marketBettingTypeUnknown.lookup(int(mbt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (mbt MarketBettingType) MarshalText() ([]byte, error) {
+	elem, ok := marketBettingTypeToString[mbt]
+	if !ok {
+		elem, ok = marketBettingTypeUnknown.lookup(int(mbt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MarketBettingType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (mbt *MarketBettingType) UnmarshalText(data []byte) error {
+	result, ok := marketBettingTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MarketBettingType enum value")
+		}
+		result = MarketBettingType(marketBettingTypeUnknown.register(string(data)))
+	}
+
+	*mbt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (mbt MarketBettingType) MarshalJSON() ([]byte, error) {
-	elem, ok := marketBettingTypeToString[mbt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := mbt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MarketBettingType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -798,19 +1607,16 @@ func (mbt *MarketBettingType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := marketBettingTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MarketBettingType enum value")
-	}
-
-	*mbt = result
-	return nil
+	return mbt.UnmarshalText([]byte(j))
 }
 
 // ExecutionReportStatus ENUM
 
 type ExecutionReportStatus int
 
+// executionReportStatusUnknown keeps track of the ExecutionReportStatus values received that are not known by this library
+var executionReportStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	ExecutionReportStatus_Success ExecutionReportStatus = iota + 1
 	ExecutionReportStatus_Failure
@@ -819,7 +1625,12 @@ const (
 )
 
 func (ers ExecutionReportStatus) String() string {
-	return executionReportStatusToString[ers]
+	if elem, ok := executionReportStatusToString[ers]; ok {
+		return elem
+	}
+
+	raw, _ := executionReportStatusUnknown.lookup(int(ers))
+	return raw
 }
 
 var executionReportStatusToString = map[ExecutionReportStatus]string{
@@ -836,14 +1647,64 @@ var executionReportStatusToEnum = map[string]ExecutionReportStatus{
 	"TIMEOUT":               ExecutionReportStatus_Timeout,
 }
 
+// ExecutionReportStatusValues returns all the known values of the ExecutionReportStatus enum
+func ExecutionReportStatusValues() []ExecutionReportStatus {
+	return []ExecutionReportStatus{
+		ExecutionReportStatus_Success,
+		ExecutionReportStatus_Failure,
+		ExecutionReportStatus_ProcessedWithErrors,
+		ExecutionReportStatus_Timeout,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ers ExecutionReportStatus) IsValid() bool {
+	_, ok := executionReportStatusToString[ers]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ers ExecutionReportStatus) IsUnknown() bool {
+	_, ok := executionReportStatusUnknown.lookup(int(ers))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ers ExecutionReportStatus) MarshalText() ([]byte, error) {
+	elem, ok := executionReportStatusToString[ers]
+	if !ok {
+		elem, ok = executionReportStatusUnknown.lookup(int(ers))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal ExecutionReportStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ers *ExecutionReportStatus) UnmarshalText(data []byte) error {
+	result, ok := executionReportStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching ExecutionReportStatus enum value")
+		}
+		result = ExecutionReportStatus(executionReportStatusUnknown.register(string(data)))
+	}
+
+	*ers = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ers ExecutionReportStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := executionReportStatusToString[ers]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ers.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal ExecutionReportStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -854,19 +1715,16 @@ func (ers *ExecutionReportStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := executionReportStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching ExecutionReportStatus enum value")
-	}
-
-	*ers = result
-	return nil
+	return ers.UnmarshalText([]byte(j))
 }
 
 // ExecutionReportErrorCode ENUM
 
 type ExecutionReportErrorCode int
 
+// executionReportErrorCodeUnknown keeps track of the ExecutionReportErrorCode values received that are not known by this library
+var executionReportErrorCodeUnknown = newUnknownEnumsRegistry()
+
 const (
 	ExecutionReportErrorCode_ErrorInMatcher ExecutionReportErrorCode = iota + 1
 	ExecutionReportErrorCode_ProcessedWithErrors
@@ -892,7 +1750,12 @@ const (
 )
 
 func (erec ExecutionReportErrorCode) String() string {
-	return executionReportErrorCodeToString[erec]
+	if elem, ok := executionReportErrorCodeToString[erec]; ok {
+		return elem
+	}
+
+	raw, _ := executionReportErrorCodeUnknown.lookup(int(erec))
+	return raw
 }
 
 var executionReportErrorCodeToString = map[ExecutionReportErrorCode]string{
@@ -943,14 +1806,81 @@ var executionReportErrorCodeToEnum = map[string]ExecutionReportErrorCode{
 	"INVALID_MARKET_VERSION":      ExecutionReportErrorCode_InvalidMarketVersion,
 }
 
+// ExecutionReportErrorCodeValues returns all the known values of the ExecutionReportErrorCode enum
+func ExecutionReportErrorCodeValues() []ExecutionReportErrorCode {
+	return []ExecutionReportErrorCode{
+		ExecutionReportErrorCode_ErrorInMatcher,
+		ExecutionReportErrorCode_ProcessedWithErrors,
+		ExecutionReportErrorCode_BetActionError,
+		ExecutionReportErrorCode_InvalidAccountState,
+		ExecutionReportErrorCode_InvalidWalletStatus,
+		ExecutionReportErrorCode_InsufficientFunds,
+		ExecutionReportErrorCode_LossLimitExceeded,
+		ExecutionReportErrorCode_MarketSuspended,
+		ExecutionReportErrorCode_MarketNotOpenForBetting,
+		ExecutionReportErrorCode_DuplicateTransaction,
+		ExecutionReportErrorCode_InvalidOrder,
+		ExecutionReportErrorCode_InvalidMarketId,
+		ExecutionReportErrorCode_PermissionDenied,
+		ExecutionReportErrorCode_DuplicateBetids,
+		ExecutionReportErrorCode_NoActionRequired,
+		ExecutionReportErrorCode_ServiceUnavailable,
+		ExecutionReportErrorCode_RejectedByRegulator,
+		ExecutionReportErrorCode_NoChasing,
+		ExecutionReportErrorCode_RegulatorIsNotAvailable,
+		ExecutionReportErrorCode_TooManyInstructions,
+		ExecutionReportErrorCode_InvalidMarketVersion,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (erec ExecutionReportErrorCode) IsValid() bool {
+	_, ok := executionReportErrorCodeToString[erec]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (erec ExecutionReportErrorCode) IsUnknown() bool {
+	_, ok := executionReportErrorCodeUnknown.lookup(int(erec))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (erec ExecutionReportErrorCode) MarshalText() ([]byte, error) {
+	elem, ok := executionReportErrorCodeToString[erec]
+	if !ok {
+		elem, ok = executionReportErrorCodeUnknown.lookup(int(erec))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal ExecutionReportErrorCode enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (erec *ExecutionReportErrorCode) UnmarshalText(data []byte) error {
+	result, ok := executionReportErrorCodeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching ExecutionReportErrorCode enum value")
+		}
+		result = ExecutionReportErrorCode(executionReportErrorCodeUnknown.register(string(data)))
+	}
+
+	*erec = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (erec ExecutionReportErrorCode) MarshalJSON() ([]byte, error) {
-	elem, ok := executionReportErrorCodeToString[erec]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := erec.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal ExecutionReportErrorCode enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -961,19 +1891,16 @@ func (erec *ExecutionReportErrorCode) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := executionReportErrorCodeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching ExecutionReportErrorCode enum value")
-	}
-
-	*erec = result
-	return nil
+	return erec.UnmarshalText([]byte(j))
 }
 
 // PersistenceType ENUM
 
 type PersistenceType int
 
+// persistenceTypeUnknown keeps track of the PersistenceType values received that are not known by this library
+var persistenceTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	PersistenceType_Lapse PersistenceType = iota + 1
 	PersistenceType_Persist
@@ -981,7 +1908,12 @@ const (
 )
 
 func (pt PersistenceType) String() string {
-	return persistenceTypeToString[pt]
+	if elem, ok := persistenceTypeToString[pt]; ok {
+		return elem
+	}
+
+	raw, _ := persistenceTypeUnknown.lookup(int(pt))
+	return raw
 }
 
 var persistenceTypeToString = map[PersistenceType]string{
@@ -996,14 +1928,63 @@ var persistenceTypeToEnum = map[string]PersistenceType{
 	"MARKET_ON_CLOSE": PersistenceType_MarketOnClose,
 }
 
+// PersistenceTypeValues returns all the known values of the PersistenceType enum
+func PersistenceTypeValues() []PersistenceType {
+	return []PersistenceType{
+		PersistenceType_Lapse,
+		PersistenceType_Persist,
+		PersistenceType_MarketOnClose,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (pt PersistenceType) IsValid() bool {
+	_, ok := persistenceTypeToString[pt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (pt PersistenceType) IsUnknown() bool {
+	_, ok := persistenceTypeUnknown.lookup(int(pt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (pt PersistenceType) MarshalText() ([]byte, error) {
+	elem, ok := persistenceTypeToString[pt]
+	if !ok {
+		elem, ok = persistenceTypeUnknown.lookup(int(pt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PersistenceType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (pt *PersistenceType) UnmarshalText(data []byte) error {
+	result, ok := persistenceTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PersistenceType enum value")
+		}
+		result = PersistenceType(persistenceTypeUnknown.register(string(data)))
+	}
+
+	*pt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (pt PersistenceType) MarshalJSON() ([]byte, error) {
-	elem, ok := persistenceTypeToString[pt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := pt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PersistenceType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1014,19 +1995,16 @@ func (pt *PersistenceType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := persistenceTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PersistenceType enum value")
-	}
-
-	*pt = result
-	return nil
+	return pt.UnmarshalText([]byte(j))
 }
 
 // InstructionReportStatus ENUM
 
 type InstructionReportStatus int
 
+// instructionReportStatusUnknown keeps track of the InstructionReportStatus values received that are not known by this library
+var instructionReportStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	InstructionReportStatus_Success InstructionReportStatus = iota + 1
 	InstructionReportStatus_Failure
@@ -1034,7 +2012,12 @@ const (
 )
 
 func (irs InstructionReportStatus) String() string {
-	return instructionReportStatusToString[irs]
+	if elem, ok := instructionReportStatusToString[irs]; ok {
+		return elem
+	}
+
+	raw, _ := instructionReportStatusUnknown.lookup(int(irs))
+	return raw
 }
 
 var instructionReportStatusToString = map[InstructionReportStatus]string{
@@ -1049,14 +2032,63 @@ var instructionReportStatusToEnum = map[string]InstructionReportStatus{
 	"TIMEOUT": InstructionReportStatus_Timeout,
 }
 
+// InstructionReportStatusValues returns all the known values of the InstructionReportStatus enum
+func InstructionReportStatusValues() []InstructionReportStatus {
+	return []InstructionReportStatus{
+		InstructionReportStatus_Success,
+		InstructionReportStatus_Failure,
+		InstructionReportStatus_Timeout,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (irs InstructionReportStatus) IsValid() bool {
+	_, ok := instructionReportStatusToString[irs]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (irs InstructionReportStatus) IsUnknown() bool {
+	_, ok := instructionReportStatusUnknown.lookup(int(irs))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (irs InstructionReportStatus) MarshalText() ([]byte, error) {
+	elem, ok := instructionReportStatusToString[irs]
+	if !ok {
+		elem, ok = instructionReportStatusUnknown.lookup(int(irs))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal InstructionReportStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (irs *InstructionReportStatus) UnmarshalText(data []byte) error {
+	result, ok := instructionReportStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching InstructionReportStatus enum value")
+		}
+		result = InstructionReportStatus(instructionReportStatusUnknown.register(string(data)))
+	}
+
+	*irs = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (irs InstructionReportStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := instructionReportStatusToString[irs]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := irs.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal InstructionReportStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1067,19 +2099,16 @@ func (irs *InstructionReportStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := instructionReportStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching InstructionReportStatus enum value")
-	}
-
-	*irs = result
-	return nil
+	return irs.UnmarshalText([]byte(j))
 }
 
 // InstructionReportErrorCode ENUM
 
 type InstructionReportErrorCode int
 
+// instructionReportErrorCodeUnknown keeps track of the InstructionReportErrorCode values received that are not known by this library
+var instructionReportErrorCodeUnknown = newUnknownEnumsRegistry()
+
 const (
 	InstructionReportErrorCode_InvalidBetSize InstructionReportErrorCode = iota + 1
 	InstructionReportErrorCode_InvalidRunner
@@ -1111,7 +2140,12 @@ const (
 )
 
 func (irec InstructionReportErrorCode) String() string {
-	return instructionReportErrorCodeToString[irec]
+	if elem, ok := instructionReportErrorCodeToString[irec]; ok {
+		return elem
+	}
+
+	raw, _ := instructionReportErrorCodeUnknown.lookup(int(irec))
+	return raw
 }
 
 var instructionReportErrorCodeToString = map[InstructionReportErrorCode]string{
@@ -1174,14 +2208,87 @@ var instructionReportErrorCodeToEnum = map[string]InstructionReportErrorCode{
 	"BET_LAPSED_PRICE_IMPROVEMENT_TOO_LARGE": InstructionReportErrorCode_BetLapsedPriceImprovementTooLarge,
 }
 
+// InstructionReportErrorCodeValues returns all the known values of the InstructionReportErrorCode enum
+func InstructionReportErrorCodeValues() []InstructionReportErrorCode {
+	return []InstructionReportErrorCode{
+		InstructionReportErrorCode_InvalidBetSize,
+		InstructionReportErrorCode_InvalidRunner,
+		InstructionReportErrorCode_BetTakenOrLapsed,
+		InstructionReportErrorCode_BetInProgress,
+		InstructionReportErrorCode_RunnerRemoved,
+		InstructionReportErrorCode_MarketNotOpenForBetting,
+		InstructionReportErrorCode_LossLimitExceeded,
+		InstructionReportErrorCode_MarketNotOpenForBspBetting,
+		InstructionReportErrorCode_InvalidPriceEdit,
+		InstructionReportErrorCode_InvalidOdds,
+		InstructionReportErrorCode_InsufficientFunds,
+		InstructionReportErrorCode_InvalidPersistenceType,
+		InstructionReportErrorCode_ErrorInMatcher,
+		InstructionReportErrorCode_InvalidBackLayCombination,
+		InstructionReportErrorCode_ErrorInOrder,
+		InstructionReportErrorCode_InvalidBidType,
+		InstructionReportErrorCode_InvalidBetId,
+		InstructionReportErrorCode_CancelledNotPlaced,
+		InstructionReportErrorCode_RelatedActionFailed,
+		InstructionReportErrorCode_NoActionRequired,
+		InstructionReportErrorCode_TimeInForceConflict,
+		InstructionReportErrorCode_UnexpectedPersistenceType,
+		InstructionReportErrorCode_InvalidOrderType,
+		InstructionReportErrorCode_UnexpectedMinFillSize,
+		InstructionReportErrorCode_InvalidCustomerOrderRef,
+		InstructionReportErrorCode_InvalidMinFillSize,
+		InstructionReportErrorCode_BetLapsedPriceImprovementTooLarge,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (irec InstructionReportErrorCode) IsValid() bool {
+	_, ok := instructionReportErrorCodeToString[irec]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (irec InstructionReportErrorCode) IsUnknown() bool {
+	_, ok := instructionReportErrorCodeUnknown.lookup(int(irec))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (irec InstructionReportErrorCode) MarshalText() ([]byte, error) {
+	elem, ok := instructionReportErrorCodeToString[irec]
+	if !ok {
+		elem, ok = instructionReportErrorCodeUnknown.lookup(int(irec))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal InstructionReportErrorCode enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (irec *InstructionReportErrorCode) UnmarshalText(data []byte) error {
+	result, ok := instructionReportErrorCodeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching InstructionReportErrorCode enum value")
+		}
+		result = InstructionReportErrorCode(instructionReportErrorCodeUnknown.register(string(data)))
+	}
+
+	*irec = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (irec InstructionReportErrorCode) MarshalJSON() ([]byte, error) {
-	elem, ok := instructionReportErrorCodeToString[irec]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := irec.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal InstructionReportErrorCode enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1192,19 +2299,16 @@ func (irec *InstructionReportErrorCode) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := instructionReportErrorCodeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching InstructionReportErrorCode enum value")
-	}
-
-	*irec = result
-	return nil
+	return irec.UnmarshalText([]byte(j))
 }
 
 // RollupModel ENUM
 
 type RollupModel int
 
+// rollupModelUnknown keeps track of the RollupModel values received that are not known by this library
+var rollupModelUnknown = newUnknownEnumsRegistry()
+
 const (
 	RollupModel_Stake RollupModel = iota + 1
 	RollupModel_Payout
@@ -1213,7 +2317,12 @@ const (
 )
 
 func (rm RollupModel) String() string {
-	return rollupModelToString[rm]
+	if elem, ok := rollupModelToString[rm]; ok {
+		return elem
+	}
+
+	raw, _ := rollupModelUnknown.lookup(int(rm))
+	return raw
 }
 
 var rollupModelToString = map[RollupModel]string{
@@ -1230,14 +2339,64 @@ var rollupModelToEnum = map[string]RollupModel{
 	"NONE":              RollupModel_None,
 }
 
+// RollupModelValues returns all the known values of the RollupModel enum
+func RollupModelValues() []RollupModel {
+	return []RollupModel{
+		RollupModel_Stake,
+		RollupModel_Payout,
+		RollupModel_ManagedLiability,
+		RollupModel_None,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (rm RollupModel) IsValid() bool {
+	_, ok := rollupModelToString[rm]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (rm RollupModel) IsUnknown() bool {
+	_, ok := rollupModelUnknown.lookup(int(rm))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (rm RollupModel) MarshalText() ([]byte, error) {
+	elem, ok := rollupModelToString[rm]
+	if !ok {
+		elem, ok = rollupModelUnknown.lookup(int(rm))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal RollupModel enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (rm *RollupModel) UnmarshalText(data []byte) error {
+	result, ok := rollupModelToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching RollupModel enum value")
+		}
+		result = RollupModel(rollupModelUnknown.register(string(data)))
+	}
+
+	*rm = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (rm RollupModel) MarshalJSON() ([]byte, error) {
-	elem, ok := rollupModelToString[rm]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := rm.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal RollupModel enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1248,19 +2407,16 @@ func (rm *RollupModel) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := rollupModelToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching RollupModel enum value")
-	}
-
-	*rm = result
-	return nil
+	return rm.UnmarshalText([]byte(j))
 }
 
 // GroupBy ENUM
 
 type GroupBy int
 
+// groupByUnknown keeps track of the GroupBy values received that are not known by this library
+var groupByUnknown = newUnknownEnumsRegistry()
+
 const (
 	GroupBy_EventType GroupBy = iota + 1
 	GroupBy_Event
@@ -1270,7 +2426,12 @@ const (
 )
 
 func (gb GroupBy) String() string {
-	return groupByToString[gb]
+	if elem, ok := groupByToString[gb]; ok {
+		return elem
+	}
+
+	raw, _ := groupByUnknown.lookup(int(gb))
+	return raw
 }
 
 var groupByToString = map[GroupBy]string{
@@ -1289,14 +2450,65 @@ var groupByToEnum = map[string]GroupBy{
 	"BET":        GroupBy_Bet,
 }
 
+// GroupByValues returns all the known values of the GroupBy enum
+func GroupByValues() []GroupBy {
+	return []GroupBy{
+		GroupBy_EventType,
+		GroupBy_Event,
+		GroupBy_Market,
+		GroupBy_Side,
+		GroupBy_Bet,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (gb GroupBy) IsValid() bool {
+	_, ok := groupByToString[gb]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (gb GroupBy) IsUnknown() bool {
+	_, ok := groupByUnknown.lookup(int(gb))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (gb GroupBy) MarshalText() ([]byte, error) {
+	elem, ok := groupByToString[gb]
+	if !ok {
+		elem, ok = groupByUnknown.lookup(int(gb))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal GroupBy enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (gb *GroupBy) UnmarshalText(data []byte) error {
+	result, ok := groupByToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching GroupBy enum value")
+		}
+		result = GroupBy(groupByUnknown.register(string(data)))
+	}
+
+	*gb = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (gb GroupBy) MarshalJSON() ([]byte, error) {
-	elem, ok := groupByToString[gb]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := gb.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal GroupBy enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1307,19 +2519,16 @@ func (gb *GroupBy) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := groupByToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching GroupBy enum value")
-	}
-
-	*gb = result
-	return nil
+	return gb.UnmarshalText([]byte(j))
 }
 
 // BetStatus ENUM
 
 type BetStatus int
 
+// betStatusUnknown keeps track of the BetStatus values received that are not known by this library
+var betStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	BetStatus_Settled BetStatus = iota + 1
 	BetStatus_Voided
@@ -1328,7 +2537,12 @@ const (
 )
 
 func (bs BetStatus) String() string {
-	return betStatusToString[bs]
+	if elem, ok := betStatusToString[bs]; ok {
+		return elem
+	}
+
+	raw, _ := betStatusUnknown.lookup(int(bs))
+	return raw
 }
 
 var betStatusToString = map[BetStatus]string{
@@ -1345,14 +2559,64 @@ var betStatusToEnum = map[string]BetStatus{
 	"CANCELLED": BetStatus_Cancelled,
 }
 
+// BetStatusValues returns all the known values of the BetStatus enum
+func BetStatusValues() []BetStatus {
+	return []BetStatus{
+		BetStatus_Settled,
+		BetStatus_Voided,
+		BetStatus_Lapsed,
+		BetStatus_Cancelled,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (bs BetStatus) IsValid() bool {
+	_, ok := betStatusToString[bs]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (bs BetStatus) IsUnknown() bool {
+	_, ok := betStatusUnknown.lookup(int(bs))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (bs BetStatus) MarshalText() ([]byte, error) {
+	elem, ok := betStatusToString[bs]
+	if !ok {
+		elem, ok = betStatusUnknown.lookup(int(bs))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal BetStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (bs *BetStatus) UnmarshalText(data []byte) error {
+	result, ok := betStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching BetStatus enum value")
+		}
+		result = BetStatus(betStatusUnknown.register(string(data)))
+	}
+
+	*bs = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (bs BetStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := betStatusToString[bs]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := bs.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal BetStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1363,19 +2627,16 @@ func (bs *BetStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := betStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching BetStatus enum value")
-	}
-
-	*bs = result
-	return nil
+	return bs.UnmarshalText([]byte(j))
 }
 
 // MarketType ENUM
 
 type MarketType int
 
+// marketTypeUnknown keeps track of the MarketType values received that are not known by this library
+var marketTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	MarketType_A MarketType = iota + 1
 	MarketType_L
@@ -1385,7 +2646,12 @@ const (
 )
 
 func (mt MarketType) String() string {
-	return marketTypeToString[mt]
+	if elem, ok := marketTypeToString[mt]; ok {
+		return elem
+	}
+
+	raw, _ := marketTypeUnknown.lookup(int(mt))
+	return raw
 }
 
 var marketTypeToString = map[MarketType]string{
@@ -1404,14 +2670,65 @@ var marketTypeToEnum = map[string]MarketType{
 	"NOT_APPLICABLE": MarketType_NotApplicable,
 }
 
+// MarketTypeValues returns all the known values of the MarketType enum
+func MarketTypeValues() []MarketType {
+	return []MarketType{
+		MarketType_A,
+		MarketType_L,
+		MarketType_O,
+		MarketType_R,
+		MarketType_NotApplicable,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (mt MarketType) IsValid() bool {
+	_, ok := marketTypeToString[mt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (mt MarketType) IsUnknown() bool {
+	_, ok := marketTypeUnknown.lookup(int(mt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (mt MarketType) MarshalText() ([]byte, error) {
+	elem, ok := marketTypeToString[mt]
+	if !ok {
+		elem, ok = marketTypeUnknown.lookup(int(mt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal MarketType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (mt *MarketType) UnmarshalText(data []byte) error {
+	result, ok := marketTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching MarketType enum value")
+		}
+		result = MarketType(marketTypeUnknown.register(string(data)))
+	}
+
+	*mt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (mt MarketType) MarshalJSON() ([]byte, error) {
-	elem, ok := marketTypeToString[mt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := mt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal MarketType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1422,25 +2739,27 @@ func (mt *MarketType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := marketTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching MarketType enum value")
-	}
-
-	*mt = result
-	return nil
+	return mt.UnmarshalText([]byte(j))
 }
 
 // TimeInForce ENUM
 
 type TimeInForce int
 
+// timeInForceUnknown keeps track of the TimeInForce values received that are not known by this library
+var timeInForceUnknown = newUnknownEnumsRegistry()
+
 const (
 	TimeInForce_FillOrKill TimeInForce = iota + 1
 )
 
 func (tif TimeInForce) String() string {
-	return timeInForceToString[tif]
+	if elem, ok := timeInForceToString[tif]; ok {
+		return elem
+	}
+
+	raw, _ := timeInForceUnknown.lookup(int(tif))
+	return raw
 }
 
 var timeInForceToString = map[TimeInForce]string{
@@ -1451,14 +2770,61 @@ var timeInForceToEnum = map[string]TimeInForce{
 	"FILL_OR_KILL": TimeInForce_FillOrKill,
 }
 
+// TimeInForceValues returns all the known values of the TimeInForce enum
+func TimeInForceValues() []TimeInForce {
+	return []TimeInForce{
+		TimeInForce_FillOrKill,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (tif TimeInForce) IsValid() bool {
+	_, ok := timeInForceToString[tif]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (tif TimeInForce) IsUnknown() bool {
+	_, ok := timeInForceUnknown.lookup(int(tif))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (tif TimeInForce) MarshalText() ([]byte, error) {
+	elem, ok := timeInForceToString[tif]
+	if !ok {
+		elem, ok = timeInForceUnknown.lookup(int(tif))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal TimeInForce enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (tif *TimeInForce) UnmarshalText(data []byte) error {
+	result, ok := timeInForceToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching TimeInForce enum value")
+		}
+		result = TimeInForce(timeInForceUnknown.register(string(data)))
+	}
+
+	*tif = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (tif TimeInForce) MarshalJSON() ([]byte, error) {
-	elem, ok := timeInForceToString[tif]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := tif.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal TimeInForce enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1469,26 +2835,28 @@ func (tif *TimeInForce) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := timeInForceToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching TimeInForce enum value")
-	}
-
-	*tif = result
-	return nil
+	return tif.UnmarshalText([]byte(j))
 }
 
 // BetTargetType ENUM
 
 type BetTargetType int
 
+// betTargetTypeUnknown keeps track of the BetTargetType values received that are not known by this library
+var betTargetTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	BetTargetType_BackersProfit BetTargetType = iota + 1
 	BetTargetType_Payout
 )
 
 func (btt BetTargetType) String() string {
-	return betTargetTypeToString[btt]
+	if elem, ok := betTargetTypeToString[btt]; ok {
+		return elem
+	}
+
+	raw, _ := betTargetTypeUnknown.lookup(int(btt))
+	return raw
 }
 
 var betTargetTypeToString = map[BetTargetType]string{
@@ -1501,14 +2869,62 @@ var betTargetTypeToEnum = map[string]BetTargetType{
 	"PAYOUT":         BetTargetType_Payout,
 }
 
+// BetTargetTypeValues returns all the known values of the BetTargetType enum
+func BetTargetTypeValues() []BetTargetType {
+	return []BetTargetType{
+		BetTargetType_BackersProfit,
+		BetTargetType_Payout,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (btt BetTargetType) IsValid() bool {
+	_, ok := betTargetTypeToString[btt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (btt BetTargetType) IsUnknown() bool {
+	_, ok := betTargetTypeUnknown.lookup(int(btt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (btt BetTargetType) MarshalText() ([]byte, error) {
+	elem, ok := betTargetTypeToString[btt]
+	if !ok {
+		elem, ok = betTargetTypeUnknown.lookup(int(btt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal BetTargetType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (btt *BetTargetType) UnmarshalText(data []byte) error {
+	result, ok := betTargetTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching BetTargetType enum value")
+		}
+		result = BetTargetType(betTargetTypeUnknown.register(string(data)))
+	}
+
+	*btt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (btt BetTargetType) MarshalJSON() ([]byte, error) {
-	elem, ok := betTargetTypeToString[btt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := btt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal BetTargetType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1519,19 +2935,16 @@ func (btt *BetTargetType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := betTargetTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching BetTargetType enum value")
-	}
-
-	*btt = result
-	return nil
+	return btt.UnmarshalText([]byte(j))
 }
 
 // PriceLadderType ENUM
 
 type PriceLadderType int
 
+// priceLadderTypeUnknown keeps track of the PriceLadderType values received that are not known by this library
+var priceLadderTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	PriceLadderType_Classic PriceLadderType = iota + 1
 	PriceLadderType_Finest
@@ -1539,7 +2952,12 @@ const (
 )
 
 func (plt PriceLadderType) String() string {
-	return priceLadderTypeToString[plt]
+	if elem, ok := priceLadderTypeToString[plt]; ok {
+		return elem
+	}
+
+	raw, _ := priceLadderTypeUnknown.lookup(int(plt))
+	return raw
 }
 
 var priceLadderTypeToString = map[PriceLadderType]string{
@@ -1554,14 +2972,63 @@ var priceLadderTypeToEnum = map[string]PriceLadderType{
 	"LINE_RANGE": PriceLadderType_LineRange,
 }
 
+// PriceLadderTypeValues returns all the known values of the PriceLadderType enum
+func PriceLadderTypeValues() []PriceLadderType {
+	return []PriceLadderType{
+		PriceLadderType_Classic,
+		PriceLadderType_Finest,
+		PriceLadderType_LineRange,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (plt PriceLadderType) IsValid() bool {
+	_, ok := priceLadderTypeToString[plt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (plt PriceLadderType) IsUnknown() bool {
+	_, ok := priceLadderTypeUnknown.lookup(int(plt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (plt PriceLadderType) MarshalText() ([]byte, error) {
+	elem, ok := priceLadderTypeToString[plt]
+	if !ok {
+		elem, ok = priceLadderTypeUnknown.lookup(int(plt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PriceLadderType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (plt *PriceLadderType) UnmarshalText(data []byte) error {
+	result, ok := priceLadderTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PriceLadderType enum value")
+		}
+		result = PriceLadderType(priceLadderTypeUnknown.register(string(data)))
+	}
+
+	*plt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (plt PriceLadderType) MarshalJSON() ([]byte, error) {
-	elem, ok := priceLadderTypeToString[plt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := plt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PriceLadderType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1572,19 +3039,16 @@ func (plt *PriceLadderType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := priceLadderTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PriceLadderType enum value")
-	}
-
-	*plt = result
-	return nil
+	return plt.UnmarshalText([]byte(j))
 }
 
 // APINGExceptionCode ENUM
 
 type APINGExceptionCode int
 
+// aPINGExceptionCodeUnknown keeps track of the APINGExceptionCode values received that are not known by this library
+var aPINGExceptionCodeUnknown = newUnknownEnumsRegistry()
+
 const (
 	APINGExceptionCode_TooMuchData APINGExceptionCode = iota + 1
 	APINGExceptionCode_InvalidInputData
@@ -1601,7 +3065,12 @@ const (
 )
 
 func (apingec APINGExceptionCode) String() string {
-	return aPINGExceptionCodeToString[apingec]
+	if elem, ok := aPINGExceptionCodeToString[apingec]; ok {
+		return elem
+	}
+
+	raw, _ := aPINGExceptionCodeUnknown.lookup(int(apingec))
+	return raw
 }
 
 var aPINGExceptionCodeToString = map[APINGExceptionCode]string{
@@ -1634,14 +3103,72 @@ var aPINGExceptionCodeToEnum = map[string]APINGExceptionCode{
 	"ACCESS_DENIED":               APINGExceptionCode_AccessDenied,
 }
 
+// APINGExceptionCodeValues returns all the known values of the APINGExceptionCode enum
+func APINGExceptionCodeValues() []APINGExceptionCode {
+	return []APINGExceptionCode{
+		APINGExceptionCode_TooMuchData,
+		APINGExceptionCode_InvalidInputData,
+		APINGExceptionCode_InvalidSessionInformation,
+		APINGExceptionCode_NoAppKey,
+		APINGExceptionCode_NoSession,
+		APINGExceptionCode_UnexpectedError,
+		APINGExceptionCode_InvalidAppKey,
+		APINGExceptionCode_TooManyRequests,
+		APINGExceptionCode_ServiceBusy,
+		APINGExceptionCode_TimeoutError,
+		APINGExceptionCode_RequestSizeExceedsLimit,
+		APINGExceptionCode_AccessDenied,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (apingec APINGExceptionCode) IsValid() bool {
+	_, ok := aPINGExceptionCodeToString[apingec]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (apingec APINGExceptionCode) IsUnknown() bool {
+	_, ok := aPINGExceptionCodeUnknown.lookup(int(apingec))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (apingec APINGExceptionCode) MarshalText() ([]byte, error) {
+	elem, ok := aPINGExceptionCodeToString[apingec]
+	if !ok {
+		elem, ok = aPINGExceptionCodeUnknown.lookup(int(apingec))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal APINGExceptionCode enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (apingec *APINGExceptionCode) UnmarshalText(data []byte) error {
+	result, ok := aPINGExceptionCodeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching APINGExceptionCode enum value")
+		}
+		result = APINGExceptionCode(aPINGExceptionCodeUnknown.register(string(data)))
+	}
+
+	*apingec = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (apingec APINGExceptionCode) MarshalJSON() ([]byte, error) {
-	elem, ok := aPINGExceptionCodeToString[apingec]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := apingec.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal APINGExceptionCode enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -1652,11 +3179,5 @@ func (apingec *APINGExceptionCode) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := aPINGExceptionCodeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching APINGExceptionCode enum value")
-	}
-
-	*apingec = result
-	return nil
+	return apingec.UnmarshalText([]byte(j))
 }
@@ -0,0 +1,132 @@
+package betting
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestEnumsText(t *testing.T) {
+	text, err := MarketStatus_Suspended.MarshalText()
+	if err != nil {
+		t.Fatalf("error while marshalling: %s", err)
+	}
+
+	if string(text) != "SUSPENDED" {
+		t.Errorf("mismatched text: %s", text)
+	}
+
+	var ms MarketStatus
+	err = ms.UnmarshalText([]byte("CLOSED"))
+	if err != nil {
+		t.Fatalf("error while unmarshalling: %s", err)
+	}
+
+	if ms != MarketStatus_Closed || !ms.IsValid() || ms.IsUnknown() {
+		t.Errorf("mismatched enum: %v", ms)
+	}
+}
+
+func TestEnumsValues(t *testing.T) {
+	values := MarketStatusValues()
+
+	if len(values) != len(marketStatusToString) {
+		t.Fatalf("mismatched number of values: %d", len(values))
+	}
+
+	for _, value := range values {
+		if !value.IsValid() {
+			t.Errorf("invalid value in Values(): %d", value)
+		}
+	}
+
+	if MarketStatus(0).IsValid() {
+		t.Errorf("zero value should not be valid")
+	}
+}
+
+func TestEnumsUnknownStrict(t *testing.T) {
+	mss := []MarketStatus{}
+
+	err := json.Unmarshal([]byte(`["OPEN", "NEW_STATUS"]`), &mss)
+	if err == nil {
+		t.Errorf("expected error while unmarshalling unknown enum value")
+	}
+}
+
+func TestEnumsUnknownTolerant(t *testing.T) {
+	SetTolerantEnums(true)
+	defer SetTolerantEnums(false)
+
+	mss := []MarketStatus{}
+
+	err := json.Unmarshal([]byte(`["OPEN", "NEW_STATUS"]`), &mss)
+	if err != nil {
+		t.Fatalf("error while unmarshalling: %s", err)
+	}
+
+	if mss[0] != MarketStatus_Open {
+		t.Errorf("mismatched enum: %v", mss[0])
+	}
+
+	if !mss[1].IsUnknown() || mss[1].IsValid() || mss[1].String() != "NEW_STATUS" {
+		t.Errorf("unknown enum not preserved: %d - %s", mss[1], mss[1])
+	}
+
+	bytes, err := json.Marshal(mss)
+	if err != nil {
+		t.Fatalf("error while marshalling: %s", err)
+	}
+
+	if string(bytes) != `["OPEN","NEW_STATUS"]` {
+		t.Errorf("mismatched json: %s", bytes)
+	}
+}
+
+func TestEnumsUnknownPerType(t *testing.T) {
+	SetTolerantEnums(true)
+	defer SetTolerantEnums(false)
+
+	var ot OrderType
+	if err := ot.UnmarshalText([]byte("NEW_ORDER_TYPE")); err != nil {
+		t.Fatalf("error while unmarshalling: %s", err)
+	}
+
+	// The same negative value on another enum type isn't one of its unknown values
+	pt := PersistenceType(ot)
+	if pt.IsUnknown() || pt.String() != "" {
+		t.Errorf("unknown value leaked to another enum type: %d - %s", pt, pt)
+	}
+	if _, err := pt.MarshalText(); err == nil {
+		t.Errorf("expected error while marshalling an unregistered value")
+	}
+}
+
+func TestEnumsUnknownLimit(t *testing.T) {
+	SetTolerantEnums(true)
+	defer SetTolerantEnums(false)
+
+	var tifs []TimeInForce
+	for i := 0; i < maxUnknownEnums+10; i++ {
+		var tif TimeInForce
+		if err := tif.UnmarshalText([]byte(fmt.Sprintf("NEW_TIME_IN_FORCE_%d", i))); err != nil {
+			t.Fatalf("error while unmarshalling: %s", err)
+		}
+		tifs = append(tifs, tif)
+	}
+
+	if len(timeInForceUnknown.toValue) != maxUnknownEnums {
+		t.Errorf("got %d unknown values kept, want %d", len(timeInForceUnknown.toValue), maxUnknownEnums)
+	}
+
+	first, last := tifs[0], tifs[len(tifs)-1]
+	if !first.IsUnknown() || first.String() != "NEW_TIME_IN_FORCE_0" {
+		t.Errorf("unknown enum not preserved: %d - %s", first, first)
+	}
+	if !last.IsUnknown() || last.IsValid() || last.String() != "" {
+		t.Errorf("got %d - %s past the limit, want an unknown value without the raw string", last, last)
+	}
+	if _, err := last.MarshalText(); err == nil {
+		t.Errorf("expected error while marshalling an unknown value past the limit")
+	}
+}
@@ -2,6 +2,23 @@ package betting
 
 import "fmt"
 
+type BetfairAPIError struct {
+	Detail      BetfairDetailError `json:"detail"`
+	FaultCode   string             `json:"faultCode"`
+	FaultString string             `json:"faultstring"`
+}
+
+type BetfairDetailError struct {
+	APINGException APINGException `json:"APINGException"`
+	ExceptionName  string         `json:"exceptionname"`
+}
+
+type APINGException struct {
+	ErrorCode    APINGExceptionCode `json:"errorCode"`
+	ErrorDetails string             `json:"errorDetails"`
+	RequestUUID  string             `json:"requestUUID"`
+}
+
 type BettingAPIError struct {
 	ErrorCode    APINGExceptionCode
 	ErrorDetails string
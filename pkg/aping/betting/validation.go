@@ -0,0 +1,111 @@
+package betting
+
+import (
+	"fmt"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/ladder"
+)
+
+// PriceLadderFunc returns the price ladder used by the market.
+type PriceLadderFunc func(marketID string) (ladder.Ladder, error)
+
+// ClassicPriceLadder is a PriceLadderFunc that returns the CLASSIC ladder for every market.
+func ClassicPriceLadder(marketID string) (ladder.Ladder, error) {
+	return ladder.Classic(), nil
+}
+
+// PriceValidationError is returned when an order price is not on the market's price ladder.
+type PriceValidationError struct {
+	MarketID string
+	// Instruction is the index of the offending instruction in the request
+	Instruction int
+	Price       decimal.Price
+	Err         error
+}
+
+func (e *PriceValidationError) Error() string {
+	return fmt.Sprintf("invalid price %s in instruction %d for market %s: %s", e.Price, e.Instruction, e.MarketID, e.Err)
+}
+
+func (e *PriceValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validatePlaceOrders checks the limit order prices against the market's price ladder.
+func (b BettingAPI) validatePlaceOrders(c ContainerPlaceOrders) error {
+	if b.PriceLadder == nil {
+		return nil
+	}
+
+	l, err := b.PriceLadder(c.MarketID)
+	if err != nil {
+		return fmt.Errorf("error while getting price ladder for market %s %w", c.MarketID, err)
+	}
+
+	for i, instruction := range c.Instructions {
+		if instruction.LimitOrder != nil {
+			if err := validatePrice(l, c.MarketID, i, instruction.LimitOrder.Price); err != nil {
+				return err
+			}
+		}
+
+		if instruction.LimitOnCloseOrder != nil {
+			if err := validatePrice(l, c.MarketID, i, instruction.LimitOnCloseOrder.Price); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateReplaceOrders checks the new prices against the market's price ladder.
+func (b BettingAPI) validateReplaceOrders(c ContainerReplaceOrders) error {
+	if b.PriceLadder == nil {
+		return nil
+	}
+
+	l, err := b.PriceLadder(c.MarketID)
+	if err != nil {
+		return fmt.Errorf("error while getting price ladder for market %s %w", c.MarketID, err)
+	}
+
+	for i, instruction := range c.Instructions {
+		if err := validatePrice(l, c.MarketID, i, instruction.NewPrice); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validatePrice(l ladder.Ladder, marketID string, instruction int, price decimal.Price) error {
+	if _, err := l.Index(price); err != nil {
+		return &PriceValidationError{MarketID: marketID, Instruction: instruction, Price: price, Err: err}
+	}
+	return nil
+}
+
+// PriceLadder returns the price ladder described by the market description.
+// Markets without a price ladder description use the CLASSIC ladder.
+func (md MarketDescription) PriceLadder() (ladder.Ladder, error) {
+	if md.PriceLadderDescription == nil {
+		return ladder.Classic(), nil
+	}
+
+	switch md.PriceLadderDescription.Type {
+	case PriceLadderType_Classic:
+		return ladder.Classic(), nil
+	case PriceLadderType_Finest:
+		return ladder.Finest(), nil
+	case PriceLadderType_LineRange:
+		if md.LineRangeInfo == nil {
+			return ladder.Ladder{}, fmt.Errorf("missing line range info for %s price ladder", md.PriceLadderDescription.Type)
+		}
+		return ladder.LineRange(decimal.NewPrice(md.LineRangeInfo.MinUnitValue),
+			decimal.NewPrice(md.LineRangeInfo.MaxUnitValue), decimal.NewPrice(md.LineRangeInfo.Interval))
+	}
+
+	return ladder.Ladder{}, fmt.Errorf("unknown price ladder type %s", md.PriceLadderDescription.Type)
+}
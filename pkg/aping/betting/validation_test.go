@@ -0,0 +1,55 @@
+package betting
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gustavooferreira/betfair/pkg/aping"
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/ladder"
+)
+
+func TestPriceValidation(t *testing.T) {
+	bs := NewBettingAPI(aping.BetfairAPI{AppKey: "", SessionToken: ""})
+	bs.PriceLadder = ClassicPriceLadder
+
+	container := ContainerPlaceOrders{
+		MarketID: "1.170000000",
+		Instructions: []PlaceInstruction{
+			{OrderType: OrderType_Limit, SelectionID: 123, Side: Side_Back,
+				LimitOrder: &LimitOrder{Size: decimal.NewMoneyFromCents(200), Price: decimal.NewPrice(2.02)}},
+			{OrderType: OrderType_Limit, SelectionID: 123, Side: Side_Back,
+				LimitOrder: &LimitOrder{Size: decimal.NewMoneyFromCents(200), Price: decimal.NewPrice(2.01)}},
+		},
+	}
+
+	err := bs.validatePlaceOrders(container)
+	var pve *PriceValidationError
+	if !errors.As(err, &pve) {
+		t.Fatalf("expected PriceValidationError, got: %v", err)
+	}
+
+	if pve.Instruction != 1 || pve.Price != decimal.NewPrice(2.01) || !errors.Is(err, ladder.ErrInvalidPrice) {
+		t.Errorf("PriceValidationError not properly filled, got: %+v", pve)
+	}
+
+	// PlaceOrders must fail before sending anything
+	if _, err := bs.PlaceOrders(container); !errors.As(err, &pve) {
+		t.Errorf("expected PriceValidationError from PlaceOrders, got: %v", err)
+	}
+
+	replace := ContainerReplaceOrders{MarketID: "1.170000000", Instructions: []ReplaceInstruction{{BetID: "1", NewPrice: decimal.NewPrice(1500)}}}
+	if err := bs.validateReplaceOrders(replace); !errors.Is(err, ladder.ErrOutOfRange) {
+		t.Errorf("expected out of range error, got: %v", err)
+	}
+
+	container.Instructions = container.Instructions[:1]
+	if err := bs.validatePlaceOrders(container); err != nil {
+		t.Errorf("unexpected error for valid prices: %s", err)
+	}
+
+	bs.PriceLadder = nil
+	if err := bs.validateReplaceOrders(replace); err != nil {
+		t.Errorf("validation must be skipped when PriceLadder is not set, got: %s", err)
+	}
+}
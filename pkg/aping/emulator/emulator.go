@@ -0,0 +1,284 @@
+// Package emulator is a local stand-in for the betfair Betting API (REST).
+// Exchange is an http.Handler serving the betting endpoints over an in-memory exchange: markets and runners loaded
+// from fixtures, resting liquidity on the price ladders, our orders matched against that liquidity, APINGException
+// errors and rate limiting. Serve it with httptest.NewServer (or any http.Server) and point a BettingAPI at it by
+// setting its Endpoint to Endpoint(server URL), so order flows can be tested and paper traded without betfair.
+//
+// The model is deliberately simple:
+//   - Only LIMIT orders are supported, LIMIT_ON_CLOSE, MARKET_ON_CLOSE and bet target orders are rejected
+//     with INVALID_ORDER_TYPE. There is no starting price reconciliation.
+//   - Our orders only match the liquidity set on the ladders (never each other), at the ladder prices.
+//     Unmatched orders rest on the opposite side of the ladder and match when liquidity is added at their price.
+//   - Async requests and the bet delay are ignored, every request is executed straight away.
+//   - Markets are never settled, closing a market lapses the unmatched orders.
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/aping/betting"
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// Path prefix of the betting endpoints, the operation name follows
+const bettingPath = "/exchange/betting/rest/v1.0/"
+
+// Largest request body accepted
+const maxRequestSize = 1024 * 1024
+
+// Defaults used when the config leaves them unset
+const (
+	defaultMinimumStakeCents = 100
+	defaultBurst             = 1
+)
+
+// Config holds the emulator settings.
+type Config struct {
+	// AppKey accepted on the X-Application header, any app key is accepted when empty
+	AppKey string
+	// SessionTokens accepted on the X-Authentication header, any session token is accepted when empty.
+	// More can be added and revoked later on.
+	SessionTokens []string
+	// MinimumStake is the smallest size accepted on orders (1 by default)
+	MinimumStake decimal.Money
+	// RequestsPerSecond limits the requests of each session token, going over it fails with TOO_MANY_REQUESTS
+	// (0 means no limit)
+	RequestsPerSecond float64
+	// Burst is how many requests can be made at once before the rate limit kicks in (1 by default)
+	Burst int
+}
+
+// Exchange is the emulated Betting API.
+// It's thread safe!
+type Exchange struct {
+	config Config
+
+	// Guards everything below
+	mu            sync.Mutex
+	sessionTokens map[string]bool
+	// Set when only the session tokens in sessionTokens are accepted
+	checkTokens bool
+	limiters    map[string]*rateLimiter
+	// Requests to fail (key: operation)
+	failures map[string][]betting.APINGException
+	// Number of requests served so far, used for the request UUIDs
+	requestCount int
+	markets      map[string]*market
+	// Market IDs in the order they were added
+	marketIDs []string
+	orders    map[string]*order
+	// Bet IDs in the order the orders were placed
+	betIDs     []string
+	betCount   int64
+	matchCount int64
+	// When each customerRef was last seen, used to reject duplicate transactions
+	customerRefs map[string]time.Time
+}
+
+// NewExchange creates an exchange without any markets, add them with AddMarket or LoadFixtures.
+func NewExchange(config Config) *Exchange {
+	if config.MinimumStake <= 0 {
+		config.MinimumStake = decimal.NewMoneyFromCents(defaultMinimumStakeCents)
+	}
+	if config.Burst <= 0 {
+		config.Burst = defaultBurst
+	}
+
+	e := &Exchange{
+		config:        config,
+		sessionTokens: make(map[string]bool),
+		checkTokens:   len(config.SessionTokens) > 0,
+		limiters:      make(map[string]*rateLimiter),
+		failures:      make(map[string][]betting.APINGException),
+		markets:       make(map[string]*market),
+		orders:        make(map[string]*order),
+		customerRefs:  make(map[string]time.Time),
+	}
+	for _, token := range config.SessionTokens {
+		e.sessionTokens[token] = true
+	}
+
+	return e
+}
+
+// AddSessionToken makes the exchange accept a new session token.
+// Only makes a difference once session tokens are checked, i.e. SessionTokens was set or a token was revoked.
+func (e *Exchange) AddSessionToken(token string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.sessionTokens[token] = true
+}
+
+// RevokeSessionToken makes the exchange reject a session token with INVALID_SESSION_INFORMATION.
+func (e *Exchange) RevokeSessionToken(token string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.sessionTokens, token)
+	e.checkTokens = true
+}
+
+// FailNext makes the next request to the operation (e.g. "placeOrders") fail with the APINGException error code.
+// Calling it again queues more failures.
+func (e *Exchange) FailNext(operation string, code betting.APINGExceptionCode, details string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures[operation] = append(e.failures[operation], betting.APINGException{ErrorCode: code, ErrorDetails: details})
+}
+
+// ServeHTTP answers a Betting API request.
+func (e *Exchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, bettingPath) {
+		http.NotFound(w, r)
+		return
+	} else if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	operation := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, bettingPath), "/")
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requestCount++
+	requestUUID := fmt.Sprintf("emulator-%010d", e.requestCount)
+
+	if err != nil {
+		e.writeError(w, requestUUID, betting.APINGExceptionCode_RequestSizeExceedsLimit, "request body too large")
+		return
+	}
+
+	if apinge := e.checkRequest(r.Header, operation, time.Now()); apinge != nil {
+		e.writeError(w, requestUUID, apinge.ErrorCode, apinge.ErrorDetails)
+		return
+	}
+
+	handler, ok := handlers[operation]
+	if !ok {
+		e.writeError(w, requestUUID, betting.APINGExceptionCode_InvalidInputData, "operation not supported by the emulator: "+operation)
+		return
+	}
+
+	result, apinge := handler(e, body)
+	if apinge != nil {
+		e.writeError(w, requestUUID, apinge.ErrorCode, apinge.ErrorDetails)
+		return
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Log(globals.Logger, log.ERROR, "emulator failed marshalling response", log.Fields{"operation": operation, "error": err.Error()})
+		e.writeError(w, requestUUID, betting.APINGExceptionCode_UnexpectedError, "error marshalling response")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// checkRequest checks the credentials, the rate limit and the queued failures (must hold mu)
+func (e *Exchange) checkRequest(header http.Header, operation string, now time.Time) *betting.APINGException {
+	appKey := header.Get("X-Application")
+	sessionToken := header.Get("X-Authentication")
+
+	if appKey == "" {
+		return apingException(betting.APINGExceptionCode_NoAppKey, "missing X-Application header")
+	} else if e.config.AppKey != "" && appKey != e.config.AppKey {
+		return apingException(betting.APINGExceptionCode_InvalidAppKey, "invalid app key")
+	} else if sessionToken == "" {
+		return apingException(betting.APINGExceptionCode_NoSession, "missing X-Authentication header")
+	} else if e.checkTokens && !e.sessionTokens[sessionToken] {
+		return apingException(betting.APINGExceptionCode_InvalidSessionInformation, "invalid session token")
+	}
+
+	if e.config.RequestsPerSecond > 0 {
+		limiter, ok := e.limiters[sessionToken]
+		if !ok {
+			limiter = newRateLimiter(e.config.RequestsPerSecond, e.config.Burst, now)
+			e.limiters[sessionToken] = limiter
+		}
+		if !limiter.allow(now) {
+			return apingException(betting.APINGExceptionCode_TooManyRequests, "too many requests")
+		}
+	}
+
+	if failures := e.failures[operation]; len(failures) > 0 {
+		e.failures[operation] = failures[1:]
+		return &failures[0]
+	}
+
+	return nil
+}
+
+// writeError replies with an APINGException, the same way betfair does
+func (e *Exchange) writeError(w http.ResponseWriter, requestUUID string, code betting.APINGExceptionCode, details string) {
+	bapie := betting.BetfairAPIError{
+		Detail: betting.BetfairDetailError{
+			APINGException: betting.APINGException{ErrorCode: code, ErrorDetails: details, RequestUUID: requestUUID},
+			ExceptionName:  "APINGException",
+		},
+		FaultCode:   "Client",
+		FaultString: fmt.Sprintf("ANGX-%04d", int(code)),
+	}
+
+	data, err := json.Marshal(bapie)
+	if err != nil {
+		log.Log(globals.Logger, log.ERROR, "emulator failed marshalling error", log.Fields{"error": err.Error()})
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(data)
+}
+
+func apingException(code betting.APINGExceptionCode, details string) *betting.APINGException {
+	return &betting.APINGException{ErrorCode: code, ErrorDetails: details}
+}
+
+// rateLimiter is a token bucket
+type rateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int, now time.Time) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// allow takes a token from the bucket, returns false if there are none left
+func (rl *rateLimiter) allow(now time.Time) bool {
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.last = now
+
+	if rl.tokens < 1 {
+		return false
+	}
+	rl.tokens--
+	return true
+}
+
+// Endpoint returns the Betting API endpoint of the emulator served at serverURL (e.g. httptest.Server.URL),
+// to set on BettingAPI.Endpoint.
+func Endpoint(serverURL string) string {
+	return strings.TrimSuffix(serverURL, "/") + bettingPath
+}
@@ -0,0 +1,537 @@
+package emulator
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gustavooferreira/betfair/pkg/aping"
+	"github.com/gustavooferreira/betfair/pkg/aping/betting"
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+)
+
+const (
+	horseRace    = "1.170000001"
+	football     = "1.180000001"
+	redRum       = 101
+	arkle        = 102
+	desertOrchid = 103
+	homeTeam     = 201
+	appKey       = "app_key"
+	sessionKey   = "session_token"
+)
+
+// newExchange creates an exchange loaded with the test fixtures and a client for it
+func newExchange(t *testing.T, config Config) (*Exchange, betting.BettingAPI) {
+	t.Helper()
+
+	e := NewExchange(config)
+
+	f, err := os.Open("testdata/markets.json")
+	if err != nil {
+		t.Fatalf("error opening fixtures - error: %s", err)
+	}
+	defer f.Close()
+
+	if err := e.LoadFixtures(f); err != nil {
+		t.Fatalf("error loading fixtures - error: %s", err)
+	}
+
+	ts := httptest.NewServer(e)
+	t.Cleanup(ts.Close)
+
+	bapi := betting.NewBettingAPI(aping.NewBetfairAPI(ts.Client(), appKey, sessionKey))
+	bapi.Endpoint = Endpoint(ts.URL)
+
+	return e, bapi
+}
+
+func limitOrder(selectionID int64, side betting.Side, price string, size string) betting.PlaceInstruction {
+	return betting.PlaceInstruction{
+		OrderType:   betting.OrderType_Limit,
+		SelectionID: selectionID,
+		Side:        side,
+		LimitOrder:  &betting.LimitOrder{Price: p(price), Size: money(size), PersistenceType: betting.PersistenceType_Lapse},
+	}
+}
+
+// place places the orders, failing the test unless the request succeeds
+func place(t *testing.T, bapi betting.BettingAPI, marketID string, instructions ...betting.PlaceInstruction) betting.PlaceExecutionReport {
+	t.Helper()
+
+	report, err := bapi.PlaceOrders(betting.ContainerPlaceOrders{MarketID: marketID, Instructions: instructions})
+	if err != nil {
+		t.Fatalf("error placing orders - error: %s", err)
+	} else if report.Status != betting.ExecutionReportStatus_Success {
+		t.Fatalf("placing orders failed - report: %+v", report)
+	}
+	return report
+}
+
+func currentOrder(t *testing.T, bapi betting.BettingAPI, betID string) betting.CurrentOrderSummary {
+	t.Helper()
+
+	report, err := bapi.ListCurrentOrders(betting.ContainerListCurrentOrders{BetIDs: []string{betID}})
+	if err != nil {
+		t.Fatalf("error listing orders - error: %s", err)
+	} else if len(report.CurrentOrders) != 1 {
+		t.Fatalf("expected 1 order, got %d", len(report.CurrentOrders))
+	}
+	return report.CurrentOrders[0]
+}
+
+func runnerBook(t *testing.T, bapi betting.BettingAPI, marketID string, selectionID int64) betting.Runner {
+	t.Helper()
+
+	pp := betting.PriceProjection{PriceData: []betting.PriceData{betting.PriceData_ExAllOffers}}
+	books, err := bapi.ListRunnerBook(betting.ContainerListRunnerBook{MarketID: marketID, SelectionID: selectionID, PriceProjection: &pp})
+	if err != nil {
+		t.Fatalf("error listing runner book - error: %s", err)
+	} else if len(books) != 1 || len(books[0].Runners) != 1 {
+		t.Fatalf("expected 1 runner book, got %+v", books)
+	}
+	return books[0].Runners[0]
+}
+
+func p(s string) decimal.Price {
+	price, _ := decimal.ParsePrice(s)
+	return price
+}
+
+func money(s string) decimal.Money {
+	m, _ := decimal.ParseMoney(s)
+	return m
+}
+
+func apingErrorCode(t *testing.T, err error) betting.APINGExceptionCode {
+	t.Helper()
+
+	var bae *betting.BettingAPIError
+	if !errors.As(err, &bae) {
+		t.Fatalf("expected a betting API error, got %v", err)
+	}
+	return bae.ErrorCode
+}
+
+func TestListMarketCatalogue(t *testing.T) {
+	_, bapi := newExchange(t, Config{})
+
+	tests := map[string]struct {
+		filter   betting.MarketFilter
+		sort     *betting.MarketSort
+		expected []string
+	}{
+		"all markets":     {filter: betting.MarketFilter{}, expected: []string{horseRace, football}},
+		"event type":      {filter: betting.MarketFilter{EventTypeIDs: []string{"7"}}, expected: []string{horseRace}},
+		"market type":     {filter: betting.MarketFilter{MarketTypeCodes: []string{"MATCH_ODDS"}}, expected: []string{football}},
+		"venue":           {filter: betting.MarketFilter{Venues: []string{"Cheltenham"}}, expected: []string{horseRace}},
+		"text query":      {filter: betting.MarketFilter{TextQuery: "home v"}, expected: []string{football}},
+		"no match":        {filter: betting.MarketFilter{MarketCountries: []string{"IE"}}, expected: []string{}},
+		"last to start":   {filter: betting.MarketFilter{}, sort: marketSort(betting.MarketSort_LastToStart), expected: []string{football, horseRace}},
+		"maximum traded":  {filter: betting.MarketFilter{}, sort: marketSort(betting.MarketSort_MaximumTraded), expected: []string{horseRace, football}},
+		"competition IDs": {filter: betting.MarketFilter{CompetitionIDs: []string{"10932509"}}, expected: []string{football}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mcs, err := bapi.ListMarketCatalogue(betting.ContainerListMarketCatalogue{Filter: test.filter, Sort: test.sort, MaxResults: 10})
+			if err != nil {
+				t.Fatalf("error listing markets - error: %s", err)
+			}
+
+			var ids []string
+			for _, mc := range mcs {
+				ids = append(ids, mc.MarketID)
+			}
+			if len(ids) != len(test.expected) {
+				t.Fatalf("expected markets %v, got %v", test.expected, ids)
+			}
+			for i := range ids {
+				if ids[i] != test.expected[i] {
+					t.Fatalf("expected markets %v, got %v", test.expected, ids)
+				}
+			}
+		})
+	}
+}
+
+func marketSort(ms betting.MarketSort) *betting.MarketSort {
+	return &ms
+}
+
+func TestListMarketCatalogueProjection(t *testing.T) {
+	_, bapi := newExchange(t, Config{})
+
+	mcs, err := bapi.ListMarketCatalogue(betting.ContainerListMarketCatalogue{
+		Filter:           betting.MarketFilter{MarketIDs: []string{horseRace}},
+		MarketProjection: []betting.MarketProjection{betting.MarketProjection_RunnerDescription, betting.MarketProjection_Event},
+		MaxResults:       1,
+	})
+	if err != nil {
+		t.Fatalf("error listing markets - error: %s", err)
+	} else if len(mcs) != 1 {
+		t.Fatalf("expected 1 market, got %d", len(mcs))
+	}
+
+	mc := mcs[0]
+	if mc.Event == nil || mc.Event.Venue != "Cheltenham" {
+		t.Errorf("expected the event, got %+v", mc.Event)
+	}
+	if mc.Description != nil || mc.EventType != nil {
+		t.Errorf("expected no description nor event type, got %+v %+v", mc.Description, mc.EventType)
+	}
+	if len(mc.Runners) != 3 || mc.Runners[0].RunnerName != "Red Rum" || mc.Runners[0].Metadata != nil {
+		t.Errorf("expected runners without metadata, got %+v", mc.Runners)
+	}
+	if mc.TotalMatched == nil || *mc.TotalMatched != money("370") {
+		t.Errorf("expected total matched 370, got %v", mc.TotalMatched)
+	}
+
+	_, err = bapi.ListMarketCatalogue(betting.ContainerListMarketCatalogue{
+		MarketProjection: []betting.MarketProjection{betting.MarketProjection_MarketDescription, betting.MarketProjection_RunnerMetadata},
+		MaxResults:       101,
+	})
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_TooMuchData {
+		t.Errorf("expected TOO_MUCH_DATA, got %s", code)
+	}
+}
+
+func TestListMarketBook(t *testing.T) {
+	_, bapi := newExchange(t, Config{})
+
+	depth := 2
+	pp := betting.PriceProjection{
+		PriceData:             []betting.PriceData{betting.PriceData_ExBestOffers, betting.PriceData_ExTraded},
+		ExBestOffersOverrides: &betting.ExBestOffersOverrides{BestPricesDepth: &depth},
+	}
+	books, err := bapi.ListMarketBook(betting.ContainerListMarketBook{MarketIDs: []string{horseRace, "1.0"}, PriceProjection: &pp})
+	if err != nil {
+		t.Fatalf("error listing market book - error: %s", err)
+	} else if len(books) != 1 {
+		t.Fatalf("expected 1 market book, got %d", len(books))
+	}
+
+	mb := books[0]
+	if *mb.Status != betting.MarketStatus_Open || *mb.NumberOfActiveRunners != 3 || len(mb.Runners) != 3 {
+		t.Fatalf("unexpected market book %+v", mb)
+	}
+
+	ex := mb.Runners[0].Ex
+	expectedATB := []betting.PriceSize{{Price: p("3"), Size: money("10")}, {Price: p("2.98"), Size: money("20")}}
+	if len(ex.AvailableToBack) != 2 || ex.AvailableToBack[0] != expectedATB[0] || ex.AvailableToBack[1] != expectedATB[1] {
+		t.Errorf("expected available to back %v, got %v", expectedATB, ex.AvailableToBack)
+	}
+	if len(ex.AvailableToLay) != 2 || ex.AvailableToLay[0].Price != p("3.05") {
+		t.Errorf("unexpected available to lay %v", ex.AvailableToLay)
+	}
+	if len(ex.TradedVolume) != 2 || ex.TradedVolume[0].Price != p("3") {
+		t.Errorf("unexpected traded volume %v", ex.TradedVolume)
+	}
+
+	marketIDs := make([]string, 10)
+	pp = betting.PriceProjection{PriceData: []betting.PriceData{betting.PriceData_ExAllOffers, betting.PriceData_ExTraded}}
+	_, err = bapi.ListMarketBook(betting.ContainerListMarketBook{MarketIDs: marketIDs, PriceProjection: &pp})
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_TooMuchData {
+		t.Errorf("expected TOO_MUCH_DATA, got %s", code)
+	}
+}
+
+func TestPlaceOrdersMatching(t *testing.T) {
+	_, bapi := newExchange(t, Config{})
+
+	// Backing at 2.98 takes the 3.0 and 2.98 levels, the rest of the order waits at 2.98
+	report := place(t, bapi, horseRace, limitOrder(redRum, betting.Side_Back, "2.98", "40"))
+	ir := report.InstructionReports[0]
+	if ir.Status != betting.InstructionReportStatus_Success || *ir.OrderStatus != betting.OrderStatus_Executable {
+		t.Fatalf("unexpected instruction report %+v", ir)
+	}
+	if *ir.SizeMatched != money("30") || *ir.AveragePriceMatched != p("2.986667") {
+		t.Errorf("expected 30 matched at 2.986667, got %s at %s", ir.SizeMatched, ir.AveragePriceMatched)
+	}
+
+	rb := runnerBook(t, bapi, horseRace, redRum)
+	if rb.Ex.AvailableToBack[0] != (betting.PriceSize{Price: p("2.96"), Size: money("50")}) {
+		t.Errorf("expected the best price to back to be 50 at 2.96, got %v", rb.Ex.AvailableToBack[0])
+	}
+	if rb.Ex.AvailableToLay[0] != (betting.PriceSize{Price: p("2.98"), Size: money("10")}) {
+		t.Errorf("expected our order on the lay side, got %v", rb.Ex.AvailableToLay[0])
+	}
+	if *rb.LastPriceTraded != p("2.98") {
+		t.Errorf("expected last price traded 2.98, got %s", rb.LastPriceTraded)
+	}
+
+	// Laying at 4.6 takes part of the liquidity and the order is complete
+	report = place(t, bapi, horseRace, limitOrder(arkle, betting.Side_Lay, "4.7", "5"))
+	ir = report.InstructionReports[0]
+	if *ir.OrderStatus != betting.OrderStatus_ExecutionComplete || *ir.SizeMatched != money("5") || *ir.AveragePriceMatched != p("4.6") {
+		t.Errorf("unexpected instruction report %+v", ir)
+	}
+
+	pl, err := bapi.ListMarketProfitAndLoss(betting.ContainerListMarketProfitAndLoss{MarketIDs: []string{horseRace}})
+	if err != nil {
+		t.Fatalf("error listing profit and loss - error: %s", err)
+	}
+
+	// Back 10 at 3, 20 at 2.98 and lay 5 at 4.6
+	expected := map[int64]decimal.Money{redRum: money("64.6"), arkle: money("-48"), desertOrchid: money("-25")}
+	for _, rpl := range pl[0].ProfitAndLosses {
+		if *rpl.IfWin != expected[*rpl.SelectionID] {
+			t.Errorf("expected %s if %d wins, got %s", expected[*rpl.SelectionID], *rpl.SelectionID, rpl.IfWin)
+		}
+	}
+}
+
+func TestRestingOrderMatchesNewLiquidity(t *testing.T) {
+	e, bapi := newExchange(t, Config{})
+
+	report := place(t, bapi, horseRace, limitOrder(redRum, betting.Side_Back, "3.2", "10"))
+	betID := report.InstructionReports[0].BetID
+	if *report.InstructionReports[0].SizeMatched != 0 {
+		t.Fatalf("expected the order to wait, matched %s", report.InstructionReports[0].SizeMatched)
+	}
+
+	err := e.UpdateLadder(betting.RunnerID{MarketID: horseRace, SelectionID: redRum}, betting.Side_Back,
+		[]betting.PriceSize{{Price: p("3.25"), Size: money("4")}})
+	if err != nil {
+		t.Fatalf("error updating ladder - error: %s", err)
+	}
+
+	cos := currentOrder(t, bapi, betID)
+	if cos.Status != betting.OrderStatus_Executable || *cos.SizeMatched != money("4") || *cos.SizeRemaining != money("6") {
+		t.Errorf("expected 4 matched and 6 remaining, got %+v", cos)
+	}
+	if cos.MatchedDate == nil || *cos.AveragePriceMatched != p("3.25") {
+		t.Errorf("expected matched at 3.25, got %+v", cos)
+	}
+}
+
+func TestPlaceOrdersErrors(t *testing.T) {
+	e, bapi := newExchange(t, Config{})
+
+	fillOrKill := betting.TimeInForce_FillOrKill
+	fok := limitOrder(redRum, betting.Side_Back, "3", "15")
+	fok.LimitOrder.TimeInForce = &fillOrKill
+
+	tests := map[string]struct {
+		marketID       string
+		instructions   []betting.PlaceInstruction
+		errorCode      betting.ExecutionReportErrorCode
+		instructionErr betting.InstructionReportErrorCode
+	}{
+		"unknown market":   {marketID: "1.0", instructions: []betting.PlaceInstruction{limitOrder(redRum, betting.Side_Back, "3", "2")}, errorCode: betting.ExecutionReportErrorCode_InvalidMarketId},
+		"invalid odds":     {marketID: horseRace, instructions: []betting.PlaceInstruction{limitOrder(redRum, betting.Side_Back, "3.01", "2")}, errorCode: betting.ExecutionReportErrorCode_BetActionError, instructionErr: betting.InstructionReportErrorCode_InvalidOdds},
+		"invalid bet size": {marketID: horseRace, instructions: []betting.PlaceInstruction{limitOrder(redRum, betting.Side_Back, "3", "0.5")}, errorCode: betting.ExecutionReportErrorCode_BetActionError, instructionErr: betting.InstructionReportErrorCode_InvalidBetSize},
+		"invalid runner":   {marketID: horseRace, instructions: []betting.PlaceInstruction{limitOrder(999, betting.Side_Back, "3", "2")}, errorCode: betting.ExecutionReportErrorCode_BetActionError, instructionErr: betting.InstructionReportErrorCode_InvalidRunner},
+		"fill or kill persistence": {marketID: horseRace, instructions: []betting.PlaceInstruction{func() betting.PlaceInstruction {
+			pi := limitOrder(redRum, betting.Side_Back, "3", "15")
+			pi.LimitOrder.TimeInForce = &fillOrKill
+			pi.LimitOrder.PersistenceType = betting.PersistenceType_Persist
+			return pi
+		}()}, errorCode: betting.ExecutionReportErrorCode_BetActionError, instructionErr: betting.InstructionReportErrorCode_TimeInForceConflict},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			report, err := bapi.PlaceOrders(betting.ContainerPlaceOrders{MarketID: test.marketID, Instructions: test.instructions})
+			if err != nil {
+				t.Fatalf("error placing orders - error: %s", err)
+			}
+			if report.Status != betting.ExecutionReportStatus_Failure || *report.ErrorCode != test.errorCode {
+				t.Fatalf("expected failure with %s, got %+v", test.errorCode, report)
+			}
+			if test.instructionErr != 0 && *report.InstructionReports[0].ErrorCode != test.instructionErr {
+				t.Errorf("expected instruction error %s, got %s", test.instructionErr, report.InstructionReports[0].ErrorCode)
+			}
+		})
+	}
+
+	// Only 10 available at 3, the order is killed
+	report := place(t, bapi, horseRace, fok)
+	if *report.InstructionReports[0].OrderStatus != betting.OrderStatus_Expired || *report.InstructionReports[0].SizeMatched != 0 {
+		t.Errorf("expected the order to be killed, got %+v", report.InstructionReports[0])
+	}
+
+	// Nothing is placed when one of the instructions fails
+	report, err := bapi.PlaceOrders(betting.ContainerPlaceOrders{MarketID: horseRace, Instructions: []betting.PlaceInstruction{
+		limitOrder(redRum, betting.Side_Back, "3", "2"), limitOrder(redRum, betting.Side_Back, "3.01", "2")}})
+	if err != nil {
+		t.Fatalf("error placing orders - error: %s", err)
+	} else if *report.InstructionReports[0].ErrorCode != betting.InstructionReportErrorCode_RelatedActionFailed {
+		t.Errorf("expected RELATED_ACTION_FAILED, got %+v", report.InstructionReports[0])
+	}
+	if orders, _ := bapi.ListCurrentOrders(betting.ContainerListCurrentOrders{}); len(orders.CurrentOrders) != 1 {
+		t.Errorf("expected only the killed order, got %+v", orders.CurrentOrders)
+	}
+
+	// Duplicate transactions are rejected
+	c := betting.ContainerPlaceOrders{MarketID: horseRace, CustomerRef: "ref-1",
+		Instructions: []betting.PlaceInstruction{limitOrder(redRum, betting.Side_Back, "5", "2")}}
+	if report, err := bapi.PlaceOrders(c); err != nil || report.Status != betting.ExecutionReportStatus_Success {
+		t.Fatalf("expected success, got %+v %v", report, err)
+	}
+	if report, err := bapi.PlaceOrders(c); err != nil || *report.ErrorCode != betting.ExecutionReportErrorCode_DuplicateTransaction {
+		t.Errorf("expected DUPLICATE_TRANSACTION, got %+v %v", report, err)
+	}
+
+	if err := e.SetMarketStatus(horseRace, betting.MarketStatus_Suspended); err != nil {
+		t.Fatalf("error suspending market - error: %s", err)
+	}
+	report, err = bapi.PlaceOrders(betting.ContainerPlaceOrders{MarketID: horseRace, Instructions: []betting.PlaceInstruction{limitOrder(redRum, betting.Side_Back, "3", "2")}})
+	if err != nil || *report.ErrorCode != betting.ExecutionReportErrorCode_MarketSuspended {
+		t.Errorf("expected MARKET_SUSPENDED, got %+v %v", report, err)
+	}
+}
+
+func TestCancelReplaceUpdateOrders(t *testing.T) {
+	e, bapi := newExchange(t, Config{})
+
+	report := place(t, bapi, horseRace,
+		limitOrder(redRum, betting.Side_Back, "4", "10"),
+		limitOrder(arkle, betting.Side_Lay, "4", "10"),
+		limitOrder(desertOrchid, betting.Side_Back, "10", "10"))
+	backBetID := report.InstructionReports[0].BetID
+	layBetID := report.InstructionReports[1].BetID
+	otherBetID := report.InstructionReports[2].BetID
+
+	// Partial cancel
+	reduction := money("4")
+	cancelReport, err := bapi.CancelOrders(betting.ContainerCancelOrders{MarketID: horseRace,
+		Instructions: []betting.CancelInstruction{{BetID: backBetID, SizeReduction: &reduction}, {BetID: "1"}}})
+	if err != nil {
+		t.Fatalf("error cancelling orders - error: %s", err)
+	}
+	if cancelReport.Status != betting.ExecutionReportStatus_ProcessedWithErrors {
+		t.Errorf("expected PROCESSED_WITH_ERRORS, got %s", cancelReport.Status)
+	}
+	if ir := cancelReport.InstructionReports[0]; ir.Status != betting.InstructionReportStatus_Success || ir.SizeCancelled != reduction {
+		t.Errorf("expected 4 cancelled, got %+v", ir)
+	}
+	if ir := cancelReport.InstructionReports[1]; *ir.ErrorCode != betting.InstructionReportErrorCode_InvalidBetId {
+		t.Errorf("expected INVALID_BET_ID, got %+v", ir)
+	}
+
+	// Replacing the back order at 3 matches 6 at 3
+	replaceReport, err := bapi.ReplaceOrders(betting.ContainerReplaceOrders{MarketID: horseRace,
+		Instructions: []betting.ReplaceInstruction{{BetID: backBetID, NewPrice: p("3")}}})
+	if err != nil {
+		t.Fatalf("error replacing orders - error: %s", err)
+	} else if replaceReport.Status != betting.ExecutionReportStatus_Success {
+		t.Fatalf("expected success, got %+v", replaceReport)
+	}
+	pir := replaceReport.InstructionReports[0].PlaceInstructionReport
+	if replaceReport.InstructionReports[0].CancelInstructionReport.SizeCancelled != money("6") || *pir.SizeMatched != money("6") {
+		t.Errorf("expected 6 replaced and matched, got %+v", replaceReport.InstructionReports[0])
+	}
+	if cos := currentOrder(t, bapi, backBetID); cos.Status != betting.OrderStatus_ExecutionComplete || *cos.SizeCancelled != money("10") {
+		t.Errorf("expected the old order cancelled, got %+v", cos)
+	}
+
+	updateReport, err := bapi.UpdateOrders(betting.ContainerUpdateOrders{MarketID: horseRace, Instructions: []betting.UpdateInstruction{
+		{BetID: layBetID, NewPersistenceType: betting.PersistenceType_Persist},
+		{BetID: backBetID, NewPersistenceType: betting.PersistenceType_Persist}}})
+	if err != nil {
+		t.Fatalf("error updating orders - error: %s", err)
+	}
+	if updateReport.InstructionReports[0].Status != betting.InstructionReportStatus_Success {
+		t.Errorf("expected the update to succeed, got %+v", updateReport.InstructionReports[0])
+	}
+	if ir := updateReport.InstructionReports[1]; *ir.ErrorCode != betting.InstructionReportErrorCode_BetTakenOrLapsed {
+		t.Errorf("expected BET_TAKEN_OR_LAPSED, got %+v", ir)
+	}
+
+	// Only the persisted order survives turning in-play
+	if err := e.SetInPlay(horseRace, true, 1); err != nil {
+		t.Fatalf("error turning in-play - error: %s", err)
+	}
+	if cos := currentOrder(t, bapi, layBetID); cos.Status != betting.OrderStatus_Executable {
+		t.Errorf("expected the persisted order to survive, got %+v", cos)
+	}
+	if cos := currentOrder(t, bapi, otherBetID); cos.Status != betting.OrderStatus_ExecutionComplete || *cos.SizeLapsed != money("10") {
+		t.Errorf("expected the order lapsed, got %+v", cos)
+	}
+
+	// Cancelling everything
+	cancelReport, err = bapi.CancelOrders(betting.ContainerCancelOrders{})
+	if err != nil || cancelReport.Status != betting.ExecutionReportStatus_Success {
+		t.Fatalf("expected success, got %+v %v", cancelReport, err)
+	}
+	executable := betting.OrderProjection_Executable
+	if orders, _ := bapi.ListCurrentOrders(betting.ContainerListCurrentOrders{OrderProjection: &executable}); len(orders.CurrentOrders) != 0 {
+		t.Errorf("expected no executable orders, got %+v", orders.CurrentOrders)
+	}
+}
+
+func TestListCurrentOrdersPaging(t *testing.T) {
+	_, bapi := newExchange(t, Config{})
+
+	for i := 0; i < 5; i++ {
+		place(t, bapi, football, limitOrder(homeTeam, betting.Side_Back, "10", "2"))
+	}
+
+	from, count := 3, 2
+	sortDir := betting.SortDir_LatestToEarliest
+	report, err := bapi.ListCurrentOrders(betting.ContainerListCurrentOrders{FromRecord: &from, RecordCount: &count})
+	if err != nil {
+		t.Fatalf("error listing orders - error: %s", err)
+	} else if len(report.CurrentOrders) != 2 || report.MoreAvailable {
+		t.Errorf("expected the last 2 orders, got %d, more available: %t", len(report.CurrentOrders), report.MoreAvailable)
+	}
+
+	from = 0
+	latest, err := bapi.ListCurrentOrders(betting.ContainerListCurrentOrders{FromRecord: &from, RecordCount: &count, SortDir: &sortDir})
+	if err != nil {
+		t.Fatalf("error listing orders - error: %s", err)
+	} else if !latest.MoreAvailable || latest.CurrentOrders[0].BetID != report.CurrentOrders[1].BetID {
+		t.Errorf("expected the latest order first, got %+v", latest.CurrentOrders)
+	}
+}
+
+func TestAPINGExceptions(t *testing.T) {
+	e, bapi := newExchange(t, Config{AppKey: appKey, SessionTokens: []string{sessionKey}})
+
+	c := betting.ContainerListMarketCatalogue{MaxResults: 1}
+
+	e.FailNext("listMarketCatalogue", betting.APINGExceptionCode_ServiceBusy, "busy")
+	_, err := bapi.ListMarketCatalogue(c)
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_ServiceBusy {
+		t.Errorf("expected SERVICE_BUSY, got %s", code)
+	}
+	if _, err := bapi.ListMarketCatalogue(c); err != nil {
+		t.Errorf("expected the next request to succeed, got %s", err)
+	}
+
+	wrongKey := bapi
+	wrongKey.AppKey = "wrong"
+	_, err = wrongKey.ListMarketCatalogue(c)
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_InvalidAppKey {
+		t.Errorf("expected INVALID_APP_KEY, got %s", code)
+	}
+
+	_, err = bapi.ListEventTypes(betting.ContainerListEventTypes{})
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_InvalidInputData {
+		t.Errorf("expected INVALID_INPUT_DATA for an unsupported operation, got %s", code)
+	}
+
+	e.RevokeSessionToken(sessionKey)
+	_, err = bapi.ListMarketCatalogue(c)
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_InvalidSessionInformation {
+		t.Errorf("expected INVALID_SESSION_INFORMATION, got %s", code)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	_, bapi := newExchange(t, Config{RequestsPerSecond: 0.001, Burst: 3})
+
+	c := betting.ContainerListMarketCatalogue{MaxResults: 1}
+	for i := 0; i < 3; i++ {
+		if _, err := bapi.ListMarketCatalogue(c); err != nil {
+			t.Fatalf("expected request %d to succeed, got %s", i, err)
+		}
+	}
+
+	_, err := bapi.ListMarketCatalogue(c)
+	if code := apingErrorCode(t, err); code != betting.APINGExceptionCode_TooManyRequests {
+		t.Errorf("expected TOO_MANY_REQUESTS, got %s", code)
+	}
+}
@@ -0,0 +1,31 @@
+package emulator
+
+import (
+	"encoding/json"
+
+	"github.com/gustavooferreira/betfair/pkg/aping/betting"
+)
+
+// handler answers a request to an operation, taking the json encoded parameters (called holding mu)
+type handler func(e *Exchange, body []byte) (interface{}, *betting.APINGException)
+
+// handlers has the operations supported by the emulator (key: operation)
+var handlers = map[string]handler{
+	"listMarketCatalogue":     (*Exchange).listMarketCatalogue,
+	"listMarketBook":          (*Exchange).listMarketBook,
+	"listRunnerBook":          (*Exchange).listRunnerBook,
+	"listMarketProfitAndLoss": (*Exchange).listMarketProfitAndLoss,
+	"listCurrentOrders":       (*Exchange).listCurrentOrders,
+	"placeOrders":             (*Exchange).placeOrders,
+	"cancelOrders":            (*Exchange).cancelOrders,
+	"replaceOrders":           (*Exchange).replaceOrders,
+	"updateOrders":            (*Exchange).updateOrders,
+}
+
+// decode unmarshals the request parameters, failing with INVALID_INPUT_DATA
+func decode(body []byte, params interface{}) *betting.APINGException {
+	if err := json.Unmarshal(body, params); err != nil {
+		return apingException(betting.APINGExceptionCode_InvalidInputData, "invalid request: "+err.Error())
+	}
+	return nil
+}
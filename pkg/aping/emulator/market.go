@@ -0,0 +1,817 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/aping/betting"
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/ladder"
+)
+
+// Fixtures holds the markets loaded into the exchange by LoadFixtures.
+type Fixtures struct {
+	Markets []MarketFixture `json:"markets"`
+}
+
+// MarketFixture describes a market and the liquidity available on its runners.
+type MarketFixture struct {
+	// Catalogue is returned by listMarketCatalogue (according to the projection), its runners are the market's runners.
+	// The price ladder is taken from the description, CLASSIC by default.
+	Catalogue betting.MarketCatalogue `json:"catalogue"`
+	// OPEN by default
+	Status   betting.MarketStatus `json:"status"`
+	InPlay   bool                 `json:"inplay"`
+	BetDelay int                  `json:"betDelay"`
+	// Runners holds the state of the runners, the ones left out are active with empty ladders
+	Runners []RunnerFixture `json:"runners"`
+}
+
+// RunnerFixture describes the state of a runner.
+type RunnerFixture struct {
+	SelectionID int64   `json:"selectionId"`
+	Handicap    float64 `json:"handicap"`
+	// ACTIVE by default
+	Status          betting.RunnerStatus `json:"status"`
+	AvailableToBack []betting.PriceSize  `json:"availableToBack"`
+	AvailableToLay  []betting.PriceSize  `json:"availableToLay"`
+	TradedVolume    []betting.PriceSize  `json:"tradedVolume"`
+	LastPriceTraded *decimal.Price       `json:"lastPriceTraded"`
+}
+
+// runnerKey identifies a runner in a market
+type runnerKey struct {
+	selectionID int64
+	handicap    float64
+}
+
+type runner struct {
+	key         runnerKey
+	status      betting.RunnerStatus
+	removalDate *time.Time
+	// Liquidity offered by the rest of the market (key: price), our orders are not included
+	availableToBack map[decimal.Price]decimal.Money
+	availableToLay  map[decimal.Price]decimal.Money
+	tradedVolume    map[decimal.Price]decimal.Money
+	lastPriceTraded *decimal.Price
+}
+
+type market struct {
+	catalogue     betting.MarketCatalogue
+	ladder        ladder.Ladder
+	status        betting.MarketStatus
+	inPlay        bool
+	betDelay      int
+	version       int64
+	lastMatchTime *time.Time
+	// In the catalogue order
+	runners []*runner
+}
+
+// LoadFixtures adds the markets read from r, a json encoded Fixtures.
+func (e *Exchange) LoadFixtures(r io.Reader) error {
+	var fixtures Fixtures
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return fmt.Errorf("error decoding fixtures: %w", err)
+	}
+
+	for _, mf := range fixtures.Markets {
+		if err := e.AddMarket(mf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddMarket adds a market to the exchange.
+func (e *Exchange) AddMarket(mf MarketFixture) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	marketID := mf.Catalogue.MarketID
+	if marketID == "" {
+		return fmt.Errorf("market without ID")
+	} else if _, ok := e.markets[marketID]; ok {
+		return fmt.Errorf("market %s already exists", marketID)
+	}
+
+	l, err := priceLadder(mf.Catalogue.Description)
+	if err != nil {
+		return fmt.Errorf("error getting price ladder for market %s: %w", marketID, err)
+	}
+
+	m := &market{catalogue: mf.Catalogue, ladder: l, status: mf.Status, inPlay: mf.InPlay, betDelay: mf.BetDelay, version: 1}
+	if m.status == 0 {
+		m.status = betting.MarketStatus_Open
+	}
+
+	for _, rc := range mf.Catalogue.Runners {
+		m.runners = append(m.runners, &runner{
+			key:             runnerKey{selectionID: rc.SelectionID, handicap: rc.Handicap},
+			status:          betting.RunnerStatus_Active,
+			availableToBack: make(map[decimal.Price]decimal.Money),
+			availableToLay:  make(map[decimal.Price]decimal.Money),
+			tradedVolume:    make(map[decimal.Price]decimal.Money),
+		})
+	}
+
+	for _, rf := range mf.Runners {
+		r := m.runner(runnerKey{selectionID: rf.SelectionID, handicap: rf.Handicap})
+		if r == nil {
+			return fmt.Errorf("runner %d not in the catalogue of market %s", rf.SelectionID, marketID)
+		}
+
+		if rf.Status != 0 {
+			r.status = rf.Status
+		}
+		r.lastPriceTraded = rf.LastPriceTraded
+
+		for _, level := range []struct {
+			prices []betting.PriceSize
+			sizes  map[decimal.Price]decimal.Money
+		}{{rf.AvailableToBack, r.availableToBack}, {rf.AvailableToLay, r.availableToLay}, {rf.TradedVolume, r.tradedVolume}} {
+			if err := updateLevels(m.ladder, level.sizes, level.prices); err != nil {
+				return fmt.Errorf("invalid prices for runner %d of market %s: %w", rf.SelectionID, marketID, err)
+			}
+		}
+	}
+
+	e.markets[marketID] = m
+	e.marketIDs = append(e.marketIDs, marketID)
+	return nil
+}
+
+// SetMarketStatus changes the status of a market.
+// Closing the market lapses the unmatched orders.
+func (e *Exchange) SetMarketStatus(marketID string, status betting.MarketStatus) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.markets[marketID]
+	if !ok {
+		return fmt.Errorf("market %s not found", marketID)
+	}
+
+	m.status = status
+	m.version++
+
+	if status == betting.MarketStatus_Closed {
+		e.lapseOrders(marketID, func(o *order) bool { return true })
+	}
+	return nil
+}
+
+// SetInPlay turns a market in-play (or back).
+// Turning in-play lapses the unmatched orders not persisted (LAPSE and MARKET_ON_CLOSE persistence types).
+func (e *Exchange) SetInPlay(marketID string, inPlay bool, betDelay int) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.markets[marketID]
+	if !ok {
+		return fmt.Errorf("market %s not found", marketID)
+	}
+
+	if inPlay && !m.inPlay {
+		e.lapseOrders(marketID, func(o *order) bool { return o.persistenceType != betting.PersistenceType_Persist })
+	}
+
+	m.inPlay = inPlay
+	m.betDelay = betDelay
+	m.version++
+	return nil
+}
+
+// SetRunnerStatus changes the status of a runner.
+// Removing a runner lapses its unmatched orders and voids the matched ones.
+func (e *Exchange) SetRunnerStatus(id betting.RunnerID, status betting.RunnerStatus) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, r, err := e.findRunner(id)
+	if err != nil {
+		return err
+	}
+
+	r.status = status
+	m.version++
+
+	if status == betting.RunnerStatus_Removed || status == betting.RunnerStatus_RemovedVacant {
+		now := time.Now().UTC()
+		r.removalDate = &now
+		r.availableToBack = make(map[decimal.Price]decimal.Money)
+		r.availableToLay = make(map[decimal.Price]decimal.Money)
+
+		for _, betID := range e.betIDs {
+			o := e.orders[betID]
+			if o.marketID == id.MarketID && o.runner == r.key {
+				o.sizeLapsed = o.sizeLapsed.Add(o.sizeRemaining())
+				o.sizeVoided = o.sizeMatched
+			}
+		}
+	}
+	return nil
+}
+
+// UpdateLadder sets the size available at each price on one side of a runner's ladder, a size of zero removes
+// the price. Side_Back updates the prices available to back (offered by layers), Side_Lay the prices available
+// to lay.
+// Unmatched orders are matched against the new liquidity, oldest first.
+func (e *Exchange) UpdateLadder(id betting.RunnerID, side betting.Side, prices []betting.PriceSize) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, r, err := e.findRunner(id)
+	if err != nil {
+		return err
+	}
+
+	sizes := r.availableToBack
+	if side == betting.Side_Lay {
+		sizes = r.availableToLay
+	} else if side != betting.Side_Back {
+		return fmt.Errorf("invalid side %d", side)
+	}
+
+	if err := updateLevels(m.ladder, sizes, prices); err != nil {
+		return err
+	}
+
+	e.matchResting(m, r, time.Now().UTC())
+	return nil
+}
+
+// findRunner returns the market and runner with the given ID (must hold mu)
+func (e *Exchange) findRunner(id betting.RunnerID) (*market, *runner, error) {
+	m, ok := e.markets[id.MarketID]
+	if !ok {
+		return nil, nil, fmt.Errorf("market %s not found", id.MarketID)
+	}
+
+	key := runnerKey{selectionID: id.SelectionID}
+	if id.Handicap != nil {
+		key.handicap = *id.Handicap
+	}
+
+	r := m.runner(key)
+	if r == nil {
+		return nil, nil, fmt.Errorf("runner %d not found in market %s", id.SelectionID, id.MarketID)
+	}
+	return m, r, nil
+}
+
+func (m *market) runner(key runnerKey) *runner {
+	for _, r := range m.runners {
+		if r.key == key {
+			return r
+		}
+	}
+	return nil
+}
+
+// startTime returns the market start time, nil if the catalogue doesn't have it
+func (m *market) startTime() *time.Time {
+	if m.catalogue.MarketStartTime != nil {
+		return m.catalogue.MarketStartTime
+	} else if m.catalogue.Description != nil {
+		return &m.catalogue.Description.MarketTime
+	}
+	return nil
+}
+
+func (m *market) bspMarket() bool {
+	return m.catalogue.Description != nil && m.catalogue.Description.BSPMarket
+}
+
+func (m *market) totalMatched() decimal.Money {
+	var total decimal.Money
+	for _, r := range m.runners {
+		for _, size := range r.tradedVolume {
+			total = total.Add(size)
+		}
+	}
+	return total
+}
+
+func (m *market) totalAvailable() decimal.Money {
+	var total decimal.Money
+	for _, r := range m.runners {
+		for _, size := range r.availableToBack {
+			total = total.Add(size)
+		}
+		for _, size := range r.availableToLay {
+			total = total.Add(size)
+		}
+	}
+	return total
+}
+
+// priceLadder returns the ladder used by a market
+func priceLadder(description *betting.MarketDescription) (ladder.Ladder, error) {
+	if description == nil || description.PriceLadderDescription == nil {
+		return ladder.Classic(), nil
+	}
+
+	switch description.PriceLadderDescription.Type {
+	case betting.PriceLadderType_Classic:
+		return ladder.Classic(), nil
+	case betting.PriceLadderType_Finest:
+		return ladder.Finest(), nil
+	case betting.PriceLadderType_LineRange:
+		if description.LineRangeInfo == nil {
+			return ladder.Ladder{}, fmt.Errorf("line range market without line range info")
+		}
+		lri := description.LineRangeInfo
+		return ladder.LineRange(decimal.NewPrice(lri.MinUnitValue), decimal.NewPrice(lri.MaxUnitValue), decimal.NewPrice(lri.Interval))
+	}
+	return ladder.Ladder{}, fmt.Errorf("unknown price ladder type %s", description.PriceLadderDescription.Type)
+}
+
+// updateLevels sets the sizes of the prices, removing the ones with zero size
+func updateLevels(l ladder.Ladder, sizes map[decimal.Price]decimal.Money, prices []betting.PriceSize) error {
+	for _, ps := range prices {
+		if !l.IsValid(ps.Price) {
+			return fmt.Errorf("price %s not on the %s ladder", ps.Price, l.Type())
+		} else if ps.Size.Sign() < 0 {
+			return fmt.Errorf("negative size %s at price %s", ps.Size, ps.Price)
+		}
+
+		if ps.Size.IsZero() {
+			delete(sizes, ps.Price)
+		} else {
+			sizes[ps.Price] = ps.Size
+		}
+	}
+	return nil
+}
+
+// sortedLevels returns the levels sorted by price, best price to back (highest) first when descending
+func sortedLevels(sizes map[decimal.Price]decimal.Money, descending bool) []betting.PriceSize {
+	levels := make([]betting.PriceSize, 0, len(sizes))
+	for price, size := range sizes {
+		levels = append(levels, betting.PriceSize{Price: price, Size: size})
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	return levels
+}
+
+// matchesFilter reports whether the market is selected by the filter (must hold mu)
+func (e *Exchange) matchesFilter(m *market, f betting.MarketFilter) bool {
+	mc := m.catalogue
+
+	if f.TextQuery != "" && !matchesText(f.TextQuery, m) {
+		return false
+	}
+	if len(f.MarketIDs) > 0 && !contains(f.MarketIDs, mc.MarketID) {
+		return false
+	}
+	if len(f.EventTypeIDs) > 0 && (mc.EventType == nil || !contains(f.EventTypeIDs, mc.EventType.ID)) {
+		return false
+	}
+	if len(f.EventIDs) > 0 && (mc.Event == nil || !contains(f.EventIDs, mc.Event.ID)) {
+		return false
+	}
+	if len(f.CompetitionIDs) > 0 && (mc.Competition == nil || !contains(f.CompetitionIDs, mc.Competition.ID)) {
+		return false
+	}
+	if len(f.Venues) > 0 && (mc.Event == nil || !contains(f.Venues, mc.Event.Venue)) {
+		return false
+	}
+	if len(f.MarketCountries) > 0 && (mc.Event == nil || !contains(f.MarketCountries, mc.Event.CountryCode)) {
+		return false
+	}
+
+	description := mc.Description
+	if description == nil {
+		description = &betting.MarketDescription{}
+	}
+	if len(f.MarketTypeCodes) > 0 && !contains(f.MarketTypeCodes, description.MarketType) {
+		return false
+	}
+	if len(f.RaceTypes) > 0 && !contains(f.RaceTypes, description.RaceType) {
+		return false
+	}
+	if len(f.MarketBettingTypes) > 0 {
+		found := false
+		for _, bt := range f.MarketBettingTypes {
+			found = found || bt == description.BettingType
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.BSPOnly != nil && *f.BSPOnly != description.BSPMarket {
+		return false
+	}
+	if f.TurnInPlayEnabled != nil && *f.TurnInPlayEnabled != description.TurnInPlayEnabled {
+		return false
+	}
+	if f.InPlayOnly != nil && *f.InPlayOnly != m.inPlay {
+		return false
+	}
+
+	if tr := f.MarketStartTime; tr != nil {
+		start := m.startTime()
+		if start == nil || (tr.From != nil && start.Before(*tr.From)) || (tr.To != nil && start.After(*tr.To)) {
+			return false
+		}
+	}
+
+	if len(f.WithOrders) > 0 {
+		found := false
+		for _, betID := range e.betIDs {
+			o := e.orders[betID]
+			if o.marketID != mc.MarketID {
+				continue
+			}
+			for _, status := range f.WithOrders {
+				found = found || status == o.status()
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// listMarketCatalogue returns the markets selected by the filter, closed markets are left out
+func (e *Exchange) listMarketCatalogue(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerListMarketCatalogue
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	weight := 0
+	for _, mp := range c.MarketProjection {
+		if mp == betting.MarketProjection_MarketDescription || mp == betting.MarketProjection_RunnerMetadata {
+			weight++
+		}
+	}
+
+	if c.MaxResults <= 0 {
+		return nil, apingException(betting.APINGExceptionCode_InvalidInputData, "maxResults must be positive")
+	} else if c.MaxResults > 1000 || c.MaxResults*weight > 200 {
+		return nil, apingException(betting.APINGExceptionCode_TooMuchData, "too much data requested")
+	}
+
+	var markets []*market
+	for _, marketID := range e.marketIDs {
+		m := e.markets[marketID]
+		if m.status != betting.MarketStatus_Closed && e.matchesFilter(m, c.Filter) {
+			markets = append(markets, m)
+		}
+	}
+
+	if c.Sort != nil {
+		sortMarkets(markets, *c.Sort)
+	}
+	if len(markets) > c.MaxResults {
+		markets = markets[:c.MaxResults]
+	}
+
+	projection := make(map[betting.MarketProjection]bool)
+	for _, mp := range c.MarketProjection {
+		projection[mp] = true
+	}
+
+	result := []betting.MarketCatalogue{}
+	for _, m := range markets {
+		totalMatched := m.totalMatched()
+		mc := betting.MarketCatalogue{MarketID: m.catalogue.MarketID, MarketName: m.catalogue.MarketName, TotalMatched: &totalMatched}
+
+		if projection[betting.MarketProjection_MarketStartTime] {
+			mc.MarketStartTime = m.startTime()
+		}
+		if projection[betting.MarketProjection_MarketDescription] {
+			mc.Description = m.catalogue.Description
+		}
+		if projection[betting.MarketProjection_EventType] {
+			mc.EventType = m.catalogue.EventType
+		}
+		if projection[betting.MarketProjection_Competition] {
+			mc.Competition = m.catalogue.Competition
+		}
+		if projection[betting.MarketProjection_Event] {
+			mc.Event = m.catalogue.Event
+		}
+		if projection[betting.MarketProjection_RunnerDescription] || projection[betting.MarketProjection_RunnerMetadata] {
+			for _, rc := range m.catalogue.Runners {
+				if !projection[betting.MarketProjection_RunnerMetadata] {
+					rc.Metadata = nil
+				}
+				mc.Runners = append(mc.Runners, rc)
+			}
+		}
+
+		result = append(result, mc)
+	}
+
+	return result, nil
+}
+
+func sortMarkets(markets []*market, ms betting.MarketSort) {
+	sort.SliceStable(markets, func(i, j int) bool {
+		a, b := markets[i], markets[j]
+		switch ms {
+		case betting.MarketSort_MinimumTraded:
+			return a.totalMatched() < b.totalMatched()
+		case betting.MarketSort_MaximumTraded:
+			return a.totalMatched() > b.totalMatched()
+		case betting.MarketSort_MinimumAvailable:
+			return a.totalAvailable() < b.totalAvailable()
+		case betting.MarketSort_MaximumAvailable:
+			return a.totalAvailable() > b.totalAvailable()
+		case betting.MarketSort_FirstToStart, betting.MarketSort_LastToStart:
+			sa, sb := a.startTime(), b.startTime()
+			if sa == nil || sb == nil {
+				return sa != nil
+			}
+			if ms == betting.MarketSort_FirstToStart {
+				return sa.Before(*sb)
+			}
+			return sa.After(*sb)
+		}
+		return false
+	})
+}
+
+// bookProjection holds what's asked for on listMarketBook and listRunnerBook
+type bookProjection struct {
+	priceProjection *betting.PriceProjection
+	orderProjection *betting.OrderProjection
+	matchProjection *betting.MatchProjection
+	betIDs          []string
+	matchedSince    *time.Time
+}
+
+// weight returns the data weight of a market with this projection, betfair rejects requests over 200 points
+func (bp bookProjection) weight() int {
+	if bp.priceProjection == nil || len(bp.priceProjection.PriceData) == 0 {
+		return 2
+	}
+
+	weight := 0
+	priceData := make(map[betting.PriceData]bool)
+	for _, pd := range bp.priceProjection.PriceData {
+		priceData[pd] = true
+	}
+	for pd := range priceData {
+		switch pd {
+		case betting.PriceData_SpAvailable:
+			weight += 3
+		case betting.PriceData_SpTraded:
+			weight += 7
+		case betting.PriceData_ExBestOffers:
+			weight += 5
+		case betting.PriceData_ExAllOffers, betting.PriceData_ExTraded:
+			weight += 17
+		}
+	}
+
+	// Traded volume is cheaper together with the offers
+	if priceData[betting.PriceData_ExTraded] && (priceData[betting.PriceData_ExBestOffers] || priceData[betting.PriceData_ExAllOffers]) {
+		weight -= 2
+	}
+	return weight
+}
+
+// listMarketBook returns the dynamic data of the markets
+func (e *Exchange) listMarketBook(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerListMarketBook
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	bp := bookProjection{priceProjection: c.PriceProjection, orderProjection: c.OrderProjection,
+		matchProjection: c.MatchProjection, betIDs: c.BetIDs, matchedSince: c.MatchedSince}
+
+	if len(c.MarketIDs) == 0 {
+		return nil, apingException(betting.APINGExceptionCode_InvalidInputData, "marketIds is required")
+	} else if len(c.MarketIDs)*bp.weight() > 200 {
+		return nil, apingException(betting.APINGExceptionCode_TooMuchData, "too much data requested")
+	}
+
+	result := []betting.MarketBook{}
+	for _, marketID := range c.MarketIDs {
+		if m, ok := e.markets[marketID]; ok {
+			result = append(result, e.marketBook(m, m.runners, bp))
+		}
+	}
+
+	return result, nil
+}
+
+// listRunnerBook returns the dynamic data of a runner
+func (e *Exchange) listRunnerBook(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerListRunnerBook
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	bp := bookProjection{priceProjection: c.PriceProjection, orderProjection: c.OrderProjection,
+		matchProjection: c.MatchProjection, betIDs: c.BetIDs, matchedSince: c.MatchedSince}
+
+	result := []betting.MarketBook{}
+	m, ok := e.markets[c.MarketID]
+	if !ok {
+		return result, nil
+	}
+
+	var runners []*runner
+	for _, r := range m.runners {
+		if r.key.selectionID == c.SelectionID && (c.Handicap == nil || r.key.handicap == *c.Handicap) {
+			runners = append(runners, r)
+		}
+	}
+	if len(runners) > 0 {
+		result = append(result, e.marketBook(m, runners, bp))
+	}
+
+	return result, nil
+}
+
+// marketBook builds the book of a market with the given runners (must hold mu)
+func (e *Exchange) marketBook(m *market, runners []*runner, bp bookProjection) betting.MarketBook {
+	status := m.status
+	betDelay := m.betDelay
+	inPlay := m.inPlay
+	complete := true
+	falseValue := false
+	numberOfRunners := len(m.runners)
+	version := m.version
+	totalMatched := m.totalMatched()
+	totalAvailable := m.totalAvailable()
+
+	numberOfActiveRunners := 0
+	for _, r := range m.runners {
+		if r.status == betting.RunnerStatus_Active {
+			numberOfActiveRunners++
+		}
+	}
+
+	mb := betting.MarketBook{
+		MarketID:              m.catalogue.MarketID,
+		Status:                &status,
+		BetDelay:              &betDelay,
+		BSPReconciled:         &falseValue,
+		Complete:              &complete,
+		InPlay:                &inPlay,
+		NumberOfRunners:       &numberOfRunners,
+		NumberOfActiveRunners: &numberOfActiveRunners,
+		LastMatchTime:         m.lastMatchTime,
+		TotalMatched:          &totalMatched,
+		TotalAvailable:        &totalAvailable,
+		CrossMatching:         &falseValue,
+		RunnersVoidable:       &falseValue,
+		Version:               &version,
+	}
+
+	for _, r := range runners {
+		mb.Runners = append(mb.Runners, e.runnerBook(m, r, bp))
+	}
+	return mb
+}
+
+// runnerBook builds the book of a runner (must hold mu)
+func (e *Exchange) runnerBook(m *market, r *runner, bp bookProjection) betting.Runner {
+	var runnerMatched decimal.Money
+	for _, size := range r.tradedVolume {
+		runnerMatched = runnerMatched.Add(size)
+	}
+
+	rb := betting.Runner{
+		SelectionID:     r.key.selectionID,
+		Handicap:        r.key.handicap,
+		Status:          r.status,
+		LastPriceTraded: r.lastPriceTraded,
+		TotalMatched:    &runnerMatched,
+		RemovalDate:     r.removalDate,
+	}
+
+	var orders []*order
+	for _, betID := range e.betIDs {
+		o := e.orders[betID]
+		if o.marketID == m.catalogue.MarketID && o.runner == r.key {
+			orders = append(orders, o)
+		}
+	}
+
+	if pp := bp.priceProjection; pp != nil && len(pp.PriceData) > 0 {
+		// Our unmatched orders are offered to the rest of the market
+		availableToBack := make(map[decimal.Price]decimal.Money)
+		availableToLay := make(map[decimal.Price]decimal.Money)
+		for price, size := range r.availableToBack {
+			availableToBack[price] = size
+		}
+		for price, size := range r.availableToLay {
+			availableToLay[price] = size
+		}
+		for _, o := range orders {
+			if remaining := o.sizeRemaining(); remaining.Sign() > 0 {
+				if o.side == betting.Side_Back {
+					availableToLay[o.price] = availableToLay[o.price].Add(remaining)
+				} else {
+					availableToBack[o.price] = availableToBack[o.price].Add(remaining)
+				}
+			}
+		}
+
+		depth := -1
+		ex := &betting.ExchangePrices{}
+		for _, pd := range pp.PriceData {
+			switch pd {
+			case betting.PriceData_ExBestOffers:
+				if depth == -1 {
+					depth = 3
+					if pp.ExBestOffersOverrides != nil && pp.ExBestOffersOverrides.BestPricesDepth != nil {
+						depth = *pp.ExBestOffersOverrides.BestPricesDepth
+					}
+				}
+			case betting.PriceData_ExAllOffers:
+				depth = 0
+			case betting.PriceData_ExTraded:
+				ex.TradedVolume = sortedLevels(r.tradedVolume, false)
+			case betting.PriceData_SpAvailable, betting.PriceData_SpTraded:
+				rb.SP = &betting.StartingPrices{}
+			}
+		}
+
+		if depth != -1 {
+			ex.AvailableToBack = sortedLevels(availableToBack, true)
+			ex.AvailableToLay = sortedLevels(availableToLay, false)
+			if depth > 0 && len(ex.AvailableToBack) > depth {
+				ex.AvailableToBack = ex.AvailableToBack[:depth]
+			}
+			if depth > 0 && len(ex.AvailableToLay) > depth {
+				ex.AvailableToLay = ex.AvailableToLay[:depth]
+			}
+		}
+		rb.Ex = ex
+	}
+
+	if len(bp.betIDs) > 0 {
+		var selected []*order
+		for _, o := range orders {
+			if contains(bp.betIDs, o.betID) {
+				selected = append(selected, o)
+			}
+		}
+		orders = selected
+	}
+
+	if bp.orderProjection != nil {
+		for _, o := range orders {
+			if o.matchesProjection(*bp.orderProjection) {
+				rb.Orders = append(rb.Orders, o.bookOrder())
+			}
+		}
+	}
+
+	if bp.matchProjection != nil {
+		rb.Matches = rollupMatches(orders, *bp.matchProjection, bp.matchedSince)
+	}
+
+	return rb
+}
+
+func matchesText(query string, m *market) bool {
+	texts := []string{m.catalogue.MarketName}
+	if m.catalogue.Event != nil {
+		texts = append(texts, m.catalogue.Event.Name)
+	}
+	if m.catalogue.EventType != nil {
+		texts = append(texts, m.catalogue.EventType.Name)
+	}
+	if m.catalogue.Competition != nil {
+		texts = append(texts, m.catalogue.Competition.Name)
+	}
+
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), strings.ToLower(query)) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
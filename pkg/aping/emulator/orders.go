@@ -0,0 +1,889 @@
+package emulator
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/aping/betting"
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+)
+
+// Instruction limits per request
+const (
+	maxPlaceInstructions   = 200
+	maxCancelInstructions  = 60
+	maxReplaceInstructions = 60
+	maxUpdateInstructions  = 60
+)
+
+// Longest customer references accepted
+const (
+	maxCustomerOrderRefLength    = 32
+	maxCustomerStrategyRefLength = 15
+)
+
+// How long a customerRef is remembered to reject duplicate transactions
+const duplicateWindow = 60 * time.Second
+
+// First bet ID handed out
+const firstBetID = 200000000000
+
+type match struct {
+	id    string
+	price decimal.Price
+	size  decimal.Money
+	date  time.Time
+}
+
+// order is one of our LIMIT orders
+type order struct {
+	betID               string
+	marketID            string
+	runner              runnerKey
+	side                betting.Side
+	price               decimal.Price
+	size                decimal.Money
+	persistenceType     betting.PersistenceType
+	placedDate          time.Time
+	customerOrderRef    string
+	customerStrategyRef string
+
+	matches         []match
+	avgPriceMatched decimal.Price
+	sizeMatched     decimal.Money
+	sizeCancelled   decimal.Money
+	sizeLapsed      decimal.Money
+	sizeVoided      decimal.Money
+	// Killed by its time in force
+	expired bool
+}
+
+func (o *order) sizeRemaining() decimal.Money {
+	return o.size.Sub(o.sizeMatched).Sub(o.sizeCancelled).Sub(o.sizeLapsed)
+}
+
+func (o *order) status() betting.OrderStatus {
+	if o.sizeRemaining().Sign() > 0 {
+		return betting.OrderStatus_Executable
+	} else if o.expired {
+		return betting.OrderStatus_Expired
+	}
+	return betting.OrderStatus_ExecutionComplete
+}
+
+// matchedDate returns the date of the latest match, nil if the order is unmatched
+func (o *order) matchedDate() *time.Time {
+	if len(o.matches) == 0 {
+		return nil
+	}
+	date := o.matches[len(o.matches)-1].date
+	return &date
+}
+
+func (o *order) matchesProjection(op betting.OrderProjection) bool {
+	switch op {
+	case betting.OrderProjection_Executable:
+		return o.status() == betting.OrderStatus_Executable
+	case betting.OrderProjection_ExecutionComplete:
+		return o.status() != betting.OrderStatus_Executable
+	}
+	return true
+}
+
+// bookOrder returns the order as sent on listMarketBook
+func (o *order) bookOrder() betting.Order {
+	avgPriceMatched := o.avgPriceMatched
+	sizeMatched := o.sizeMatched
+	sizeRemaining := o.sizeRemaining()
+	sizeLapsed := o.sizeLapsed
+	sizeCancelled := o.sizeCancelled
+	sizeVoided := o.sizeVoided
+
+	return betting.Order{
+		BetID:               o.betID,
+		OrderType:           betting.OrderType_Limit,
+		Status:              o.status(),
+		PersistenceType:     o.persistenceType,
+		Side:                o.side,
+		Price:               o.price,
+		Size:                o.size,
+		PlacedDate:          o.placedDate,
+		AvgPriceMatched:     &avgPriceMatched,
+		SizeMatched:         &sizeMatched,
+		SizeRemaining:       &sizeRemaining,
+		SizeLapsed:          &sizeLapsed,
+		SizeCancelled:       &sizeCancelled,
+		SizeVoided:          &sizeVoided,
+		CustomerOrderRef:    o.customerOrderRef,
+		CustomerStrategyRef: o.customerStrategyRef,
+	}
+}
+
+// currentOrder returns the order as sent on listCurrentOrders
+func (o *order) currentOrder(marketID string) betting.CurrentOrderSummary {
+	bo := o.bookOrder()
+
+	return betting.CurrentOrderSummary{
+		BetID:               o.betID,
+		MarketID:            marketID,
+		SelectionID:         o.runner.selectionID,
+		Handicap:            o.runner.handicap,
+		PriceSize:           betting.PriceSize{Price: o.price, Size: o.size},
+		Side:                o.side,
+		Status:              bo.Status,
+		PersistenceType:     o.persistenceType,
+		OrderType:           betting.OrderType_Limit,
+		PlacedDate:          o.placedDate,
+		MatchedDate:         o.matchedDate(),
+		AveragePriceMatched: bo.AvgPriceMatched,
+		SizeMatched:         bo.SizeMatched,
+		SizeRemaining:       bo.SizeRemaining,
+		SizeLapsed:          bo.SizeLapsed,
+		SizeCancelled:       bo.SizeCancelled,
+		SizeVoided:          bo.SizeVoided,
+		CustomerOrderRef:    o.customerOrderRef,
+		CustomerStrategyRef: o.customerStrategyRef,
+	}
+}
+
+// rollupMatches returns the matches of the orders rolled up according to the projection
+func rollupMatches(orders []*order, mp betting.MatchProjection, since *time.Time) []betting.Match {
+	var matches []betting.Match
+
+	// Rolled up matches (key: side and price, the price is left out when rolling up by average price)
+	type rollupKey struct {
+		side  betting.Side
+		price decimal.Price
+	}
+	rolledUp := make(map[rollupKey]int)
+
+	for _, o := range orders {
+		if o.sizeVoided.Sign() > 0 {
+			continue
+		}
+
+		for _, m := range o.matches {
+			if since != nil && m.date.Before(*since) {
+				continue
+			}
+
+			date := m.date
+			if mp == betting.MatchProjection_NoRollup {
+				matches = append(matches, betting.Match{BetID: o.betID, MatchID: m.id, Side: o.side, Price: m.price, Size: m.size, MatchDate: &date})
+				continue
+			}
+
+			key := rollupKey{side: o.side, price: m.price}
+			if mp == betting.MatchProjection_RolledUpByAvgPrice {
+				key.price = 0
+			}
+
+			i, ok := rolledUp[key]
+			if !ok {
+				rolledUp[key] = len(matches)
+				matches = append(matches, betting.Match{Side: o.side, Price: m.price, Size: m.size})
+				continue
+			}
+
+			matches[i].Price = decimal.AveragePrice(matches[i].Size, matches[i].Price, m.size, m.price)
+			matches[i].Size = matches[i].Size.Add(m.size)
+		}
+	}
+
+	return matches
+}
+
+// newOrder creates an order with a new bet ID (must hold mu)
+func (e *Exchange) newOrder(marketID string, key runnerKey, side betting.Side, price decimal.Price, size decimal.Money,
+	persistenceType betting.PersistenceType, now time.Time) *order {
+
+	e.betCount++
+	o := &order{
+		betID:           strconv.FormatInt(firstBetID+e.betCount, 10),
+		marketID:        marketID,
+		runner:          key,
+		side:            side,
+		price:           price,
+		size:            size,
+		persistenceType: persistenceType,
+		placedDate:      now,
+	}
+
+	e.orders[o.betID] = o
+	e.betIDs = append(e.betIDs, o.betID)
+	return o
+}
+
+// available returns the size the order can be matched against
+func available(r *runner, o *order) decimal.Money {
+	var total decimal.Money
+	for _, ps := range matchableLevels(r, o) {
+		total = total.Add(ps.Size)
+	}
+	return total
+}
+
+// matchableLevels returns the levels the order can be matched against, best price first
+func matchableLevels(r *runner, o *order) []betting.PriceSize {
+	var levels []betting.PriceSize
+
+	if o.side == betting.Side_Back {
+		for _, ps := range sortedLevels(r.availableToBack, true) {
+			if ps.Price >= o.price {
+				levels = append(levels, ps)
+			}
+		}
+	} else {
+		for _, ps := range sortedLevels(r.availableToLay, false) {
+			if ps.Price <= o.price {
+				levels = append(levels, ps)
+			}
+		}
+	}
+
+	return levels
+}
+
+// fill matches up to size of the order against the liquidity on the runner, at the best prices first (must hold mu)
+func (e *Exchange) fill(m *market, r *runner, o *order, size decimal.Money, now time.Time) {
+	liquidity := r.availableToBack
+	if o.side == betting.Side_Lay {
+		liquidity = r.availableToLay
+	}
+
+	for _, ps := range matchableLevels(r, o) {
+		if size.Sign() <= 0 {
+			break
+		}
+
+		matched := ps.Size
+		if size < matched {
+			matched = size
+		}
+
+		if matched == ps.Size {
+			delete(liquidity, ps.Price)
+		} else {
+			liquidity[ps.Price] = ps.Size.Sub(matched)
+		}
+
+		e.matchCount++
+		o.matches = append(o.matches, match{id: strconv.FormatInt(e.matchCount, 10), price: ps.Price, size: matched, date: now})
+		o.avgPriceMatched = decimal.AveragePrice(o.sizeMatched, o.avgPriceMatched, matched, ps.Price)
+		o.sizeMatched = o.sizeMatched.Add(matched)
+		size = size.Sub(matched)
+
+		price := ps.Price
+		r.tradedVolume[price] = r.tradedVolume[price].Add(matched)
+		r.lastPriceTraded = &price
+		matchTime := now
+		m.lastMatchTime = &matchTime
+	}
+}
+
+// matchResting matches the unmatched orders on the runner, oldest first (must hold mu)
+func (e *Exchange) matchResting(m *market, r *runner, now time.Time) {
+	if m.status != betting.MarketStatus_Open {
+		return
+	}
+
+	for _, betID := range e.betIDs {
+		o := e.orders[betID]
+		if o.marketID == m.catalogue.MarketID && o.runner == r.key && o.sizeRemaining().Sign() > 0 {
+			e.fill(m, r, o, o.sizeRemaining(), now)
+		}
+	}
+}
+
+// lapseOrders lapses the unmatched orders of the market selected by the function (must hold mu)
+func (e *Exchange) lapseOrders(marketID string, selected func(o *order) bool) {
+	for _, betID := range e.betIDs {
+		o := e.orders[betID]
+		if o.marketID == marketID && selected(o) {
+			o.sizeLapsed = o.sizeLapsed.Add(o.sizeRemaining())
+		}
+	}
+}
+
+// checkDuplicate reports whether the customerRef was already used recently, remembering it otherwise (must hold mu)
+func (e *Exchange) checkDuplicate(customerRef string, now time.Time) bool {
+	for ref, seen := range e.customerRefs {
+		if now.Sub(seen) >= duplicateWindow {
+			delete(e.customerRefs, ref)
+		}
+	}
+
+	if customerRef == "" {
+		return false
+	} else if _, ok := e.customerRefs[customerRef]; ok {
+		return true
+	}
+
+	e.customerRefs[customerRef] = now
+	return false
+}
+
+// checkMarket returns the error placing orders on the market, if any
+func checkMarket(m *market) *betting.ExecutionReportErrorCode {
+	if m == nil {
+		return executionError(betting.ExecutionReportErrorCode_InvalidMarketId)
+	} else if m.status == betting.MarketStatus_Suspended {
+		return executionError(betting.ExecutionReportErrorCode_MarketSuspended)
+	} else if m.status != betting.MarketStatus_Open {
+		return executionError(betting.ExecutionReportErrorCode_MarketNotOpenForBetting)
+	}
+	return nil
+}
+
+// staleVersion reports whether the orders were sent for an older version of the market, in which case they lapse
+func staleVersion(m *market, mv *betting.MarketVersion) bool {
+	return mv != nil && mv.Version != nil && *mv.Version < m.version
+}
+
+// validSize reports whether the size is positive and in whole cents
+func validSize(size decimal.Money) bool {
+	return size.Sign() > 0 && size == size.RoundToCurrency()
+}
+
+func executionError(code betting.ExecutionReportErrorCode) *betting.ExecutionReportErrorCode {
+	return &code
+}
+
+func instructionError(code betting.InstructionReportErrorCode) *betting.InstructionReportErrorCode {
+	return &code
+}
+
+// executionStatus returns the status of a request given how many instructions failed
+func executionStatus(failed int, total int) (betting.ExecutionReportStatus, *betting.ExecutionReportErrorCode) {
+	switch {
+	case failed == 0:
+		return betting.ExecutionReportStatus_Success, nil
+	case failed < total:
+		return betting.ExecutionReportStatus_ProcessedWithErrors, executionError(betting.ExecutionReportErrorCode_ProcessedWithErrors)
+	}
+	return betting.ExecutionReportStatus_Failure, executionError(betting.ExecutionReportErrorCode_BetActionError)
+}
+
+// validatePlaceInstruction returns the error of the instruction, if any
+func (e *Exchange) validatePlaceInstruction(m *market, pi betting.PlaceInstruction) *betting.InstructionReportErrorCode {
+	if pi.OrderType != betting.OrderType_Limit {
+		return instructionError(betting.InstructionReportErrorCode_InvalidOrderType)
+	}
+
+	lo := pi.LimitOrder
+	if lo == nil || (pi.Side != betting.Side_Back && pi.Side != betting.Side_Lay) {
+		return instructionError(betting.InstructionReportErrorCode_ErrorInOrder)
+	} else if lo.BetTargetType != nil || lo.BetTargetSize != nil {
+		return instructionError(betting.InstructionReportErrorCode_InvalidOrderType)
+	}
+
+	key := runnerKey{selectionID: pi.SelectionID}
+	if pi.Handicap != nil {
+		key.handicap = *pi.Handicap
+	}
+	r := m.runner(key)
+	if r == nil {
+		return instructionError(betting.InstructionReportErrorCode_InvalidRunner)
+	} else if r.status != betting.RunnerStatus_Active {
+		return instructionError(betting.InstructionReportErrorCode_RunnerRemoved)
+	}
+
+	if !m.ladder.IsValid(lo.Price) {
+		return instructionError(betting.InstructionReportErrorCode_InvalidOdds)
+	} else if !validSize(lo.Size) || lo.Size < e.config.MinimumStake {
+		return instructionError(betting.InstructionReportErrorCode_InvalidBetSize)
+	}
+
+	if lo.TimeInForce != nil {
+		if lo.PersistenceType != 0 && lo.PersistenceType != betting.PersistenceType_Lapse {
+			return instructionError(betting.InstructionReportErrorCode_TimeInForceConflict)
+		} else if lo.MinFillSize != nil && (lo.MinFillSize.Sign() < 0 || *lo.MinFillSize > lo.Size) {
+			return instructionError(betting.InstructionReportErrorCode_InvalidMinFillSize)
+		}
+	} else {
+		if lo.MinFillSize != nil {
+			return instructionError(betting.InstructionReportErrorCode_UnexpectedMinFillSize)
+		} else if lo.PersistenceType == 0 || (lo.PersistenceType == betting.PersistenceType_MarketOnClose && !m.bspMarket()) {
+			return instructionError(betting.InstructionReportErrorCode_InvalidPersistenceType)
+		}
+	}
+
+	if len(pi.CustomerOrderRef) > maxCustomerOrderRefLength {
+		return instructionError(betting.InstructionReportErrorCode_InvalidCustomerOrderRef)
+	}
+
+	return nil
+}
+
+// placeOrders places the orders, either all of them or none
+func (e *Exchange) placeOrders(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerPlaceOrders
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	now := time.Now().UTC()
+	report := betting.PlaceExecutionReport{CustomerRef: c.CustomerRef, MarketID: c.MarketID}
+	m := e.markets[c.MarketID]
+
+	errorCode := checkMarket(m)
+	if errorCode == nil {
+		if len(c.Instructions) == 0 || len(c.CustomerStrategyRef) > maxCustomerStrategyRefLength {
+			errorCode = executionError(betting.ExecutionReportErrorCode_InvalidOrder)
+		} else if len(c.Instructions) > maxPlaceInstructions {
+			errorCode = executionError(betting.ExecutionReportErrorCode_TooManyInstructions)
+		} else if e.checkDuplicate(c.CustomerRef, now) {
+			errorCode = executionError(betting.ExecutionReportErrorCode_DuplicateTransaction)
+		}
+	}
+	if errorCode != nil {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = errorCode
+		return report, nil
+	}
+
+	// Nothing is placed if any of the instructions is invalid
+	failed := false
+	for _, pi := range c.Instructions {
+		ir := betting.PlaceInstructionReport{Status: betting.InstructionReportStatus_Failure, Instruction: pi}
+		ir.ErrorCode = e.validatePlaceInstruction(m, pi)
+		failed = failed || ir.ErrorCode != nil
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+
+	if failed {
+		for i := range report.InstructionReports {
+			if report.InstructionReports[i].ErrorCode == nil {
+				report.InstructionReports[i].ErrorCode = instructionError(betting.InstructionReportErrorCode_RelatedActionFailed)
+			}
+		}
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = executionError(betting.ExecutionReportErrorCode_BetActionError)
+		return report, nil
+	}
+
+	lapsed := staleVersion(m, c.MarketVersion)
+	for i, pi := range c.Instructions {
+		key := runnerKey{selectionID: pi.SelectionID}
+		if pi.Handicap != nil {
+			key.handicap = *pi.Handicap
+		}
+		lo := pi.LimitOrder
+
+		persistenceType := lo.PersistenceType
+		if persistenceType == 0 {
+			persistenceType = betting.PersistenceType_Lapse
+		}
+
+		o := e.newOrder(m.catalogue.MarketID, key, pi.Side, lo.Price, lo.Size, persistenceType, now)
+		o.customerOrderRef = pi.CustomerOrderRef
+		o.customerStrategyRef = c.CustomerStrategyRef
+
+		if lapsed {
+			o.sizeLapsed = o.size
+		} else {
+			e.execute(m, m.runner(key), o, lo, now)
+		}
+
+		report.InstructionReports[i] = placeReport(pi, o)
+	}
+
+	report.Status = betting.ExecutionReportStatus_Success
+	return report, nil
+}
+
+// execute matches a new order, applying its time in force (must hold mu)
+func (e *Exchange) execute(m *market, r *runner, o *order, lo *betting.LimitOrder, now time.Time) {
+	if lo.TimeInForce == nil {
+		e.fill(m, r, o, o.size, now)
+		return
+	}
+
+	// FILL_OR_KILL: matched straight away (at least MinFillSize of it) or not at all
+	needed := o.size
+	if lo.MinFillSize != nil {
+		needed = *lo.MinFillSize
+	}
+
+	if available(r, o) < needed {
+		o.sizeLapsed = o.size
+		o.expired = true
+		return
+	}
+
+	e.fill(m, r, o, o.size, now)
+	o.sizeLapsed = o.sizeLapsed.Add(o.sizeRemaining())
+}
+
+func placeReport(pi betting.PlaceInstruction, o *order) betting.PlaceInstructionReport {
+	orderStatus := o.status()
+	placedDate := o.placedDate
+	avgPriceMatched := o.avgPriceMatched
+	sizeMatched := o.sizeMatched
+
+	return betting.PlaceInstructionReport{
+		Status:              betting.InstructionReportStatus_Success,
+		OrderStatus:         &orderStatus,
+		Instruction:         pi,
+		BetID:               o.betID,
+		PlacedDate:          &placedDate,
+		AveragePriceMatched: &avgPriceMatched,
+		SizeMatched:         &sizeMatched,
+	}
+}
+
+// cancelOrders cancels the orders of the instructions, all the orders of the market when there are no
+// instructions or all the orders when the market isn't given either
+func (e *Exchange) cancelOrders(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerCancelOrders
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	now := time.Now().UTC()
+	report := betting.CancelExecutionReport{CustomerRef: c.CustomerRef, MarketID: c.MarketID}
+
+	if _, ok := e.markets[c.MarketID]; (c.MarketID != "" || len(c.Instructions) > 0) && !ok {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = executionError(betting.ExecutionReportErrorCode_InvalidMarketId)
+		return report, nil
+	} else if len(c.Instructions) > maxCancelInstructions {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = executionError(betting.ExecutionReportErrorCode_TooManyInstructions)
+		return report, nil
+	}
+
+	if len(c.Instructions) == 0 {
+		for _, betID := range e.betIDs {
+			o := e.orders[betID]
+			if c.MarketID == "" || o.marketID == c.MarketID {
+				o.sizeCancelled = o.sizeCancelled.Add(o.sizeRemaining())
+			}
+		}
+		report.Status = betting.ExecutionReportStatus_Success
+		return report, nil
+	}
+
+	failed := 0
+	for _, ci := range c.Instructions {
+		ir := e.cancel(c.MarketID, ci, now)
+		if ir.Status != betting.InstructionReportStatus_Success {
+			failed++
+		}
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+
+	report.Status, report.ErrorCode = executionStatus(failed, len(c.Instructions))
+	return report, nil
+}
+
+// cancel cancels (part of) the unmatched size of an order (must hold mu)
+func (e *Exchange) cancel(marketID string, ci betting.CancelInstruction, now time.Time) betting.CancelInstructionReport {
+	ir := betting.CancelInstructionReport{Status: betting.InstructionReportStatus_Failure, Instruction: &ci}
+
+	o, ok := e.orders[ci.BetID]
+	if !ok || o.marketID != marketID {
+		ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidBetId)
+		return ir
+	} else if o.sizeRemaining().Sign() <= 0 {
+		ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_BetTakenOrLapsed)
+		return ir
+	}
+
+	reduction := o.sizeRemaining()
+	if ci.SizeReduction != nil {
+		if !validSize(*ci.SizeReduction) {
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidBetSize)
+			return ir
+		} else if *ci.SizeReduction < reduction {
+			reduction = *ci.SizeReduction
+		}
+	}
+
+	o.sizeCancelled = o.sizeCancelled.Add(reduction)
+
+	ir.Status = betting.InstructionReportStatus_Success
+	ir.SizeCancelled = reduction
+	ir.CancelledDate = &now
+	return ir
+}
+
+// replaceOrders cancels the unmatched size of the orders and places it again at the new prices
+func (e *Exchange) replaceOrders(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerReplaceOrders
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	now := time.Now().UTC()
+	report := betting.ReplaceExecutionReport{CustomerRef: c.CustomerRef, MarketID: c.MarketID}
+	m := e.markets[c.MarketID]
+
+	errorCode := checkMarket(m)
+	if errorCode == nil {
+		if len(c.Instructions) == 0 {
+			errorCode = executionError(betting.ExecutionReportErrorCode_InvalidOrder)
+		} else if len(c.Instructions) > maxReplaceInstructions {
+			errorCode = executionError(betting.ExecutionReportErrorCode_TooManyInstructions)
+		} else if e.checkDuplicate(c.CustomerRef, now) {
+			errorCode = executionError(betting.ExecutionReportErrorCode_DuplicateTransaction)
+		}
+	}
+	if errorCode != nil {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = errorCode
+		return report, nil
+	}
+
+	lapsed := staleVersion(m, c.MarketVersion)
+	failed := 0
+	for _, ri := range c.Instructions {
+		ir := e.replace(m, ri, lapsed, now)
+		if ir.Status != betting.InstructionReportStatus_Success {
+			failed++
+		}
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+
+	report.Status, report.ErrorCode = executionStatus(failed, len(c.Instructions))
+	return report, nil
+}
+
+// replace cancels the unmatched size of an order and places it again at the new price (must hold mu)
+func (e *Exchange) replace(m *market, ri betting.ReplaceInstruction, lapsed bool, now time.Time) betting.ReplaceInstructionReport {
+	ir := betting.ReplaceInstructionReport{Status: betting.InstructionReportStatus_Failure}
+
+	if o, ok := e.orders[ri.BetID]; ok && o.marketID == m.catalogue.MarketID && o.sizeRemaining().Sign() > 0 {
+		if !m.ladder.IsValid(ri.NewPrice) {
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidOdds)
+			return ir
+		} else if ri.NewPrice == o.price {
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidPriceEdit)
+			return ir
+		}
+	}
+
+	cancelReport := e.cancel(m.catalogue.MarketID, betting.CancelInstruction{BetID: ri.BetID}, now)
+	ir.CancelInstructionReport = &cancelReport
+	if cancelReport.Status != betting.InstructionReportStatus_Success {
+		ir.ErrorCode = cancelReport.ErrorCode
+		return ir
+	}
+
+	old := e.orders[ri.BetID]
+	pi := betting.PlaceInstruction{
+		OrderType:        betting.OrderType_Limit,
+		SelectionID:      old.runner.selectionID,
+		Side:             old.side,
+		LimitOrder:       &betting.LimitOrder{Size: cancelReport.SizeCancelled, Price: ri.NewPrice, PersistenceType: old.persistenceType},
+		CustomerOrderRef: old.customerOrderRef,
+	}
+	if old.runner.handicap != 0 {
+		handicap := old.runner.handicap
+		pi.Handicap = &handicap
+	}
+
+	o := e.newOrder(old.marketID, old.runner, old.side, ri.NewPrice, cancelReport.SizeCancelled, old.persistenceType, now)
+	o.customerOrderRef = old.customerOrderRef
+	o.customerStrategyRef = old.customerStrategyRef
+
+	if lapsed {
+		o.sizeLapsed = o.size
+	} else {
+		e.execute(m, m.runner(old.runner), o, pi.LimitOrder, now)
+	}
+
+	placeReport := placeReport(pi, o)
+	ir.PlaceInstructionReport = &placeReport
+	ir.Status = betting.InstructionReportStatus_Success
+	return ir
+}
+
+// updateOrders changes the persistence type of the orders
+func (e *Exchange) updateOrders(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerUpdateOrders
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	report := betting.UpdateExecutionReport{CustomerRef: c.CustomerRef, MarketID: c.MarketID}
+	m, ok := e.markets[c.MarketID]
+	if !ok {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = executionError(betting.ExecutionReportErrorCode_InvalidMarketId)
+		return report, nil
+	} else if len(c.Instructions) > maxUpdateInstructions {
+		report.Status = betting.ExecutionReportStatus_Failure
+		report.ErrorCode = executionError(betting.ExecutionReportErrorCode_TooManyInstructions)
+		return report, nil
+	}
+
+	failed := 0
+	for _, ui := range c.Instructions {
+		ir := betting.UpdateInstructionReport{Status: betting.InstructionReportStatus_Failure, Instruction: ui}
+
+		o, ok := e.orders[ui.BetID]
+		switch {
+		case !ok || o.marketID != c.MarketID:
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidBetId)
+		case o.sizeRemaining().Sign() <= 0:
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_BetTakenOrLapsed)
+		case ui.NewPersistenceType == 0 || (ui.NewPersistenceType == betting.PersistenceType_MarketOnClose && !m.bspMarket()):
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_InvalidPersistenceType)
+		case ui.NewPersistenceType == o.persistenceType:
+			ir.ErrorCode = instructionError(betting.InstructionReportErrorCode_NoActionRequired)
+		default:
+			o.persistenceType = ui.NewPersistenceType
+			ir.Status = betting.InstructionReportStatus_Success
+		}
+
+		if ir.Status != betting.InstructionReportStatus_Success {
+			failed++
+		}
+		report.InstructionReports = append(report.InstructionReports, ir)
+	}
+
+	report.Status, report.ErrorCode = executionStatus(failed, len(c.Instructions))
+	return report, nil
+}
+
+// listCurrentOrders returns our orders, ordered and paged as asked
+func (e *Exchange) listCurrentOrders(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerListCurrentOrders
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	var orders []*order
+	for _, betID := range e.betIDs {
+		o := e.orders[betID]
+
+		switch {
+		case len(c.BetIDs) > 0 && !contains(c.BetIDs, o.betID):
+		case len(c.MarketIDs) > 0 && !contains(c.MarketIDs, o.marketID):
+		case len(c.CustomerOrderRefs) > 0 && !contains(c.CustomerOrderRefs, o.customerOrderRef):
+		case len(c.CustomerStrategyRefs) > 0 && !contains(c.CustomerStrategyRefs, o.customerStrategyRef):
+		case c.OrderProjection != nil && !o.matchesProjection(*c.OrderProjection):
+		case c.DateRange != nil && c.DateRange.From != nil && o.placedDate.Before(*c.DateRange.From):
+		case c.DateRange != nil && c.DateRange.To != nil && o.placedDate.After(*c.DateRange.To):
+		default:
+			orders = append(orders, o)
+		}
+	}
+
+	// Orders are already sorted by bet and placed time
+	if c.OrderBy != nil {
+		switch *c.OrderBy {
+		case betting.OrderBy_ByBet, betting.OrderBy_ByPlaceTime:
+		case betting.OrderBy_ByMarket:
+			sort.SliceStable(orders, func(i, j int) bool { return orders[i].marketID < orders[j].marketID })
+		case betting.OrderBy_ByMatchTime:
+			sort.SliceStable(orders, func(i, j int) bool {
+				mi, mj := orders[i].matchedDate(), orders[j].matchedDate()
+				if mi == nil || mj == nil {
+					return mi != nil
+				}
+				return mi.Before(*mj)
+			})
+		default:
+			return nil, apingException(betting.APINGExceptionCode_InvalidInputData, fmt.Sprintf("orderBy %s not supported", *c.OrderBy))
+		}
+	}
+
+	if c.SortDir != nil && *c.SortDir == betting.SortDir_LatestToEarliest {
+		for i, j := 0, len(orders)-1; i < j; i, j = i+1, j-1 {
+			orders[i], orders[j] = orders[j], orders[i]
+		}
+	}
+
+	from := 0
+	if c.FromRecord != nil {
+		from = *c.FromRecord
+	}
+	count := 1000
+	if c.RecordCount != nil && *c.RecordCount > 0 && *c.RecordCount < count {
+		count = *c.RecordCount
+	}
+	if from < 0 {
+		return nil, apingException(betting.APINGExceptionCode_InvalidInputData, "fromRecord must not be negative")
+	}
+
+	report := betting.CurrentOrderSummaryReport{CurrentOrders: []betting.CurrentOrderSummary{}}
+	for i := from; i < len(orders) && i < from+count; i++ {
+		report.CurrentOrders = append(report.CurrentOrders, orders[i].currentOrder(orders[i].marketID))
+	}
+	report.MoreAvailable = from+count < len(orders)
+
+	return report, nil
+}
+
+// listMarketProfitAndLoss returns the profit or loss of our matched orders if each runner wins
+func (e *Exchange) listMarketProfitAndLoss(body []byte) (interface{}, *betting.APINGException) {
+	var c betting.ContainerListMarketProfitAndLoss
+	if apinge := decode(body, &c); apinge != nil {
+		return nil, apinge
+	}
+
+	if len(c.MarketIDs) == 0 {
+		return nil, apingException(betting.APINGExceptionCode_InvalidInputData, "marketIds is required")
+	}
+
+	result := []betting.MarketProfitAndLoss{}
+	for _, marketID := range c.MarketIDs {
+		m, ok := e.markets[marketID]
+		if !ok {
+			continue
+		}
+
+		mpl := betting.MarketProfitAndLoss{MarketID: marketID}
+		for _, r := range m.runners {
+			ifWin, err := e.ifWin(marketID, r.key.selectionID)
+			if err != nil {
+				return nil, apingException(betting.APINGExceptionCode_UnexpectedError, err.Error())
+			}
+			selectionID := r.key.selectionID
+			mpl.ProfitAndLosses = append(mpl.ProfitAndLosses, betting.RunnerProfitAndLoss{SelectionID: &selectionID, IfWin: &ifWin})
+		}
+		result = append(result, mpl)
+	}
+
+	return result, nil
+}
+
+// ifWin returns the profit or loss of the matched orders on the market if the selection wins (must hold mu)
+func (e *Exchange) ifWin(marketID string, selectionID int64) (decimal.Money, error) {
+	var total decimal.Money
+
+	for _, betID := range e.betIDs {
+		o := e.orders[betID]
+		if o.marketID != marketID || o.sizeVoided.Sign() > 0 {
+			continue
+		}
+
+		for _, m := range o.matches {
+			winner := o.runner.selectionID == selectionID
+			switch {
+			case o.side == betting.Side_Back && winner:
+				profit, err := decimal.BackProfit(m.size, m.price)
+				if err != nil {
+					return 0, err
+				}
+				total = total.Add(profit)
+			case o.side == betting.Side_Back:
+				total = total.Sub(m.size)
+			case winner:
+				liability, err := decimal.LayLiability(m.size, m.price)
+				if err != nil {
+					return 0, err
+				}
+				total = total.Sub(liability)
+			default:
+				total = total.Add(m.size)
+			}
+		}
+	}
+
+	return total.RoundToCurrency(), nil
+}
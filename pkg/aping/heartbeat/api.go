@@ -0,0 +1,47 @@
+package heartbeat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gustavooferreira/betfair/internal/utils"
+	"github.com/gustavooferreira/betfair/pkg/aping"
+)
+
+type HeartbeatAPI struct {
+	aping.BetfairAPI
+}
+
+func NewHeartbeatAPI(bapi aping.BetfairAPI) HeartbeatAPI {
+	heartbeatAPI := HeartbeatAPI{bapi}
+	return heartbeatAPI
+}
+
+// call sends a JSON-RPC request for the operation and unmarshals the result into result.
+func (h HeartbeatAPI) call(operation string, params interface{}, result interface{}) error {
+	response, err := utils.SendJSONRPCRequest(h.HttpClient, h.AppKey, h.SessionToken, apiEndpoint, methodPrefix+operation, params)
+
+	// Encapsulate error here!
+	if errR, ok := err.(*utils.JSONRPCError); ok {
+		detail := BetfairDetailError{}
+		err = json.Unmarshal(errR.Data, &detail)
+		if err != nil {
+			return fmt.Errorf("error while unmarshalling APINGException response %w", err)
+		}
+
+		return &HeartbeatAPIError{
+			ErrorCode:    detail.APINGException.ErrorCode,
+			ErrorDetails: detail.APINGException.ErrorDetails,
+			RequestUUID:  detail.APINGException.RequestUUID,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(response, result)
+	if err != nil {
+		return fmt.Errorf("error while unmarshalling response %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,7 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package heartbeat
+
+// ContainerHeartbeat holds the parameters of the heartbeat operation.
+type ContainerHeartbeat struct {
+	PreferredTimeoutSeconds int `json:"preferredTimeoutSeconds"`
+}
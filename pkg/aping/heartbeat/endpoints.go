@@ -1 +1,19 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package heartbeat
+
+const apiEndpoint = "https://api.betfair.com/exchange/heartbeat/json-rpc/v1"
+
+const methodPrefix = "HeartbeatAPING/v1.0/"
+
+const (
+	heartbeatOperation = "heartbeat"
+)
+
+// Heartbeat sets up or keeps alive the dead man's switch.
+// If no heartbeat is received within preferredTimeoutSeconds, all unmatched bets are cancelled.
+// A preferredTimeoutSeconds of 0 disables the heartbeat.
+func (h HeartbeatAPI) Heartbeat(c ContainerHeartbeat) (HeartbeatReport, error) {
+	var result HeartbeatReport
+	err := h.call(heartbeatOperation, c, &result)
+	return result, err
+}
@@ -0,0 +1,8 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package heartbeat
+
+// HeartbeatReport is the response of heartbeat.
+type HeartbeatReport struct {
+	ActionPerformed      ActionPerformed `json:"actionPerformed"`
+	ActualTimeoutSeconds int             `json:"actualTimeoutSeconds"`
+}
@@ -0,0 +1,24 @@
+package heartbeat
+
+import "fmt"
+
+type BetfairDetailError struct {
+	APINGException APINGException `json:"APINGException"`
+	ExceptionName  string         `json:"exceptionname"`
+}
+
+type APINGException struct {
+	ErrorCode    APINGExceptionCode `json:"errorCode"`
+	ErrorDetails string             `json:"errorDetails"`
+	RequestUUID  string             `json:"requestUUID"`
+}
+
+type HeartbeatAPIError struct {
+	ErrorCode    APINGExceptionCode
+	ErrorDetails string
+	RequestUUID  string
+}
+
+func (e *HeartbeatAPIError) Error() string {
+	return fmt.Sprintf("Betfair Heartbeat APING error: %s - Details: %s - RequestUUID: %s", e.ErrorCode, e.ErrorDetails, e.RequestUUID)
+}
@@ -0,0 +1,47 @@
+package racestatus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gustavooferreira/betfair/internal/utils"
+	"github.com/gustavooferreira/betfair/pkg/aping"
+)
+
+type RaceStatusAPI struct {
+	aping.BetfairAPI
+}
+
+func NewRaceStatusAPI(bapi aping.BetfairAPI) RaceStatusAPI {
+	raceStatusAPI := RaceStatusAPI{bapi}
+	return raceStatusAPI
+}
+
+// call sends a JSON-RPC request for the operation and unmarshals the result into result.
+func (r RaceStatusAPI) call(operation string, params interface{}, result interface{}) error {
+	response, err := utils.SendJSONRPCRequest(r.HttpClient, r.AppKey, r.SessionToken, apiEndpoint, methodPrefix+operation, params)
+
+	// Encapsulate error here!
+	if errR, ok := err.(*utils.JSONRPCError); ok {
+		detail := BetfairDetailError{}
+		err = json.Unmarshal(errR.Data, &detail)
+		if err != nil {
+			return fmt.Errorf("error while unmarshalling APINGException response %w", err)
+		}
+
+		return &RaceStatusAPIError{
+			ErrorCode:    detail.APINGException.ErrorCode,
+			ErrorDetails: detail.APINGException.ErrorDetails,
+			RequestUUID:  detail.APINGException.RequestUUID,
+		}
+	} else if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(response, result)
+	if err != nil {
+		return fmt.Errorf("error while unmarshalling response %w", err)
+	}
+
+	return nil
+}
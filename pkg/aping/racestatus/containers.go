@@ -0,0 +1,8 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package racestatus
+
+// ContainerListRaceDetails holds the parameters of the listRaceDetails operation.
+type ContainerListRaceDetails struct {
+	MeetingIDs []string `json:"meetingIds,omitempty"`
+	RaceIDs    []string `json:"raceIds,omitempty"`
+}
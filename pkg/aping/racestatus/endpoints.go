@@ -1 +1,17 @@
+// Code generated by "codegen"; DO NOT EDIT.
 package racestatus
+
+const apiEndpoint = "https://api.betfair.com/exchange/scores/json-rpc/v1"
+
+const methodPrefix = "ScoresAPING/v1.0/"
+
+const (
+	listRaceDetailsOperation = "listRaceDetails"
+)
+
+// ListRaceDetails returns the race status of UK and Irish horse races.
+func (r RaceStatusAPI) ListRaceDetails(c ContainerListRaceDetails) ([]RaceDetails, error) {
+	var result []RaceDetails
+	err := r.call(listRaceDetailsOperation, c, &result)
+	return result, err
+}
@@ -0,0 +1,15 @@
+// Code generated by "codegen"; DO NOT EDIT.
+package racestatus
+
+import (
+	"time"
+)
+
+// RaceDetails holds the current status of a race.
+type RaceDetails struct {
+	MeetingID    string        `json:"meetingId"`
+	RaceID       string        `json:"raceId,omitempty"`
+	RaceStatus   *RaceStatus   `json:"raceStatus,omitempty"`
+	LastUpdated  *time.Time    `json:"lastUpdated,omitempty"`
+	ResponseCode *ResponseCode `json:"responseCode,omitempty"`
+}
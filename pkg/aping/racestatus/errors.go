@@ -0,0 +1,24 @@
+package racestatus
+
+import "fmt"
+
+type BetfairDetailError struct {
+	APINGException APINGException `json:"APINGException"`
+	ExceptionName  string         `json:"exceptionname"`
+}
+
+type APINGException struct {
+	ErrorCode    APINGExceptionCode `json:"errorCode"`
+	ErrorDetails string             `json:"errorDetails"`
+	RequestUUID  string             `json:"requestUUID"`
+}
+
+type RaceStatusAPIError struct {
+	ErrorCode    APINGExceptionCode
+	ErrorDetails string
+	RequestUUID  string
+}
+
+func (e *RaceStatusAPIError) Error() string {
+	return fmt.Sprintf("Betfair RaceStatus APING error: %s - Details: %s - RequestUUID: %s", e.ErrorCode, e.ErrorDetails, e.RequestUUID)
+}
@@ -0,0 +1,110 @@
+package decimal
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNotFinite is returned by the betting helpers when a price is PriceNaN or PriceInfinity.
+var ErrNotFinite = errors.New("decimal: price is NaN or Infinity")
+
+// Payout returns the amount returned by a winning back bet, stake included (stake * price).
+// Returns ErrNotFinite if the price is NaN or Infinity and ErrOverflow if the result doesn't fit in a Money.
+func Payout(stake Money, price Price) (Money, error) {
+	if price.IsNaN() || price.IsInf() {
+		return 0, ErrNotFinite
+	}
+
+	v, err := mul(int64(stake), int64(price))
+	return Money(v), err
+}
+
+// BackProfit returns the profit of a winning back bet (stake * (price - 1)).
+// Returns ErrNotFinite if the price is NaN or Infinity and ErrOverflow if the result doesn't fit in a Money.
+func BackProfit(stake Money, price Price) (Money, error) {
+	odds, err := oddsMinusOne(price)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := mul(int64(stake), odds)
+	return Money(v), err
+}
+
+// LayLiability returns the liability of a lay bet, i.e. the amount lost if the selection wins (stake * (price - 1)).
+// Returns ErrNotFinite if the price is NaN or Infinity and ErrOverflow if the result doesn't fit in a Money.
+func LayLiability(stake Money, price Price) (Money, error) {
+	odds, err := oddsMinusOne(price)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := mul(int64(stake), odds)
+	return Money(v), err
+}
+
+// LayStake returns the lay stake that results in the given liability (liability / (price - 1)).
+// Returns ErrNotFinite if the price is NaN or Infinity, ErrDivisionByZero if the price is 1 and ErrOverflow if the
+// result doesn't fit in a Money.
+func LayStake(liability Money, price Price) (Money, error) {
+	odds, err := oddsMinusOne(price)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := div(int64(liability), odds)
+	return Money(v), err
+}
+
+// BackStake returns the back stake needed to get the given profit (profit / (price - 1)).
+// Returns ErrNotFinite if the price is NaN or Infinity, ErrDivisionByZero if the price is 1 and ErrOverflow if the
+// result doesn't fit in a Money.
+func BackStake(profit Money, price Price) (Money, error) {
+	odds, err := oddsMinusOne(price)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := div(int64(profit), odds)
+	return Money(v), err
+}
+
+// oddsMinusOne returns price - 1 as a fixed-point value
+func oddsMinusOne(price Price) (int64, error) {
+	if price.IsNaN() || price.IsInf() {
+		return 0, ErrNotFinite
+	} else if int64(price) < math.MinInt64+unit {
+		return 0, ErrOverflow
+	}
+	return int64(price) - unit, nil
+}
+
+// AveragePrice returns the average price of two matched amounts, weighted by size.
+// It returns zero if both sizes are zero.
+// Prices with a zero size are ignored, otherwise PriceNaN is returned if a price is NaN and PriceInfinity if a price
+// is infinite. PriceNaN is also returned when the sizes are too big to work out the average.
+func AveragePrice(size1 Money, price1 Price, size2 Money, price2 Price) Price {
+	total := size1 + size2
+	if total == 0 {
+		return 0
+	}
+
+	if (size1 != 0 && price1.IsNaN()) || (size2 != 0 && price2.IsNaN()) {
+		return PriceNaN
+	} else if (size1 != 0 && price1.IsInf()) || (size2 != 0 && price2.IsInf()) {
+		return PriceInfinity
+	}
+
+	weighted1, err1 := mul(int64(size1), int64(price1))
+	weighted2, err2 := mul(int64(size2), int64(price2))
+	if err1 != nil || err2 != nil || (weighted2 > 0 && weighted1 > math.MaxInt64-weighted2) ||
+		(weighted2 < 0 && weighted1 < math.MinInt64-weighted2) {
+		return PriceNaN
+	}
+
+	average, err := div(weighted1+weighted2, int64(total))
+	if err != nil {
+		return PriceNaN
+	}
+	return Price(average)
+}
@@ -0,0 +1,208 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  string
+	}{
+		"integer":        {input: "1000", want: "1000"},
+		"two decimals":   {input: "1.01", want: "1.01"},
+		"trailing zeros": {input: "12.50", want: "12.5"},
+		"negative":       {input: "-3.25", want: "-3.25"},
+		"no int part":    {input: ".5", want: "0.5"},
+		"round half up":  {input: "0.0000005", want: "0.000001"},
+		"round down":     {input: "0.0000004", want: "0"},
+		"exponent":       {input: "1.5E-4", want: "0.00015"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := ParseMoney(test.input)
+			if err != nil {
+				t.Fatalf("error while parsing: %s", err)
+			}
+
+			if m.String() != test.want {
+				t.Errorf("got: %s, want: %s", m, test.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{"", "abc", "1.2.3", "-", "1,5"} {
+		if _, err := ParsePrice(input); err == nil {
+			t.Errorf("expected error while parsing %q", input)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data := `{"price":1.01,"size":"12.34","bsp":"NaN","far":"Infinity","missing":null}`
+
+	v := struct {
+		Price   Price  `json:"price"`
+		Size    Money  `json:"size"`
+		BSP     Price  `json:"bsp"`
+		Far     Price  `json:"far"`
+		Missing *Money `json:"missing"`
+	}{}
+
+	err := json.Unmarshal([]byte(data), &v)
+	if err != nil {
+		t.Fatalf("error while unmarshalling: %s", err)
+	}
+
+	if v.Price != NewPrice(1.01) || v.Size != NewMoney(12.34) || !v.BSP.IsNaN() || !v.Far.IsInf() || v.Missing != nil {
+		t.Errorf("mismatched values: %+v", v)
+	}
+
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("error while marshalling: %s", err)
+	}
+
+	want := `{"price":1.01,"size":12.34,"bsp":"NaN","far":"Infinity","missing":null}`
+	if string(bytes) != want {
+		t.Errorf("got: %s, want: %s", bytes, want)
+	}
+}
+
+func TestSumIsExact(t *testing.T) {
+	var total Money
+	for i := 0; i < 10; i++ {
+		total = total.Add(NewMoney(0.1))
+	}
+
+	if total != NewMoney(1) {
+		t.Errorf("got: %s, want: 1", total)
+	}
+}
+
+func TestRoundToCurrency(t *testing.T) {
+	tests := map[string]struct {
+		input Money
+		want  Money
+	}{
+		"round up":            {input: NewMoney(2.345), want: NewMoney(2.35)},
+		"round down":          {input: NewMoney(2.344), want: NewMoney(2.34)},
+		"negative round up":   {input: NewMoney(-2.345), want: NewMoney(-2.35)},
+		"negative round down": {input: NewMoney(-2.344), want: NewMoney(-2.34)},
+		"already rounded":     {input: NewMoney(2.3), want: NewMoney(2.3)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.input.RoundToCurrency(); got != test.want {
+				t.Errorf("got: %s, want: %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBettingHelpers(t *testing.T) {
+	stake := NewMoney(10)
+	price := NewPrice(3.45)
+
+	moneyTests := map[string]struct {
+		fn    func(Money, Price) (Money, error)
+		input Money
+		price Price
+		want  Money
+	}{
+		"Payout":       {fn: Payout, input: stake, price: price, want: NewMoney(34.5)},
+		"BackProfit":   {fn: BackProfit, input: stake, price: price, want: NewMoney(24.5)},
+		"LayLiability": {fn: LayLiability, input: stake, price: price, want: NewMoney(24.5)},
+		"LayStake":     {fn: LayStake, input: NewMoney(24.5), price: price, want: stake},
+		"BackStake":    {fn: BackStake, input: NewMoney(5), price: NewPrice(1.5), want: stake},
+	}
+
+	for name, test := range moneyTests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := test.fn(test.input, test.price); err != nil || got != test.want {
+				t.Errorf("got: %s, error: %v, want: %s", got, err, test.want)
+			}
+
+			for _, sentinel := range []Price{PriceNaN, PriceInfinity} {
+				if _, err := test.fn(test.input, sentinel); err != ErrNotFinite {
+					t.Errorf("price %s: got error %v, want ErrNotFinite", sentinel, err)
+				}
+			}
+		})
+	}
+
+	if got := AveragePrice(NewMoney(10), NewPrice(2), NewMoney(30), NewPrice(3)); got != NewPrice(2.75) {
+		t.Errorf("AveragePrice got: %s", got)
+	}
+}
+
+func TestBettingHelpersErrors(t *testing.T) {
+	huge := Money(math.MaxInt64 / 2)
+
+	tests := map[string]struct {
+		fn    func(Money, Price) (Money, error)
+		input Money
+		price Price
+		want  error
+	}{
+		"LayStake at 1":         {fn: LayStake, input: NewMoney(10), price: NewPrice(1), want: ErrDivisionByZero},
+		"BackStake at 1":        {fn: BackStake, input: NewMoney(10), price: NewPrice(1), want: ErrDivisionByZero},
+		"Payout overflow":       {fn: Payout, input: huge, price: NewPrice(1000), want: ErrOverflow},
+		"BackProfit overflow":   {fn: BackProfit, input: huge, price: NewPrice(1000), want: ErrOverflow},
+		"LayLiability overflow": {fn: LayLiability, input: huge, price: NewPrice(1000), want: ErrOverflow},
+		"LayStake overflow":     {fn: LayStake, input: huge, price: NewPrice(1.01), want: ErrOverflow},
+		"BackStake overflow":    {fn: BackStake, input: huge, price: NewPrice(1.01), want: ErrOverflow},
+		"negative price":        {fn: BackProfit, input: NewMoney(10), price: PriceNaN + 1, want: ErrOverflow},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := test.fn(test.input, test.price); err != test.want {
+				t.Errorf("got: %s, error: %v, want error: %v", got, err, test.want)
+			}
+		})
+	}
+
+	if got := AveragePrice(huge, NewPrice(1000), huge, NewPrice(1000)); got != PriceNaN {
+		t.Errorf("AveragePrice got: %s, want NaN on overflow", got)
+	}
+}
+
+func TestPriceSentinels(t *testing.T) {
+	two := NewPrice(2)
+	size := NewMoney(10)
+
+	tests := map[string]struct {
+		got  Price
+		want Price
+	}{
+		"NaN + price":           {got: PriceNaN.Add(two), want: PriceNaN},
+		"price + NaN":           {got: two.Add(PriceNaN), want: PriceNaN},
+		"Infinity + price":      {got: PriceInfinity.Add(two), want: PriceInfinity},
+		"price + Infinity":      {got: two.Add(PriceInfinity), want: PriceInfinity},
+		"NaN + Infinity":        {got: PriceNaN.Add(PriceInfinity), want: PriceNaN},
+		"NaN - price":           {got: PriceNaN.Sub(two), want: PriceNaN},
+		"price - NaN":           {got: two.Sub(PriceNaN), want: PriceNaN},
+		"Infinity - price":      {got: PriceInfinity.Sub(two), want: PriceInfinity},
+		"price - Infinity":      {got: two.Sub(PriceInfinity), want: PriceNaN},
+		"Infinity - Infinity":   {got: PriceInfinity.Sub(PriceInfinity), want: PriceNaN},
+		"average NaN":           {got: AveragePrice(size, PriceNaN, size, two), want: PriceNaN},
+		"average Infinity":      {got: AveragePrice(size, two, size, PriceInfinity), want: PriceInfinity},
+		"average NaN Infinity":  {got: AveragePrice(size, PriceInfinity, size, PriceNaN), want: PriceNaN},
+		"average zero size NaN": {got: AveragePrice(size, two, 0, PriceNaN), want: two},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.got != test.want {
+				t.Errorf("got: %s, want: %s", test.got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package decimal provides exact fixed-point types for betfair prices and money amounts.
+package decimal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Places is the number of decimal places kept by Price and Money.
+const Places = 6
+
+// unit is the integer representation of 1 (10^Places).
+const unit int64 = 1000000
+
+var errInvalidFormat = errors.New("decimal: invalid format")
+
+// ErrOverflow is returned when the result of an operation doesn't fit in a Price or Money.
+var ErrOverflow = errors.New("decimal: overflow")
+
+// ErrDivisionByZero is returned when dividing by zero, e.g. when working out a stake at price 1.
+var ErrDivisionByZero = errors.New("decimal: division by zero")
+
+// fromFloat converts a float64 into its fixed-point representation, rounding to the nearest unit.
+func fromFloat(f float64) int64 {
+	return int64(math.Round(f * float64(unit)))
+}
+
+// toFloat converts a fixed-point value into a float64.
+func toFloat(v int64) float64 {
+	return float64(v) / float64(unit)
+}
+
+// errOutOfRange copies s into the error, so s doesn't escape and parsing doesn't allocate
+func errOutOfRange(s string) error {
+	return fmt.Errorf("decimal: value out of range: %s", string([]byte(s)))
+}
+
+// parse converts a decimal string (e.g. "-12.345") into its fixed-point representation.
+// Extra decimal places are rounded half away from zero.
+// Strings in exponent notation are parsed as floats.
+func parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errInvalidFormat
+	}
+
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, errInvalidFormat
+		}
+		return fromFloat(f), nil
+	}
+
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	if intPart == "" && fracPart == "" {
+		return 0, errInvalidFormat
+	}
+
+	var result int64
+	for _, c := range intPart {
+		if c < '0' || c > '9' {
+			return 0, errInvalidFormat
+		}
+		if result > (math.MaxInt64-int64(c-'0'))/10 {
+			return 0, errOutOfRange(s)
+		}
+		result = result*10 + int64(c-'0')
+	}
+
+	if result > math.MaxInt64/unit {
+		return 0, errOutOfRange(s)
+	}
+	result *= unit
+
+	var frac int64
+	multiplier := unit / 10
+	roundUp := false
+	for i, c := range fracPart {
+		if c < '0' || c > '9' {
+			return 0, errInvalidFormat
+		}
+		if i < Places {
+			frac += int64(c-'0') * multiplier
+			multiplier /= 10
+		} else if i == Places {
+			roundUp = c >= '5'
+		}
+	}
+
+	result += frac
+	if roundUp {
+		result++
+	}
+
+	if negative {
+		result = -result
+	}
+
+	return result, nil
+}
+
+// format converts a fixed-point value into its shortest decimal string (e.g. "2.5", "1000").
+func format(v int64) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+
+	intPart := u / uint64(unit)
+	fracPart := u % uint64(unit)
+
+	if fracPart == 0 {
+		return sign + strconv.FormatUint(intPart, 10)
+	}
+
+	frac := fmt.Sprintf("%0*d", Places, fracPart)
+	frac = strings.TrimRight(frac, "0")
+
+	return sign + strconv.FormatUint(intPart, 10) + "." + frac
+}
+
+// mul multiplies two fixed-point values, rounding half away from zero.
+// Returns ErrOverflow if the result overflows.
+func mul(a int64, b int64) (int64, error) {
+	negative := (a < 0) != (b < 0)
+	hi, lo := bits.Mul64(abs(a), abs(b))
+
+	if hi >= uint64(unit) {
+		return 0, ErrOverflow
+	}
+
+	q, r := bits.Div64(hi, lo, uint64(unit))
+	if r*2 >= uint64(unit) {
+		q++
+	}
+
+	return applySign(q, negative)
+}
+
+// div divides two fixed-point values, rounding half away from zero.
+// Returns ErrDivisionByZero on division by zero and ErrOverflow if the result overflows.
+func div(a int64, b int64) (int64, error) {
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+
+	negative := (a < 0) != (b < 0)
+	ub := abs(b)
+	hi, lo := bits.Mul64(abs(a), uint64(unit))
+
+	if hi >= ub {
+		return 0, ErrOverflow
+	}
+
+	q, r := bits.Div64(hi, lo, ub)
+	if r >= ub-r {
+		q++
+	}
+
+	return applySign(q, negative)
+}
+
+// round rounds a fixed-point value to the given number of decimal places, half away from zero.
+func round(v int64, places int) int64 {
+	if places >= Places {
+		return v
+	}
+	if places < 0 {
+		places = 0
+	}
+
+	step := unit
+	for i := 0; i < places; i++ {
+		step /= 10
+	}
+
+	r := v % step
+	v -= r
+
+	if r > 0 && r*2 >= step {
+		v += step
+	} else if r < 0 && -r*2 >= step {
+		v -= step
+	}
+
+	return v
+}
+
+func abs(v int64) uint64 {
+	if v < 0 {
+		return uint64(-v)
+	}
+	return uint64(v)
+}
+
+func applySign(u uint64, negative bool) (int64, error) {
+	if u > math.MaxInt64 {
+		return 0, ErrOverflow
+	}
+	if negative {
+		return -int64(u), nil
+	}
+	return int64(u), nil
+}
+
+// unmarshalNumber decodes a json number, or a quoted json string holding a number.
+// A null value leaves the target untouched, which is reported by the second return value being false.
+func unmarshalNumber(data []byte) (int64, bool, error) {
+	s := string(data)
+	if s == "null" {
+		return 0, false, nil
+	}
+
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return v, true, nil
+}
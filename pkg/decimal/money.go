@@ -0,0 +1,129 @@
+package decimal
+
+// CurrencyPlaces is the number of decimal places used by betfair for currency amounts.
+const CurrencyPlaces = 2
+
+// Money is an exact decimal money amount (stakes, sizes, volumes, profits), stored as a fixed-point number with
+// Places decimal places.
+type Money int64
+
+// NewMoney creates a Money from a float64, rounding it to Places decimal places.
+func NewMoney(f float64) Money {
+	return Money(fromFloat(f))
+}
+
+// NewMoneyFromCents creates a Money from an amount in cents (hundredths of the currency unit).
+func NewMoneyFromCents(cents int64) Money {
+	return Money(cents * (unit / 100))
+}
+
+// ParseMoney parses a decimal string (e.g. "12.50") into a Money.
+func ParseMoney(s string) (Money, error) {
+	v, err := parse(s)
+	return Money(v), err
+}
+
+// Sum returns the sum of all the amounts.
+func Sum(amounts ...Money) Money {
+	var result Money
+	for _, amount := range amounts {
+		result += amount
+	}
+	return result
+}
+
+// IsZero reports whether the amount is zero.
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount.
+func (m Money) Sign() int {
+	return m.Cmp(0)
+}
+
+// Add returns m + other.
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns m - other.
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return -m
+}
+
+// Abs returns the absolute value of m.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// Cmp compares m and other and returns -1, 0 or +1.
+func (m Money) Cmp(other Money) int {
+	if m < other {
+		return -1
+	} else if m > other {
+		return 1
+	}
+	return 0
+}
+
+// Round rounds the amount to the given number of decimal places (half away from zero).
+func (m Money) Round(places int) Money {
+	return Money(round(int64(m), places))
+}
+
+// RoundToCurrency rounds the amount to the currency precision (CurrencyPlaces).
+func (m Money) RoundToCurrency() Money {
+	return m.Round(CurrencyPlaces)
+}
+
+// Float64 returns the amount as a float64.
+func (m Money) Float64() float64 {
+	return toFloat(int64(m))
+}
+
+// String returns the shortest decimal representation of the amount (e.g. "12.5").
+func (m Money) String() string {
+	return format(int64(m))
+}
+
+// MarshalText marshals the amount as a decimal string
+func (m Money) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText unmarshals a decimal string into the amount
+func (m *Money) UnmarshalText(data []byte) error {
+	result, err := ParseMoney(string(data))
+	if err != nil {
+		return err
+	}
+
+	*m = result
+	return nil
+}
+
+// MarshalJSON marshals the amount as a json number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON unmarshals a json number (or a json string holding a number) into the amount
+func (m *Money) UnmarshalJSON(data []byte) error {
+	v, ok, err := unmarshalNumber(data)
+	if err != nil {
+		return err
+	} else if ok {
+		*m = Money(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package decimal
+
+import (
+	"math"
+	"strconv"
+)
+
+// Price is an exact decimal price (odds), stored as a fixed-point number with Places decimal places.
+// The betfair stream may send "NaN" and "Infinity" for starting prices, those are kept as PriceNaN and PriceInfinity.
+type Price int64
+
+// PriceNaN represents a price that is not a number (e.g. a starting price not yet available).
+const PriceNaN Price = math.MinInt64
+
+// PriceInfinity represents an infinite price.
+const PriceInfinity Price = math.MaxInt64
+
+// NewPrice creates a Price from a float64, rounding it to Places decimal places.
+func NewPrice(f float64) Price {
+	if math.IsNaN(f) {
+		return PriceNaN
+	} else if math.IsInf(f, 0) {
+		return PriceInfinity
+	}
+	return Price(fromFloat(f))
+}
+
+// ParsePrice parses a decimal string (e.g. "1.01") into a Price.
+func ParsePrice(s string) (Price, error) {
+	switch s {
+	case "NaN":
+		return PriceNaN, nil
+	case "Infinity":
+		return PriceInfinity, nil
+	}
+
+	v, err := parse(s)
+	return Price(v), err
+}
+
+// IsNaN reports whether the price is not a number.
+func (p Price) IsNaN() bool {
+	return p == PriceNaN
+}
+
+// IsInf reports whether the price is infinite.
+func (p Price) IsInf() bool {
+	return p == PriceInfinity
+}
+
+// IsZero reports whether the price is zero.
+func (p Price) IsZero() bool {
+	return p == 0
+}
+
+// Add returns p + other.
+// The result is PriceNaN if either price is NaN and PriceInfinity if either price is infinite.
+func (p Price) Add(other Price) Price {
+	if p.IsNaN() || other.IsNaN() {
+		return PriceNaN
+	} else if p.IsInf() || other.IsInf() {
+		return PriceInfinity
+	}
+	return p + other
+}
+
+// Sub returns p - other.
+// The result is PriceNaN if either price is NaN or other is infinite (there's no negative infinity),
+// and PriceInfinity if only p is infinite.
+func (p Price) Sub(other Price) Price {
+	if p.IsNaN() || other.IsNaN() || other.IsInf() {
+		return PriceNaN
+	} else if p.IsInf() {
+		return PriceInfinity
+	}
+	return p - other
+}
+
+// Cmp compares p and other and returns -1, 0 or +1.
+func (p Price) Cmp(other Price) int {
+	if p < other {
+		return -1
+	} else if p > other {
+		return 1
+	}
+	return 0
+}
+
+// Float64 returns the price as a float64.
+func (p Price) Float64() float64 {
+	switch p {
+	case PriceNaN:
+		return math.NaN()
+	case PriceInfinity:
+		return math.Inf(1)
+	}
+	return toFloat(int64(p))
+}
+
+// String returns the shortest decimal representation of the price (e.g. "2.5").
+func (p Price) String() string {
+	switch p {
+	case PriceNaN:
+		return "NaN"
+	case PriceInfinity:
+		return "Infinity"
+	}
+	return format(int64(p))
+}
+
+// MarshalText marshals the price as a decimal string
+func (p Price) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText unmarshals a decimal string into the price
+func (p *Price) UnmarshalText(data []byte) error {
+	result, err := ParsePrice(string(data))
+	if err != nil {
+		return err
+	}
+
+	*p = result
+	return nil
+}
+
+// MarshalJSON marshals the price as a json number ("NaN" and "Infinity" are marshalled as json strings)
+func (p Price) MarshalJSON() ([]byte, error) {
+	if p.IsNaN() || p.IsInf() {
+		return []byte(strconv.Quote(p.String())), nil
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON unmarshals a json number (or a json string holding a number) into the price
+func (p *Price) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == `"NaN"` || s == `"Infinity"` {
+		return p.UnmarshalText(data[1 : len(data)-1])
+	}
+
+	v, ok, err := unmarshalNumber(data)
+	if err != nil {
+		return err
+	} else if ok {
+		*p = Price(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,314 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/internal/streammerge"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// BackpressurePolicy defines what happens when a change message can't be sent because the channel is full
+type BackpressurePolicy int
+
+const (
+	// BackpressurePolicy_Block waits until there is room in the channel (default).
+	// While waiting no other message is processed, including responses to requests.
+	BackpressurePolicy_Block BackpressurePolicy = iota + 1
+	// BackpressurePolicy_DropOldest drops the oldest message in the channel to make room for the new one
+	BackpressurePolicy_DropOldest
+	// BackpressurePolicy_Coalesce merges the messages per market until there is room in the channel.
+	// Only available for market changes.
+	BackpressurePolicy_Coalesce
+	// BackpressurePolicy_Disconnect disconnects with ErrSlowConsumer, without reconnecting
+	BackpressurePolicy_Disconnect
+)
+
+func (bp BackpressurePolicy) String() string {
+	if elem, ok := backpressurePolicyToString[bp]; ok {
+		return elem
+	}
+	return ""
+}
+
+var backpressurePolicyToString = map[BackpressurePolicy]string{
+	BackpressurePolicy_Block:      "BLOCK",
+	BackpressurePolicy_DropOldest: "DROP_OLDEST",
+	BackpressurePolicy_Coalesce:   "COALESCE",
+	BackpressurePolicy_Disconnect: "DISCONNECT",
+}
+
+// BackpressureConfig sets the policy for each of the change channels (MCMChan and OCMChan).
+// When a StreamHandler is registered, the policies apply to its market and order queues instead.
+type BackpressureConfig struct {
+	Market BackpressurePolicy
+	Order  BackpressurePolicy
+}
+
+// SetBackpressure sets what happens when MCMChan or OCMChan are full
+func (esaclient *ESAClient) SetBackpressure(config BackpressureConfig) error {
+	if _, ok := backpressurePolicyToString[config.Market]; !ok {
+		return fmt.Errorf("invalid market backpressure policy: %d", config.Market)
+	}
+	if _, ok := backpressurePolicyToString[config.Order]; !ok {
+		return fmt.Errorf("invalid order backpressure policy: %d", config.Order)
+	}
+	if config.Order == BackpressurePolicy_Coalesce {
+		return fmt.Errorf("backpressure policy %s is only available for market changes", config.Order)
+	}
+
+	esaclient.backpressure.Store(config)
+	return nil
+}
+
+// ErrSlowConsumer is the cause of the disconnection when a channel with BackpressurePolicy_Disconnect fills up
+var ErrSlowConsumer = errors.New("consumer not keeping up with the stream")
+
+// BackpressureError reports which channel filled up
+type BackpressureError struct {
+	// Op is either mcm or ocm
+	Op string
+}
+
+func (e BackpressureError) Error() string {
+	return fmt.Sprintf("%s channel full: %s", e.Op, ErrSlowConsumer)
+}
+
+func (e BackpressureError) Unwrap() error {
+	return ErrSlowConsumer
+}
+
+// BackpressureStats reports how the consumers are keeping up with the change channels
+type BackpressureStats struct {
+	MarketQueueDepth int
+	OrderQueueDepth  int
+	// Messages dropped with BackpressurePolicy_DropOldest
+	MarketDropped uint64
+	OrderDropped  uint64
+	// Messages merged with BackpressurePolicy_Coalesce
+	MarketCoalesced uint64
+}
+
+// backpressureCounters are updated atomically (kept in their own struct so they're 64-bit aligned)
+type backpressureCounters struct {
+	marketDropped   uint64
+	orderDropped    uint64
+	marketCoalesced uint64
+}
+
+// BackpressureStats returns the current queue depths and the messages dropped or merged so far.
+// The queue depths are the handler's when a StreamHandler is registered.
+func (esaclient *ESAClient) BackpressureStats() BackpressureStats {
+	stats := BackpressureStats{
+		MarketQueueDepth: len(esaclient.MCMChan),
+		OrderQueueDepth:  len(esaclient.OCMChan),
+		MarketDropped:    atomic.LoadUint64(&esaclient.backpressureCounters.marketDropped),
+		OrderDropped:     atomic.LoadUint64(&esaclient.backpressureCounters.orderDropped),
+		MarketCoalesced:  atomic.LoadUint64(&esaclient.backpressureCounters.marketCoalesced),
+	}
+
+	if d := esaclient.getDispatcher(); d != nil {
+		stats.MarketQueueDepth = d.market.len()
+		stats.OrderQueueDepth = d.order.len()
+	}
+
+	return stats
+}
+
+// backpressureCollectors exposes BackpressureStats as prometheus metrics
+func (esaclient *ESAClient) backpressureCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "mcm_queue_depth", Help: "Market change messages waiting in MCMChan"},
+			func() float64 { return float64(len(esaclient.MCMChan)) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "ocm_queue_depth", Help: "Order change messages waiting in OCMChan"},
+			func() float64 { return float64(len(esaclient.OCMChan)) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "mcm_dropped", Help: "Market change messages dropped because MCMChan was full"},
+			func() float64 { return float64(atomic.LoadUint64(&esaclient.backpressureCounters.marketDropped)) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "ocm_dropped", Help: "Order change messages dropped because OCMChan was full"},
+			func() float64 { return float64(atomic.LoadUint64(&esaclient.backpressureCounters.orderDropped)) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "mcm_coalesced", Help: "Market change messages merged because MCMChan was full"},
+			func() float64 { return float64(atomic.LoadUint64(&esaclient.backpressureCounters.marketCoalesced)) }),
+	}
+}
+
+// publishMCM sends the message to MCMChan according to the market backpressure policy.
+// Only called by the controller, ctx is the connection context: waiting for room stops when it's done.
+// Returns an error if the connection must be dropped, or ctx.Err() if it was cancelled.
+func (esaclient *ESAClient) publishMCM(ctx context.Context, mcm MarketChangeM) error {
+	// Keep the order, once coalescing started everything goes through the coalescer until it's flushed
+	if !esaclient.coalescer.empty() {
+		esaclient.coalescer.add(mcm)
+		atomic.AddUint64(&esaclient.backpressureCounters.marketCoalesced, 1)
+		esaclient.coalescer.flush(esaclient.MCMChan)
+		return nil
+	}
+
+	switch esaclient.backpressure.Load().(BackpressureConfig).Market {
+	case BackpressurePolicy_DropOldest:
+		for {
+			select {
+			case esaclient.MCMChan <- mcm:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			select {
+			case <-esaclient.MCMChan:
+				atomic.AddUint64(&esaclient.backpressureCounters.marketDropped, 1)
+			default:
+			}
+		}
+	case BackpressurePolicy_Coalesce:
+		select {
+		case esaclient.MCMChan <- mcm:
+		default:
+			esaclient.coalescer.add(mcm)
+			atomic.AddUint64(&esaclient.backpressureCounters.marketCoalesced, 1)
+		}
+	case BackpressurePolicy_Disconnect:
+		select {
+		case esaclient.MCMChan <- mcm:
+		default:
+			return BackpressureError{Op: "mcm"}
+		}
+	default:
+		select {
+		case esaclient.MCMChan <- mcm:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// publishOCM sends the message to OCMChan according to the order backpressure policy.
+// Only called by the controller, ctx is the connection context: waiting for room stops when it's done.
+// Returns an error if the connection must be dropped, or ctx.Err() if it was cancelled.
+func (esaclient *ESAClient) publishOCM(ctx context.Context, ocm OrderChangeM) error {
+	switch esaclient.backpressure.Load().(BackpressureConfig).Order {
+	case BackpressurePolicy_DropOldest:
+		for {
+			select {
+			case esaclient.OCMChan <- ocm:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			select {
+			case <-esaclient.OCMChan:
+				atomic.AddUint64(&esaclient.backpressureCounters.orderDropped, 1)
+			default:
+			}
+		}
+	case BackpressurePolicy_Disconnect:
+		select {
+		case esaclient.OCMChan <- ocm:
+		default:
+			return BackpressureError{Op: "ocm"}
+		}
+	default:
+		select {
+		case esaclient.OCMChan <- ocm:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// marketCoalescer merges market change messages while MCMChan is full.
+// Changes to the same market are merged into one, keeping only the latest values for each price.
+// It outlives the connections, so nothing is lost when reconnecting, and it's only used by the controller.
+type marketCoalescer struct {
+	pending *MarketChangeM
+	// Position of each market in pending.MarketChanges
+	index map[string]int
+}
+
+func (c *marketCoalescer) empty() bool {
+	return c.pending == nil
+}
+
+func (c *marketCoalescer) add(mcm MarketChangeM) {
+	if c.pending == nil {
+		pending := mcm
+		pending.MarketChanges = nil
+		c.pending = &pending
+		c.index = make(map[string]int)
+	} else {
+		c.pending.ID = mcm.ID
+		c.pending.PublishTime = mcm.PublishTime
+		if mcm.Clk != "" {
+			c.pending.Clk = mcm.Clk
+		}
+		if mcm.InitialClk != "" {
+			c.pending.InitialClk = mcm.InitialClk
+		}
+		if mcm.Status != nil {
+			c.pending.Status = mcm.Status
+		}
+		// A message with an image stays an image
+		if c.pending.ChangeType == nil || *c.pending.ChangeType == ChangeType_Heartbeat {
+			c.pending.ChangeType = mcm.ChangeType
+		}
+	}
+
+	for _, mc := range mcm.MarketChanges {
+		if i, ok := c.index[mc.ID]; ok {
+			c.pending.MarketChanges[i] = mergeMarketChange(c.pending.MarketChanges[i], mc)
+		} else {
+			c.index[mc.ID] = len(c.pending.MarketChanges)
+			c.pending.MarketChanges = append(c.pending.MarketChanges, mc)
+		}
+	}
+}
+
+// flush sends the merged message if there is room in the channel
+func (c *marketCoalescer) flush(ch chan<- MarketChangeM) bool {
+	if c.pending == nil {
+		return true
+	}
+
+	select {
+	case ch <- *c.pending:
+		c.pending = nil
+		c.index = nil
+		return true
+	default:
+		return false
+	}
+}
+
+// mergeMarketChange applies the newer change on top of the older one, the result is marked as conflated.
+// Ladder entries with size zero are kept, as they remove the price from the consumer's view, unless the result is an
+// image: an image only lists the prices available, as betfair sends it.
+func mergeMarketChange(older MarketChange, newer MarketChange) MarketChange {
+	if newer.Image != nil && *newer.Image {
+		newer.RunnerChanges = streammerge.CompactRunnerChanges(newer.RunnerChanges)
+		return newer
+	}
+
+	result := older
+	conflated := true
+	result.Conflated = &conflated
+	if newer.TotalVolume != nil {
+		result.TotalVolume = newer.TotalVolume
+	}
+	if newer.MarketDefinition != nil {
+		result.MarketDefinition = newer.MarketDefinition
+	}
+
+	result.RunnerChanges = streammerge.MergeRunnerChanges(older.RunnerChanges, newer.RunnerChanges)
+	if result.Image != nil && *result.Image {
+		result.RunnerChanges = streammerge.CompactRunnerChanges(result.RunnerChanges)
+	}
+	return result
+}
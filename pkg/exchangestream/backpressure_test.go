@@ -0,0 +1,216 @@
+package exchangestream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMarketCoalescer(t *testing.T) {
+	messages := []string{
+		`{"op":"mcm","clk":"clk1","pt":1,"mc":[{"id":"1.1","tv":10,"rc":[{"id":1,"atb":[[1.5,10],[1.6,20]],"batb":[[0,1.6,20]]}]}]}`,
+		`{"op":"mcm","clk":"clk2","pt":2,"mc":[{"id":"1.2","rc":[{"id":1,"ltp":3}]}]}`,
+		`{"op":"mcm","clk":"clk3","pt":3,"mc":[{"id":"1.1","tv":15,"rc":[{"id":1,"atb":[[1.6,0],[1.7,5]],"batb":[[0,1.7,5]],"ltp":1.6},{"id":2,"ltp":4}]}]}`,
+	}
+
+	coalescer := marketCoalescer{}
+	for _, data := range messages {
+		mcm := MarketChangeM{}
+		if err := json.Unmarshal([]byte(data), &mcm.MarketChangeMessage); err != nil {
+			t.Fatalf("error unmarshalling message - error: %s", err)
+		}
+		coalescer.add(mcm)
+	}
+
+	ch := make(chan MarketChangeM, 1)
+	if !coalescer.flush(ch) || !coalescer.empty() {
+		t.Fatalf("coalescer not flushed into an empty channel")
+	}
+	mcm := <-ch
+
+	if mcm.Clk != "clk3" || mcm.PublishTime.Millis() != 3 || len(mcm.MarketChanges) != 2 {
+		t.Fatalf("got message %+v, want clk3, pt 3 and 2 market changes", mcm)
+	}
+
+	data, err := json.Marshal(mcm.MarketChanges[0])
+	if err != nil {
+		t.Fatalf("error marshalling market change - error: %s", err)
+	}
+
+	expected := `{"rc":[{"batb":[[0,1.7,5]],"ltp":1.6,"atb":[[1.5,10],[1.6,0],[1.7,5]],"id":1},{"ltp":4,"id":2}],"tv":15,"con":true,"id":"1.1"}`
+	if string(data) != expected {
+		t.Errorf("got merged market change %s, want %s", data, expected)
+	}
+
+	// A full channel keeps the messages in the coalescer
+	coalescer.add(mcm)
+	ch <- mcm
+	if coalescer.flush(ch) || coalescer.empty() {
+		t.Errorf("coalescer flushed into a full channel")
+	}
+}
+
+func TestMarketCoalescerImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		expected string
+	}{
+		{
+			name: "image over deltas",
+			messages: []string{
+				`{"op":"mcm","clk":"clk1","pt":1,"mc":[{"id":"1.1","rc":[{"id":1,"atb":[[1.5,10]]}]}]}`,
+				`{"op":"mcm","clk":"clk2","pt":2,"mc":[{"id":"1.1","img":true,"rc":[{"id":1,"atb":[[1.5,0],[1.6,20]],"batb":[[0,1.6,20],[1,1.5,0]]}]}]}`,
+			},
+			expected: `{"rc":[{"batb":[[0,1.6,20]],"atb":[[1.6,20]],"id":1}],"img":true,"tv":null,"id":"1.1"}`,
+		},
+		{
+			name: "deltas over an image",
+			messages: []string{
+				`{"op":"mcm","clk":"clk1","pt":1,"mc":[{"id":"1.1","img":true,"rc":[{"id":1,"atb":[[1.5,10],[1.6,20]],"trd":[[1.6,5]]}]}]}`,
+				`{"op":"mcm","clk":"clk2","pt":2,"mc":[{"id":"1.1","rc":[{"id":1,"atb":[[1.6,0]],"atl":[[1.7,0]]}]}]}`,
+			},
+			expected: `{"rc":[{"trd":[[1.6,5]],"atb":[[1.5,10]],"id":1}],"img":true,"tv":null,"con":true,"id":"1.1"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			coalescer := marketCoalescer{}
+			for _, data := range test.messages {
+				mcm := MarketChangeM{}
+				if err := json.Unmarshal([]byte(data), &mcm.MarketChangeMessage); err != nil {
+					t.Fatalf("error unmarshalling message - error: %s", err)
+				}
+				coalescer.add(mcm)
+			}
+
+			ch := make(chan MarketChangeM, 1)
+			coalescer.flush(ch)
+			mcm := <-ch
+
+			data, err := json.Marshal(mcm.MarketChanges[0])
+			if err != nil {
+				t.Fatalf("error marshalling market change - error: %s", err)
+			}
+			if string(data) != test.expected {
+				t.Errorf("got merged market change %s, want %s", data, test.expected)
+			}
+		})
+	}
+}
+
+func TestBackpressurePolicies(t *testing.T) {
+	tests := map[BackpressurePolicy]func(t *testing.T, esaclient *ESAClient){
+		BackpressurePolicy_DropOldest: func(t *testing.T, esaclient *ESAClient) {
+			stats := waitStats(t, esaclient, func(stats BackpressureStats) bool { return stats.MarketDropped == 500 })
+			if stats.MarketQueueDepth != cap(esaclient.MCMChan) {
+				t.Errorf("got queue depth %d, want %d", stats.MarketQueueDepth, cap(esaclient.MCMChan))
+			}
+
+			if mcm := <-esaclient.MCMChan; mcm.Clk != "500" {
+				t.Errorf("got clk %s as the oldest message, want 500", mcm.Clk)
+			}
+		},
+		BackpressurePolicy_Coalesce: func(t *testing.T, esaclient *ESAClient) {
+			waitStats(t, esaclient, func(stats BackpressureStats) bool { return stats.MarketCoalesced == 500 })
+
+			for i := 0; i < cap(esaclient.MCMChan); i++ {
+				<-esaclient.MCMChan
+			}
+
+			select {
+			case mcm := <-esaclient.MCMChan:
+				if mcm.Clk != "1499" || len(mcm.MarketChanges) != 1 {
+					t.Errorf("got merged message %+v, want clk 1499 and 1 market change", mcm)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timeout waiting for the merged message")
+			}
+		},
+		BackpressurePolicy_Disconnect: func(t *testing.T, esaclient *ESAClient) {
+			event := waitState(t, esaclient, ConnectionState_Disconnected)
+			var bpErr BackpressureError
+			if !errors.As(event.Err, &bpErr) || bpErr.Op != "mcm" || !errors.Is(event.Err, ErrSlowConsumer) {
+				t.Errorf("got event %+v, want disconnection caused by the mcm channel", event)
+			}
+		},
+	}
+
+	for policy, check := range tests {
+		t.Run(policy.String(), func(t *testing.T) {
+			ts := newTestServer(t)
+			defer ts.close()
+
+			esaclient := NewESAClient("app_key", "session_token")
+			if err := esaclient.SetBackpressure(BackpressureConfig{Market: policy, Order: BackpressurePolicy_Block}); err != nil {
+				t.Fatalf("error setting backpressure policy - error: %s", err)
+			}
+
+			connConfig := ts.connConfig()
+			connConfig.Reconnect = true
+			if err := esaclient.Connect(context.Background(), connConfig); err != nil {
+				t.Fatalf("error connecting - error: %s", err)
+			}
+			defer esaclient.Disconnect()
+
+			if _, err := esaclient.Authenticate(); err != nil {
+				t.Fatalf("error authenticating - error: %s", err)
+			}
+
+			for i := 0; i < 1500; i++ {
+				ts.send(fmt.Sprintf(`{"op":"mcm","id":1,"clk":"%d","pt":1594999999999,"mc":[{"id":"1.1","tv":%d}]}`, i, i))
+			}
+
+			check(t, &esaclient)
+		})
+	}
+
+	esaclient := NewESAClient("app_key", "session_token")
+	err := esaclient.SetBackpressure(BackpressureConfig{Market: BackpressurePolicy_Block, Order: BackpressurePolicy_Coalesce})
+	if err == nil {
+		t.Errorf("expected error setting coalesce policy for orders")
+	}
+}
+
+// waitStats waits for the backpressure stats to meet the condition
+func TestPublishCancelled(t *testing.T) {
+	esaclient := NewESAClient("app_key", "session_token")
+
+	// Full channels, nobody reading them
+	for i := 0; i < cap(esaclient.MCMChan); i++ {
+		esaclient.MCMChan <- MarketChangeM{}
+	}
+	for i := 0; i < cap(esaclient.OCMChan); i++ {
+		esaclient.OCMChan <- OrderChangeM{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := esaclient.publishMCM(ctx, MarketChangeM{}); err != context.Canceled {
+		t.Errorf("got error %v publishing a market change, want %v", err, context.Canceled)
+	}
+	if err := esaclient.publishOCM(ctx, OrderChangeM{}); err != context.Canceled {
+		t.Errorf("got error %v publishing an order change, want %v", err, context.Canceled)
+	}
+}
+
+func waitStats(t *testing.T, esaclient *ESAClient, condition func(stats BackpressureStats) bool) BackpressureStats {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := esaclient.BackpressureStats()
+		if condition(stats) {
+			return stats
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for backpressure stats, got: %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,31 +1,108 @@
 package market
 
+import (
+	"sort"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
 type MarketCache struct {
 	Clk          string
 	InitialClk   string
-	PublishTime  *int
+	PublishTime  *time.Time
 	MarketID     string
-	TradedVolume *float64
-	// MarketDefinition *MarketDefinition
-	Runners map[int64]RunnerCache // key is the RunnerID
+	TradedVolume *decimal.Money
+	// Latest market definition received
+	MarketDefinition *exchangestream.MarketDefinition
+	// Every version of the market definition received, oldest first
+	DefinitionHistory []DefinitionVersion
+	Runners           map[RunnerKey]RunnerCache
+}
+
+// RunnerKey identifies a runner.
+// Handicap markets (e.g. asian handicap) have a runner per selection and handicap, it's zero on the other markets.
+type RunnerKey struct {
+	SelectionID int64
+	Handicap    float64
 }
 
-func NewMarketCache() MarketCache {
-	mc := MarketCache{}
+func NewMarketCache(marketID string) MarketCache {
+	mc := MarketCache{MarketID: marketID, Runners: make(map[RunnerKey]RunnerCache)}
 
 	return mc
 }
 
-func (mc *MarketCache) Update() {
+// Update applies a change to the cache.
+// An image (img=true) replaces the prices and volumes of the cache, otherwise the change is applied as a delta:
+// ladder entries with size zero are removed and the fields not sent are kept.
+// The definition history and the runner definition fields (status, BSP, etc.) are kept across images.
+// Returns the events brought by the market definition, if the change has one.
+func (mc *MarketCache) Update(change exchangestream.MarketChange) []MarketEvent {
+	if change.Image != nil && *change.Image {
+		mc.TradedVolume = nil
+		mc.Runners = make(map[RunnerKey]RunnerCache)
+
+		// Images don't always bring the definition, the runners get it back from the latest one
+		if change.MarketDefinition == nil && mc.MarketDefinition != nil {
+			mc.updateRunnerDefinitions(*mc.MarketDefinition)
+		}
+	}
+
+	if change.TotalVolume != nil {
+		tv := *change.TotalVolume
+		mc.TradedVolume = &tv
+	}
+
+	var events []MarketEvent
+	if change.MarketDefinition != nil {
+		events = mc.updateDefinition(*change.MarketDefinition)
+	}
+
+	for _, rc := range change.RunnerChanges {
+		key := RunnerKey{SelectionID: int64(rc.ID)}
+		if rc.Handicap != nil {
+			key.Handicap = *rc.Handicap
+		}
+
+		runner, ok := mc.Runners[key]
+		if !ok {
+			runner = RunnerCache{SelectionID: key.SelectionID, Handicap: key.Handicap}
+		}
+		runner.update(rc)
+		mc.Runners[key] = runner
+	}
+
+	return events
+}
+
+// clone copies the cache, so it's not affected by later updates.
+// The ladders are never changed in place, so only the runners map needs copying.
+func (mc MarketCache) clone() MarketCache {
+	runners := make(map[RunnerKey]RunnerCache, len(mc.Runners))
+	for key, runner := range mc.Runners {
+		runners[key] = runner
+	}
+	mc.Runners = runners
 
+	return mc
 }
 
 type RunnerCache struct {
-	SelectionID                int64
-	LastTradedPrice            *float64
-	TradedVolume               *float64
-	StartingPriceNear          *float64
-	StartingPriceFar           *float64
+	SelectionID int64
+	Handicap    float64
+	// From the runner definition
+	Status           exchangestream.RunnerStatus
+	SortPriority     uint
+	AdjustmentFactor float64
+	RemovalDate      *time.Time
+	BSP              *decimal.Price
+
+	LastTradedPrice            *decimal.Price
+	TradedVolume               *decimal.Money
+	StartingPriceNear          *decimal.Price
+	StartingPriceFar           *decimal.Price
 	Traded                     []PriceStep
 	AvailableToBack            []PriceStep
 	AvailableToLay             []PriceStep
@@ -37,8 +114,114 @@ type RunnerCache struct {
 	BestDisplayAvailableToLay  []PriceStep
 }
 
+// update applies a runner change
+func (rc *RunnerCache) update(change exchangestream.RunnerChange) {
+	if change.LTP != nil {
+		ltp := *change.LTP
+		rc.LastTradedPrice = &ltp
+	}
+	if change.TotalVolume != nil {
+		tv := *change.TotalVolume
+		rc.TradedVolume = &tv
+	}
+	if change.SPN != nil {
+		spn := *change.SPN
+		rc.StartingPriceNear = &spn
+	}
+	if change.SPF != nil {
+		spf := *change.SPF
+		rc.StartingPriceFar = &spf
+	}
+
+	rc.AvailableToBack = updatePriceLadder(rc.AvailableToBack, change.ATB, true)
+	rc.AvailableToLay = updatePriceLadder(rc.AvailableToLay, change.ATL, false)
+	rc.Traded = updatePriceLadder(rc.Traded, change.TRD, false)
+	rc.StartingPriceBack = updatePriceLadder(rc.StartingPriceBack, change.SPB, true)
+	rc.StartingPriceLay = updatePriceLadder(rc.StartingPriceLay, change.SPL, false)
+
+	rc.BestAvailableToBack = updateLevelLadder(rc.BestAvailableToBack, change.BATB)
+	rc.BestAvailableToLay = updateLevelLadder(rc.BestAvailableToLay, change.BATL)
+	rc.BestDisplayAvailableToBack = updateLevelLadder(rc.BestDisplayAvailableToBack, change.BDATB)
+	rc.BestDisplayAvailableToLay = updateLevelLadder(rc.BestDisplayAvailableToLay, change.BDATL)
+}
+
+// PriceStep is a ladder entry.
+// Position is the level on level based ladders (e.g. BestAvailableToBack) and the index on price keyed ladders,
+// starting at 0 on the best price.
 type PriceStep struct {
-	Position float64
-	Price    float64
-	Size     float64
+	Position uint
+	Price    decimal.Price
+	Size     decimal.Money
+}
+
+// updatePriceLadder applies the changes to a price keyed ladder, kept sorted best price first:
+// highest first on the back side (descending), lowest first otherwise.
+// Returns a new slice, the ladder passed in is never changed.
+func updatePriceLadder(steps []PriceStep, changes []exchangestream.PriceSize, descending bool) []PriceStep {
+	if len(changes) == 0 {
+		return steps
+	}
+
+	result := make([]PriceStep, len(steps), len(steps)+len(changes))
+	copy(result, steps)
+
+	for _, ps := range changes {
+		i := sort.Search(len(result), func(i int) bool {
+			if descending {
+				return result[i].Price.Cmp(ps.Price) <= 0
+			}
+			return result[i].Price.Cmp(ps.Price) >= 0
+		})
+		found := i < len(result) && result[i].Price == ps.Price
+
+		if ps.Size.IsZero() {
+			if found {
+				result = append(result[:i], result[i+1:]...)
+			}
+		} else if found {
+			result[i].Size = ps.Size
+		} else {
+			result = append(result, PriceStep{})
+			copy(result[i+1:], result[i:])
+			result[i] = PriceStep{Price: ps.Price, Size: ps.Size}
+		}
+	}
+
+	for i := range result {
+		result[i].Position = uint(i)
+	}
+
+	return result
+}
+
+// updateLevelLadder applies the changes to a level based ladder, kept sorted by level.
+// Levels with size zero are removed.
+// Returns a new slice, the ladder passed in is never changed.
+func updateLevelLadder(steps []PriceStep, changes []exchangestream.LevelPriceSize) []PriceStep {
+	if len(changes) == 0 {
+		return steps
+	}
+
+	result := make([]PriceStep, len(steps), len(steps)+len(changes))
+	copy(result, steps)
+
+	for _, lps := range changes {
+		i := sort.Search(len(result), func(i int) bool { return result[i].Position >= lps.Level })
+		found := i < len(result) && result[i].Position == lps.Level
+
+		if lps.Size.IsZero() {
+			if found {
+				result = append(result[:i], result[i+1:]...)
+			}
+		} else if found {
+			result[i].Price = lps.Price
+			result[i].Size = lps.Size
+		} else {
+			result = append(result, PriceStep{})
+			copy(result[i+1:], result[i:])
+			result[i] = PriceStep{Position: lps.Level, Price: lps.Price, Size: lps.Size}
+		}
+	}
+
+	return result
 }
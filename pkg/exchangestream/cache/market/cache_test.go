@@ -0,0 +1,506 @@
+package market
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/emulator"
+)
+
+// decodeMCM decodes a message as sent by betfair
+func decodeMCM(t *testing.T, data string) exchangestream.MarketChangeM {
+	t.Helper()
+
+	respMsg := exchangestream.ResponseMessage{}
+	if err := json.Unmarshal([]byte(data), &respMsg); err != nil {
+		t.Fatalf("error decoding message %s - error: %s", data, err)
+	}
+	return exchangestream.MarketChangeM{ID: respMsg.ID, MarketChangeMessage: *respMsg.MarketChangeMessage}
+}
+
+// ladderString formats a ladder as "position:price@size" entries
+func ladderString(steps []PriceStep) string {
+	entries := make([]string, 0, len(steps))
+	for _, step := range steps {
+		entries = append(entries, fmt.Sprintf("%d:%s@%s", step.Position, step.Price, step.Size))
+	}
+	return strings.Join(entries, " ")
+}
+
+func optionalString(v fmt.Stringer, isNil bool) string {
+	if isNil {
+		return "nil"
+	}
+	return v.String()
+}
+
+// runnerFields formats the prices, volumes and ladders of a runner
+func runnerFields(rc RunnerCache) map[string]string {
+	return map[string]string{
+		"ltp":   optionalString(rc.LastTradedPrice, rc.LastTradedPrice == nil),
+		"tv":    optionalString(rc.TradedVolume, rc.TradedVolume == nil),
+		"spn":   optionalString(rc.StartingPriceNear, rc.StartingPriceNear == nil),
+		"spf":   optionalString(rc.StartingPriceFar, rc.StartingPriceFar == nil),
+		"atb":   ladderString(rc.AvailableToBack),
+		"atl":   ladderString(rc.AvailableToLay),
+		"trd":   ladderString(rc.Traded),
+		"spb":   ladderString(rc.StartingPriceBack),
+		"spl":   ladderString(rc.StartingPriceLay),
+		"batb":  ladderString(rc.BestAvailableToBack),
+		"batl":  ladderString(rc.BestAvailableToLay),
+		"bdatb": ladderString(rc.BestDisplayAvailableToBack),
+		"bdatl": ladderString(rc.BestDisplayAvailableToLay),
+	}
+}
+
+// processFile feeds the messages of a recorded file (one per line, bz2 compressed if named .bz2) to the cache manager
+func processFile(t *testing.T, cm *CacheManager, name string) {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("error opening recorded messages - error: %s", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".bz2") {
+		r = bzip2.NewReader(f)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			if _, err := cm.Process(decodeMCM(t, line)); err != nil {
+				t.Fatalf("error processing message - error: %s", err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("error reading recorded messages - error: %s", err)
+	}
+}
+
+func TestMarketCacheUpdate(t *testing.T) {
+	image := `{"op":"mcm","id":1,"clk":"c1","pt":1594990000000,"mc":[{"id":"1.1","img":true,"tv":100,"rc":[` +
+		`{"id":10,"ltp":3.5,"tv":60,"spn":3.6,"spf":3.55,` +
+		`"atb":[[3.5,10],[3.4,5]],"atl":[[3.6,10],[3.7,5]],"trd":[[3.5,100]],"spb":[[1000,20]],"spl":[[1.01,30]],` +
+		`"batb":[[0,3.5,10],[1,3.4,5],[2,3.3,2]],"batl":[[0,3.6,10],[1,3.7,5]],` +
+		`"bdatb":[[0,3.5,10],[1,3.4,5]],"bdatl":[[0,3.6,10]]},` +
+		`{"id":20,"ltp":1.5,"tv":40}]}]}`
+
+	key := RunnerKey{SelectionID: 10}
+
+	tests := []struct {
+		name   string
+		deltas []string
+		// Expected fields of runner 10, formatted
+		want map[string]string
+	}{
+		{
+			name: "image",
+			want: map[string]string{
+				"ltp": "3.5", "tv": "60", "spn": "3.6", "spf": "3.55",
+				"atb": "0:3.5@10 1:3.4@5", "atl": "0:3.6@10 1:3.7@5", "trd": "0:3.5@100",
+				"spb": "0:1000@20", "spl": "0:1.01@30",
+				"batb": "0:3.5@10 1:3.4@5 2:3.3@2", "batl": "0:3.6@10 1:3.7@5",
+				"bdatb": "0:3.5@10 1:3.4@5", "bdatl": "0:3.6@10",
+			},
+		},
+		{
+			name: "price keyed ladders",
+			deltas: []string{
+				`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","rc":[{"id":10,` +
+					`"atb":[[3.45,7],[3.5,0],[3.6,1]],"atl":[[3.65,2],[3.7,0],[3.8,0]],"trd":[[3.5,150],[3.55,20]],` +
+					`"spb":[[1000,0]],"spl":[[1.01,35],[1.02,5]]}]}]}`,
+			},
+			want: map[string]string{
+				"atb": "0:3.6@1 1:3.45@7 2:3.4@5", "atl": "0:3.6@10 1:3.65@2", "trd": "0:3.5@150 1:3.55@20",
+				"spb": "", "spl": "0:1.01@35 1:1.02@5",
+				"batb": "0:3.5@10 1:3.4@5 2:3.3@2",
+			},
+		},
+		{
+			name: "level based ladders",
+			deltas: []string{
+				`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","rc":[{"id":10,` +
+					`"batb":[[1,3.45,7],[2,0,0]],"batl":[[0,3.65,2],[2,3.8,1]],"bdatb":[[0,0,0]],"bdatl":[[1,3.7,4]]}]}]}`,
+			},
+			want: map[string]string{
+				"batb": "0:3.5@10 1:3.45@7", "batl": "0:3.65@2 1:3.7@5 2:3.8@1",
+				"bdatb": "1:3.4@5", "bdatl": "0:3.6@10 1:3.7@4",
+				"atb": "0:3.5@10 1:3.4@5",
+			},
+		},
+		{
+			name: "prices and volumes",
+			deltas: []string{
+				`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","rc":[{"id":10,"ltp":3.45,"tv":75}]}]}`,
+				`{"op":"mcm","id":1,"clk":"c3","pt":1594990000200,"mc":[{"id":"1.1","rc":[{"id":10,"spn":3.7}]}]}`,
+			},
+			want: map[string]string{"ltp": "3.45", "tv": "75", "spn": "3.7", "spf": "3.55"},
+		},
+		{
+			name: "image replaces the cache",
+			deltas: []string{
+				`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","img":true,"rc":[{"id":10,"atb":[[2,1]]}]}]}`,
+			},
+			want: map[string]string{
+				"ltp": "nil", "tv": "nil", "spn": "nil", "spf": "nil",
+				"atb": "0:2@1", "atl": "", "trd": "", "batb": "", "bdatl": "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cm := NewCacheManager()
+			for _, data := range append([]string{image}, test.deltas...) {
+				if _, err := cm.Process(decodeMCM(t, data)); err != nil {
+					t.Fatalf("error processing message - error: %s", err)
+				}
+			}
+
+			mc, ok := cm.GetCache("1.1")
+			if !ok {
+				t.Fatalf("cache not found")
+			}
+			got := runnerFields(mc.Runners[key])
+			for field, want := range test.want {
+				if got[field] != want {
+					t.Errorf("%s: got %q, want %q", field, got[field], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMarketCacheMarketFields(t *testing.T) {
+	cm := NewCacheManager()
+
+	messages := []string{
+		`{"op":"mcm","id":1,"initialClk":"i1","clk":"c1","pt":1594990000000,"mc":[` +
+			`{"id":"1.1","img":true,"tv":100,"rc":[{"id":10,"hc":-0.5,"ltp":1.9},{"id":10,"hc":0.5,"ltp":2.1},{"id":20,"ltp":3}]},` +
+			`{"id":"1.2","img":true,"tv":5}]}`,
+		`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","tv":120,"rc":[{"id":10,"hc":0.5,"ltp":2.2}]}]}`,
+	}
+
+	var updated []string
+	for _, data := range messages {
+		ids, err := cm.Process(decodeMCM(t, data))
+		if err != nil {
+			t.Fatalf("error processing message - error: %s", err)
+		}
+		updated = append(updated, ids...)
+	}
+	if strings.Join(updated, " ") != "1.1 1.2 1.1" {
+		t.Errorf("got updated markets %v, want 1.1 1.2 1.1", updated)
+	}
+
+	mc, _ := cm.GetCache("1.1")
+	if mc.Clk != "c2" || mc.InitialClk != "i1" || mc.PublishTime == nil ||
+		!mc.PublishTime.Equal(exchangestream.NewEpochMillis(1594990000100).Time) {
+		t.Errorf("got clocks %s/%s and publish time %v", mc.InitialClk, mc.Clk, mc.PublishTime)
+	}
+	if mc.TradedVolume == nil || *mc.TradedVolume != decimal.NewMoneyFromCents(12000) {
+		t.Errorf("got traded volume %v, want 120", mc.TradedVolume)
+	}
+
+	ltps := map[RunnerKey]string{
+		{SelectionID: 10, Handicap: -0.5}: "1.9",
+		{SelectionID: 10, Handicap: 0.5}:  "2.2",
+		{SelectionID: 20}:                 "3",
+	}
+	if len(mc.Runners) != len(ltps) {
+		t.Errorf("got %d runners, want %d", len(mc.Runners), len(ltps))
+	}
+	for key, want := range ltps {
+		rc := mc.Runners[key]
+		if rc.SelectionID != key.SelectionID || rc.Handicap != key.Handicap || rc.LastTradedPrice == nil || rc.LastTradedPrice.String() != want {
+			t.Errorf("runner %+v: got %+v, want last traded price %s", key, rc, want)
+		}
+	}
+
+	// The copy returned isn't affected by later messages
+	if _, err := cm.Process(decodeMCM(t, `{"op":"mcm","id":1,"clk":"c3","pt":1594990000200,"mc":[{"id":"1.1","img":true}]}`)); err != nil {
+		t.Fatalf("error processing message - error: %s", err)
+	}
+	if len(mc.Runners) != 3 {
+		t.Errorf("got %d runners on the copy, want 3", len(mc.Runners))
+	}
+	if mc, _ := cm.GetCache("1.1"); len(mc.Runners) != 0 || mc.TradedVolume != nil {
+		t.Errorf("got %d runners and traded volume %v after the image, want none", len(mc.Runners), mc.TradedVolume)
+	}
+
+	// Messages from the previous subscription are rejected
+	if _, err := cm.Process(decodeMCM(t, `{"op":"mcm","id":2,"clk":"c4","pt":1594990000300}`)); err != nil {
+		t.Fatalf("error processing message - error: %s", err)
+	}
+	if _, err := cm.Process(decodeMCM(t, `{"op":"mcm","id":1,"clk":"c5","pt":1594990000400,"mc":[{"id":"1.1","tv":1}]}`)); err != ErrOldSubscription {
+		t.Errorf("got error %v, want ErrOldSubscription", err)
+	}
+}
+
+// TestMarketCacheFixtures replays recorded markets and checks the final state of every runner.
+// 1.171234568 has a conflated change (con=true) and a runner (203) without prices in the image.
+func TestMarketCacheFixtures(t *testing.T) {
+	tests := []struct {
+		file         string
+		marketID     string
+		clk          string
+		tradedVolume string
+		// Expected fields of each runner, formatted
+		runners map[int64]map[string]string
+	}{
+		{
+			file: "testdata/1.171234568.txt", marketID: "1.171234568", clk: "AKcBAJ8BAJ0B004", tradedVolume: "275.7",
+			runners: map[int64]map[string]string{
+				201: {
+					"ltp": "2.52", "tv": "180.2",
+					"atb": "0:2.5@14 1:2.46@100 2:2.44@12.3", "atl": "0:2.52@10 1:2.54@18.77 2:2.56@30",
+					"trd":  "0:2.5@120.2 1:2.52@60",
+					"batb": "0:2.5@14 1:2.46@100 2:2.44@12.3", "batl": "0:2.52@10 1:2.54@18.77 2:2.56@30",
+				},
+				202: {
+					"ltp": "3.1", "tv": "95.5",
+					"atb": "0:3.1@4.2", "atl": "0:3.15@6.5 1:3.2@9.5", "trd": "0:3.1@95.5",
+					"batb": "0:3.1@4.2", "batl": "0:3.15@6.5 1:3.2@9.5",
+				},
+				203: {
+					"ltp": "nil", "tv": "nil",
+					"atb": "0:5.7@20", "atl": "0:6.2@8.4", "trd": "",
+					"batb": "0:5.7@20", "batl": "0:6.2@8.4",
+				},
+			},
+		},
+		{
+			file: "../../../historicdata/testdata/1.100000001.bz2", marketID: "1.100000001", clk: "1005", tradedVolume: "135",
+			runners: map[int64]map[string]string{
+				101: {"ltp": "3.4", "tv": "15", "atb": "", "atl": "", "trd": ""},
+				102: {"ltp": "1.5", "tv": "120", "atb": "", "atl": "", "trd": ""},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.marketID, func(t *testing.T) {
+			cm := NewCacheManager()
+			processFile(t, &cm, test.file)
+
+			mc, ok := cm.GetCache(test.marketID)
+			if !ok {
+				t.Fatalf("cache not found")
+			}
+			if mc.Clk != test.clk || mc.TradedVolume == nil || mc.TradedVolume.String() != test.tradedVolume {
+				t.Errorf("got clock %s and traded volume %v, want %s and %s", mc.Clk, mc.TradedVolume, test.clk, test.tradedVolume)
+			}
+			if len(mc.Runners) != len(test.runners) {
+				t.Errorf("got %d runners, want %d", len(mc.Runners), len(test.runners))
+			}
+
+			for selectionID, want := range test.runners {
+				got := runnerFields(mc.Runners[RunnerKey{SelectionID: selectionID}])
+				for field, value := range want {
+					if got[field] != value {
+						t.Errorf("runner %d %s: got %q, want %q", selectionID, field, got[field], value)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMarketCacheRecorded(t *testing.T) {
+	cm := NewCacheManager()
+	processFile(t, &cm, "../../testdata/mcm_recorded.txt")
+
+	mc, ok := cm.GetCache("1.171234567")
+	if !ok || len(mc.Runners) != 12 {
+		t.Fatalf("got %d runners, want 12", len(mc.Runners))
+	}
+
+	tests := []struct {
+		selectionID int64
+		ltp         string
+		tv          string
+		atbSize     int
+		atbBest     string
+		atlSize     int
+		atlBest     string
+		trdSize     int
+		batb        string
+		batl        string
+	}{
+		{selectionID: 10000010, ltp: "16.1", tv: "22814.2", atbSize: 21, atbBest: "0:18.5@95.97", atlSize: 17, atlBest: "0:3.5@241.75",
+			trdSize: 19, batb: "0:2.2@156.91 1:4.4@240.55 2:4.3@342.48", batl: "0:4.6@383.95 1:4.7@309.25 2:4.8@322.1"},
+		{selectionID: 10000004, ltp: "5.5", tv: "43350.92", atbSize: 23, atbBest: "0:19.5@351.63", atlSize: 16, atlBest: "0:3.6@452.35",
+			trdSize: 20, batb: "0:15.1@185.05 1:17@413.43 2:16.9@107.1", batl: "0:17.2@127.41 1:17.3@147.9 2:17.4@121.79"},
+	}
+
+	for _, test := range tests {
+		rc := mc.Runners[RunnerKey{SelectionID: test.selectionID}]
+
+		if rc.LastTradedPrice == nil || rc.LastTradedPrice.String() != test.ltp || rc.TradedVolume == nil || rc.TradedVolume.String() != test.tv {
+			t.Errorf("runner %d: got ltp %v and tv %v, want %s and %s", test.selectionID, rc.LastTradedPrice, rc.TradedVolume, test.ltp, test.tv)
+		}
+		if len(rc.AvailableToBack) != test.atbSize || ladderString(rc.AvailableToBack[:1]) != test.atbBest {
+			t.Errorf("runner %d: got atb %s, want %d prices starting at %s", test.selectionID, ladderString(rc.AvailableToBack), test.atbSize, test.atbBest)
+		}
+		if len(rc.AvailableToLay) != test.atlSize || ladderString(rc.AvailableToLay[:1]) != test.atlBest {
+			t.Errorf("runner %d: got atl %s, want %d prices starting at %s", test.selectionID, ladderString(rc.AvailableToLay), test.atlSize, test.atlBest)
+		}
+		if len(rc.Traded) != test.trdSize {
+			t.Errorf("runner %d: got %d traded prices, want %d", test.selectionID, len(rc.Traded), test.trdSize)
+		}
+		if got := ladderString(rc.BestAvailableToBack); got != test.batb {
+			t.Errorf("runner %d: got batb %s, want %s", test.selectionID, got, test.batb)
+		}
+		if got := ladderString(rc.BestAvailableToLay); got != test.batl {
+			t.Errorf("runner %d: got batl %s, want %s", test.selectionID, got, test.batl)
+		}
+	}
+}
+
+func TestCacheManagerConnections(t *testing.T) {
+	cm := NewCacheManager()
+
+	// Messages merged from two connections, each numbering its own messages
+	messages := []struct {
+		connectionID string
+		data         string
+		wantErr      error
+	}{
+		{connectionID: "001-1", data: `{"op":"mcm","id":5,"clk":"a1","pt":1594990000000,"mc":[{"id":"1.1","img":true,"tv":10}]}`},
+		{connectionID: "001-2", data: `{"op":"mcm","id":2,"clk":"b1","pt":1594990000000,"mc":[{"id":"1.2","img":true,"tv":20}]}`},
+		{connectionID: "001-1", data: `{"op":"mcm","id":5,"clk":"a2","pt":1594990000100,"mc":[{"id":"1.1","tv":11}]}`},
+		{connectionID: "001-2", data: `{"op":"mcm","id":2,"clk":"b2","pt":1594990000100,"mc":[{"id":"1.2","tv":21}]}`},
+		{connectionID: "001-2", data: `{"op":"mcm","id":1,"clk":"b3","pt":1594990000200,"mc":[{"id":"1.2","tv":22}]}`, wantErr: ErrOldSubscription},
+	}
+
+	for _, msg := range messages {
+		mcm := decodeMCM(t, msg.data)
+		mcm.ConnectionID = msg.connectionID
+		if _, err := cm.Process(mcm); err != msg.wantErr {
+			t.Errorf("message %s from %s: got error %v, want %v", mcm.Clk, msg.connectionID, err, msg.wantErr)
+		}
+	}
+
+	for marketID, want := range map[string]string{"1.1": "11", "1.2": "21"} {
+		if mc, _ := cm.GetCache(marketID); mc.TradedVolume == nil || mc.TradedVolume.String() != want {
+			t.Errorf("market %s: got traded volume %v, want %s", marketID, mc.TradedVolume, want)
+		}
+	}
+}
+
+func TestCacheManagerResubscription(t *testing.T) {
+	cm := NewCacheManager()
+
+	// Messages without a connection ID, the IDs start over after reconnecting
+	messages := []struct {
+		data    string
+		wantErr error
+	}{
+		{data: `{"op":"mcm","id":3,"ct":"SUB_IMAGE","clk":"a1","pt":1594990000000,"mc":[{"id":"1.1","img":true,"tv":10}]}`},
+		{data: `{"op":"mcm","id":3,"clk":"a2","pt":1594990000100,"mc":[{"id":"1.1","tv":11}]}`},
+		{data: `{"op":"mcm","id":1,"ct":"SUB_IMAGE","clk":"b1","pt":1594990000200,"mc":[{"id":"1.1","img":true,"tv":12}]}`},
+		{data: `{"op":"mcm","id":1,"clk":"b2","pt":1594990000300,"mc":[{"id":"1.1","tv":13}]}`},
+		{data: `{"op":"mcm","id":0,"clk":"b3","pt":1594990000400,"mc":[{"id":"1.1","tv":14}]}`, wantErr: ErrOldSubscription},
+	}
+
+	for _, msg := range messages {
+		mcm := decodeMCM(t, msg.data)
+		if _, err := cm.Process(mcm); err != msg.wantErr {
+			t.Errorf("message %s: got error %v, want %v", mcm.Clk, err, msg.wantErr)
+		}
+	}
+	if mc, _ := cm.GetCache("1.1"); mc.TradedVolume == nil || mc.TradedVolume.String() != "13" {
+		t.Errorf("got traded volume %v, want 13", mc.TradedVolume)
+	}
+
+	// Without a subscription message, the IDs seen need to be forgotten
+	cm.Reset()
+	if _, err := cm.Process(decodeMCM(t, `{"op":"mcm","id":0,"clk":"c1","pt":1594990000500,"mc":[{"id":"1.1","tv":15}]}`)); err != nil {
+		t.Errorf("got error %v after resetting, want nil", err)
+	}
+	if mc, _ := cm.GetCache("1.1"); mc.TradedVolume == nil || mc.TradedVolume.String() != "15" {
+		t.Errorf("got traded volume %v after resetting, want 15", mc.TradedVolume)
+	}
+}
+
+func TestCacheManagerReconnect(t *testing.T) {
+	s, err := emulator.NewServer(emulator.Config{})
+	if err != nil {
+		t.Fatalf("error starting emulator - error: %s", err)
+	}
+	defer s.Close()
+
+	image := true
+	def := exchangestream.MarketDefinition{Status: exchangestream.RaceStatus_Open, BettingType: exchangestream.BettingType_Odds,
+		PriceLadderDefinition: exchangestream.PriceLadderDefinition{Type: exchangestream.PriceLadderType_Classic}}
+	publish := func(mc exchangestream.MarketChange) {
+		t.Helper()
+		if err := s.PublishMarketChanges(mc); err != nil {
+			t.Fatalf("error publishing - error: %s", err)
+		}
+	}
+	tradedVolume := func(tv float64) exchangestream.MarketChange {
+		money := decimal.NewMoney(tv)
+		return exchangestream.MarketChange{ID: "1.1", TotalVolume: &money}
+	}
+	publish(exchangestream.MarketChange{ID: "1.1", Image: &image, MarketDefinition: &def})
+
+	connConfig := s.ConnectionConfig()
+	connConfig.Reconnect = true
+	esaclient := exchangestream.NewESAClient("app_key", "session_token")
+	if err := esaclient.Connect(context.Background(), connConfig); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+	if sm, err := esaclient.Authenticate(); err != nil || sm.StatusCode != exchangestream.StatusCode_Success {
+		t.Fatalf("error authenticating - status: %+v, error: %v", sm, err)
+	}
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	cm := NewCacheManager()
+	// process processes the messages until the traded volume is the one wanted
+	process := func(want string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case mcm := <-esaclient.MCMChan:
+				if _, err := cm.Process(mcm); err != nil {
+					t.Fatalf("error processing message %+v - error: %s", mcm, err)
+				}
+				if mc, ok := cm.GetCache("1.1"); ok && mc.TradedVolume != nil && mc.TradedVolume.String() == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("timeout waiting for traded volume %s", want)
+			}
+		}
+	}
+
+	publish(tradedVolume(10))
+	process("10")
+
+	// The client resubscribes after reconnecting, numbering its messages from the start
+	s.DropConnections()
+	publish(tradedVolume(20))
+	process("20")
+	publish(tradedVolume(30))
+	process("30")
+}
@@ -0,0 +1,183 @@
+package market
+
+import (
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// MarketEventType is the kind of change reported by a MarketEvent
+type MarketEventType int
+
+const (
+	// MarketEventType_StatusChange is a market status transition (e.g. OPEN -> SUSPENDED -> CLOSED)
+	MarketEventType_StatusChange MarketEventType = iota + 1
+	// MarketEventType_InPlayChange is the market turning in-play (or back)
+	MarketEventType_InPlayChange
+	// MarketEventType_RunnerRemoved is a runner being removed (REMOVED or REMOVED_VACANT)
+	MarketEventType_RunnerRemoved
+	// MarketEventType_BetDelayChange is a change of the bet delay
+	MarketEventType_BetDelayChange
+)
+
+func (met MarketEventType) String() string {
+	if elem, ok := marketEventTypeToString[met]; ok {
+		return elem
+	}
+	return ""
+}
+
+var marketEventTypeToString = map[MarketEventType]string{
+	MarketEventType_StatusChange:   "STATUS_CHANGE",
+	MarketEventType_InPlayChange:   "IN_PLAY_CHANGE",
+	MarketEventType_RunnerRemoved:  "RUNNER_REMOVED",
+	MarketEventType_BetDelayChange: "BET_DELAY_CHANGE",
+}
+
+// MarketEvent is a change between two versions of a market definition.
+// Only the fields of its type are set.
+type MarketEvent struct {
+	Type     MarketEventType
+	MarketID string
+	// Version of the market definition bringing the change
+	Version     uint
+	PublishTime time.Time
+
+	// MarketEventType_StatusChange
+	PreviousStatus exchangestream.RaceStatus
+	Status         exchangestream.RaceStatus
+	// MarketEventType_InPlayChange
+	InPlay bool
+	// MarketEventType_RunnerRemoved
+	Runner RunnerKey
+	// MarketEventType_BetDelayChange
+	PreviousBetDelay uint
+	BetDelay         uint
+}
+
+// DefinitionVersion is a market definition as received
+type DefinitionVersion struct {
+	PublishTime time.Time
+	Definition  exchangestream.MarketDefinition
+}
+
+// updateDefinition stores the definition, merges its runners into the runner caches and returns the changes
+// since the previous definition
+func (mc *MarketCache) updateDefinition(md exchangestream.MarketDefinition) []MarketEvent {
+	var pt time.Time
+	if mc.PublishTime != nil {
+		pt = *mc.PublishTime
+	}
+
+	var events []MarketEvent
+	if mc.MarketDefinition != nil {
+		events = definitionEvents(mc.MarketID, *mc.MarketDefinition, md, pt)
+	}
+
+	def := md
+	mc.MarketDefinition = &def
+
+	// A definition resent with the same version replaces the one in the history.
+	// The history is never changed in place, so the copies of the cache aren't affected.
+	version := DefinitionVersion{PublishTime: pt, Definition: md}
+	if n := len(mc.DefinitionHistory); n > 0 && mc.DefinitionHistory[n-1].Definition.Version == md.Version {
+		mc.DefinitionHistory = append(mc.DefinitionHistory[:n-1:n-1], version)
+	} else {
+		mc.DefinitionHistory = append(mc.DefinitionHistory, version)
+	}
+
+	mc.updateRunnerDefinitions(md)
+
+	return events
+}
+
+// updateRunnerDefinitions merges the runners of the definition into the runner caches
+func (mc *MarketCache) updateRunnerDefinitions(md exchangestream.MarketDefinition) {
+	for _, rd := range md.Runners {
+		key := runnerDefinitionKey(rd)
+		runner, ok := mc.Runners[key]
+		if !ok {
+			runner = RunnerCache{SelectionID: key.SelectionID, Handicap: key.Handicap}
+		}
+		runner.updateDefinition(rd)
+		mc.Runners[key] = runner
+	}
+}
+
+// updateDefinition merges the runner definition
+func (rc *RunnerCache) updateDefinition(rd exchangestream.RunnerDefinition) {
+	rc.Status = rd.Status
+	rc.SortPriority = rd.SortPriority
+	rc.AdjustmentFactor = rd.AdjustmentFactor
+
+	rc.RemovalDate = nil
+	if rd.RemovalDate != nil {
+		removalDate := *rd.RemovalDate
+		rc.RemovalDate = &removalDate
+	}
+
+	rc.BSP = nil
+	if rd.BSP != nil {
+		bsp := *rd.BSP
+		rc.BSP = &bsp
+	}
+}
+
+// definitionEvents compares two versions of a market definition
+func definitionEvents(marketID string, previous exchangestream.MarketDefinition, current exchangestream.MarketDefinition, pt time.Time) []MarketEvent {
+	var events []MarketEvent
+	newEvent := func(eventType MarketEventType) MarketEvent {
+		return MarketEvent{Type: eventType, MarketID: marketID, Version: current.Version, PublishTime: pt}
+	}
+
+	if previous.Status != current.Status {
+		event := newEvent(MarketEventType_StatusChange)
+		event.PreviousStatus = previous.Status
+		event.Status = current.Status
+		events = append(events, event)
+	}
+
+	if inPlay := isSet(current.InPlay); isSet(previous.InPlay) != inPlay {
+		event := newEvent(MarketEventType_InPlayChange)
+		event.InPlay = inPlay
+		events = append(events, event)
+	}
+
+	if previous.BetDelay != current.BetDelay {
+		event := newEvent(MarketEventType_BetDelayChange)
+		event.PreviousBetDelay = previous.BetDelay
+		event.BetDelay = current.BetDelay
+		events = append(events, event)
+	}
+
+	removed := make(map[RunnerKey]bool, len(previous.Runners))
+	for _, rd := range previous.Runners {
+		removed[runnerDefinitionKey(rd)] = isRemoved(rd.Status)
+	}
+	for _, rd := range current.Runners {
+		key := runnerDefinitionKey(rd)
+		if wasRemoved, ok := removed[key]; ok && !wasRemoved && isRemoved(rd.Status) {
+			event := newEvent(MarketEventType_RunnerRemoved)
+			event.Runner = key
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func runnerDefinitionKey(rd exchangestream.RunnerDefinition) RunnerKey {
+	key := RunnerKey{SelectionID: int64(rd.ID)}
+	if rd.Handicap != nil {
+		key.Handicap = *rd.Handicap
+	}
+	return key
+}
+
+func isRemoved(status exchangestream.RunnerStatus) bool {
+	return status == exchangestream.RunnerStatus_Removed || status == exchangestream.RunnerStatus_RemovedVacant
+}
+
+func isSet(b *bool) bool {
+	return b != nil && *b
+}
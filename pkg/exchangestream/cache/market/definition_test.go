@@ -0,0 +1,145 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// definitionMessage builds a message with a market definition as sent by betfair
+func definitionMessage(clk string, pt int64, image bool, version int, status string, inPlay bool, betDelay int, runners string) string {
+	return fmt.Sprintf(`{"op":"mcm","id":1,"clk":"%s","pt":%d,"mc":[{"id":"1.1","img":%t,"marketDefinition":{`+
+		`"bspMarket":true,"turnInPlayEnabled":true,"persistenceEnabled":true,"marketBaseRate":5,"eventId":"30000001",`+
+		`"eventTypeId":"7","numberOfWinners":1,"bettingType":"ODDS","marketType":"WIN","marketTime":"2020-07-17T13:30:00.000Z",`+
+		`"bspReconciled":false,"complete":true,"inPlay":%t,"crossMatching":true,"runnersVoidable":false,"betDelay":%d,`+
+		`"status":"%s","runners":[%s],"regulators":["MR_INT"],"venue":"Ascot","countryCode":"GB","timezone":"Europe/London",`+
+		`"version":%d,"priceLadderDefinition":{"type":"CLASSIC"}}}]}`,
+		clk, pt, image, inPlay, betDelay, status, runners, version)
+}
+
+func eventString(event MarketEvent) string {
+	var details string
+	switch event.Type {
+	case MarketEventType_StatusChange:
+		details = fmt.Sprintf("%s->%s", event.PreviousStatus, event.Status)
+	case MarketEventType_InPlayChange:
+		details = fmt.Sprintf("%t", event.InPlay)
+	case MarketEventType_RunnerRemoved:
+		details = fmt.Sprintf("%d", event.Runner.SelectionID)
+	case MarketEventType_BetDelayChange:
+		details = fmt.Sprintf("%d->%d", event.PreviousBetDelay, event.BetDelay)
+	}
+	return fmt.Sprintf("%s %s v%d", event.Type, details, event.Version)
+}
+
+func TestMarketDefinitionTracking(t *testing.T) {
+	active := `{"id":10,"status":"ACTIVE","sortPriority":1,"adjustmentFactor":50},` +
+		`{"id":20,"status":"ACTIVE","sortPriority":2,"adjustmentFactor":30},` +
+		`{"id":30,"status":"ACTIVE","sortPriority":3,"adjustmentFactor":20}`
+	removed := `{"id":10,"status":"ACTIVE","sortPriority":1,"adjustmentFactor":60},` +
+		`{"id":20,"status":"ACTIVE","sortPriority":2,"adjustmentFactor":40},` +
+		`{"id":30,"status":"REMOVED","sortPriority":3,"adjustmentFactor":20,"removalDate":"2020-07-17T13:00:00.000Z"}`
+	settled := `{"id":10,"status":"WINNER","sortPriority":1,"adjustmentFactor":60,"bsp":3.2},` +
+		`{"id":20,"status":"LOSER","sortPriority":2,"adjustmentFactor":40,"bsp":2.5},` +
+		`{"id":30,"status":"REMOVED","sortPriority":3,"adjustmentFactor":20,"removalDate":"2020-07-17T13:00:00.000Z"}`
+
+	steps := []struct {
+		name       string
+		message    string
+		wantEvents string
+	}{
+		{name: "first definition", message: definitionMessage("c1", 1594990000000, true, 1, "OPEN", false, 0, active)},
+		{name: "suspended with a removal", message: definitionMessage("c2", 1594990000100, false, 2, "SUSPENDED", false, 0, removed),
+			wantEvents: "STATUS_CHANGE OPEN->SUSPENDED v2, RUNNER_REMOVED 30 v2"},
+		{name: "in-play", message: definitionMessage("c3", 1594990000200, false, 3, "OPEN", true, 5, removed),
+			wantEvents: "STATUS_CHANGE SUSPENDED->OPEN v3, IN_PLAY_CHANGE true v3, BET_DELAY_CHANGE 0->5 v3"},
+		{name: "same version resent with an image", message: definitionMessage("c4", 1594990000300, true, 3, "OPEN", true, 5, removed)},
+		{name: "prices only", message: `{"op":"mcm","id":1,"clk":"c5","pt":1594990000400,"mc":[{"id":"1.1","rc":[{"id":10,"ltp":3.2}]}]}`},
+		{name: "closed", message: definitionMessage("c6", 1594990000500, false, 4, "CLOSED", true, 5, settled),
+			wantEvents: "STATUS_CHANGE OPEN->CLOSED v4"},
+	}
+
+	cm := NewCacheManager()
+	var events []MarketEvent
+	cm.SetEventHandler(func(event MarketEvent) { events = append(events, event) })
+
+	for _, step := range steps {
+		events = nil
+		if _, err := cm.Process(decodeMCM(t, step.message)); err != nil {
+			t.Fatalf("%s: error processing message - error: %s", step.name, err)
+		}
+
+		got := make([]string, 0, len(events))
+		for _, event := range events {
+			got = append(got, eventString(event))
+			if event.MarketID != "1.1" || event.PublishTime.IsZero() {
+				t.Errorf("%s: got event %+v, want market 1.1 and the publish time", step.name, event)
+			}
+		}
+		if strings.Join(got, ", ") != step.wantEvents {
+			t.Errorf("%s: got events %q, want %q", step.name, strings.Join(got, ", "), step.wantEvents)
+		}
+	}
+
+	mc, _ := cm.GetCache("1.1")
+
+	if mc.MarketDefinition == nil || mc.MarketDefinition.Version != 4 || mc.MarketDefinition.Status != exchangestream.RaceStatus_Closed {
+		t.Errorf("got definition %+v, want version 4 closed", mc.MarketDefinition)
+	}
+
+	var versions []string
+	for _, dv := range mc.DefinitionHistory {
+		versions = append(versions, fmt.Sprintf("%d@%d", dv.Definition.Version, exchangestream.EpochMillis{Time: dv.PublishTime}.Millis()))
+	}
+	wantVersions := "1@1594990000000 2@1594990000100 3@1594990000300 4@1594990000500"
+	if strings.Join(versions, " ") != wantVersions {
+		t.Errorf("got history %s, want %s", strings.Join(versions, " "), wantVersions)
+	}
+
+	winner := mc.Runners[RunnerKey{SelectionID: 10}]
+	if winner.Status != exchangestream.RunnerStatus_Winner || winner.AdjustmentFactor != 60 || winner.SortPriority != 1 ||
+		winner.BSP == nil || winner.BSP.String() != "3.2" || winner.LastTradedPrice == nil || winner.LastTradedPrice.String() != "3.2" {
+		t.Errorf("got winner %+v", winner)
+	}
+
+	nonRunner := mc.Runners[RunnerKey{SelectionID: 30}]
+	if nonRunner.Status != exchangestream.RunnerStatus_Removed || nonRunner.BSP != nil || nonRunner.RemovalDate == nil ||
+		!nonRunner.RemovalDate.Equal(time.Date(2020, 7, 17, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("got removed runner %+v", nonRunner)
+	}
+}
+
+func TestMarketDefinitionKeptAcrossImages(t *testing.T) {
+	runners := `{"id":10,"status":"ACTIVE","sortPriority":1,"adjustmentFactor":60,"bsp":3.2},` +
+		`{"id":30,"status":"REMOVED","sortPriority":2,"adjustmentFactor":40,"removalDate":"2020-07-17T13:00:00.000Z"}`
+
+	cm := NewCacheManager()
+	for _, data := range []string{
+		definitionMessage("c1", 1594990000000, true, 1, "OPEN", false, 0, runners),
+		`{"op":"mcm","id":1,"clk":"c2","pt":1594990000100,"mc":[{"id":"1.1","img":true,"rc":[{"id":10,"ltp":2.5}]}]}`,
+	} {
+		if _, err := cm.Process(decodeMCM(t, data)); err != nil {
+			t.Fatalf("error processing message - error: %s", err)
+		}
+	}
+
+	mc, _ := cm.GetCache("1.1")
+	if len(mc.Runners) != 2 {
+		t.Fatalf("got %d runners, want 2", len(mc.Runners))
+	}
+
+	active := mc.Runners[RunnerKey{SelectionID: 10}]
+	if active.Status != exchangestream.RunnerStatus_Active || active.SortPriority != 1 || active.AdjustmentFactor != 60 ||
+		active.BSP == nil || active.BSP.String() != "3.2" || active.LastTradedPrice == nil || active.LastTradedPrice.String() != "2.5" {
+		t.Errorf("got runner %+v, want the definition fields and the prices of the image", active)
+	}
+
+	nonRunner := mc.Runners[RunnerKey{SelectionID: 30}]
+	if nonRunner.Status != exchangestream.RunnerStatus_Removed || nonRunner.SortPriority != 2 || nonRunner.AdjustmentFactor != 40 ||
+		nonRunner.RemovalDate == nil || nonRunner.LastTradedPrice != nil {
+		t.Errorf("got removed runner %+v, want the definition fields only", nonRunner)
+	}
+}
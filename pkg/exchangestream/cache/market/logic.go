@@ -1,36 +1,104 @@
 package market
 
-import "github.com/gustavooferreira/betfair/pkg/exchangestream"
+import (
+	"errors"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
 
 type CacheManager struct {
 	// caches are not thread-safe
 	caches map[string]MarketCache
-	// Message ID
-	msgID uint32
+	// Latest message ID per connection (key: MarketChangeM.ConnectionID)
+	msgIDs map[string]uint32
+	// Gets the market events, nil when not set
+	eventHandler func(event MarketEvent)
 }
 
 func NewCacheManager() CacheManager {
 	caches := make(map[string]MarketCache)
-	cm := CacheManager{caches: caches}
+	cm := CacheManager{caches: caches, msgIDs: make(map[string]uint32)}
 
 	return cm
 }
 
+// ErrOldSubscription is returned for messages of a subscription that has since been replaced
+var ErrOldSubscription = errors.New("message from an old subscription")
+
 // Process gets a MarketChangeM message and updates the relevant caches accordingly
 // Returns a list with the MarketIDs of the caches that were updated
 func (cm *CacheManager) Process(mcm exchangestream.MarketChangeM) ([]string, error) {
+	// The first ID seen is accepted, after that only messages with that ID are accepted.
+	// A higher ID means there was a subscription change, the new ID is accepted and the old one rejected.
+	// The first message of a subscription (SUB_IMAGE or RESUB_DELTA) is accepted whatever its ID, as the IDs start
+	// over when the client reconnects.
+	// Messages without an ID (e.g. historic data) are always accepted.
+	// IDs are tracked per connection, so the messages merged from several connections (e.g. StreamPool) are accepted.
+	if mcm.ID != nil {
+		newSubscription := mcm.ChangeType != nil &&
+			(*mcm.ChangeType == exchangestream.ChangeType_SubImage || *mcm.ChangeType == exchangestream.ChangeType_ResubDelta)
+		if *mcm.ID < cm.msgIDs[mcm.ConnectionID] && !newSubscription {
+			return nil, ErrOldSubscription
+		}
+		cm.msgIDs[mcm.ConnectionID] = *mcm.ID
+	}
+
+	// Segmented messages are expected to be reassembled by the client (see ESAClient.SetSegmentation)
 
-	// Detect if ID has changed!
-	// If msgID is zero, accept the first ID in the message
-	// After that lock in all message changes to that ID
-	// When a new higher ID shows up, that means there was a subscription change
-	// Accept the new ID, update the msgID and reject everything else!
+	updated := []string{}
+	for _, change := range mcm.MarketChanges {
+		mc, ok := cm.caches[change.ID]
+		if !ok {
+			mc = NewMarketCache(change.ID)
+		}
 
-	// If you get a marketID update that wasn't in the cache that might mean we delete it?
+		// The publish time is set first, it goes into the definition history and the events
+		if mcm.InitialClk != "" {
+			mc.InitialClk = mcm.InitialClk
+		}
+		if mcm.Clk != "" {
+			mc.Clk = mcm.Clk
+		}
+		if !mcm.PublishTime.IsZero() {
+			pt := mcm.PublishTime.Time
+			mc.PublishTime = &pt
+		}
+		events := mc.Update(change)
 
-	// Support segmentation
+		cm.caches[change.ID] = mc
+		updated = append(updated, change.ID)
 
-	return []string{}, nil
+		if cm.eventHandler != nil {
+			for _, event := range events {
+				cm.eventHandler(event)
+			}
+		}
+	}
+
+	return updated, nil
+}
+
+// Reset forgets the message IDs seen, so the next messages are accepted whatever their ID.
+// Call it when the messages come from a new connection without being told apart by ConnectionID.
+// The caches are kept, the new subscription brings them up to date.
+func (cm *CacheManager) Reset() {
+	cm.msgIDs = make(map[string]uint32)
+}
+
+// SetEventHandler registers the function that gets the market events (status transitions, in-play flips,
+// runner removals and bet delay changes), it's called from Process once the cache is updated.
+// Passing nil unregisters it.
+func (cm *CacheManager) SetEventHandler(handler func(event MarketEvent)) {
+	cm.eventHandler = handler
+}
+
+// GetCache returns a copy of the cache of a market, it's not affected by the messages processed afterwards.
+func (cm *CacheManager) GetCache(marketID string) (MarketCache, bool) {
+	mc, ok := cm.caches[marketID]
+	if !ok {
+		return MarketCache{}, false
+	}
+	return mc.clone(), true
 }
 
 // GetCachesAvailable returns the list of MarketIDs available in the caches.
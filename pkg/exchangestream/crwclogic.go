@@ -1,10 +1,12 @@
 package exchangestream
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/gustavooferreira/betfair/pkg/globals"
@@ -15,80 +17,261 @@ import (
 // timeout of 0.5 seconds
 const timeoutDuration = 500 * time.Millisecond
 
-func controller(esaclient *ESAClient, connMsgChan chan<- ConnectionMessage) {
+// How long the writer waits for a request to be written to the connection
+const writeTimeout = 5 * time.Second
+
+// How often the controller looks for expired requests in the lookup table
+const lookupTableExpiryInterval = 500 * time.Millisecond
+
+// How often the controller tries to send the market changes merged while MCMChan was full
+const coalescerFlushInterval = 50 * time.Millisecond
+
+// requestFrame is a request ready to be written to the connection
+type requestFrame struct {
+	id   uint32
+	data []byte
+}
+
+// writeFailure tells the controller which request the writer failed to write
+type writeFailure struct {
+	id  uint32
+	err error
+}
+
+// reply sends the result back to the caller, without blocking (the caller might have given up already)
+func (wu WorkUnit) reply(result workResult) {
+	select {
+	case wu.respChan <- result:
+	default:
+	}
+}
+
+func controller(esaclient *ESAClient, ctx context.Context, connMsgChan chan<- ConnectionMessage) {
 	connPhaseDone := false
+	// Set from the connection message, change messages are tagged with it
+	connectionID := ""
 
 	respMsgChan := make(chan ResponseMessage, 1000)
-	readerStopChan := make(chan bool)
-	reqMsgChan := make(chan RequestMessage, 1000)
-	writerStopInformChan := make(chan bool)
-	connTrackerStopChan := make(chan bool)
-	connTrackerStopInformChan := make(chan bool)
+	// Closed when the controller exits, which stops the writer
+	reqMsgChan := make(chan requestFrame, 1000)
+	defer close(reqMsgChan)
+	// The writer reports here the request it failed to write, before signaling the connection was lost
+	writeErrChan := make(chan writeFailure, 1)
+	// reader, writer and connTracker signal here when they find the connection is no longer good
+	connLostChan := make(chan error, 3)
+	connLostReported := false
+	generation := atomic.LoadUint32(&esaclient.connGeneration)
 
 	// LookupTable (key: msgID)
-	// TODO: implement expiration (if we don't get a response back, it never gets cleared!)
-	lookupTable := make(map[uint32](chan ResponseMessage))
+	// Entries are expired by expiryTicker, in case a response never arrives
+	lookupTable := make(map[uint32]WorkUnit)
+	expiryTicker := time.NewTicker(lookupTableExpiryInterval)
+	defer expiryTicker.Stop()
+	// Sends the market changes merged while MCMChan was full, as soon as there is room
+	coalescerTicker := time.NewTicker(coalescerFlushInterval)
+	defer coalescerTicker.Stop()
+
+	// Segmented change messages are buffered here until complete, if reassembly is on
+	segmentationConfig := esaclient.segmentationConfig.Load().(SegmentationConfig)
+	marketSegs := marketSegments{config: segmentationConfig}
+	orderSegs := orderSegments{config: segmentationConfig}
+
+	// failPending fails all the requests waiting for a response
+	failPending := func(err error) {
+		for id, workUnit := range lookupTable {
+			delete(lookupTable, id)
+			workUnit.reply(workResult{err: err})
+		}
+	}
 
-	// Spawn reader, writer and connTracker goroutines
-	go esaclient.reader(respMsgChan, readerStopChan)
-	go esaclient.writer(reqMsgChan, writerStopInformChan)
-	go esaclient.connTracker(connTrackerStopChan, connTrackerStopInformChan)
+	// Spawn reader, writer and connTracker goroutines, reader and connTracker stop when ctx is cancelled
+	esaclient.connWG.Add(3)
+	go esaclient.reader(ctx, respMsgChan, connLostChan)
+	go esaclient.writer(reqMsgChan, writeErrChan, connLostChan)
+	go esaclient.connTracker(ctx, connLostChan)
+
+	// reportConnLost reports the connection loss once to the supervisor, which will replace this connection
+	reportConnLost := func(err error) {
+		if connLostReported {
+			return
+		}
+		connLostReported = true
 
-	// If we get a signal on stopChan, trigger reader, writer and connTracker to end too
-	// When getting the connection message, send the data and close the channel
-	for {
 		select {
-		case <-esaclient.stopChan:
-			close(connTrackerStopChan)
-			close(readerStopChan)
-			close(reqMsgChan)
+		case esaclient.connLostChan <- connLost{generation: generation, err: err}:
+		default:
+		}
+	}
 
-			// Wait on all 3 informs and then return!
-			// When getting all informs, send signal on stopInformChan Channel
-			esaclient.stopInformChan <- true
+	handleResponse := func(respMsg ResponseMessage) {
+		// log.Log(globals.Logger, log.DEBUG, "betfair message received", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
 
-			return
-		case respMsg, ok := <-respMsgChan:
-			if !ok {
-				// This should never happen!
-				// 	close(esaclient.stopInformChan)
-				// 	return
+		if respMsg.Op == "connection" {
+			if !connPhaseDone {
+				connectionID = respMsg.ConnectionMessage.ConnectionID
+				select {
+				case connMsgChan <- *respMsg.ConnectionMessage:
+				case <-ctx.Done():
+				}
+				close(connMsgChan)
+				connPhaseDone = true
+			} else {
+				log.Log(globals.Logger, log.ERROR, "got a ConnectionMessage while not being in connection phase", log.Fields{"connectionID": respMsg.ID})
+			}
+		} else if respMsg.Op == "mcm" {
+			mcm := MarketChangeM{ID: respMsg.ID, ConnectionID: connectionID, MarketChangeMessage: *respMsg.MarketChangeMessage}
+			if segmentationConfig.Reassemble {
+				var complete bool
+				var err error
+				if mcm, complete, err = marketSegs.add(mcm, time.Now()); err != nil {
+					esaclient.reportError(err)
+				}
+				if !complete {
+					return
+				}
+			}
+
+			esaclient.subscriptions.updateMarketClocks(mcm.InitialClk, mcm.Clk)
+			if mcm.HeartbeatMs != 0 {
+				atomic.StoreUint32(&esaclient.negotiated.marketHeartbeatMS, uint32(mcm.HeartbeatMs))
+				atomic.StoreUint32(&esaclient.negotiated.marketConflateMS, uint32(mcm.ConflateMs))
+			}
+			if d := esaclient.getDispatcher(); d != nil {
+				if err := d.market.push(ctx, mcm, esaclient.backpressure.Load().(BackpressureConfig).Market); err != nil && ctx.Err() == nil {
+					esaclient.reportError(err)
+					reportConnLost(err)
+				}
+			} else if err := esaclient.publishMCM(ctx, mcm); err != nil && ctx.Err() == nil {
+				esaclient.reportError(err)
+				reportConnLost(err)
+			}
+		} else if respMsg.Op == "ocm" {
+			ocm := OrderChangeM{ID: respMsg.ID, ConnectionID: connectionID, OrderChangeMessage: *respMsg.OrderChangeMessage}
+			if segmentationConfig.Reassemble {
+				var complete bool
+				var err error
+				if ocm, complete, err = orderSegs.add(ocm, time.Now()); err != nil {
+					esaclient.reportError(err)
+				}
+				if !complete {
+					return
+				}
+			}
+
+			esaclient.subscriptions.updateOrderClocks(ocm.InitialClk, ocm.Clk)
+			if ocm.HeartbeatMs != 0 {
+				atomic.StoreUint32(&esaclient.negotiated.orderHeartbeatMS, uint32(ocm.HeartbeatMs))
+				atomic.StoreUint32(&esaclient.negotiated.orderConflateMS, uint32(ocm.ConflateMs))
 			}
-			// log.Log(globals.Logger, log.DEBUG, "betfair message received", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
-
-			if respMsg.Op == "connection" {
-				if !connPhaseDone {
-					connMsgChan <- *respMsg.ConnectionMessage
-					close(connMsgChan)
-					connPhaseDone = true
-				} else {
-					log.Log(globals.Logger, log.ERROR, "got a ConnectionMessage while not being in connection phase", log.Fields{"connectionID": respMsg.ID})
+			if d := esaclient.getDispatcher(); d != nil {
+				if err := d.order.push(ctx, ocm, esaclient.backpressure.Load().(BackpressureConfig).Order); err != nil && ctx.Err() == nil {
+					esaclient.reportError(err)
+					reportConnLost(err)
 				}
-			} else if respMsg.Op == "mcm" {
-				esaclient.MCMChan <- MarketChangeM{ID: respMsg.ID, MarketChangeMessage: *respMsg.MarketChangeMessage}
-			} else if respMsg.Op == "ocm" {
-				esaclient.OCMChan <- OrderChangeM{ID: respMsg.ID, OrderChangeMessage: *respMsg.OrderChangeMessage}
-			} else if respMsg.Op == "status" {
-				// Validate there is even an ID!
-				if respMsg.ID == nil {
-					// Error, no ID found!
-					log.Log(globals.Logger, log.ERROR, "got status message without an ID", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
-				} else if result, ok := lookupTable[*respMsg.ID]; !ok {
-					// Error, no ID found!
-					log.Log(globals.Logger, log.ERROR, "got status message with no matching ID", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
-				} else {
-					// Delete entry from the lookup table!
-					delete(lookupTable, *respMsg.ID)
-
-					result <- respMsg
+			} else if err := esaclient.publishOCM(ctx, ocm); err != nil && ctx.Err() == nil {
+				esaclient.reportError(err)
+				reportConnLost(err)
+			}
+		} else if respMsg.Op == "status" {
+			if d := esaclient.getDispatcher(); d != nil {
+				d.status.push(ctx, StatusM{ID: respMsg.ID, StatusMessage: *respMsg.StatusMessage}, BackpressurePolicy_DropOldest)
+			}
+
+			// Betfair reports errors through status messages, some of them unsolicited (without an ID)
+			if respMsg.StatusMessage.StatusCode != StatusCode_Success {
+				statusErr := NewStatusError(*respMsg.StatusMessage)
+				esaclient.reportError(statusErr)
+
+				if statusErr.ConnectionClosed {
+					reportConnLost(statusErr)
 				}
+			}
+
+			// Validate there is even an ID!
+			if respMsg.ID == nil {
+				// Error, no ID found!
+				log.Log(globals.Logger, log.ERROR, "got status message without an ID", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
+			} else if workUnit, ok := lookupTable[*respMsg.ID]; !ok {
+				// Error, no ID found!
+				log.Log(globals.Logger, log.ERROR, "got status message with no matching ID", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
 			} else {
-				log.Log(globals.Logger, log.ERROR, "unknown message operation type", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
+				// Delete entry from the lookup table!
+				delete(lookupTable, *respMsg.ID)
+
+				// Keep track of successful subscriptions, done here so no change message gets in before the subscription is recorded
+				if respMsg.StatusMessage.StatusCode == StatusCode_Success {
+					if workUnit.req.MarketSubscriptionMessage != nil {
+						esaclient.subscriptions.setMarket(*workUnit.req.MarketSubscriptionMessage, *respMsg.ID)
+					} else if workUnit.req.OrderSubscriptionMessage != nil {
+						esaclient.subscriptions.setOrder(*workUnit.req.OrderSubscriptionMessage)
+					}
+				}
+
+				workUnit.reply(workResult{resp: respMsg})
 			}
-		case workUnit, ok := <-esaclient.reqMsgChan:
-			if !ok {
-				// This should never happen!
-				// Error
+		} else {
+			log.Log(globals.Logger, log.ERROR, "unknown message operation type", log.Fields{"message": fmt.Sprintf("%+v", respMsg)})
+		}
+	}
+
+	// When ctx is cancelled, reader and connTracker stop too and closing reqMsgChan stops the writer
+	// When getting the connection message, send the data and close the channel
+	for {
+		select {
+		case <-ctx.Done():
+			failPending(ErrConnectionLost)
+			return
+		case err := <-connLostChan:
+			// Handle the messages read before the connection was lost first,
+			// as they might explain why (e.g. a status message with connectionClosed set)
+			for len(respMsgChan) > 0 {
+				handleResponse(<-respMsgChan)
+			}
+
+			// The request that couldn't be written gets the write error
+			select {
+			case failure := <-writeErrChan:
+				if workUnit, ok := lookupTable[failure.id]; ok {
+					delete(lookupTable, failure.id)
+					workUnit.reply(workResult{err: failure.err})
+				}
+			default:
+			}
+
+			reportConnLost(err)
+
+			// No responses will come through this connection anymore
+			failPending(ErrConnectionLost)
+		case <-coalescerTicker.C:
+			esaclient.coalescer.flush(esaclient.MCMChan)
+		case now := <-expiryTicker.C:
+			if err := marketSegs.expire(now); err != nil {
+				esaclient.reportError(err)
+			}
+			if err := orderSegs.expire(now); err != nil {
+				esaclient.reportError(err)
+			}
+
+			for id, workUnit := range lookupTable {
+				if now.After(workUnit.expiry) {
+					log.Log(globals.Logger, log.WARN, "request expired without a response", log.Fields{"id": id, "op": workUnit.req.Op})
+					delete(lookupTable, id)
+					workUnit.reply(workResult{err: ErrRequestExpired})
+				}
+			}
+		case respMsg := <-respMsgChan:
+			handleResponse(respMsg)
+		case workUnit := <-esaclient.reqMsgChan:
+			// Don't send anything else through a connection that is gone
+			if connLostReported {
+				workUnit.reply(workResult{err: ErrConnectionLost})
+				continue
+			}
+
+			// The caller gave up on it already (e.g. queued while reconnecting)
+			if time.Now().After(workUnit.expiry) {
+				workUnit.reply(workResult{err: ErrRequestExpired})
+				continue
 			}
 
 			// Check if ID is set, if not, get one and set it on the struct
@@ -99,225 +282,207 @@ func controller(esaclient *ESAClient, connMsgChan chan<- ConnectionMessage) {
 				*workUnit.req.ID = esaclient.getNewID()
 			}
 
-			// TODO: If message if of type MarketSub or OrderSub set heartbeatMS!
-			// _ = esaclient.heartbeatMS
+			// Subscriptions use the client heartbeat and conflation settings, unless they set their own
+			esaclient.applySettings(&workUnit.req)
 
-			lookupTable[*workUnit.req.ID] = workUnit.respChan
+			data, err := json.Marshal(workUnit.req)
+			if err != nil {
+				log.Log(globals.Logger, log.ERROR, "failed marshalling request", log.Fields{"id": *workUnit.req.ID, "error": err.Error()})
+				workUnit.reply(workResult{err: err})
+				continue
+			}
 
-			reqMsgChan <- workUnit.req
+			lookupTable[*workUnit.req.ID] = workUnit
+
+			// The writer might be stuck on a dead connection, the request is failed along with the others when ctx is done
+			select {
+			case reqMsgChan <- requestFrame{id: *workUnit.req.ID, data: append(data, '\r', '\n')}:
+			case <-ctx.Done():
+			}
 		}
 	}
 }
 
 // reader is responsible for reading all incoming messages and sending the corresponding objects down the channel
-func reader(esaclient *ESAClient, respMsgChan chan<- ResponseMessage, stopChan <-chan bool) {
-	// Create a buffer for incoming messages
-	var buf []byte = make([]byte, esaclient.readerBufferSize)
-	indiceStart := 0
-	indiceStop := 0
-
-	for {
-		// check stopChan without blocking, if set then exit function
-		select {
-		case _, ok := <-stopChan:
-			if !ok {
-				close(respMsgChan)
-				return
-			}
-		default:
+func reader(esaclient *ESAClient, ctx context.Context, respMsgChan chan<- ResponseMessage, connLostChan chan<- error) {
+	// Hold on to this connection, esaclient.conn gets replaced when reconnecting
+	conn := esaclient.conn
+
+	fr := newFrameReader(conn, int(esaclient.readerBufferSize), int(atomic.LoadUint32(&esaclient.maxMessageSize)))
+	fr.onRead = func(n int) {
+		if atomic.LoadUint32(&esaclient.metricsFlag) == 1 {
+			esaclient.readCounter.Add(float64(n))
 		}
+	}
 
-		// Call Read with timeout
-		esaclient.conn.SetReadDeadline(time.Now().Add(timeoutDuration))
-
-		n, err := esaclient.conn.Read(buf[indiceStop : len(buf)-1])
+	// Connection ID of this connection, recorded along with the messages
+	connectionID := ""
 
-		if esaclient.metricsFlag == 1 {
-			if n != 0 {
-				esaclient.readCounter.Add(float64(n))
-			}
+	for {
+		// check ctx without blocking, if done then exit function
+		if ctx.Err() != nil {
+			return
 		}
 
-		log.Log(globals.Logger, log.DEBUG, fmt.Sprintf("read %d bytes from connection", n), nil)
+		// Call Read with timeout
+		conn.SetReadDeadline(time.Now().Add(timeoutDuration))
 
-		if err1, ok := err.(*net.OpError); ok {
-			// If timeout, continue
-			if err1.Timeout() {
-				log.Log(globals.Logger, log.DEBUG, "timeout occured while waiting to read", nil)
-				continue
-			}
-			log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-			// Before continue, prob need to do something with the potential stuff that is in the buffer!
-			// TODO: get the extra data read and update indices!
+		frame, err := fr.next()
 
-			// Inform the main program that connection was closed!
+		if err != nil && ctx.Err() != nil {
+			// The connection was closed on our side to stop the reader
 			return
+		} else if err1, ok := err.(net.Error); ok && err1.Timeout() {
+			// Timeout, check ctx and carry on from where it was left
+			continue
+		} else if err == ErrMessageTooLarge {
+			log.Log(globals.Logger, log.ERROR, "message over the maximum size, skipping it", log.Fields{"maxSize": fr.maxSize})
+			esaclient.reportError(err)
+			continue
 		} else if err == io.EOF {
 			// Connection was disconnected!
 			log.Log(globals.Logger, log.ERROR, "connection closed on the server side", nil)
 
 			// Inform the main program that connection was closed!
+			signalConnLost(connLostChan, err)
 			return
 		} else if err != nil {
 			log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-			// Before continue, prob need to do something with the potential stuff that is in the buffer!
-			// TODO: get the extra data read and update indices!
 
 			// Inform the main program that connection was closed!
+			signalConnLost(connLostChan, err)
 			return
 		}
 
-		fields := log.Fields{"message": fmt.Sprintf("%s", buf[indiceStart:n]), "type": "reader-data"}
-		log.Log(globals.Logger, log.TRACE, "reader received message", fields)
-
-		indiceStop += n
-		progress := true
-
-		// Iterate through the various potential messages (delim \r\n) we might have
-		for progress {
-			progress = false
-
-			for i := indiceStart; i < indiceStop; i++ {
-				if buf[i] == '\n' {
-					if i-indiceStart == 0 || i-indiceStart == 1 {
-						// discard
-						indiceStart = i + 1
-						progress = true
-						break
-					} else if buf[i-1] != '\r' {
-						// discard
-						indiceStart = i + 1
-						progress = true
-						break
-					} else {
-						// Log this into its own message field, and define a "type" field that specifies this is message related (like zap)
-						log.Log(globals.Logger, log.DEBUG, fmt.Sprintf("message ready to be assembled: %s", buf[indiceStart:i-1]), nil)
-
-						// Unmarshal into an object
-						respMsg := ResponseMessage{}
-						err = json.Unmarshal(buf[indiceStart:i-1], &respMsg)
-						if err != nil {
-							log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-						} else {
-							// Push the newly created object down the channel
-							esaclient.heartbeatUpdateChan <- 1
-							respMsgChan <- respMsg
-						}
-
-						indiceStart = i + 1
-						progress = true
-						break
-					}
-				}
-			}
+		if globals.Logger != nil {
+			log.Log(globals.Logger, log.TRACE, "reader received message", log.Fields{"message": string(frame), "type": "reader-data"})
 		}
 
-		// Shift content to the beginning of the buffer
-		if indiceStart != 0 {
-			if indiceStop == indiceStart {
-				indiceStart = 0
-				indiceStop = 0
-			} else {
-				copy(buf[:], buf[indiceStart:indiceStop])
-				indiceStop -= indiceStart
-				indiceStart = 0
-			}
+		respMsg := ResponseMessage{}
+		err = respMsg.decode(frame)
+
+		if respMsg.ConnectionMessage != nil {
+			connectionID = respMsg.ConnectionMessage.ConnectionID
+		}
+		// The recorder copies the frame, the messages that fail to decode are recorded too
+		if rec := esaclient.getRecorder(); rec != nil {
+			rec.Record(frame, connectionID)
+		}
+
+		if err != nil {
+			log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
+			continue
+		}
+
+		// Let connTracker know the connection is alive, one pending update is enough
+		select {
+		case esaclient.heartbeatUpdateChan <- 1:
+		default:
+		}
+		select {
+		case respMsgChan <- respMsg:
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
-func writer(esaclient *ESAClient, reqMsgChan <-chan RequestMessage, stopInformChan chan<- bool) {
+func writer(esaclient *ESAClient, reqMsgChan <-chan requestFrame, writeErrChan chan<- writeFailure, connLostChan chan<- error) {
+	// Hold on to this connection, esaclient.conn gets replaced when reconnecting
+	writeLoop(esaclient.conn, reqMsgChan, writeErrChan, connLostChan)
+}
+
+// deadlineWriter is the part of the connection used by the writer
+type deadlineWriter interface {
+	io.Writer
+	SetWriteDeadline(t time.Time) error
+}
+
+// writeLoop writes the requests to the connection until reqMsgChan is closed
+func writeLoop(conn deadlineWriter, reqMsgChan <-chan requestFrame, writeErrChan chan<- writeFailure, connLostChan chan<- error) {
 	for {
 		select {
-		case reqMsg, ok := <-reqMsgChan:
+		case frame, ok := <-reqMsgChan:
 			if !ok {
-				close(stopInformChan)
 				return
 			}
 
-			// log.Log(globals.Logger, log.DEBUG, fmt.Sprintf("struct received on writer: %+v", reqMsg), nil)
-
-			// Marhsal Request
-			bytes, err := json.Marshal(reqMsg)
-			if err != nil {
-				log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-				continue
+			if globals.Logger != nil {
+				log.Log(globals.Logger, log.TRACE, "writer sending message", log.Fields{"message": string(frame.data), "type": "writer-data"})
 			}
 
-			fields := log.Fields{"message": fmt.Sprintf("%s", bytes), "type": "writer-data"}
-			log.Log(globals.Logger, log.TRACE, "writer sending message", fields)
-
-			esaclient.conn.SetWriteDeadline(time.Now().Add(timeoutDuration))
+			// A write timeout leaves the TLS connection unusable, so every write error means the connection is gone
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 
-			// TODO: Write function might not write all bytes to kernel!
-			n, err := esaclient.conn.Write(append(bytes[:], []byte{'\r', '\n'}...))
+			if err := writeFull(conn, frame.data); err != nil {
+				log.Log(globals.Logger, log.ERROR, "failed writing request to the connection", log.Fields{"id": frame.id, "error": err.Error()})
 
-			log.Log(globals.Logger, log.DEBUG, fmt.Sprintf("write %d bytes to connection", n), nil)
+				// Let the caller know first, then bring the connection down
+				writeErrChan <- writeFailure{id: frame.id, err: WriteError{Err: err}}
+				signalConnLost(connLostChan, err)
 
-			if err1, ok := err.(*net.OpError); ok {
-				// If timeout, continue
-				if err1.Timeout() {
-					log.Log(globals.Logger, log.DEBUG, "timeout occured while waiting to write", nil)
-					continue
+				// Nothing else can be written, wait to be stopped
+				for range reqMsgChan {
 				}
-				log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-				// Before continue, prob need to do something with the potential stuff that is in the buffer!
-				// TODO: get the extra data read and update indices!
-
-				// Inform the main program that connection was closed!
-				return
-			} else if err == io.EOF {
-				// Connection was disconnected!
-				log.Log(globals.Logger, log.ERROR, "connection closed on the server side", nil)
-
-				// Inform the main program that connection was closed!
-				return
-			} else if err != nil {
-				// If EOF, connection was closed!!
-				log.Log(globals.Logger, log.ERROR, fmt.Sprintf("error: type [%T] - %+[1]v", err), nil)
-				// Before continue, prob need to do something with the potential stuff that is in the buffer!
-				// TODO: get the extra data read and update indices!
-
-				// Inform the main program that connection was closed!
 				return
 			}
 		}
 	}
 }
 
-func connTracker(esaclient *ESAClient, stopChan <-chan bool, stopInformChan chan<- bool) {
+// writeFull writes the whole frame, a short write without error is reported as io.ErrShortWrite
+func writeFull(w io.Writer, data []byte) error {
+	for len(data) > 0 {
+		n, err := w.Write(data)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func connTracker(esaclient *ESAClient, ctx context.Context, connLostChan chan<- error) {
 	// Wait here until Auth has been done
-	select {
-	case _, ok := <-stopChan:
-		if !ok {
-			close(stopInformChan)
+	// Keep draining the updates meanwhile, otherwise the reader blocks on a full heartbeatUpdateChan
+	authenticated := false
+	for !authenticated {
+		select {
+		case <-ctx.Done():
 			return
+		case <-esaclient.heartbeatUpdateChan:
+		case <-esaclient.authSuccessChan:
+			// After Auth was successful then proceed
+			authenticated = true
 		}
-	case <-esaclient.authSuccessChan:
-		// After Auth was successful then proceed
 	}
 
 	// Before calling MarketSubscribe or OrderSubscribe there won't be any heartbeats, but that's fine
 	// because calls to the Heartbeat function must still succeed
 	for {
 		select {
-		case _, ok := <-stopChan:
-			if !ok {
-				close(stopInformChan)
-				return
-			}
+		case <-ctx.Done():
+			return
 		case <-esaclient.heartbeatUpdateChan:
 			// Got update
-		case <-time.After(time.Duration(esaclient.heartbeatMultiplier*esaclient.heartbeatMS/100) * time.Millisecond):
+		case <-time.After(esaclient.livenessTimeout()):
 			// Call heartbeat function
 			fields := log.Fields{"source": "connTracker"}
 			log.Log(globals.Logger, log.DEBUG, "time's up, sending heartbeat to check connection status", fields)
-			sm, err := esaclient.Heartbeat()
-			if err != nil {
+			// Bound to ctx as well, so stopping doesn't wait for the heartbeat to time out
+			heartbeatCtx, cancel := context.WithTimeout(ctx, time.Duration(atomic.LoadInt64(&esaclient.requestTimeout)))
+			sm, err := esaclient.HeartbeatWithContext(heartbeatCtx)
+			cancel()
+			if ctx.Err() != nil {
+				return
+			} else if err != nil {
 				log.Log(globals.Logger, log.ERROR, "error while doing conn tracking", fields)
 
 				// Inform the main program that connection is no longer good!
-
+				signalConnLost(connLostChan, err)
 			} else if sm.StatusCode != StatusCode_Success {
 				fields = log.Fields{"source": "connTracker", "StatusMessage": sm}
 				log.Log(globals.Logger, log.ERROR, "heartbeat was not successfull! show status message", fields)
@@ -329,3 +494,11 @@ func connTracker(esaclient *ESAClient, stopChan <-chan bool, stopInformChan chan
 		}
 	}
 }
+
+// signalConnLost informs the controller that the connection is no longer good, without blocking
+func signalConnLost(connLostChan chan<- error, err error) {
+	select {
+	case connLostChan <- err:
+	default:
+	}
+}
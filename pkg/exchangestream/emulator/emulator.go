@@ -0,0 +1,322 @@
+// Package emulator is a local stand-in for the betfair Exchange Stream API.
+// It speaks the protocol well enough to test the client and the caches end-to-end without network access:
+// authentication, heartbeats, market and order subscriptions with images and deltas, resubscription from a
+// clock, segmentation, conflation, status errors and forced disconnects.
+// The market filter (market IDs and the market definition fields) is applied. The market data filter and the order
+// filter are not, subscriptions get every price and all the orders.
+package emulator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// Defaults used when the config leaves them unset
+const (
+	defaultClockHistory = 1000
+	defaultQueueSize    = 10000
+	defaultHeartbeatMs  = 5000
+)
+
+// Config holds the emulator settings.
+type Config struct {
+	// Addr is the address to listen on (a random local port by default)
+	Addr string
+	// Plaintext serves without TLS (ESAClient always uses TLS, this is meant for other clients)
+	Plaintext bool
+	// Certificate used for TLS, a self-signed certificate for 127.0.0.1 is generated when nil
+	Certificate *tls.Certificate
+	// AppKey accepted on authentication, any app key is accepted when empty
+	AppKey string
+	// SessionTokens accepted on authentication, any session token is accepted when empty.
+	// More can be added and revoked later on.
+	SessionTokens []string
+	// MaxConnections is how many connections can be open at once (0 means no limit)
+	MaxConnections int
+	// SegmentSize is the maximum number of market or order changes in a message, messages with more changes
+	// are segmented for subscriptions with segmentation enabled (0 never segments)
+	SegmentSize int
+	// ClockHistory is how many changes are kept to resume subscriptions from a clock (1000 by default).
+	// Resubscribing from an older clock fails with INVALID_CLOCK.
+	ClockHistory int
+	// QueueSize is how many messages can be waiting to be written to a connection before it's dropped (10000 by default)
+	QueueSize int
+}
+
+// Server is the emulated Exchange Stream API server.
+// It's thread safe!
+type Server struct {
+	config   Config
+	listener net.Listener
+	// Tracks the accept loop and the connection goroutines
+	wg sync.WaitGroup
+
+	// Guards everything below
+	mu            sync.Mutex
+	closed        bool
+	sessionTokens map[string]bool
+	// Set when only the session tokens in sessionTokens are accepted
+	checkTokens bool
+	sessions    map[*session]bool
+	// Number of connections accepted so far, used for the connection IDs
+	connCount int
+	// Requests to fail (key: op)
+	failures map[string][]exchangestream.StatusMessage
+	// Clock incremented on every change published
+	clk     uint64
+	markets *marketState
+	orders  *orderState
+}
+
+// NewServer creates a server listening on config.Addr.
+func NewServer(config Config) (*Server, error) {
+	if config.ClockHistory <= 0 {
+		config.ClockHistory = defaultClockHistory
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = defaultQueueSize
+	}
+	if config.Addr == "" {
+		config.Addr = "127.0.0.1:0"
+	}
+
+	var listener net.Listener
+	var err error
+
+	if config.Plaintext {
+		listener, err = net.Listen("tcp", config.Addr)
+	} else {
+		if config.Certificate == nil {
+			cert, err := selfSignedCert()
+			if err != nil {
+				return nil, fmt.Errorf("error creating certificate: %w", err)
+			}
+			config.Certificate = &cert
+		}
+		listener, err = tls.Listen("tcp", config.Addr, &tls.Config{Certificates: []tls.Certificate{*config.Certificate}})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listening: %w", err)
+	}
+
+	s := &Server{
+		config:        config,
+		listener:      listener,
+		sessionTokens: make(map[string]bool),
+		checkTokens:   len(config.SessionTokens) > 0,
+		sessions:      make(map[*session]bool),
+		failures:      make(map[string][]exchangestream.StatusMessage),
+		markets:       newMarketState(config.ClockHistory),
+		orders:        newOrderState(config.ClockHistory),
+	}
+	for _, token := range config.SessionTokens {
+		s.sessionTokens[token] = true
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() *net.TCPAddr {
+	return s.listener.Addr().(*net.TCPAddr)
+}
+
+// ConnectionConfig returns a client configuration pointing to this server, assuming it listens on a local address.
+func (s *Server) ConnectionConfig() exchangestream.ConnectionConfig {
+	return exchangestream.ConnectionConfig{
+		ServerHost:         "127.0.0.1",
+		ServerPort:         uint(s.Addr().Port),
+		InsecureSkipVerify: true,
+		ConnectionTimeout:  1000,
+		Retries:            3,
+		MaximumBackoff:     1,
+	}
+}
+
+// Close stops the server, closing all the connections, and waits for its goroutines to exit.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for sess := range s.sessions {
+		sess.close()
+	}
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// Connections returns the number of open connections.
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+// AddSessionToken makes the server accept a new session token.
+// Only makes a difference once session tokens are checked, i.e. SessionTokens was set or a token was revoked.
+func (s *Server) AddSessionToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessionTokens[token] = true
+}
+
+// RevokeSessionToken makes the server reject a session token.
+// Connections authenticated with it get an INVALID_SESSION_INFORMATION status and are closed.
+func (s *Server) RevokeSessionToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessionTokens, token)
+	s.checkTokens = true
+
+	for sess := range s.sessions {
+		if sess.sessionToken == token {
+			sess.sendStatus(nil, failure(exchangestream.ErrorCode_InvalidSessionInformation, "session token revoked", true))
+		}
+	}
+}
+
+// FailNext makes the next request with the given op (e.g. "marketSubscription") fail with the error code.
+// Calling it again queues more failures.
+func (s *Server) FailNext(op string, code exchangestream.ErrorCode, message string, closeConnection bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[op] = append(s.failures[op], failure(code, message, closeConnection))
+}
+
+// SendStatus sends an unsolicited failure status to all the connections, closing them if asked to.
+func (s *Server) SendStatus(code exchangestream.ErrorCode, message string, closeConnection bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sess := range s.sessions {
+		sess.sendStatus(nil, failure(code, message, closeConnection))
+	}
+}
+
+// DropConnections closes all the connections abruptly, without sending anything.
+func (s *Server) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sess := range s.sessions {
+		sess.close()
+	}
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+
+		s.connCount++
+		sess := newSession(s, conn, "emulator-"+strconv.Itoa(s.connCount))
+		limitExceeded := s.config.MaxConnections > 0 && len(s.sessions) >= s.config.MaxConnections
+		s.sessions[sess] = true
+		s.mu.Unlock()
+
+		log.Log(globals.Logger, log.DEBUG, "emulator accepted connection", log.Fields{"connectionID": sess.id})
+
+		s.wg.Add(2)
+		go sess.writer()
+		go sess.reader(limitExceeded)
+	}
+}
+
+// removeSession forgets about a closed connection
+func (s *Server) removeSession(sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sess)
+}
+
+// authenticate checks the credentials, returns the failure status if they're not accepted (must hold mu)
+func (s *Server) authenticate(appKey string, sessionToken string) *exchangestream.StatusMessage {
+	if s.config.AppKey != "" && appKey != s.config.AppKey {
+		sm := failure(exchangestream.ErrorCode_InvalidAppKey, "invalid app key", true)
+		return &sm
+	}
+	if s.checkTokens && !s.sessionTokens[sessionToken] {
+		sm := failure(exchangestream.ErrorCode_InvalidSessionInformation, "invalid session token", true)
+		return &sm
+	}
+	return nil
+}
+
+// nextFailure returns the failure queued for the op, if any (must hold mu)
+func (s *Server) nextFailure(op string) *exchangestream.StatusMessage {
+	queued := s.failures[op]
+	if len(queued) == 0 {
+		return nil
+	}
+
+	sm := queued[0]
+	s.failures[op] = queued[1:]
+	return &sm
+}
+
+func success() exchangestream.StatusMessage {
+	closed := false
+	return exchangestream.StatusMessage{StatusCode: exchangestream.StatusCode_Success, ConnectionClosed: &closed}
+}
+
+func failure(code exchangestream.ErrorCode, message string, closeConnection bool) exchangestream.StatusMessage {
+	return exchangestream.StatusMessage{
+		StatusCode:       exchangestream.StatusCode_Failure,
+		ErrorCode:        code,
+		ErrorMessage:     message,
+		ConnectionClosed: &closeConnection,
+	}
+}
+
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"betfair exchange stream emulator"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,523 @@
+package emulator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+func newServer(t *testing.T, config Config) *Server {
+	t.Helper()
+
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("error starting emulator - error: %s", err)
+	}
+	return s
+}
+
+func connect(t *testing.T, s *Server, connConfig exchangestream.ConnectionConfig, sessionToken string) *exchangestream.ESAClient {
+	t.Helper()
+
+	esaclient := exchangestream.NewESAClient("app_key", sessionToken)
+	if err := esaclient.Connect(context.Background(), connConfig); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	if sm, err := esaclient.Authenticate(); err != nil || sm.StatusCode != exchangestream.StatusCode_Success {
+		t.Fatalf("error authenticating - status: %+v, error: %v", sm, err)
+	}
+	return &esaclient
+}
+
+// waitMCM waits for the next market change message, skipping heartbeats
+func waitMCM(t *testing.T, esaclient *exchangestream.ESAClient) exchangestream.MarketChangeM {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case mcm := <-esaclient.MCMChan:
+			if mcm.ChangeType != nil && *mcm.ChangeType == exchangestream.ChangeType_Heartbeat {
+				continue
+			}
+			return mcm
+		case <-timeout:
+			t.Fatalf("timeout waiting for market change message")
+			return exchangestream.MarketChangeM{}
+		}
+	}
+}
+
+func market(id string, eventTypeID string) exchangestream.MarketChange {
+	image := true
+	def := exchangestream.MarketDefinition{EventTypeID: eventTypeID, Status: exchangestream.RaceStatus_Open,
+		BettingType: exchangestream.BettingType_Odds, PriceLadderDefinition: exchangestream.PriceLadderDefinition{Type: exchangestream.PriceLadderType_Classic}}
+	return exchangestream.MarketChange{ID: id, Image: &image, MarketDefinition: &def}
+}
+
+// publish publishes the market changes, failing the test on error
+func publish(t *testing.T, s *Server, mcs ...exchangestream.MarketChange) {
+	t.Helper()
+
+	if err := s.PublishMarketChanges(mcs...); err != nil {
+		t.Fatalf("error publishing - error: %s", err)
+	}
+}
+
+func runnerChange(runnerID uint, price float64, size float64) exchangestream.RunnerChange {
+	return exchangestream.RunnerChange{ID: runnerID,
+		ATB: []exchangestream.PriceSize{{Price: decimal.NewPrice(price), Size: decimal.NewMoney(size)}}}
+}
+
+func TestMarketSubscription(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	publish(t, s, market("1.1", "7"), market("1.2", "1"))
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.5, 10)}})
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	msm := exchangestream.MarketSubscriptionMessage{MarketFilter: exchangestream.MarketFilter{EventTypeIDs: []string{"7"}}}
+	if sm, err := esaclient.MarketSubscribe(msm); err != nil || sm.StatusCode != exchangestream.StatusCode_Success {
+		t.Fatalf("error subscribing - status: %+v, error: %v", sm, err)
+	}
+
+	mcm := waitMCM(t, esaclient)
+	if mcm.ChangeType == nil || *mcm.ChangeType != exchangestream.ChangeType_SubImage || mcm.InitialClk == "" {
+		t.Fatalf("got %+v, want an image with the initial clock", mcm)
+	}
+	if len(mcm.MarketChanges) != 1 || mcm.MarketChanges[0].ID != "1.1" || len(mcm.MarketChanges[0].RunnerChanges) != 1 {
+		t.Fatalf("got market changes %+v, want the image of market 1.1 only", mcm.MarketChanges)
+	}
+	if image := mcm.MarketChanges[0]; image.Image == nil || !*image.Image {
+		t.Errorf("market change not flagged as an image")
+	}
+
+	// The change to market 1.2 doesn't match the filter
+	publish(t, s, exchangestream.MarketChange{ID: "1.2", RunnerChanges: []exchangestream.RunnerChange{runnerChange(20, 3, 5)}})
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.5, 0)}})
+
+	mcm = waitMCM(t, esaclient)
+	if mcm.ChangeType != nil || len(mcm.MarketChanges) != 1 || mcm.MarketChanges[0].ID != "1.1" {
+		t.Fatalf("got %+v, want the delta of market 1.1", mcm)
+	}
+	if mcm.Clk != "4" {
+		t.Errorf("got clock %s, want 4", mcm.Clk)
+	}
+
+	// A new subscription gets the image without the price removed
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	mcm = waitMCM(t, esaclient)
+	if len(mcm.MarketChanges) != 2 || len(mcm.MarketChanges[0].RunnerChanges[0].ATB) != 0 {
+		t.Errorf("got market changes %+v, want both markets without prices on market 1.1", mcm.MarketChanges)
+	}
+}
+
+func TestResubscribe(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	publish(t, s, market("1.1", "7"))
+
+	connConfig := s.ConnectionConfig()
+	connConfig.Reconnect = true
+	esaclient := connect(t, s, connConfig, "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	waitMCM(t, esaclient)
+
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.5, 10)}})
+	waitMCM(t, esaclient)
+
+	// Changes published while the client is away are sent when it resubscribes
+	s.DropConnections()
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.6, 20)}})
+
+	mcm := waitMCM(t, esaclient)
+	if mcm.ChangeType == nil || *mcm.ChangeType != exchangestream.ChangeType_ResubDelta {
+		t.Fatalf("got %+v, want a resubscription delta", mcm)
+	}
+	if len(mcm.MarketChanges) != 1 || len(mcm.MarketChanges[0].RunnerChanges) != 1 ||
+		mcm.MarketChanges[0].RunnerChanges[0].ATB[0].Price != decimal.NewPrice(2.6) {
+		t.Errorf("got market changes %+v, want the change published while disconnected", mcm.MarketChanges)
+	}
+}
+
+func TestResubscribeInvalidClock(t *testing.T) {
+	s := newServer(t, Config{ClockHistory: 1})
+	defer s.Close()
+
+	publish(t, s, market("1.1", "7"))
+	publish(t, s, market("1.2", "7"))
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	sm, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{Clk: "0"})
+	if err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	if sm.StatusCode != exchangestream.StatusCode_Failure || sm.ErrorCode != exchangestream.ErrorCode_InvalidClock {
+		t.Errorf("got status %+v, want INVALID_CLOCK", sm)
+	}
+}
+
+func TestResubscribeFailures(t *testing.T) {
+	tests := map[string]struct {
+		failures int
+		want     exchangestream.ConnectionState
+	}{
+		"recovers":   {failures: 2, want: exchangestream.ConnectionState_Subscribed},
+		"gives up":   {failures: 10, want: exchangestream.ConnectionState_Disconnected},
+		"no failure": {failures: 0, want: exchangestream.ConnectionState_Subscribed},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newServer(t, Config{})
+			defer s.Close()
+
+			connConfig := s.ConnectionConfig()
+			connConfig.Reconnect = true
+			connConfig.Retries = 3
+			connConfig.MaximumBackoff = 0
+			esaclient := connect(t, s, connConfig, "session_token")
+			defer esaclient.Disconnect()
+
+			if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil {
+				t.Fatalf("error subscribing - error: %s", err)
+			}
+
+			// Resubscribing fails on every reconnection, each failure uses one of the retries
+			for i := 0; i < test.failures; i++ {
+				s.FailNext("marketSubscription", exchangestream.ErrorCode_InvalidClock, "clock too old", false)
+			}
+			s.DropConnections()
+
+			// Skip the events from before the connection was dropped
+			reconnecting := false
+			timeout := time.After(5 * time.Second)
+			for {
+				select {
+				case event := <-esaclient.EventChan:
+					reconnecting = reconnecting || event.To == exchangestream.ConnectionState_Reconnecting
+					if !reconnecting || (event.To != exchangestream.ConnectionState_Subscribed && event.To != exchangestream.ConnectionState_Disconnected) {
+						continue
+					}
+					if event.To != test.want {
+						t.Fatalf("got event %+v, want state %s", event, test.want)
+					}
+					if event.To == exchangestream.ConnectionState_Disconnected {
+						var statusErr exchangestream.StatusError
+						if !errors.As(event.Err, &statusErr) || statusErr.ErrorCode != exchangestream.ErrorCode_InvalidClock {
+							t.Errorf("got error %v, want the INVALID_CLOCK StatusError", event.Err)
+						}
+					}
+					return
+				case <-timeout:
+					t.Fatalf("timeout waiting for state %s, current state: %s", test.want, esaclient.State())
+				}
+			}
+		})
+	}
+}
+
+func TestSegmentation(t *testing.T) {
+	s := newServer(t, Config{SegmentSize: 2})
+	defer s.Close()
+
+	for i := 0; i < 5; i++ {
+		publish(t, s, market("1."+strconv.Itoa(i), "7"))
+	}
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{SegmentationEnabled: true}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	expected := []exchangestream.SegmentType{exchangestream.SegmentType_SegStart, exchangestream.SegmentType_Seg, exchangestream.SegmentType_SegEnd}
+	for _, st := range expected {
+		mcm := waitMCM(t, esaclient)
+		if mcm.SegmentType == nil || *mcm.SegmentType != st {
+			t.Fatalf("got segment type %v, want %s", mcm.SegmentType, st)
+		}
+	}
+
+	// Reassembled by the client
+	reassembling := exchangestream.NewESAClient("app_key", "session_token")
+	if err := reassembling.SetSegmentation(exchangestream.SegmentationConfig{Reassemble: true}); err != nil {
+		t.Fatalf("error setting segmentation - error: %s", err)
+	}
+	if err := reassembling.Connect(context.Background(), s.ConnectionConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer reassembling.Disconnect()
+
+	if _, err := reassembling.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+	if _, err := reassembling.MarketSubscribe(exchangestream.MarketSubscriptionMessage{SegmentationEnabled: true}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	if mcm := waitMCM(t, &reassembling); len(mcm.MarketChanges) != 5 {
+		t.Errorf("got %d market changes, want 5", len(mcm.MarketChanges))
+	}
+}
+
+func TestConflation(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	publish(t, s, market("1.1", "7"))
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{ConflateMs: 200}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	if mcm := waitMCM(t, esaclient); mcm.ConflateMs != 200 {
+		t.Errorf("got conflateMs %d on the image, want 200", mcm.ConflateMs)
+	}
+
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.5, 10)}})
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.6, 20)}})
+	publish(t, s, exchangestream.MarketChange{ID: "1.1", RunnerChanges: []exchangestream.RunnerChange{runnerChange(10, 2.5, 0)}})
+
+	mcm := waitMCM(t, esaclient)
+	if len(mcm.MarketChanges) != 1 || mcm.Clk != "4" {
+		t.Fatalf("got %+v, want the changes up to clock 4 in a single message", mcm)
+	}
+	mc := mcm.MarketChanges[0]
+	if mc.Conflated == nil || !*mc.Conflated || len(mc.RunnerChanges) != 1 || len(mc.RunnerChanges[0].ATB) != 2 {
+		t.Errorf("got market change %+v, want the conflated ladder", mc)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{HeartbeatMs: 500}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	waitMCM(t, esaclient)
+
+	select {
+	case mcm := <-esaclient.MCMChan:
+		if mcm.ChangeType == nil || *mcm.ChangeType != exchangestream.ChangeType_Heartbeat {
+			t.Errorf("got %+v, want a heartbeat", mcm)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for heartbeat")
+	}
+}
+
+func TestOrderSubscription(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	// Orders need all their enums set, otherwise they can't be sent
+	order := exchangestream.Order{ID: "1", Price: decimal.NewPrice(2.5), Size: decimal.NewMoney(10), SizeRemaining: decimal.NewMoney(10)}
+	err := s.PublishOrderChanges(exchangestream.OrderMarketChange{ID: "1.1", AccountID: 1,
+		OrderChanges: []exchangestream.OrderRunnerChange{{ID: 10, UnmatchedOrders: []exchangestream.Order{order}}}})
+	if err == nil {
+		t.Fatalf("expected error publishing an order without side")
+	}
+
+	order.Side, order.PersistenceType = exchangestream.OrderSide_Back, exchangestream.PersistenceType_Lapse
+	order.OrderType, order.Status = exchangestream.OrderType_Limit, exchangestream.OrderStatus_Executable
+	err = s.PublishOrderChanges(exchangestream.OrderMarketChange{ID: "1.1", AccountID: 1,
+		OrderChanges: []exchangestream.OrderRunnerChange{{ID: 10, UnmatchedOrders: []exchangestream.Order{order}}}})
+	if err != nil {
+		t.Fatalf("error publishing - error: %s", err)
+	}
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.OrderSubscribe(exchangestream.OrderSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	ocm := <-esaclient.OCMChan
+	if ocm.ChangeType == nil || *ocm.ChangeType != exchangestream.ChangeType_SubImage || len(ocm.OrderMarketChanges) != 1 {
+		t.Fatalf("got %+v, want the image of the orders", ocm)
+	}
+	if orc := ocm.OrderMarketChanges[0].OrderChanges[0]; orc.FullImage == nil || !*orc.FullImage || len(orc.UnmatchedOrders) != 1 {
+		t.Errorf("got runner change %+v, want a full image with the order", orc)
+	}
+
+	order.SizeMatched, order.SizeRemaining = decimal.NewMoney(10), decimal.Money(0)
+	order.Status = exchangestream.OrderStatus_ExecutableComplete
+	err = s.PublishOrderChanges(exchangestream.OrderMarketChange{ID: "1.1",
+		OrderChanges: []exchangestream.OrderRunnerChange{{ID: 10, UnmatchedOrders: []exchangestream.Order{order}}}})
+	if err != nil {
+		t.Fatalf("error publishing - error: %s", err)
+	}
+
+	select {
+	case ocm = <-esaclient.OCMChan:
+		if len(ocm.OrderMarketChanges) != 1 || ocm.OrderMarketChanges[0].OrderChanges[0].UnmatchedOrders[0].SizeMatched != decimal.NewMoney(10) {
+			t.Errorf("got %+v, want the order update", ocm)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for order change message")
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	s := newServer(t, Config{SessionTokens: []string{"session_token"}})
+	defer s.Close()
+
+	// Wrong session token
+	esaclient := exchangestream.NewESAClient("app_key", "wrong_token")
+	if err := esaclient.Connect(context.Background(), s.ConnectionConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	sm, err := esaclient.Authenticate()
+	if err != nil || sm.ErrorCode != exchangestream.ErrorCode_InvalidSessionInformation || sm.ConnectionClosed == nil || !*sm.ConnectionClosed {
+		t.Errorf("got (%+v, %v), want INVALID_SESSION_INFORMATION closing the connection", sm, err)
+	}
+	esaclient.Disconnect()
+
+	// Failure injected
+	s.FailNext("marketSubscription", exchangestream.ErrorCode_SubscriptionLimitExceeded, "too many markets", false)
+
+	client := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer client.Disconnect()
+
+	sm, err = client.MarketSubscribe(exchangestream.MarketSubscriptionMessage{})
+	if err != nil || sm.ErrorCode != exchangestream.ErrorCode_SubscriptionLimitExceeded {
+		t.Errorf("got (%+v, %v), want SUBSCRIPTION_LIMIT_EXCEEDED", sm, err)
+	}
+	if sm, err = client.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil || sm.StatusCode != exchangestream.StatusCode_Success {
+		t.Errorf("got (%+v, %v) after the failure, want success", sm, err)
+	}
+}
+
+func TestRevokeSessionToken(t *testing.T) {
+	s := newServer(t, Config{SessionTokens: []string{"old_token"}})
+	defer s.Close()
+
+	connConfig := s.ConnectionConfig()
+	connConfig.Reconnect = true
+
+	esaclient := exchangestream.NewESAClient("app_key", "old_token")
+	esaclient.SetTokenProvider(func(ctx context.Context) (string, error) {
+		return "new_token", nil
+	})
+	if err := esaclient.Connect(context.Background(), connConfig); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	// The client logs in again and carries on with the new token
+	s.AddSessionToken("new_token")
+	s.RevokeSessionToken("old_token")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, token, _, _ := esaclient.GetSessionInfo(); token == "new_token" && esaclient.State() == exchangestream.ConnectionState_Authenticated {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("client didn't authenticate with the new token, state: %s", esaclient.State())
+}
+
+func TestPublishRecorded(t *testing.T) {
+	s := newServer(t, Config{})
+	defer s.Close()
+
+	f, err := os.Open("../testdata/mcm_recorded.txt")
+	if err != nil {
+		t.Fatalf("error opening recording - error: %s", err)
+	}
+	defer f.Close()
+
+	if err := s.PublishRecorded(f); err != nil {
+		t.Fatalf("error publishing recording - error: %s", err)
+	}
+
+	esaclient := connect(t, s, s.ConnectionConfig(), "session_token")
+	defer esaclient.Disconnect()
+
+	if _, err := esaclient.MarketSubscribe(exchangestream.MarketSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	mcm := waitMCM(t, esaclient)
+	if mcm.Clk != "61" || len(mcm.MarketChanges) != 1 || mcm.MarketChanges[0].ID != "1.171234567" {
+		t.Fatalf("got %+v, want the image of the recorded market at clock 61", mcm)
+	}
+	for _, rc := range mcm.MarketChanges[0].RunnerChanges {
+		for _, ps := range rc.ATB {
+			if ps.Size.IsZero() {
+				t.Errorf("got price %s with size zero on runner %d", ps.Price, rc.ID)
+			}
+		}
+	}
+}
+
+func TestPlaintext(t *testing.T) {
+	s := newServer(t, Config{Plaintext: true})
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", s.Addr().String())
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	read := func() map[string]interface{} {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		if !scanner.Scan() {
+			t.Fatalf("error reading - error: %v", scanner.Err())
+		}
+		msg := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			t.Fatalf("error decoding %s - error: %s", scanner.Bytes(), err)
+		}
+		return msg
+	}
+
+	if msg := read(); msg["op"] != "connection" || msg["connectionId"] != "emulator-1" {
+		t.Fatalf("got %v, want the connection message", msg)
+	}
+
+	// Subscribing before authenticating closes the connection
+	conn.Write([]byte(`{"op":"marketSubscription","id":1,"marketFilter":{}}` + "\r\n"))
+	if msg := read(); msg["statusCode"] != "FAILURE" || msg["errorCode"] != "NOT_AUTHORIZED" || msg["connectionClosed"] != true {
+		t.Errorf("got %v, want NOT_AUTHORIZED closing the connection", msg)
+	}
+	if scanner.Scan() {
+		t.Errorf("got %s, want the connection closed", scanner.Bytes())
+	}
+}
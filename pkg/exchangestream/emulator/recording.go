@@ -0,0 +1,48 @@
+package emulator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// Largest message accepted from a recording
+const maxRecordedMessageSize = 64 * 1024 * 1024
+
+// PublishRecorded publishes the change messages read from r, one json message per line as sent by betfair.
+// Every message is published with its own clock, heartbeats and any other messages are skipped.
+func (s *Server) PublishRecorded(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxRecordedMessageSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var rm exchangestream.ResponseMessage
+		if err := json.Unmarshal(data, &rm); err != nil {
+			return fmt.Errorf("error decoding message on line %d: %w", line, err)
+		}
+
+		var err error
+		if rm.MarketChangeMessage != nil && len(rm.MarketChangeMessage.MarketChanges) > 0 {
+			err = s.PublishMarketChanges(rm.MarketChangeMessage.MarketChanges...)
+		} else if rm.OrderChangeMessage != nil && len(rm.OrderChangeMessage.OrderMarketChanges) > 0 {
+			err = s.PublishOrderChanges(rm.OrderChangeMessage.OrderMarketChanges...)
+		}
+		if err != nil {
+			return fmt.Errorf("error publishing message on line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
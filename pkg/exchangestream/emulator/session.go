@@ -0,0 +1,356 @@
+package emulator
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// How often subscriptions are checked for conflated changes to send and heartbeats due
+const tickInterval = 10 * time.Millisecond
+
+// How long writing a message to the connection can take before the connection is dropped
+const writeTimeout = 5 * time.Second
+
+// Largest request accepted from the client
+const maxRequestSize = 1024 * 1024
+
+// request has the fields of all the requests sent by the client
+type request struct {
+	Op           string  `json:"op"`
+	ID           *uint32 `json:"id"`
+	AppKey       string  `json:"appKey"`
+	SessionToken string  `json:"session"`
+}
+
+type connectionResponse struct {
+	Op string `json:"op"`
+	exchangestream.ConnectionMessage
+}
+
+type statusResponse struct {
+	Op string  `json:"op"`
+	ID *uint32 `json:"id,omitempty"`
+	exchangestream.StatusMessage
+}
+
+type marketResponse struct {
+	Op string  `json:"op"`
+	ID *uint32 `json:"id,omitempty"`
+	exchangestream.MarketChangeMessage
+}
+
+type orderResponse struct {
+	Op string  `json:"op"`
+	ID *uint32 `json:"id,omitempty"`
+	exchangestream.OrderChangeMessage
+}
+
+// subscription is a market or order subscription of a connection
+type subscription struct {
+	// ID of the subscription request, sent on every change message
+	id           *uint32
+	segmentation bool
+	heartbeat    time.Duration
+	conflate     time.Duration
+	filter       exchangestream.MarketFilter
+	// Clock of the latest change sent
+	clk uint64
+	// When the latest message was sent, heartbeats are sent after a period without messages
+	lastSent time.Time
+	// Changes waiting for the conflation period to end
+	pendingMarkets []exchangestream.MarketChange
+	pendingOrders  []exchangestream.OrderMarketChange
+	pendingClk     uint64
+	lastFlush      time.Time
+}
+
+func newSubscription(id *uint32, segmentation bool, heartbeatMs uint, conflateMs uint) *subscription {
+	if heartbeatMs == 0 {
+		heartbeatMs = defaultHeartbeatMs
+	} else if heartbeatMs < 500 {
+		heartbeatMs = 500
+	} else if heartbeatMs > 5000 {
+		heartbeatMs = 5000
+	}
+
+	now := time.Now()
+	return &subscription{
+		id:           id,
+		segmentation: segmentation,
+		heartbeat:    time.Duration(heartbeatMs) * time.Millisecond,
+		conflate:     time.Duration(conflateMs) * time.Millisecond,
+		lastSent:     now,
+		lastFlush:    now,
+	}
+}
+
+// session is a client connection
+type session struct {
+	server *Server
+	conn   net.Conn
+	id     string
+	// Messages waiting to be written, a nil message closes the connection once everything before it is written
+	queue chan []byte
+	// Closed when the connection is closed
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// Guarded by server.mu
+	authenticated bool
+	sessionToken  string
+	// The connection is going to be closed, nothing else is sent
+	closing bool
+	market  *subscription
+	order   *subscription
+}
+
+func newSession(server *Server, conn net.Conn, id string) *session {
+	return &session{
+		server: server,
+		conn:   conn,
+		id:     id,
+		queue:  make(chan []byte, server.config.QueueSize),
+		done:   make(chan struct{}),
+	}
+}
+
+// close closes the connection straight away
+func (sess *session) close() {
+	sess.closeOnce.Do(func() {
+		close(sess.done)
+		sess.conn.Close()
+	})
+}
+
+// send queues a message to be written (must hold server.mu)
+func (sess *session) send(msg interface{}) {
+	if sess.closing {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Log(globals.Logger, log.ERROR, "emulator failed marshalling message", log.Fields{"error": err.Error()})
+		return
+	}
+
+	select {
+	case sess.queue <- append(data, '\r', '\n'):
+	default:
+		log.Log(globals.Logger, log.ERROR, "emulator client not keeping up, dropping connection", log.Fields{"connectionID": sess.id})
+		sess.closing = true
+		sess.close()
+	}
+}
+
+// sendStatus sends a status message, closing the connection afterwards if the status says so (must hold server.mu)
+func (sess *session) sendStatus(id *uint32, sm exchangestream.StatusMessage) {
+	sm.ConnectionID = sess.id
+	sess.send(statusResponse{Op: "status", ID: id, StatusMessage: sm})
+
+	if sm.ConnectionClosed != nil && *sm.ConnectionClosed && !sess.closing {
+		sess.closing = true
+		select {
+		case sess.queue <- nil:
+		default:
+			sess.close()
+		}
+	}
+}
+
+// sendMarketChanges sends the changes, segmenting them if needed (must hold server.mu)
+func (sess *session) sendMarketChanges(sub *subscription, ct *exchangestream.ChangeType, clk uint64, initialClk string, mcs []exchangestream.MarketChange) {
+	now := time.Now()
+	chunks := segments(len(mcs), sess.server.config.SegmentSize, sub.segmentation)
+
+	for i, chunk := range chunks {
+		mcm := exchangestream.MarketChangeMessage{
+			ChangeType:    ct,
+			Clk:           strconv.FormatUint(clk, 10),
+			PublishTime:   exchangestream.EpochMillis{Time: now},
+			InitialClk:    initialClk,
+			MarketChanges: mcs[chunk[0]:chunk[1]],
+			SegmentType:   segmentType(i, len(chunks)),
+		}
+		if ct != nil && *ct != exchangestream.ChangeType_Heartbeat {
+			mcm.HeartbeatMs = uint(sub.heartbeat / time.Millisecond)
+			mcm.ConflateMs = uint(sub.conflate / time.Millisecond)
+		}
+		sess.send(marketResponse{Op: "mcm", ID: sub.id, MarketChangeMessage: mcm})
+	}
+
+	sub.clk = clk
+	sub.lastSent = now
+}
+
+// sendOrderChanges sends the changes, segmenting them if needed (must hold server.mu)
+func (sess *session) sendOrderChanges(sub *subscription, ct *exchangestream.ChangeType, clk uint64, initialClk string, ocs []exchangestream.OrderMarketChange) {
+	now := time.Now()
+	chunks := segments(len(ocs), sess.server.config.SegmentSize, sub.segmentation)
+
+	for i, chunk := range chunks {
+		ocm := exchangestream.OrderChangeMessage{
+			ChangeType:         ct,
+			Clk:                strconv.FormatUint(clk, 10),
+			PublishTime:        exchangestream.EpochMillis{Time: now},
+			InitialClk:         initialClk,
+			OrderMarketChanges: ocs[chunk[0]:chunk[1]],
+			SegmentType:        segmentType(i, len(chunks)),
+		}
+		if ct != nil && *ct != exchangestream.ChangeType_Heartbeat {
+			ocm.HeartbeatMs = uint(sub.heartbeat / time.Millisecond)
+			ocm.ConflateMs = uint(sub.conflate / time.Millisecond)
+		}
+		sess.send(orderResponse{Op: "ocm", ID: sub.id, OrderChangeMessage: ocm})
+	}
+
+	sub.clk = clk
+	sub.lastSent = now
+}
+
+// segments splits n changes into the ranges sent on each message, there is always at least one message
+func segments(n int, size int, enabled bool) [][2]int {
+	if !enabled || size <= 0 || n <= size {
+		return [][2]int{{0, n}}
+	}
+
+	var chunks [][2]int
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		chunks = append(chunks, [2]int{start, end})
+	}
+	return chunks
+}
+
+// segmentType returns the segment type of the i-th message out of n, nil when the changes fit in a single message
+func segmentType(i int, n int) *exchangestream.SegmentType {
+	if n == 1 {
+		return nil
+	}
+
+	st := exchangestream.SegmentType_Seg
+	if i == 0 {
+		st = exchangestream.SegmentType_SegStart
+	} else if i == n-1 {
+		st = exchangestream.SegmentType_SegEnd
+	}
+	return &st
+}
+
+// reader handles the requests sent by the client
+func (sess *session) reader(limitExceeded bool) {
+	defer sess.server.wg.Done()
+	defer sess.server.removeSession(sess)
+	defer sess.close()
+
+	sess.server.mu.Lock()
+	sess.send(connectionResponse{Op: "connection", ConnectionMessage: exchangestream.ConnectionMessage{ConnectionID: sess.id}})
+	if limitExceeded {
+		sess.sendStatus(nil, failure(exchangestream.ErrorCode_MaxConnectionLimitExceeded, "too many connections", true))
+	}
+	sess.server.mu.Unlock()
+
+	scanner := bufio.NewScanner(sess.conn)
+	scanner.Buffer(make([]byte, 4096), maxRequestSize)
+	for scanner.Scan() {
+		sess.handle(scanner.Bytes())
+	}
+
+	log.Log(globals.Logger, log.DEBUG, "emulator connection closed", log.Fields{"connectionID": sess.id})
+}
+
+// writer writes the queued messages to the connection and takes care of the subscriptions timers
+func (sess *session) writer() {
+	defer sess.server.wg.Done()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-sess.done:
+			return
+		case data := <-sess.queue:
+			if data == nil {
+				sess.close()
+				return
+			}
+
+			sess.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := sess.conn.Write(data); err != nil {
+				sess.close()
+				return
+			}
+		case now := <-ticker.C:
+			sess.server.tick(sess, now)
+		}
+	}
+}
+
+// handle answers a request
+func (sess *session) handle(data []byte) {
+	s := sess.server
+
+	var req request
+	err := json.Unmarshal(data, &req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sess.closing {
+		return
+	} else if err != nil {
+		sess.sendStatus(nil, failure(exchangestream.ErrorCode_InvalidInput, "invalid request: "+err.Error(), true))
+		return
+	}
+
+	if sm := s.nextFailure(req.Op); sm != nil {
+		sess.sendStatus(req.ID, *sm)
+		return
+	}
+
+	if req.Op != "authentication" && !sess.authenticated {
+		sess.sendStatus(req.ID, failure(exchangestream.ErrorCode_NotAuthorized, "not authenticated", true))
+		return
+	}
+
+	switch req.Op {
+	case "authentication":
+		if sm := s.authenticate(req.AppKey, req.SessionToken); sm != nil {
+			sess.sendStatus(req.ID, *sm)
+			return
+		}
+		sess.authenticated = true
+		sess.sessionToken = req.SessionToken
+		sess.sendStatus(req.ID, success())
+	case "heartbeat":
+		sess.sendStatus(req.ID, success())
+	case "marketSubscription":
+		var msm exchangestream.MarketSubscriptionMessage
+		if err := json.Unmarshal(data, &msm); err != nil {
+			sess.sendStatus(req.ID, failure(exchangestream.ErrorCode_InvalidInput, "invalid market subscription: "+err.Error(), true))
+			return
+		}
+		s.subscribeMarkets(sess, req.ID, msm)
+	case "orderSubscription":
+		var osm exchangestream.OrderSubscriptionMessage
+		if err := json.Unmarshal(data, &osm); err != nil {
+			sess.sendStatus(req.ID, failure(exchangestream.ErrorCode_InvalidInput, "invalid order subscription: "+err.Error(), true))
+			return
+		}
+		s.subscribeOrders(sess, req.ID, osm)
+	default:
+		sess.sendStatus(req.ID, failure(exchangestream.ErrorCode_InvalidRequest, "unknown operation: "+req.Op, true))
+	}
+}
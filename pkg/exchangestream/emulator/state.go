@@ -0,0 +1,349 @@
+package emulator
+
+import (
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/internal/streammerge"
+)
+
+type marketEntry struct {
+	clk uint64
+	mc  exchangestream.MarketChange
+}
+
+// marketState keeps the image of every market and the latest changes, to resume subscriptions from a clock
+type marketState struct {
+	images map[string]exchangestream.MarketChange
+	// Market IDs in the order they were first published, so images are always sent in the same order
+	ids     []string
+	history []marketEntry
+	// Subscriptions can resume from this clock onwards, older changes were dropped from history
+	historyStart uint64
+	historySize  int
+}
+
+func newMarketState(historySize int) *marketState {
+	return &marketState{images: make(map[string]exchangestream.MarketChange), historySize: historySize}
+}
+
+func (ms *marketState) apply(clk uint64, mcs []exchangestream.MarketChange) {
+	for _, mc := range mcs {
+		image, ok := ms.images[mc.ID]
+		if !ok {
+			ms.ids = append(ms.ids, mc.ID)
+			image = mc
+		} else {
+			image = mergeMarketChange(image, mc)
+		}
+		ms.images[mc.ID] = compactMarketChange(image)
+		ms.history = append(ms.history, marketEntry{clk: clk, mc: mc})
+	}
+
+	if drop := len(ms.history) - ms.historySize; drop > 0 {
+		ms.historyStart = ms.history[drop-1].clk
+		ms.history = append([]marketEntry{}, ms.history[drop:]...)
+	}
+}
+
+func (ms *marketState) canResume(clk uint64) bool {
+	return clk >= ms.historyStart
+}
+
+// image returns the images of the markets matching the filter
+func (ms *marketState) image(filter exchangestream.MarketFilter) []exchangestream.MarketChange {
+	var mcs []exchangestream.MarketChange
+	for _, id := range ms.ids {
+		if ms.matches(filter, id) {
+			mcs = append(mcs, ms.images[id])
+		}
+	}
+	return mcs
+}
+
+// since returns the changes published after the clock to the markets matching the filter, merged by market
+func (ms *marketState) since(clk uint64, filter exchangestream.MarketFilter) []exchangestream.MarketChange {
+	var mcs []exchangestream.MarketChange
+	for _, entry := range ms.history {
+		if entry.clk > clk && ms.matches(filter, entry.mc.ID) {
+			mcs = mergeMarketChanges(mcs, []exchangestream.MarketChange{entry.mc})
+		}
+	}
+	return mcs
+}
+
+// matches checks the market against the filter, using its latest market definition
+func (ms *marketState) matches(filter exchangestream.MarketFilter, id string) bool {
+	if len(filter.MarketIDs) > 0 && !contains(filter.MarketIDs, id) {
+		return false
+	}
+
+	def := ms.images[id].MarketDefinition
+	if def == nil {
+		// Nothing else can be checked
+		return len(filter.EventTypeIDs) == 0 && len(filter.EventIDs) == 0 && len(filter.CountryCodes) == 0 &&
+			len(filter.MarketTypes) == 0 && len(filter.Venues) == 0 && len(filter.RaceTypes) == 0 &&
+			len(filter.BettingTypes) == 0 && filter.BSPMarket == nil && filter.TurnInPlayEnabled == nil
+	}
+
+	if len(filter.EventTypeIDs) > 0 && !contains(filter.EventTypeIDs, def.EventTypeID) {
+		return false
+	}
+	if len(filter.EventIDs) > 0 && !contains(filter.EventIDs, def.EventID) {
+		return false
+	}
+	if len(filter.CountryCodes) > 0 && !contains(filter.CountryCodes, def.CountryCode) {
+		return false
+	}
+	if len(filter.MarketTypes) > 0 && !contains(filter.MarketTypes, def.MarketType) {
+		return false
+	}
+	if len(filter.Venues) > 0 && !contains(filter.Venues, def.Venue) {
+		return false
+	}
+	if len(filter.RaceTypes) > 0 && !contains(filter.RaceTypes, def.RaceType) {
+		return false
+	}
+	if len(filter.BettingTypes) > 0 {
+		found := false
+		for _, bt := range filter.BettingTypes {
+			found = found || bt == def.BettingType
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.BSPMarket != nil && (def.BSPMarket == nil || *def.BSPMarket != *filter.BSPMarket) {
+		return false
+	}
+	if filter.TurnInPlayEnabled != nil && (def.TurnInPlayEnabled == nil || *def.TurnInPlayEnabled != *filter.TurnInPlayEnabled) {
+		return false
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeMarketChanges merges the newer changes into the older ones, by market
+func mergeMarketChanges(older []exchangestream.MarketChange, newer []exchangestream.MarketChange) []exchangestream.MarketChange {
+	result := append([]exchangestream.MarketChange{}, older...)
+	for _, mc := range newer {
+		merged := false
+		for i := range result {
+			if result[i].ID == mc.ID {
+				result[i] = mergeMarketChange(result[i], mc)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			result = append(result, mc)
+		}
+	}
+	return result
+}
+
+// mergeMarketChange applies the newer change on top of the older one, the result is marked as conflated.
+// Runners are merged as the client merges them while coalescing, ladder entries with size zero are kept, as they
+// remove the price from the client's view.
+func mergeMarketChange(older exchangestream.MarketChange, newer exchangestream.MarketChange) exchangestream.MarketChange {
+	if newer.Image != nil && *newer.Image {
+		return newer
+	}
+
+	result := older
+	conflated := true
+	result.Conflated = &conflated
+	if newer.TotalVolume != nil {
+		result.TotalVolume = newer.TotalVolume
+	}
+	if newer.MarketDefinition != nil {
+		result.MarketDefinition = newer.MarketDefinition
+	}
+	result.RunnerChanges = streammerge.MergeRunnerChanges(older.RunnerChanges, newer.RunnerChanges)
+
+	return result
+}
+
+// compactMarketChange turns a merged change into an image, dropping the ladder entries with size zero
+func compactMarketChange(mc exchangestream.MarketChange) exchangestream.MarketChange {
+	image := true
+	mc.Image = &image
+	mc.Conflated = nil
+	mc.RunnerChanges = streammerge.CompactRunnerChanges(mc.RunnerChanges)
+
+	return mc
+}
+
+type orderEntry struct {
+	clk uint64
+	oc  exchangestream.OrderMarketChange
+}
+
+// orderState keeps the image of the orders of every market and the latest changes, to resume subscriptions from a clock
+type orderState struct {
+	images map[string]exchangestream.OrderMarketChange
+	// Market IDs in the order they were first published, so images are always sent in the same order
+	ids          []string
+	history      []orderEntry
+	historyStart uint64
+	historySize  int
+}
+
+func newOrderState(historySize int) *orderState {
+	return &orderState{images: make(map[string]exchangestream.OrderMarketChange), historySize: historySize}
+}
+
+func (ors *orderState) apply(clk uint64, ocs []exchangestream.OrderMarketChange) {
+	for _, oc := range ocs {
+		image, ok := ors.images[oc.ID]
+		if !ok {
+			ors.ids = append(ors.ids, oc.ID)
+			image = oc
+		} else {
+			image = mergeOrderMarketChange(image, oc)
+		}
+		ors.images[oc.ID] = image
+		ors.history = append(ors.history, orderEntry{clk: clk, oc: oc})
+	}
+
+	if drop := len(ors.history) - ors.historySize; drop > 0 {
+		ors.historyStart = ors.history[drop-1].clk
+		ors.history = append([]orderEntry{}, ors.history[drop:]...)
+	}
+}
+
+func (ors *orderState) canResume(clk uint64) bool {
+	return clk >= ors.historyStart
+}
+
+// image returns the images of the orders of all the markets
+func (ors *orderState) image() []exchangestream.OrderMarketChange {
+	var ocs []exchangestream.OrderMarketChange
+	for _, id := range ors.ids {
+		oc := ors.images[id]
+		if oc.Closed != nil && *oc.Closed {
+			continue
+		}
+		ocs = append(ocs, fullImage(oc))
+	}
+	return ocs
+}
+
+// since returns the changes published after the clock, merged by market
+func (ors *orderState) since(clk uint64) []exchangestream.OrderMarketChange {
+	var ocs []exchangestream.OrderMarketChange
+	for _, entry := range ors.history {
+		if entry.clk > clk {
+			ocs = mergeOrderChanges(ocs, []exchangestream.OrderMarketChange{entry.oc})
+		}
+	}
+	return ocs
+}
+
+// fullImage marks every runner of the market as a full image
+func fullImage(oc exchangestream.OrderMarketChange) exchangestream.OrderMarketChange {
+	full := true
+	runners := make([]exchangestream.OrderRunnerChange, len(oc.OrderChanges))
+	for i, orc := range oc.OrderChanges {
+		orc.FullImage = &full
+		orc.MatchedBacks = streammerge.CompactPriceSizes(orc.MatchedBacks)
+		orc.MatchedLays = streammerge.CompactPriceSizes(orc.MatchedLays)
+		runners[i] = orc
+	}
+	oc.OrderChanges = runners
+	return oc
+}
+
+// mergeOrderChanges merges the newer changes into the older ones, by market
+func mergeOrderChanges(older []exchangestream.OrderMarketChange, newer []exchangestream.OrderMarketChange) []exchangestream.OrderMarketChange {
+	result := append([]exchangestream.OrderMarketChange{}, older...)
+	for _, oc := range newer {
+		merged := false
+		for i := range result {
+			if result[i].ID == oc.ID {
+				result[i] = mergeOrderMarketChange(result[i], oc)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			result = append(result, oc)
+		}
+	}
+	return result
+}
+
+// mergeOrderMarketChange applies the newer change on top of the older one
+func mergeOrderMarketChange(older exchangestream.OrderMarketChange, newer exchangestream.OrderMarketChange) exchangestream.OrderMarketChange {
+	result := older
+	if newer.AccountID != 0 {
+		result.AccountID = newer.AccountID
+	}
+	if newer.Closed != nil {
+		result.Closed = newer.Closed
+	}
+
+	result.OrderChanges = append([]exchangestream.OrderRunnerChange{}, older.OrderChanges...)
+	for _, orc := range newer.OrderChanges {
+		merged := false
+		for i := range result.OrderChanges {
+			if result.OrderChanges[i].ID == orc.ID && result.OrderChanges[i].Handicap == orc.Handicap {
+				result.OrderChanges[i] = mergeOrderRunnerChange(result.OrderChanges[i], orc)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			result.OrderChanges = append(result.OrderChanges, orc)
+		}
+	}
+
+	return result
+}
+
+// mergeOrderRunnerChange applies the newer change on top of the older one, orders are replaced by ID
+func mergeOrderRunnerChange(older exchangestream.OrderRunnerChange, newer exchangestream.OrderRunnerChange) exchangestream.OrderRunnerChange {
+	if newer.FullImage != nil && *newer.FullImage {
+		return newer
+	}
+
+	result := older
+	result.MatchedBacks = streammerge.MergePriceSizes(older.MatchedBacks, newer.MatchedBacks)
+	result.MatchedLays = streammerge.MergePriceSizes(older.MatchedLays, newer.MatchedLays)
+
+	result.UnmatchedOrders = append([]exchangestream.Order{}, older.UnmatchedOrders...)
+	for _, order := range newer.UnmatchedOrders {
+		replaced := false
+		for i := range result.UnmatchedOrders {
+			if result.UnmatchedOrders[i].ID == order.ID {
+				result.UnmatchedOrders[i] = order
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			result.UnmatchedOrders = append(result.UnmatchedOrders, order)
+		}
+	}
+
+	if len(newer.StrategyMatches) > 0 {
+		result.StrategyMatches = make(map[string]exchangestream.StrategyMatchChange, len(older.StrategyMatches))
+		for ref, smc := range older.StrategyMatches {
+			result.StrategyMatches[ref] = smc
+		}
+		for ref, smc := range newer.StrategyMatches {
+			current := result.StrategyMatches[ref]
+			current.MatchedBacks = streammerge.MergePriceSizes(current.MatchedBacks, smc.MatchedBacks)
+			current.MatchedLays = streammerge.MergePriceSizes(current.MatchedLays, smc.MatchedLays)
+			result.StrategyMatches[ref] = current
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,175 @@
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// PublishMarketChanges applies the changes to the markets and sends them to the market subscriptions matching
+// each market. Changes with img set replace the market, the first change of a market is taken as its image.
+// All the changes share the same clock.
+// Returns an error if the changes can't be sent (e.g. a market definition missing some of its enums).
+func (s *Server) PublishMarketChanges(mcs ...exchangestream.MarketChange) error {
+	if _, err := json.Marshal(mcs); err != nil {
+		return fmt.Errorf("invalid market changes: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clk++
+	s.markets.apply(s.clk, mcs)
+
+	for sess := range s.sessions {
+		sub := sess.market
+		if sub == nil {
+			continue
+		}
+
+		var matching []exchangestream.MarketChange
+		for _, mc := range mcs {
+			if s.markets.matches(sub.filter, mc.ID) {
+				matching = append(matching, mc)
+			}
+		}
+		if len(matching) == 0 {
+			continue
+		}
+
+		if sub.conflate > 0 {
+			sub.pendingMarkets = mergeMarketChanges(sub.pendingMarkets, matching)
+			sub.pendingClk = s.clk
+			continue
+		}
+		sess.sendMarketChanges(sub, nil, s.clk, "", matching)
+	}
+
+	return nil
+}
+
+// PublishOrderChanges applies the changes to the orders and sends them to the order subscriptions.
+// All the changes share the same clock.
+// Returns an error if the changes can't be sent (e.g. an order missing some of its enums).
+func (s *Server) PublishOrderChanges(ocs ...exchangestream.OrderMarketChange) error {
+	if _, err := json.Marshal(ocs); err != nil {
+		return fmt.Errorf("invalid order changes: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.clk++
+	s.orders.apply(s.clk, ocs)
+
+	for sess := range s.sessions {
+		sub := sess.order
+		if sub == nil {
+			continue
+		}
+
+		if sub.conflate > 0 {
+			sub.pendingOrders = mergeOrderChanges(sub.pendingOrders, ocs)
+			sub.pendingClk = s.clk
+			continue
+		}
+		sess.sendOrderChanges(sub, nil, s.clk, "", ocs)
+	}
+
+	return nil
+}
+
+// subscribeMarkets replaces the market subscription of the connection, sending the image of the markets
+// or, when resubscribing from a clock, the changes since then (must hold mu)
+func (s *Server) subscribeMarkets(sess *session, id *uint32, msm exchangestream.MarketSubscriptionMessage) {
+	sub := newSubscription(id, msm.SegmentationEnabled, msm.HeartbeatMs, msm.ConflateMs)
+	sub.filter = msm.MarketFilter
+
+	ct := exchangestream.ChangeType_SubImage
+	initialClk := strconv.FormatUint(s.clk, 10)
+	var mcs []exchangestream.MarketChange
+
+	if msm.Clk != "" {
+		clk, err := strconv.ParseUint(msm.Clk, 10, 64)
+		if err != nil || clk > s.clk || !s.markets.canResume(clk) {
+			sess.sendStatus(id, failure(exchangestream.ErrorCode_InvalidClock, "can't resume from clock "+msm.Clk, true))
+			return
+		}
+		ct = exchangestream.ChangeType_ResubDelta
+		initialClk = ""
+		mcs = s.markets.since(clk, sub.filter)
+	} else {
+		mcs = s.markets.image(sub.filter)
+	}
+
+	sess.market = sub
+	sess.sendStatus(id, success())
+	sess.sendMarketChanges(sub, &ct, s.clk, initialClk, mcs)
+}
+
+// subscribeOrders replaces the order subscription of the connection, sending the image of the orders
+// or, when resubscribing from a clock, the changes since then (must hold mu)
+func (s *Server) subscribeOrders(sess *session, id *uint32, osm exchangestream.OrderSubscriptionMessage) {
+	sub := newSubscription(id, osm.SegmentationEnabled, osm.HeartbeatMs, osm.ConflateMs)
+
+	ct := exchangestream.ChangeType_SubImage
+	initialClk := strconv.FormatUint(s.clk, 10)
+	var ocs []exchangestream.OrderMarketChange
+
+	if osm.Clk != "" {
+		clk, err := strconv.ParseUint(osm.Clk, 10, 64)
+		if err != nil || clk > s.clk || !s.orders.canResume(clk) {
+			sess.sendStatus(id, failure(exchangestream.ErrorCode_InvalidClock, "can't resume from clock "+osm.Clk, true))
+			return
+		}
+		ct = exchangestream.ChangeType_ResubDelta
+		initialClk = ""
+		ocs = s.orders.since(clk)
+	} else {
+		ocs = s.orders.image()
+	}
+
+	sess.order = sub
+	sess.sendStatus(id, success())
+	sess.sendOrderChanges(sub, &ct, s.clk, initialClk, ocs)
+}
+
+// tick sends the conflated changes and the heartbeats that are due
+func (s *Server) tick(sess *session, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sub := sess.market; sub != nil {
+		if len(sub.pendingMarkets) > 0 && now.Sub(sub.lastFlush) >= sub.conflate {
+			sess.sendMarketChanges(sub, nil, sub.pendingClk, "", sub.pendingMarkets)
+			sub.pendingMarkets = nil
+			sub.lastFlush = now
+		}
+		if now.Sub(sub.lastSent) >= sub.heartbeat {
+			if len(sub.pendingMarkets) == 0 {
+				// Nothing left to send up to now
+				sub.clk = s.clk
+			}
+			ct := exchangestream.ChangeType_Heartbeat
+			sess.sendMarketChanges(sub, &ct, sub.clk, "", nil)
+		}
+	}
+
+	if sub := sess.order; sub != nil {
+		if len(sub.pendingOrders) > 0 && now.Sub(sub.lastFlush) >= sub.conflate {
+			sess.sendOrderChanges(sub, nil, sub.pendingClk, "", sub.pendingOrders)
+			sub.pendingOrders = nil
+			sub.lastFlush = now
+		}
+		if now.Sub(sub.lastSent) >= sub.heartbeat {
+			if len(sub.pendingOrders) == 0 {
+				sub.clk = s.clk
+			}
+			ct := exchangestream.ChangeType_Heartbeat
+			sess.sendOrderChanges(sub, &ct, sub.clk, "", nil)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package exchangestream
 
+import (
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/internal/streammerge"
+)
+
 type ConnectionMessage struct {
 	ConnectionID string `json:"connectionId"`
 }
@@ -64,7 +71,7 @@ type MarketChangeMessage struct {
 	ChangeType    *ChangeType    `json:"ct,omitempty"`
 	Clk           string         `json:"clk"`
 	HeartbeatMs   uint           `json:"heartbeatMs"`
-	PublishTime   uint           `json:"pt"`
+	PublishTime   EpochMillis    `json:"pt"`
 	InitialClk    string         `json:"initialClk"`
 	MarketChanges []MarketChange `json:"mc,omitempty"`
 	ConflateMs    uint           `json:"conflateMs"`
@@ -75,7 +82,7 @@ type MarketChangeMessage struct {
 type MarketChange struct {
 	RunnerChanges    []RunnerChange    `json:"rc,omitempty"`
 	Image            *bool             `json:"img,omitempty"`
-	TotalVolume      *float64          `json:"tv"`
+	TotalVolume      *decimal.Money    `json:"tv"`
 	Conflated        *bool             `json:"con,omitempty"`
 	MarketDefinition *MarketDefinition `json:"marketDefinition,omitempty"`
 	ID               string            `json:"id"`
@@ -84,7 +91,7 @@ type MarketChange struct {
 type MarketDefinition struct {
 	Venue                 string                `json:"venue"`
 	RaceType              string                `json:"raceType"`
-	SettledTime           string                `json:"settledTime"` // TODO: time.Time!
+	SettledTime           *time.Time            `json:"settledTime,omitempty"`
 	Timezone              string                `json:"timezone"`
 	EachWayDivisor        float64               `json:"eachWayDivisor"`
 	Regulators            []string              `json:"regulators"`
@@ -105,28 +112,28 @@ type MarketDefinition struct {
 	TurnInPlayEnabled     *bool                 `json:"turnInPlayEnabled,omitempty"`
 	PriceLadderDefinition PriceLadderDefinition `json:"priceLadderDefinition"`
 	KeyLineDefinition     KeyLineDefinition     `json:"keyLineDefinition"`
-	SuspendTime           string                `json:"suspendTime"` // TODO: time.Time!
+	SuspendTime           *time.Time            `json:"suspendTime,omitempty"`
 	DiscountAllowed       *bool                 `json:"discountAllowed,omitempty"`
 	PersistenceEnabled    *bool                 `json:"persistenceEnabled,omitempty"`
 	Runners               []RunnerDefinition    `json:"runners"`
 	Version               uint                  `json:"version"`
 	EventTypeID           string                `json:"eventTypeId"`
 	Complete              *bool                 `json:"complete,omitempty"`
-	OpenDate              string                `json:"openDate"`   // TODO: time.Time!
-	MarketTime            string                `json:"marketTime"` // TODO: time.Time!
+	OpenDate              time.Time             `json:"openDate"`
+	MarketTime            time.Time             `json:"marketTime"`
 	BSPReconciled         *bool                 `json:"bspReconciled,omitempty"`
 	LineInterval          float64               `json:"lineInterval"`
 	Status                RaceStatus            `json:"status"`
 }
 
 type RunnerDefinition struct {
-	SortPriority     uint         `json:"sortPriority"`
-	RemovalDate      string       `json:"removalDate"` // TODO: time.Time!
-	ID               uint         `json:"id"`
-	Handicap         *float64     `json:"hc,omitempty"`
-	AdjustmentFactor float64      `json:"adjustmentFactor"`
-	BSP              float64      `json:"bsp"`
-	Status           RunnerStatus `json:"status"`
+	SortPriority     uint           `json:"sortPriority"`
+	RemovalDate      *time.Time     `json:"removalDate,omitempty"`
+	ID               uint           `json:"id"`
+	Handicap         *float64       `json:"hc,omitempty"`
+	AdjustmentFactor float64        `json:"adjustmentFactor"`
+	BSP              *decimal.Price `json:"bsp,omitempty"`
+	Status           RunnerStatus   `json:"status"`
 }
 
 type PriceLadderDefinition struct {
@@ -142,29 +149,14 @@ type KeyLineSelection struct {
 	Handicap float64 `json:"hc"`
 }
 
-type RunnerChange struct {
-	TotalVolume *float64    `json:"tv,omitempty"`
-	BATB        [][]float64 `json:"batb,omitempty"`
-	SPB         [][]float64 `json:"spb,omitempty"`
-	BDATL       [][]float64 `json:"bdatl,omitempty"`
-	TRD         [][]float64 `json:"trd,omitempty"`
-	SPF         *float64    `json:"spf,omitempty"`
-	LTP         *float64    `json:"ltp,omitempty"`
-	ATB         [][]float64 `json:"atb,omitempty"`
-	SPL         [][]float64 `json:"spl,omitempty"`
-	SPN         *float64    `json:"spn,omitempty"`
-	ATL         [][]float64 `json:"atl,omitempty"`
-	BATL        [][]float64 `json:"batl,omitempty"`
-	ID          uint        `json:"id,omitempty"`
-	Handicap    *float64    `json:"hc,omitempty"`
-	BDATB       [][]float64 `json:"bdatb,omitempty"`
-}
+// RunnerChange is the change to a runner of a market change message
+type RunnerChange = streammerge.RunnerChange
 
 type OrderChangeMessage struct {
 	ChangeType         *ChangeType         `json:"ct,omitempty"`
 	Clk                string              `json:"clk"`
 	HeartbeatMs        uint                `json:"heartbeatMs"`
-	PublishTime        uint                `json:"pt"`
+	PublishTime        EpochMillis         `json:"pt"`
 	OrderMarketChanges []OrderMarketChange `json:"oc,omitempty"`
 	InitialClk         string              `json:"initialClk"`
 	ConflateMs         uint                `json:"conflateMs"`
@@ -180,40 +172,46 @@ type OrderMarketChange struct {
 }
 
 type OrderRunnerChange struct {
-	MatchedBacks    [][]float64                    `json:"mb,omitempty"`
+	MatchedBacks    []PriceSize                    `json:"mb,omitempty"`
 	StrategyMatches map[string]StrategyMatchChange `json:"smc,omitempty"`
 	UnmatchedOrders []Order                        `json:"uo,omitempty"`
 	ID              uint                           `json:"id"`
 	Handicap        float64                        `json:"hc,omitempty"`
 	FullImage       *bool                          `json:"fullImage,omitempty"`
-	MatchedLays     [][]float64                    `json:"ml,omitempty"`
+	MatchedLays     []PriceSize                    `json:"ml,omitempty"`
 }
 
 type Order struct {
 	Side                OrderSide       `json:"side"`
-	SizeVoided          float64         `json:"sv"`
+	SizeVoided          decimal.Money   `json:"sv"`
 	PersistenceType     PersistenceType `json:"pt"`
 	OrderType           OrderType       `json:"ot"`
-	Price               float64         `json:"p"`
-	SizeCancelled       float64         `json:"sc"`
+	Price               decimal.Price   `json:"p"`
+	SizeCancelled       decimal.Money   `json:"sc"`
 	RegulatorCode       string          `json:"rc"`
-	Size                float64         `json:"s"`
-	PlacedDate          uint            `json:"pd"` //TODO: time.Time!
+	Size                decimal.Money   `json:"s"`
+	PlacedDate          EpochMillis     `json:"pd"`
 	RegulatorAuthCode   string          `json:"rac"`
-	MatchedDate         *uint           `json:"md,omitempty"` //TODO: time.Time!
-	LapsedDate          *uint           `json:"ld,omitempty"` //TODO: time.Time!
-	SizeLapsed          float64         `json:"sl"`
-	AveragePriceMatched *float64        `json:"avp,omitempty"`
-	SizeMatched         float64         `json:"sm"`
+	MatchedDate         *EpochMillis    `json:"md,omitempty"`
+	LapsedDate          *EpochMillis    `json:"ld,omitempty"`
+	SizeLapsed          decimal.Money   `json:"sl"`
+	AveragePriceMatched *decimal.Price  `json:"avp,omitempty"`
+	SizeMatched         decimal.Money   `json:"sm"`
 	OrderReference      *string         `json:"rfo,omitempty"`
 	ID                  string          `json:"id"`
-	BSP                 *float64        `json:"bsp,omitempty"`
+	BSP                 *decimal.Money  `json:"bsp,omitempty"`
 	StrategyReference   *string         `json:"rfs,omitempty"`
 	Status              OrderStatus     `json:"status"`
-	SizeRemaining       float64         `json:"sr"`
+	SizeRemaining       decimal.Money   `json:"sr"`
 }
 
 type StrategyMatchChange struct {
-	MatchedBacks [][]float64 `json:"mb,omitempty"`
-	MatchedLays  [][]float64 `json:"ml,omitempty"`
+	MatchedBacks []PriceSize `json:"mb,omitempty"`
+	MatchedLays  []PriceSize `json:"ml,omitempty"`
 }
+
+// PriceSize is a price-keyed ladder entry, sent by betfair as a [price, size] json array
+type PriceSize = streammerge.PriceSize
+
+// LevelPriceSize is a level-based ladder entry, sent by betfair as a [level, price, size] json array
+type LevelPriceSize = streammerge.LevelPriceSize
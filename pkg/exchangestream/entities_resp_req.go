@@ -2,7 +2,6 @@ package exchangestream
 
 import (
 	"encoding/json"
-	"errors"
 )
 
 type RequestMessage struct {
@@ -93,44 +92,5 @@ type ResponseMessage struct {
 
 // UnmarshalJSON unmarshals ResponseMessage struct
 func (rm *ResponseMessage) UnmarshalJSON(data []byte) error {
-	temp := struct {
-		Op string  `json:"op"`
-		ID *uint32 `json:"id,omitempty"`
-	}{}
-	if err := json.Unmarshal(data, &temp); err != nil {
-		return err
-	}
-
-	rm.Op = temp.Op
-	rm.ID = temp.ID
-
-	if rm.Op == "connection" {
-		var connectionMessage ConnectionMessage
-		if err := json.Unmarshal(data, &connectionMessage); err != nil {
-			return err
-		}
-		rm.ConnectionMessage = &connectionMessage
-	} else if rm.Op == "status" {
-		var statusMessage StatusMessage
-		if err := json.Unmarshal(data, &statusMessage); err != nil {
-			return err
-		}
-		rm.StatusMessage = &statusMessage
-	} else if rm.Op == "mcm" {
-		var marketChangeMessage MarketChangeMessage
-		if err := json.Unmarshal(data, &marketChangeMessage); err != nil {
-			return err
-		}
-		rm.MarketChangeMessage = &marketChangeMessage
-	} else if rm.Op == "ocm" {
-		var orderChangeMessage OrderChangeMessage
-		if err := json.Unmarshal(data, &orderChangeMessage); err != nil {
-			return err
-		}
-		rm.OrderChangeMessage = &orderChangeMessage
-	} else {
-		return errors.New("Invalid object value")
-	}
-
-	return nil
+	return rm.decode(data)
 }
@@ -3,6 +3,9 @@ package exchangestream
 import (
 	"encoding/json"
 	"testing"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
 )
 
 func TestConnectionMessage(t *testing.T) {
@@ -19,6 +22,88 @@ func TestConnectionMessage(t *testing.T) {
 	}
 }
 
+func TestMarketChangeLadders(t *testing.T) {
+	msg := `{"op":"mcm","id":2,"clk":"AAAAAAAA","pt":1594999999999,"mc":[{"id":"1.170000000","tv":1234.56,` +
+		`"rc":[{"id":123,"ltp":1.01,"tv":0.3,"atb":[[1.01,2.1],[1.02,0]],"batl":[[0,3.45,10.01]],"spn":"NaN","spf":"Infinity"}]}]}`
+
+	msgStruct := ResponseMessage{}
+	err := json.Unmarshal([]byte(msg), &msgStruct)
+	if err != nil {
+		t.Fatalf("error while decoding message - error: %s", err)
+	}
+
+	mc := msgStruct.MarketChangeMessage.MarketChanges[0]
+	if *mc.TotalVolume != decimal.NewMoneyFromCents(123456) {
+		t.Errorf("market total volume not properly decoded, got: %s", mc.TotalVolume)
+	}
+
+	rc := mc.RunnerChanges[0]
+	if *rc.LTP != decimal.NewPrice(1.01) || *rc.TotalVolume != decimal.NewMoneyFromCents(30) {
+		t.Errorf("runner change not properly decoded, got: %+v", rc)
+	}
+
+	if len(rc.ATB) != 2 || rc.ATB[0] != (PriceSize{Price: decimal.NewPrice(1.01), Size: decimal.NewMoneyFromCents(210)}) || !rc.ATB[1].Size.IsZero() {
+		t.Errorf("atb ladder not properly decoded, got: %+v", rc.ATB)
+	}
+
+	if len(rc.BATL) != 1 || rc.BATL[0] != (LevelPriceSize{Level: 0, Price: decimal.NewPrice(3.45), Size: decimal.NewMoneyFromCents(1001)}) {
+		t.Errorf("batl ladder not properly decoded, got: %+v", rc.BATL)
+	}
+
+	if !rc.SPN.IsNaN() || !rc.SPF.IsInf() {
+		t.Errorf("starting prices not properly decoded, got: spn %s, spf %s", rc.SPN, rc.SPF)
+	}
+
+	bytes, err := json.Marshal(rc.ATB)
+	if err != nil {
+		t.Fatalf("error while encoding ladder - error: %s", err)
+	}
+
+	if string(bytes) != `[[1.01,2.1],[1.02,0]]` {
+		t.Errorf("atb ladder not properly encoded, got: %s", bytes)
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	msg := `{"op":"mcm","id":2,"clk":"AAAAAAAA","pt":1594999999999,"mc":[{"id":"1.170000000","marketDefinition":` +
+		`{"marketTime":"2020-07-17T15:30:00.000Z","openDate":"2020-07-17T15:30:00.000Z","suspendTime":"2020-07-17T15:30:00.000Z",` +
+		`"runners":[{"id":123,"status":"ACTIVE"},{"id":124,"status":"REMOVED","removalDate":"2020-07-17T10:00:00.000Z"}]}}]}`
+
+	msgStruct := ResponseMessage{}
+	err := json.Unmarshal([]byte(msg), &msgStruct)
+	if err != nil {
+		t.Fatalf("error while decoding message - error: %s", err)
+	}
+
+	mcm := msgStruct.MarketChangeMessage
+	if !mcm.PublishTime.Equal(time.Date(2020, 7, 17, 15, 33, 19, 999000000, time.UTC)) {
+		t.Errorf("publish time not properly decoded, got: %s", mcm.PublishTime)
+	}
+
+	md := mcm.MarketChanges[0].MarketDefinition
+	marketTime := time.Date(2020, 7, 17, 15, 30, 0, 0, time.UTC)
+	if !md.MarketTime.Equal(marketTime) || md.SuspendTime == nil || !md.SuspendTime.Equal(marketTime) || md.SettledTime != nil {
+		t.Errorf("market definition times not properly decoded, got: %+v", md)
+	}
+
+	if md.Runners[0].RemovalDate != nil || md.Runners[1].RemovalDate == nil {
+		t.Errorf("runner removal dates not properly decoded, got: %+v", md.Runners)
+	}
+
+	if ttf := md.TimeToOff(marketTime.Add(-time.Minute)); ttf != time.Minute {
+		t.Errorf("got time to off %s, want %s", ttf, time.Minute)
+	}
+
+	if latency := mcm.Latency(mcm.PublishTime.Add(50 * time.Millisecond)); latency != 50*time.Millisecond {
+		t.Errorf("got latency %s, want %s", latency, 50*time.Millisecond)
+	}
+
+	bytes, err := json.Marshal(mcm.PublishTime)
+	if err != nil || string(bytes) != "1594999999999" {
+		t.Errorf("publish time not properly encoded, got: %s (error: %v)", bytes, err)
+	}
+}
+
 // // Construct Request
 // authMsg := exchangestream.AuthenticationMessage{AppKey: "app_key", SessionToken: "session_token"}
 // reqMsg := exchangestream.RequestMessage{Op: "authentication", ID: 100, AuthenticationMessage: &authMsg}
@@ -4,13 +4,86 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"sync"
+	"sync/atomic"
 )
 
+// tolerantEnums sets on/off the tolerant mode when unmarshalling enums (0 - False | 1 - True)
+var tolerantEnums uint32
+
+// SetTolerantEnums turns on/off the tolerant mode when unmarshalling enums.
+// By default, unmarshalling an unknown enum value fails.
+// In tolerant mode, unknown values are decoded into an "Unknown" enum value (IsUnknown returns true)
+// that preserves the raw string, which is returned by String() and used when marshalling it back.
+// Up to maxUnknownEnums raw strings are kept per enum type, the unknown values received after that are all decoded
+// into the same value, whose String() is empty and which can't be marshalled.
+func SetTolerantEnums(tolerant bool) {
+	if tolerant {
+		atomic.StoreUint32(&tolerantEnums, 1)
+	} else {
+		atomic.StoreUint32(&tolerantEnums, 0)
+	}
+}
+
+// maxUnknownEnums is the maximum number of unknown raw strings kept per enum type
+const maxUnknownEnums = 64
+
+// unknownEnumOverflow is the value given to the unknown raw strings received once the registry is full
+const unknownEnumOverflow = -(maxUnknownEnums + 1)
+
+// unknownEnumsRegistry maps the unknown raw strings of an enum type to negative enum values and back.
+// It's thread safe!
+type unknownEnumsRegistry struct {
+	mu       sync.RWMutex
+	toValue  map[string]int
+	toString map[int]string
+}
+
+func newUnknownEnumsRegistry() *unknownEnumsRegistry {
+	return &unknownEnumsRegistry{toValue: map[string]int{}, toString: map[int]string{}}
+}
+
+// register returns the enum value assigned to the raw string, assigning a new one if needed.
+// Returns unknownEnumOverflow when the registry is full.
+func (uer *unknownEnumsRegistry) register(raw string) int {
+	uer.mu.Lock()
+	defer uer.mu.Unlock()
+
+	value, ok := uer.toValue[raw]
+	if !ok {
+		if len(uer.toValue) >= maxUnknownEnums {
+			return unknownEnumOverflow
+		}
+
+		value = -(len(uer.toValue) + 1)
+		uer.toValue[raw] = value
+		uer.toString[value] = raw
+	}
+
+	return value
+}
+
+// lookup returns the raw string assigned to an unknown enum value.
+// The raw string of unknownEnumOverflow is empty.
+func (uer *unknownEnumsRegistry) lookup(value int) (string, bool) {
+	if value == unknownEnumOverflow {
+		return "", true
+	}
+
+	uer.mu.RLock()
+	defer uer.mu.RUnlock()
+
+	raw, ok := uer.toString[value]
+	return raw, ok
+}
+
 // ErrorCode ENUM
 
 type ErrorCode int
 
+// errorCodeUnknown keeps track of the ErrorCode values received that are not known by this library
+var errorCodeUnknown = newUnknownEnumsRegistry()
+
 const (
 	ErrorCode_NoAppKey ErrorCode = iota + 1
 	ErrorCode_InvalidAppKey
@@ -28,7 +101,12 @@ const (
 )
 
 func (ec ErrorCode) String() string {
-	return errorCodeToString[ec]
+	if elem, ok := errorCodeToString[ec]; ok {
+		return elem
+	}
+
+	raw, _ := errorCodeUnknown.lookup(int(ec))
+	return raw
 }
 
 var errorCodeToString = map[ErrorCode]string{
@@ -63,14 +141,73 @@ var errorCodeToEnum = map[string]ErrorCode{
 	"MAX_CONNECTION_LIMIT_EXCEEDED": ErrorCode_MaxConnectionLimitExceeded,
 }
 
+// ErrorCodeValues returns all the known values of the ErrorCode enum
+func ErrorCodeValues() []ErrorCode {
+	return []ErrorCode{
+		ErrorCode_NoAppKey,
+		ErrorCode_InvalidAppKey,
+		ErrorCode_NoSession,
+		ErrorCode_InvalidSessionInformation,
+		ErrorCode_NotAuthorized,
+		ErrorCode_InvalidInput,
+		ErrorCode_InvalidClock,
+		ErrorCode_UnexpectedError,
+		ErrorCode_Timeout,
+		ErrorCode_SubscriptionLimitExceeded,
+		ErrorCode_InvalidRequest,
+		ErrorCode_ConnectionFailed,
+		ErrorCode_MaxConnectionLimitExceeded,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ec ErrorCode) IsValid() bool {
+	_, ok := errorCodeToString[ec]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ec ErrorCode) IsUnknown() bool {
+	_, ok := errorCodeUnknown.lookup(int(ec))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ec ErrorCode) MarshalText() ([]byte, error) {
+	elem, ok := errorCodeToString[ec]
+	if !ok {
+		elem, ok = errorCodeUnknown.lookup(int(ec))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal ErrorCode enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ec *ErrorCode) UnmarshalText(data []byte) error {
+	result, ok := errorCodeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching ErrorCode enum value")
+		}
+		result = ErrorCode(errorCodeUnknown.register(string(data)))
+	}
+
+	*ec = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ec ErrorCode) MarshalJSON() ([]byte, error) {
-	elem, ok := errorCodeToString[ec]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ec.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal ErrorCode enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -81,26 +218,28 @@ func (ec *ErrorCode) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := errorCodeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching ErrorCode enum value")
-	}
-
-	*ec = result
-	return nil
+	return ec.UnmarshalText([]byte(j))
 }
 
 // StatusCode ENUM
 
 type StatusCode int
 
+// statusCodeUnknown keeps track of the StatusCode values received that are not known by this library
+var statusCodeUnknown = newUnknownEnumsRegistry()
+
 const (
 	StatusCode_Success StatusCode = iota + 1
 	StatusCode_Failure
 )
 
 func (sc StatusCode) String() string {
-	return statusCodeToString[sc]
+	if elem, ok := statusCodeToString[sc]; ok {
+		return elem
+	}
+
+	raw, _ := statusCodeUnknown.lookup(int(sc))
+	return raw
 }
 
 var statusCodeToString = map[StatusCode]string{
@@ -113,14 +252,62 @@ var statusCodeToEnum = map[string]StatusCode{
 	"FAILURE": StatusCode_Failure,
 }
 
+// StatusCodeValues returns all the known values of the StatusCode enum
+func StatusCodeValues() []StatusCode {
+	return []StatusCode{
+		StatusCode_Success,
+		StatusCode_Failure,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (sc StatusCode) IsValid() bool {
+	_, ok := statusCodeToString[sc]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (sc StatusCode) IsUnknown() bool {
+	_, ok := statusCodeUnknown.lookup(int(sc))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (sc StatusCode) MarshalText() ([]byte, error) {
+	elem, ok := statusCodeToString[sc]
+	if !ok {
+		elem, ok = statusCodeUnknown.lookup(int(sc))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal StatusCode enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (sc *StatusCode) UnmarshalText(data []byte) error {
+	result, ok := statusCodeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching StatusCode enum value")
+		}
+		result = StatusCode(statusCodeUnknown.register(string(data)))
+	}
+
+	*sc = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (sc StatusCode) MarshalJSON() ([]byte, error) {
-	elem, ok := statusCodeToString[sc]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := sc.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal StatusCode enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -131,19 +318,16 @@ func (sc *StatusCode) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := statusCodeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching StatusCode enum value")
-	}
-
-	*sc = result
-	return nil
+	return sc.UnmarshalText([]byte(j))
 }
 
 // PriceData ENUM
 
 type PriceData int
 
+// priceDataUnknown keeps track of the PriceData values received that are not known by this library
+var priceDataUnknown = newUnknownEnumsRegistry()
+
 const (
 	PriceData_ExBestOffersDisp PriceData = iota + 1
 	PriceData_ExBestOffers
@@ -157,7 +341,12 @@ const (
 )
 
 func (pd PriceData) String() string {
-	return priceDataToString[pd]
+	if elem, ok := priceDataToString[pd]; ok {
+		return elem
+	}
+
+	raw, _ := priceDataUnknown.lookup(int(pd))
+	return raw
 }
 
 var priceDataToString = map[PriceData]string{
@@ -184,14 +373,69 @@ var priceDataToEnum = map[string]PriceData{
 	"SP_PROJECTED":        PriceData_SPProjected,
 }
 
+// PriceDataValues returns all the known values of the PriceData enum
+func PriceDataValues() []PriceData {
+	return []PriceData{
+		PriceData_ExBestOffersDisp,
+		PriceData_ExBestOffers,
+		PriceData_ExAllOffers,
+		PriceData_ExTraded,
+		PriceData_ExTradedVol,
+		PriceData_ExLTP,
+		PriceData_ExMarketDef,
+		PriceData_SPTraded,
+		PriceData_SPProjected,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (pd PriceData) IsValid() bool {
+	_, ok := priceDataToString[pd]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (pd PriceData) IsUnknown() bool {
+	_, ok := priceDataUnknown.lookup(int(pd))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (pd PriceData) MarshalText() ([]byte, error) {
+	elem, ok := priceDataToString[pd]
+	if !ok {
+		elem, ok = priceDataUnknown.lookup(int(pd))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PriceData enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (pd *PriceData) UnmarshalText(data []byte) error {
+	result, ok := priceDataToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PriceData enum value")
+		}
+		result = PriceData(priceDataUnknown.register(string(data)))
+	}
+
+	*pd = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (pd PriceData) MarshalJSON() ([]byte, error) {
-	elem, ok := priceDataToString[pd]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := pd.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PriceData enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -202,19 +446,16 @@ func (pd *PriceData) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := priceDataToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PriceData enum value")
-	}
-
-	*pd = result
-	return nil
+	return pd.UnmarshalText([]byte(j))
 }
 
 // BettingType ENUM
 
 type BettingType int
 
+// bettingTypeUnknown keeps track of the BettingType values received that are not known by this library
+var bettingTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	BettingType_Odds BettingType = iota + 1
 	BettingType_Line
@@ -224,7 +465,12 @@ const (
 )
 
 func (bt BettingType) String() string {
-	return bettingTypeToString[bt]
+	if elem, ok := bettingTypeToString[bt]; ok {
+		return elem
+	}
+
+	raw, _ := bettingTypeUnknown.lookup(int(bt))
+	return raw
 }
 
 var bettingTypeToString = map[BettingType]string{
@@ -243,14 +489,65 @@ var bettingTypeToEnum = map[string]BettingType{
 	"ASIAN_HANDICAP_SINGLE_LINE": BettingType_AsianHandicapSingleLine,
 }
 
+// BettingTypeValues returns all the known values of the BettingType enum
+func BettingTypeValues() []BettingType {
+	return []BettingType{
+		BettingType_Odds,
+		BettingType_Line,
+		BettingType_Range,
+		BettingType_AsianHandicapDoubleLine,
+		BettingType_AsianHandicapSingleLine,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (bt BettingType) IsValid() bool {
+	_, ok := bettingTypeToString[bt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (bt BettingType) IsUnknown() bool {
+	_, ok := bettingTypeUnknown.lookup(int(bt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (bt BettingType) MarshalText() ([]byte, error) {
+	elem, ok := bettingTypeToString[bt]
+	if !ok {
+		elem, ok = bettingTypeUnknown.lookup(int(bt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal BettingType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (bt *BettingType) UnmarshalText(data []byte) error {
+	result, ok := bettingTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching BettingType enum value")
+		}
+		result = BettingType(bettingTypeUnknown.register(string(data)))
+	}
+
+	*bt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (bt BettingType) MarshalJSON() ([]byte, error) {
-	elem, ok := bettingTypeToString[bt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := bt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal BettingType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -261,19 +558,16 @@ func (bt *BettingType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := bettingTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching BettingType enum value")
-	}
-
-	*bt = result
-	return nil
+	return bt.UnmarshalText([]byte(j))
 }
 
 // ChangeType ENUM
 
 type ChangeType int
 
+// changeTypeUnknown keeps track of the ChangeType values received that are not known by this library
+var changeTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	ChangeType_SubImage ChangeType = iota + 1
 	ChangeType_ResubDelta
@@ -281,7 +575,12 @@ const (
 )
 
 func (ct ChangeType) String() string {
-	return changeTypeToString[ct]
+	if elem, ok := changeTypeToString[ct]; ok {
+		return elem
+	}
+
+	raw, _ := changeTypeUnknown.lookup(int(ct))
+	return raw
 }
 
 var changeTypeToString = map[ChangeType]string{
@@ -296,14 +595,63 @@ var changeTypeToEnum = map[string]ChangeType{
 	"HEARTBEAT":   ChangeType_Heartbeat,
 }
 
+// ChangeTypeValues returns all the known values of the ChangeType enum
+func ChangeTypeValues() []ChangeType {
+	return []ChangeType{
+		ChangeType_SubImage,
+		ChangeType_ResubDelta,
+		ChangeType_Heartbeat,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ct ChangeType) IsValid() bool {
+	_, ok := changeTypeToString[ct]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ct ChangeType) IsUnknown() bool {
+	_, ok := changeTypeUnknown.lookup(int(ct))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ct ChangeType) MarshalText() ([]byte, error) {
+	elem, ok := changeTypeToString[ct]
+	if !ok {
+		elem, ok = changeTypeUnknown.lookup(int(ct))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal ChangeType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ct *ChangeType) UnmarshalText(data []byte) error {
+	result, ok := changeTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching ChangeType enum value")
+		}
+		result = ChangeType(changeTypeUnknown.register(string(data)))
+	}
+
+	*ct = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ct ChangeType) MarshalJSON() ([]byte, error) {
-	elem, ok := changeTypeToString[ct]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ct.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal ChangeType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -314,19 +662,16 @@ func (ct *ChangeType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := changeTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching ChangeType enum value")
-	}
-
-	*ct = result
-	return nil
+	return ct.UnmarshalText([]byte(j))
 }
 
 // SegmentType ENUM
 
 type SegmentType int
 
+// segmentTypeUnknown keeps track of the SegmentType values received that are not known by this library
+var segmentTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	SegmentType_SegStart SegmentType = iota + 1
 	SegmentType_Seg
@@ -334,7 +679,12 @@ const (
 )
 
 func (st SegmentType) String() string {
-	return segmentTypeToString[st]
+	if elem, ok := segmentTypeToString[st]; ok {
+		return elem
+	}
+
+	raw, _ := segmentTypeUnknown.lookup(int(st))
+	return raw
 }
 
 var segmentTypeToString = map[SegmentType]string{
@@ -349,14 +699,63 @@ var segmentTypeToEnum = map[string]SegmentType{
 	"SEG_END":   SegmentType_SegEnd,
 }
 
+// SegmentTypeValues returns all the known values of the SegmentType enum
+func SegmentTypeValues() []SegmentType {
+	return []SegmentType{
+		SegmentType_SegStart,
+		SegmentType_Seg,
+		SegmentType_SegEnd,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (st SegmentType) IsValid() bool {
+	_, ok := segmentTypeToString[st]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (st SegmentType) IsUnknown() bool {
+	_, ok := segmentTypeUnknown.lookup(int(st))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (st SegmentType) MarshalText() ([]byte, error) {
+	elem, ok := segmentTypeToString[st]
+	if !ok {
+		elem, ok = segmentTypeUnknown.lookup(int(st))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal SegmentType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (st *SegmentType) UnmarshalText(data []byte) error {
+	result, ok := segmentTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching SegmentType enum value")
+		}
+		result = SegmentType(segmentTypeUnknown.register(string(data)))
+	}
+
+	*st = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (st SegmentType) MarshalJSON() ([]byte, error) {
-	elem, ok := segmentTypeToString[st]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := st.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal SegmentType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -367,19 +766,16 @@ func (st *SegmentType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := segmentTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching SegmentType enum value")
-	}
-
-	*st = result
-	return nil
+	return st.UnmarshalText([]byte(j))
 }
 
 // RaceStatus ENUM
 
 type RaceStatus int
 
+// raceStatusUnknown keeps track of the RaceStatus values received that are not known by this library
+var raceStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	RaceStatus_Inactive RaceStatus = iota + 1
 	RaceStatus_Open
@@ -388,7 +784,12 @@ const (
 )
 
 func (rs RaceStatus) String() string {
-	return raceStatusToString[rs]
+	if elem, ok := raceStatusToString[rs]; ok {
+		return elem
+	}
+
+	raw, _ := raceStatusUnknown.lookup(int(rs))
+	return raw
 }
 
 var raceStatusToString = map[RaceStatus]string{
@@ -405,14 +806,64 @@ var raceStatusToEnum = map[string]RaceStatus{
 	"CLOSED":    RaceStatus_Closed,
 }
 
+// RaceStatusValues returns all the known values of the RaceStatus enum
+func RaceStatusValues() []RaceStatus {
+	return []RaceStatus{
+		RaceStatus_Inactive,
+		RaceStatus_Open,
+		RaceStatus_Suspended,
+		RaceStatus_Closed,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (rs RaceStatus) IsValid() bool {
+	_, ok := raceStatusToString[rs]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (rs RaceStatus) IsUnknown() bool {
+	_, ok := raceStatusUnknown.lookup(int(rs))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (rs RaceStatus) MarshalText() ([]byte, error) {
+	elem, ok := raceStatusToString[rs]
+	if !ok {
+		elem, ok = raceStatusUnknown.lookup(int(rs))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal RaceStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (rs *RaceStatus) UnmarshalText(data []byte) error {
+	result, ok := raceStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching RaceStatus enum value")
+		}
+		result = RaceStatus(raceStatusUnknown.register(string(data)))
+	}
+
+	*rs = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (rs RaceStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := raceStatusToString[rs]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := rs.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal RaceStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -423,19 +874,16 @@ func (rs *RaceStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := raceStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching RaceStatus enum value")
-	}
-
-	*rs = result
-	return nil
+	return rs.UnmarshalText([]byte(j))
 }
 
 // PriceLadderType ENUM
 
 type PriceLadderType int
 
+// priceLadderTypeUnknown keeps track of the PriceLadderType values received that are not known by this library
+var priceLadderTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	PriceLadderType_Classic PriceLadderType = iota + 1
 	PriceLadderType_Finest
@@ -443,7 +891,12 @@ const (
 )
 
 func (plt PriceLadderType) String() string {
-	return priceLadderTypeToString[plt]
+	if elem, ok := priceLadderTypeToString[plt]; ok {
+		return elem
+	}
+
+	raw, _ := priceLadderTypeUnknown.lookup(int(plt))
+	return raw
 }
 
 var priceLadderTypeToString = map[PriceLadderType]string{
@@ -458,14 +911,63 @@ var priceLadderTypeToEnum = map[string]PriceLadderType{
 	"LINE_RANGE": PriceLadderType_LineRange,
 }
 
+// PriceLadderTypeValues returns all the known values of the PriceLadderType enum
+func PriceLadderTypeValues() []PriceLadderType {
+	return []PriceLadderType{
+		PriceLadderType_Classic,
+		PriceLadderType_Finest,
+		PriceLadderType_LineRange,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (plt PriceLadderType) IsValid() bool {
+	_, ok := priceLadderTypeToString[plt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (plt PriceLadderType) IsUnknown() bool {
+	_, ok := priceLadderTypeUnknown.lookup(int(plt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (plt PriceLadderType) MarshalText() ([]byte, error) {
+	elem, ok := priceLadderTypeToString[plt]
+	if !ok {
+		elem, ok = priceLadderTypeUnknown.lookup(int(plt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PriceLadderType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (plt *PriceLadderType) UnmarshalText(data []byte) error {
+	result, ok := priceLadderTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PriceLadderType enum value")
+		}
+		result = PriceLadderType(priceLadderTypeUnknown.register(string(data)))
+	}
+
+	*plt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (plt PriceLadderType) MarshalJSON() ([]byte, error) {
-	elem, ok := priceLadderTypeToString[plt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := plt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PriceLadderType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -476,19 +978,16 @@ func (plt *PriceLadderType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := priceLadderTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PriceLadderType enum value")
-	}
-
-	*plt = result
-	return nil
+	return plt.UnmarshalText([]byte(j))
 }
 
 // RunnerStatus ENUM
 
 type RunnerStatus int
 
+// runnerStatusUnknown keeps track of the RunnerStatus values received that are not known by this library
+var runnerStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	RunnerStatus_Active RunnerStatus = iota + 1
 	RunnerStatus_Winner
@@ -500,7 +999,12 @@ const (
 )
 
 func (rs RunnerStatus) String() string {
-	return runnerStatusToString[rs]
+	if elem, ok := runnerStatusToString[rs]; ok {
+		return elem
+	}
+
+	raw, _ := runnerStatusUnknown.lookup(int(rs))
+	return raw
 }
 
 var runnerStatusToString = map[RunnerStatus]string{
@@ -523,14 +1027,67 @@ var runnerStatusToEnum = map[string]RunnerStatus{
 	"PLACED":         RunnerStatus_Placed,
 }
 
+// RunnerStatusValues returns all the known values of the RunnerStatus enum
+func RunnerStatusValues() []RunnerStatus {
+	return []RunnerStatus{
+		RunnerStatus_Active,
+		RunnerStatus_Winner,
+		RunnerStatus_Loser,
+		RunnerStatus_Removed,
+		RunnerStatus_RemovedVacant,
+		RunnerStatus_Hidden,
+		RunnerStatus_Placed,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (rs RunnerStatus) IsValid() bool {
+	_, ok := runnerStatusToString[rs]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (rs RunnerStatus) IsUnknown() bool {
+	_, ok := runnerStatusUnknown.lookup(int(rs))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (rs RunnerStatus) MarshalText() ([]byte, error) {
+	elem, ok := runnerStatusToString[rs]
+	if !ok {
+		elem, ok = runnerStatusUnknown.lookup(int(rs))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal RunnerStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (rs *RunnerStatus) UnmarshalText(data []byte) error {
+	result, ok := runnerStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching RunnerStatus enum value")
+		}
+		result = RunnerStatus(runnerStatusUnknown.register(string(data)))
+	}
+
+	*rs = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (rs RunnerStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := runnerStatusToString[rs]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := rs.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal RunnerStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -541,26 +1098,28 @@ func (rs *RunnerStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := runnerStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching RunnerStatus enum value")
-	}
-
-	*rs = result
-	return nil
+	return rs.UnmarshalText([]byte(j))
 }
 
 // OrderSide ENUM
 
 type OrderSide int
 
+// orderSideUnknown keeps track of the OrderSide values received that are not known by this library
+var orderSideUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderSide_Back OrderSide = iota + 1
 	OrderSide_Lay
 )
 
 func (os OrderSide) String() string {
-	return orderSideToString[os]
+	if elem, ok := orderSideToString[os]; ok {
+		return elem
+	}
+
+	raw, _ := orderSideUnknown.lookup(int(os))
+	return raw
 }
 
 var orderSideToString = map[OrderSide]string{
@@ -573,14 +1132,62 @@ var orderSideToEnum = map[string]OrderSide{
 	"L": OrderSide_Lay,
 }
 
+// OrderSideValues returns all the known values of the OrderSide enum
+func OrderSideValues() []OrderSide {
+	return []OrderSide{
+		OrderSide_Back,
+		OrderSide_Lay,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (os OrderSide) IsValid() bool {
+	_, ok := orderSideToString[os]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (os OrderSide) IsUnknown() bool {
+	_, ok := orderSideUnknown.lookup(int(os))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (os OrderSide) MarshalText() ([]byte, error) {
+	elem, ok := orderSideToString[os]
+	if !ok {
+		elem, ok = orderSideUnknown.lookup(int(os))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderSide enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (os *OrderSide) UnmarshalText(data []byte) error {
+	result, ok := orderSideToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderSide enum value")
+		}
+		result = OrderSide(orderSideUnknown.register(string(data)))
+	}
+
+	*os = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (os OrderSide) MarshalJSON() ([]byte, error) {
-	elem, ok := orderSideToString[os]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := os.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderSide enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -591,19 +1198,16 @@ func (os *OrderSide) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderSideToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderSide enum value")
-	}
-
-	*os = result
-	return nil
+	return os.UnmarshalText([]byte(j))
 }
 
 // PersistenceType ENUM
 
 type PersistenceType int
 
+// persistenceTypeUnknown keeps track of the PersistenceType values received that are not known by this library
+var persistenceTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	PersistenceType_Lapse PersistenceType = iota + 1
 	PersistenceType_Persist
@@ -611,7 +1215,12 @@ const (
 )
 
 func (pt PersistenceType) String() string {
-	return persistenceTypeToString[pt]
+	if elem, ok := persistenceTypeToString[pt]; ok {
+		return elem
+	}
+
+	raw, _ := persistenceTypeUnknown.lookup(int(pt))
+	return raw
 }
 
 var persistenceTypeToString = map[PersistenceType]string{
@@ -626,14 +1235,63 @@ var persistenceTypeToEnum = map[string]PersistenceType{
 	"MOC": PersistenceType_MarketOnClose,
 }
 
+// PersistenceTypeValues returns all the known values of the PersistenceType enum
+func PersistenceTypeValues() []PersistenceType {
+	return []PersistenceType{
+		PersistenceType_Lapse,
+		PersistenceType_Persist,
+		PersistenceType_MarketOnClose,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (pt PersistenceType) IsValid() bool {
+	_, ok := persistenceTypeToString[pt]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (pt PersistenceType) IsUnknown() bool {
+	_, ok := persistenceTypeUnknown.lookup(int(pt))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (pt PersistenceType) MarshalText() ([]byte, error) {
+	elem, ok := persistenceTypeToString[pt]
+	if !ok {
+		elem, ok = persistenceTypeUnknown.lookup(int(pt))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal PersistenceType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (pt *PersistenceType) UnmarshalText(data []byte) error {
+	result, ok := persistenceTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching PersistenceType enum value")
+		}
+		result = PersistenceType(persistenceTypeUnknown.register(string(data)))
+	}
+
+	*pt = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (pt PersistenceType) MarshalJSON() ([]byte, error) {
-	elem, ok := persistenceTypeToString[pt]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := pt.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal PersistenceType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -644,19 +1302,16 @@ func (pt *PersistenceType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := persistenceTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching PersistenceType enum value")
-	}
-
-	*pt = result
-	return nil
+	return pt.UnmarshalText([]byte(j))
 }
 
 // OrderType ENUM
 
 type OrderType int
 
+// orderTypeUnknown keeps track of the OrderType values received that are not known by this library
+var orderTypeUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderType_Limit OrderType = iota + 1
 	OrderType_MarketOnClose
@@ -664,7 +1319,12 @@ const (
 )
 
 func (ot OrderType) String() string {
-	return orderTypeToString[ot]
+	if elem, ok := orderTypeToString[ot]; ok {
+		return elem
+	}
+
+	raw, _ := orderTypeUnknown.lookup(int(ot))
+	return raw
 }
 
 var orderTypeToString = map[OrderType]string{
@@ -679,14 +1339,63 @@ var orderTypeToEnum = map[string]OrderType{
 	"MOC": OrderType_LimitOnClose,
 }
 
+// OrderTypeValues returns all the known values of the OrderType enum
+func OrderTypeValues() []OrderType {
+	return []OrderType{
+		OrderType_Limit,
+		OrderType_MarketOnClose,
+		OrderType_LimitOnClose,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (ot OrderType) IsValid() bool {
+	_, ok := orderTypeToString[ot]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (ot OrderType) IsUnknown() bool {
+	_, ok := orderTypeUnknown.lookup(int(ot))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (ot OrderType) MarshalText() ([]byte, error) {
+	elem, ok := orderTypeToString[ot]
+	if !ok {
+		elem, ok = orderTypeUnknown.lookup(int(ot))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderType enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (ot *OrderType) UnmarshalText(data []byte) error {
+	result, ok := orderTypeToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderType enum value")
+		}
+		result = OrderType(orderTypeUnknown.register(string(data)))
+	}
+
+	*ot = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (ot OrderType) MarshalJSON() ([]byte, error) {
-	elem, ok := orderTypeToString[ot]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := ot.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderType enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -697,26 +1406,28 @@ func (ot *OrderType) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderTypeToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderType enum value")
-	}
-
-	*ot = result
-	return nil
+	return ot.UnmarshalText([]byte(j))
 }
 
 // OrderStatus ENUM
 
 type OrderStatus int
 
+// orderStatusUnknown keeps track of the OrderStatus values received that are not known by this library
+var orderStatusUnknown = newUnknownEnumsRegistry()
+
 const (
 	OrderStatus_Executable OrderStatus = iota + 1
 	OrderStatus_ExecutableComplete
 )
 
 func (os OrderStatus) String() string {
-	return orderStatusToString[os]
+	if elem, ok := orderStatusToString[os]; ok {
+		return elem
+	}
+
+	raw, _ := orderStatusUnknown.lookup(int(os))
+	return raw
 }
 
 var orderStatusToString = map[OrderStatus]string{
@@ -729,14 +1440,62 @@ var orderStatusToEnum = map[string]OrderStatus{
 	"EC": OrderStatus_ExecutableComplete,
 }
 
+// OrderStatusValues returns all the known values of the OrderStatus enum
+func OrderStatusValues() []OrderStatus {
+	return []OrderStatus{
+		OrderStatus_Executable,
+		OrderStatus_ExecutableComplete,
+	}
+}
+
+// IsValid reports whether the enum holds one of the known values
+func (os OrderStatus) IsValid() bool {
+	_, ok := orderStatusToString[os]
+	return ok
+}
+
+// IsUnknown reports whether the enum holds an unknown value decoded in tolerant mode
+func (os OrderStatus) IsUnknown() bool {
+	_, ok := orderStatusUnknown.lookup(int(os))
+	return ok
+}
+
+// MarshalText marshals the enum as a string
+func (os OrderStatus) MarshalText() ([]byte, error) {
+	elem, ok := orderStatusToString[os]
+	if !ok {
+		elem, ok = orderStatusUnknown.lookup(int(os))
+	}
+
+	if ok && elem != "" {
+		return []byte(elem), nil
+	}
+
+	return nil, errors.New("couldn't marshal OrderStatus enum")
+}
+
+// UnmarshalText unmarshals a string to the enum value
+func (os *OrderStatus) UnmarshalText(data []byte) error {
+	result, ok := orderStatusToEnum[string(data)]
+	if !ok {
+		if atomic.LoadUint32(&tolerantEnums) == 0 {
+			return errors.New("couldn't find matching OrderStatus enum value")
+		}
+		result = OrderStatus(orderStatusUnknown.register(string(data)))
+	}
+
+	*os = result
+	return nil
+}
+
 // MarshalJSON marshals the enum as a quoted json string
 func (os OrderStatus) MarshalJSON() ([]byte, error) {
-	elem, ok := orderStatusToString[os]
-	if ok {
-		return bytes.NewBufferString(fmt.Sprintf(`"%s"`, elem)).Bytes(), nil
+	elem, err := os.MarshalText()
+	if err != nil {
+		return bytes.NewBufferString("").Bytes(), err
 	}
 
-	return bytes.NewBufferString("").Bytes(), errors.New("couldn't marshal OrderStatus enum")
+	return json.Marshal(string(elem))
 }
 
 // UnmarshalJSON unmashals a quoted json string to the enum value
@@ -747,11 +1506,5 @@ func (os *OrderStatus) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	result, ok := orderStatusToEnum[j]
-	if !ok {
-		return errors.New("couldn't find matching OrderStatus enum value")
-	}
-
-	*os = result
-	return nil
+	return os.UnmarshalText([]byte(j))
 }
@@ -1,6 +1,9 @@
 package exchangestream
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ConnectionError is the error returned by Connect() when an error occurs
 type ConnectionError struct {
@@ -35,3 +38,107 @@ func (e ConnectionFailedError) Error() string {
 func (e ConnectionFailedError) Unwrap() error {
 	return e.Err
 }
+
+// ErrorAction is the action the client takes to recover from an error reported by betfair
+type ErrorAction int
+
+const (
+	// ErrorAction_Retry means backing off and reconnecting
+	ErrorAction_Retry ErrorAction = iota + 1
+	// ErrorAction_Relogin means getting a new session token from the TokenProvider and reconnecting
+	ErrorAction_Relogin
+	// ErrorAction_ResetClocks means resubscribing from scratch, without the clocks (betfair sends a new image)
+	ErrorAction_ResetClocks
+	// ErrorAction_Stop means giving up, retrying would fail the same way
+	ErrorAction_Stop
+)
+
+func (ea ErrorAction) String() string {
+	switch ea {
+	case ErrorAction_Retry:
+		return "RETRY"
+	case ErrorAction_Relogin:
+		return "RELOGIN"
+	case ErrorAction_ResetClocks:
+		return "RESET_CLOCKS"
+	case ErrorAction_Stop:
+		return "STOP"
+	}
+	return ""
+}
+
+// StatusError is the error reported by betfair through a status message (StatusCode FAILURE)
+type StatusError struct {
+	ErrorCode    ErrorCode
+	ErrorMessage string
+	ConnectionID string
+	// ConnectionClosed is set when betfair closes the connection after sending the status message
+	ConnectionClosed bool
+}
+
+// NewStatusError creates a StatusError from a StatusMessage
+func NewStatusError(sm StatusMessage) StatusError {
+	se := StatusError{ErrorCode: sm.ErrorCode, ErrorMessage: sm.ErrorMessage, ConnectionID: sm.ConnectionID}
+	if sm.ConnectionClosed != nil {
+		se.ConnectionClosed = *sm.ConnectionClosed
+	}
+	return se
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("betfair status error: %s - %s (connectionClosed: %t)", e.ErrorCode, e.ErrorMessage, e.ConnectionClosed)
+}
+
+// Action returns the action the client takes to recover from this error
+func (e StatusError) Action() ErrorAction {
+	switch e.ErrorCode {
+	case ErrorCode_NoSession, ErrorCode_InvalidSessionInformation:
+		return ErrorAction_Relogin
+	case ErrorCode_InvalidClock:
+		return ErrorAction_ResetClocks
+	case ErrorCode_Timeout, ErrorCode_UnexpectedError, ErrorCode_ConnectionFailed, ErrorCode_MaxConnectionLimitExceeded:
+		return ErrorAction_Retry
+	}
+
+	// NO_APP_KEY, INVALID_APP_KEY, NOT_AUTHORIZED, INVALID_INPUT, INVALID_REQUEST and SUBSCRIPTION_LIMIT_EXCEEDED
+	// would fail again with the same request
+	return ErrorAction_Stop
+}
+
+// ErrNotConnected is returned by requests made while there is no usable connection
+var ErrNotConnected = errors.New("not connected")
+
+// ErrConnectionLost is returned by requests still waiting for a response when the connection is lost
+var ErrConnectionLost = errors.New("connection lost before getting a response")
+
+// ErrRequestExpired is returned by requests that didn't get a response in time
+var ErrRequestExpired = errors.New("no response received in time")
+
+// RequestError is the error returned by the request methods (Authenticate, Heartbeat, MarketSubscribe, OrderSubscribe)
+// when no response was received
+type RequestError struct {
+	Op  string
+	Err error
+}
+
+func (e RequestError) Error() string {
+	return fmt.Sprintf("%s request failed: %s", e.Op, e.Err)
+}
+
+func (e RequestError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError is returned by requests that couldn't be written to the connection.
+// The connection is brought down (and reconnected, if enabled) when it happens.
+type WriteError struct {
+	Err error
+}
+
+func (e WriteError) Error() string {
+	return fmt.Sprintf("failed writing request: %s", e.Err)
+}
+
+func (e WriteError) Unwrap() error {
+	return e.Err
+}
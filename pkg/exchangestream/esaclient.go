@@ -3,11 +3,11 @@ package exchangestream
 import (
 	"context"
 	"crypto/tls"
-	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -19,7 +19,15 @@ import (
 
 type WorkUnit struct {
 	req      RequestMessage
-	respChan chan ResponseMessage
+	respChan chan workResult
+	// expiry is when the controller stops waiting for a response
+	expiry time.Time
+}
+
+// workResult is sent back to the caller that made the request
+type workResult struct {
+	resp ResponseMessage
+	err  error
 }
 
 type ConnectionConfig struct {
@@ -32,7 +40,7 @@ type ConnectionConfig struct {
 
 	// ConnectionTimeout specifies the wait time (in milliseconds) before timing out the connection
 	ConnectionTimeout int
-	// Retries specifies the number of retries allowed.
+	// Retries specifies the number of retries allowed, when connecting and, per connection loss, when reconnecting.
 	// -1 means infinite number of retries
 	// 0 means to never retry
 	// Any other number greater or equal to 1 means retry X amount of times
@@ -46,12 +54,40 @@ type ConnectionConfig struct {
 // ESAClient is the client that interacts with betfair Exchange Stream API
 // It's thread safe!
 type ESAClient struct {
+	// How long to wait for the response to a request (time.Duration)
+	// Atomic, kept as the first field so it's 64-bit aligned on 32-bit platforms
+	requestTimeout int64
+
 	// Treated as immutable
-	appKey       string
-	sessionToken string
+	appKey string
+	// Session token (holds a string), replaced when logging in again through the token provider
+	sessionToken atomic.Value
+	// Gets a new session token when betfair reports the session is no longer valid (holds a TokenProvider)
+	tokenProvider atomic.Value
 
 	// Connection to server
 	conn *tls.Conn
+	// Guards connection setup and teardown (used by Connect, Disconnect and the reconnection logic)
+	connMu *sync.Mutex
+	// Set when the controller and its goroutines are running on top of conn (guarded by connMu)
+	connected bool
+	// Stops the controller and its goroutines (guarded by connMu)
+	connCancel context.CancelFunc
+	// Tracks the controller, reader, writer and connection tracker goroutines of the current connection
+	connWG *sync.WaitGroup
+	// Incremented on every new connection, used to discard connection lost signals from old connections
+	connGeneration uint32
+	// Set after a successful authentication, so the session can be restored after reconnecting (0 - False | 1 - True)
+	authenticated uint32
+	// Active subscriptions and their latest clocks, used to resubscribe after reconnecting
+	subscriptions *subscriptions
+	// Cancels the supervisor goroutine (holds a context.CancelFunc)
+	supervisorCancel atomic.Value
+	// Tracks the supervisor goroutine
+	supervisorWG *sync.WaitGroup
+	// Connection state (guarded by stateMu)
+	stateMu *sync.Mutex
+	state   ConnectionState
 
 	// Atomic
 	msgID uint32
@@ -61,12 +97,30 @@ type ESAClient struct {
 	connectionID atomic.Value
 	// When sending or receiving messages through channels, how long to wait before giving up (in seconds)
 	chanWaitTime uint32
-	// Reader buffer size
+	// Reader buffer initial size, it grows as needed up to maxMessageSize
 	readerBufferSize uint32
+	// Largest message accepted from the server (in bytes)
+	maxMessageSize uint32
 	// Metrics enable flag (0 - False | 1 - True)
 	metricsFlag uint32
+	// Dispatches the messages to the registered StreamHandler (holds a *dispatcher, nil when there is no handler)
+	dispatcher atomic.Value
+	// Records the raw messages received (holds a *Recorder, nil when there is no recorder)
+	recorder atomic.Value
+	// What to do when MCMChan or OCMChan are full (holds a BackpressureConfig)
+	backpressure atomic.Value
+	// Messages dropped or merged because the consumers are not keeping up
+	backpressureCounters *backpressureCounters
+	// Holds the market changes merged while MCMChan is full (only used by the controller)
+	coalescer *marketCoalescer
+	// How segmented change messages are handled (holds a SegmentationConfig)
+	segmentationConfig atomic.Value
 	// heartbeat MS interval (bound between 500ms and 5000ms)
 	heartbeatMS uint32
+	// conflation interval requested on subscriptions that don't set one (0 means no conflation)
+	conflateMS uint32
+	// heartbeat and conflation intervals confirmed by the server
+	negotiated *negotiatedSettings
 	// wait x times the heartbeatMS before sending Heartbeat message (in %) e.g.: 100% means wait for the whole period
 	heartbeatMultiplier uint32
 
@@ -75,20 +129,23 @@ type ESAClient struct {
 
 	// Gets messages to be sent to the betfair server
 	reqMsgChan chan WorkUnit
-	// Life cycle management
-	// Internal control channels (signal to controller to stop)
-	stopChan chan bool
-	// Inform back that the controller and its goroutines have successfully shutdown
-	stopInformChan chan bool
 	// Inform connection tracker that a new update got in
 	heartbeatUpdateChan chan uint32
 	// Signal auth success
 	authSuccessChan chan bool
+	// Inform the supervisor that the connection was lost
+	connLostChan chan connLost
 
 	// Change Streams
 	// Public channel
 	MCMChan chan MarketChangeM
 	OCMChan chan OrderChangeM
+	// Connection life cycle events
+	// Public channel
+	EventChan chan ConnectionEvent
+	// Errors reported by betfair (StatusError)
+	// Public channel
+	ErrorChan chan error
 
 	// Metrics
 	readCounter prometheus.Counter
@@ -96,24 +153,44 @@ type ESAClient struct {
 
 // NewESAClient creates a new esaclient object.
 func NewESAClient(appKey string, sessionToken string) ESAClient {
-	client := ESAClient{appKey: appKey, sessionToken: sessionToken}
+	client := ESAClient{appKey: appKey}
+	client.sessionToken.Store(sessionToken)
+	client.tokenProvider.Store(TokenProvider(nil))
 	// Set some defaults
 	client.chanWaitTime = 3
-	client.readerBufferSize = 8 * 1024 * 1024
+	client.requestTimeout = int64(3 * time.Second)
+	client.readerBufferSize = defaultReaderBufferSize
+	client.maxMessageSize = defaultMaxMessageSize
 	client.heartbeatMS = 1000
 	client.heartbeatMultiplier = 150
+	client.negotiated = &negotiatedSettings{}
 
 	client.connectionID.Store("")
 	client.connConfig.Store(ConnectionConfig{})
+	client.dispatcher.Store((*dispatcher)(nil))
+	client.recorder.Store((*Recorder)(nil))
+	client.backpressure.Store(BackpressureConfig{Market: BackpressurePolicy_Block, Order: BackpressurePolicy_Block})
+	client.backpressureCounters = &backpressureCounters{}
+	client.coalescer = &marketCoalescer{}
+	client.segmentationConfig.Store(SegmentationConfig{MaxSegments: defaultMaxSegments, Timeout: defaultSegmentTimeout})
+	client.connMu = &sync.Mutex{}
+	client.connCancel = func() {}
+	client.connWG = &sync.WaitGroup{}
+	client.supervisorWG = &sync.WaitGroup{}
+	client.subscriptions = &subscriptions{}
+	client.stateMu = &sync.Mutex{}
+	client.state = ConnectionState_Disconnected
+	client.supervisorCancel.Store(context.CancelFunc(func() {}))
 
 	// Init channels
 	client.reqMsgChan = make(chan WorkUnit, 1000)
-	client.stopChan = make(chan bool, 1)
-	client.stopInformChan = make(chan bool, 1)
 	client.heartbeatUpdateChan = make(chan uint32, 10)
 	client.authSuccessChan = make(chan bool, 10)
+	client.connLostChan = make(chan connLost, 10)
 	client.MCMChan = make(chan MarketChangeM, 1000)
 	client.OCMChan = make(chan OrderChangeM, 1000)
+	client.EventChan = make(chan ConnectionEvent, 100)
+	client.ErrorChan = make(chan error, 100)
 
 	return client
 }
@@ -135,11 +212,31 @@ func (esaclient *ESAClient) ChangeSettings(chanWaitTime uint32, heartbeatMS uint
 	return nil
 }
 
+// TokenProvider returns a new session token (e.g. by logging in again).
+// It's called by the client when betfair reports that the session is no longer valid.
+type TokenProvider func(ctx context.Context) (string, error)
+
+// SetTokenProvider sets the function used to get a new session token when the session expires.
+// Without a token provider, the client gives up when betfair rejects the session.
+func (esaclient *ESAClient) SetTokenProvider(tp TokenProvider) {
+	esaclient.tokenProvider.Store(tp)
+}
+
+// reportError publishes the error on ErrorChan.
+// If nobody is consuming ErrorChan and it's full, the error is dropped rather than blocking the client.
+func (esaclient *ESAClient) reportError(err error) {
+	select {
+	case esaclient.ErrorChan <- err:
+	default:
+		log.Log(globals.Logger, log.WARN, "error channel full, dropping error", log.Fields{"error": err.Error()})
+	}
+}
+
 // GetSessionInfo returns the application key, the session token, the connection ID and the current message ID counter.
 func (esaclient *ESAClient) GetSessionInfo() (string, string, string, uint32) {
 	connID := esaclient.connectionID.Load().(string)
 
-	return esaclient.appKey, esaclient.sessionToken, connID, esaclient.msgID
+	return esaclient.appKey, esaclient.sessionToken.Load().(string), connID, atomic.LoadUint32(&esaclient.msgID)
 }
 
 // Connect connects to the betfair server.
@@ -152,20 +249,39 @@ func (esaclient *ESAClient) Connect(ctx context.Context, connConfig ConnectionCo
 	}
 
 	esaclient.connConfig.Store(connConfig)
-	return esaclient.connectionHelper(ctx)
+
+	esaclient.connMu.Lock()
+	defer esaclient.connMu.Unlock()
+
+	esaclient.setState(ConnectionState_Connecting, "connect requested", nil)
+
+	rpe := NewPolicyExponential(connConfig.Retries, connConfig.MaximumBackoff, 0)
+	err := esaclient.connectionHelper(ctx, &rpe)
+	if err != nil {
+		esaclient.setState(ConnectionState_Disconnected, "failed to connect", err)
+		return err
+	}
+
+	// The supervisor outlives the context passed in, it only stops on Disconnect
+	supervisorCtx, cancel := context.WithCancel(context.Background())
+	esaclient.supervisorCancel.Store(cancel)
+	esaclient.supervisorWG.Add(1)
+	go esaclient.supervisor(supervisorCtx)
+
+	return nil
 }
 
 // connectionHelper is an helper function used to connect to betfair server
+// The failed attempts are retried according to the retry policy passed in, which is shared by the reconnection
+// attempts of an outage.
 // In case we pass -1 as the retry counter (which means, retry forever) then this function will never return an error
-func (esaclient *ESAClient) connectionHelper(ctx context.Context) error {
+func (esaclient *ESAClient) connectionHelper(ctx context.Context, rpe *PolicyExponential) error {
 	connConfig := esaclient.connConfig.Load().(ConnectionConfig)
 
 	config := tls.Config{InsecureSkipVerify: connConfig.InsecureSkipVerify}
 	d := net.Dialer{Timeout: time.Duration(connConfig.ConnectionTimeout) * time.Millisecond}
 	addr := connConfig.ServerHost + ":" + strconv.Itoa(int(connConfig.ServerPort))
 
-	rpe := NewPolicyExponential(connConfig.Retries, connConfig.MaximumBackoff, 0)
-
 	var cancelled bool
 	var wboErr error
 
@@ -199,23 +315,31 @@ func (esaclient *ESAClient) connRetry(ctx context.Context, d net.Dialer, addr st
 
 	log.Log(globals.Logger, log.INFO, "connection established with server", nil)
 
-	connMsgChan := make(chan ConnectionMessage)
+	atomic.AddUint32(&esaclient.connGeneration, 1)
+	esaclient.connected = true
+
+	// Buffered, so the controller doesn't block if we give up waiting for the connection message
+	connMsgChan := make(chan ConnectionMessage, 1)
 
-	// Spawn controller in a goroutine
-	go esaclient.controller(connMsgChan)
+	// Spawn controller in a goroutine, it stops when connCtx is cancelled
+	connCtx, cancel := context.WithCancel(context.Background())
+	esaclient.connCancel = cancel
+	esaclient.connWG.Add(1)
+	go esaclient.controller(connCtx, connMsgChan)
 
 	// Select connMsgChan and Timeout of X seconds
 	// If timeout, bring all 4 goroutines down: controller, reader, writer, connection tracker
 	select {
 	case <-ctx.Done():
-		err = esaclient.disconnectHelper()
+		err = esaclient.teardown()
 		return ConnectionFailedError{Msg: "context cancelled while waiting for connection message from betfair", Err: err}
 	case connMsg := <-connMsgChan:
 		esaclient.connectionID.Store(connMsg.ConnectionID)
+		esaclient.setState(ConnectionState_Connected, "connection message received", nil)
 		return nil
 	case <-time.After(time.Duration(esaclient.chanWaitTime) * time.Second):
 		// call timed out
-		err = esaclient.disconnectHelper()
+		err = esaclient.teardown()
 		return ConnectionFailedError{Msg: "timeout while waiting for connection message from betfair", Err: err}
 	}
 }
@@ -227,115 +351,236 @@ func (esaclient *ESAClient) Disconnect() error {
 		return ConnectionError{Msg: "no connection available to disconnect"}
 	}
 
-	return esaclient.disconnectHelper()
+	// Stop the supervisor first, so an ongoing reconnection gives up and releases the lock
+	esaclient.supervisorCancel.Load().(context.CancelFunc)()
+
+	esaclient.connMu.Lock()
+	err := esaclient.disconnectHelper()
+	esaclient.setState(ConnectionState_Closed, "disconnect requested", err)
+	esaclient.connMu.Unlock()
+
+	// Only after releasing the lock, the supervisor might be waiting for it
+	esaclient.supervisorWG.Wait()
+
+	return err
 }
 
+// disconnectHelper tears down the connection and forgets about the session (must hold connMu)
 func (esaclient *ESAClient) disconnectHelper() error {
-	esaclient.stopChan <- true
-
-	// Wait for the stop inform to arrive or after X seconds kill the connection anyway
-	select {
-	case <-esaclient.stopInformChan:
-		// success tearing down
-	case <-time.After(time.Duration(esaclient.chanWaitTime) * time.Second):
-		// call timed out
-		log.Log(globals.Logger, log.ERROR, "timeout while waiting for goroutines to shutdown", nil)
+	var err error
+	if esaclient.connected {
+		err = esaclient.teardown()
 	}
 
 	esaclient.connConfig.Store(ConnectionConfig{})
 	esaclient.connectionID.Store("")
 	atomic.StoreUint32(&esaclient.msgID, 0)
+	atomic.StoreUint32(&esaclient.authenticated, 0)
+	esaclient.subscriptions.reset()
 
-	return esaclient.conn.Close()
+	return err
 }
 
-func (esaclient *ESAClient) controller(connMsgChan chan<- ConnectionMessage) {
+// teardown brings down the controller and its goroutines and closes the connection (must hold connMu).
+// Returns only once the controller, reader, writer and connection tracker have all exited.
+func (esaclient *ESAClient) teardown() error {
+	esaclient.connected = false
+	esaclient.connCancel()
+
+	// Closing the connection unblocks the reader and the writer straight away
+	err := esaclient.conn.Close()
+	esaclient.connWG.Wait()
+
+	// Requests queued after the controller stopped will never be picked up
+	esaclient.failQueued(ErrConnectionLost)
+
+	// The next connection negotiates them again
+	esaclient.negotiated.reset()
+
+	return err
+}
+
+// failQueued fails the requests waiting in reqMsgChan, only safe while the controller isn't running
+func (esaclient *ESAClient) failQueued(err error) {
+	for {
+		select {
+		case workUnit := <-esaclient.reqMsgChan:
+			workUnit.reply(workResult{err: err})
+		default:
+			return
+		}
+	}
+}
+
+func (esaclient *ESAClient) controller(ctx context.Context, connMsgChan chan<- ConnectionMessage) {
+	defer esaclient.connWG.Done()
 	log.Log(globals.Logger, log.INFO, "starting controller goroutine", nil)
 	defer log.Log(globals.Logger, log.INFO, "exiting controller goroutine", nil)
 
-	controller(esaclient, connMsgChan)
+	controller(esaclient, ctx, connMsgChan)
 }
 
-func (esaclient *ESAClient) connTracker(stopChan <-chan bool, stopInformChan chan<- bool) {
+func (esaclient *ESAClient) connTracker(ctx context.Context, connLostChan chan<- error) {
+	defer esaclient.connWG.Done()
 	log.Log(globals.Logger, log.INFO, "starting connection tracker goroutine", nil)
 	defer log.Log(globals.Logger, log.INFO, "exiting connection tracker goroutine", nil)
 
-	connTracker(esaclient, stopChan, stopInformChan)
+	connTracker(esaclient, ctx, connLostChan)
 }
 
 // reader is responsible for reading all incoming messages and sending the corresponding objects down the channel
-func (esaclient *ESAClient) reader(respMsgChan chan<- ResponseMessage, stopChan <-chan bool) {
+func (esaclient *ESAClient) reader(ctx context.Context, respMsgChan chan<- ResponseMessage, connLostChan chan<- error) {
+	defer esaclient.connWG.Done()
 	log.Log(globals.Logger, log.INFO, "starting reader goroutine", nil)
 	defer log.Log(globals.Logger, log.INFO, "exiting reader goroutine", nil)
 
-	reader(esaclient, respMsgChan, stopChan)
+	reader(esaclient, ctx, respMsgChan, connLostChan)
 }
 
 // writer is responsible for sending messages to the betfair server
-func (esaclient *ESAClient) writer(reqMsgChan <-chan RequestMessage, stopInformChan chan<- bool) {
+func (esaclient *ESAClient) writer(reqMsgChan <-chan requestFrame, writeErrChan chan<- writeFailure, connLostChan chan<- error) {
+	defer esaclient.connWG.Done()
 	log.Log(globals.Logger, log.INFO, "starting writer goroutine", nil)
 	defer log.Log(globals.Logger, log.INFO, "exiting writer goroutine", nil)
 
-	writer(esaclient, reqMsgChan, stopInformChan)
+	writer(esaclient, reqMsgChan, writeErrChan, connLostChan)
+}
+
+// supervisor waits for the connection to be lost and reconnects, restoring the session and subscriptions
+func (esaclient *ESAClient) supervisor(ctx context.Context) {
+	defer esaclient.supervisorWG.Done()
+	log.Log(globals.Logger, log.INFO, "starting supervisor goroutine", nil)
+	defer log.Log(globals.Logger, log.INFO, "exiting supervisor goroutine", nil)
+
+	supervisor(esaclient, ctx)
 }
 
-// Authenticate authenticates with betfair
+// Authenticate authenticates with betfair, waiting for the response up to the request timeout
 func (esaclient *ESAClient) Authenticate() (StatusMessage, error) {
-	replyChan := make(chan ResponseMessage)
-	am := AuthenticationMessage{AppKey: esaclient.appKey, SessionToken: esaclient.sessionToken}
+	ctx, cancel := esaclient.requestContext()
+	defer cancel()
+	return esaclient.AuthenticateWithContext(ctx)
+}
+
+// AuthenticateWithContext authenticates with betfair, waiting for the response until the context is done
+func (esaclient *ESAClient) AuthenticateWithContext(ctx context.Context) (StatusMessage, error) {
+	am := AuthenticationMessage{AppKey: esaclient.appKey, SessionToken: esaclient.sessionToken.Load().(string)}
 	reqMsg := RequestMessage{Op: "authentication", AuthenticationMessage: &am}
-	esaclient.reqMsgChan <- WorkUnit{req: reqMsg, respChan: replyChan}
 
-	select {
-	case resp := <-replyChan:
-		if resp.StatusMessage.StatusCode == StatusCode_Success {
-			esaclient.authSuccessChan <- true
+	sm, err := esaclient.request(ctx, reqMsg)
+	if err == nil && sm.StatusCode == StatusCode_Success {
+		atomic.StoreUint32(&esaclient.authenticated, 1)
+		esaclient.setState(ConnectionState_Authenticated, "authentication successful", nil)
+		// Don't block if the connection tracker has a signal pending already (or is gone), one is enough
+		select {
+		case esaclient.authSuccessChan <- true:
+		default:
 		}
-		return *resp.StatusMessage, nil
-	case <-time.After(3 * time.Second):
-		return StatusMessage{}, errors.New("timeout before getting response")
 	}
+	return sm, err
 }
 
-// Heartbeat sends heartbeat message
+// Heartbeat sends heartbeat message, waiting for the response up to the request timeout
 func (esaclient *ESAClient) Heartbeat() (StatusMessage, error) {
-	replyChan := make(chan ResponseMessage)
-	reqMsg := RequestMessage{Op: "heartbeat"}
-	esaclient.reqMsgChan <- WorkUnit{req: reqMsg, respChan: replyChan}
+	ctx, cancel := esaclient.requestContext()
+	defer cancel()
+	return esaclient.HeartbeatWithContext(ctx)
+}
 
-	select {
-	case resp := <-replyChan:
-		return *resp.StatusMessage, nil
-	case <-time.After(3 * time.Second):
-		return StatusMessage{}, errors.New("timeout before getting response")
-	}
+// HeartbeatWithContext sends heartbeat message, waiting for the response until the context is done
+func (esaclient *ESAClient) HeartbeatWithContext(ctx context.Context) (StatusMessage, error) {
+	reqMsg := RequestMessage{Op: "heartbeat"}
+	return esaclient.request(ctx, reqMsg)
 }
 
-// MarketSubscribe subscribes to markets
+// MarketSubscribe subscribes to markets, waiting for the response up to the request timeout
 func (esaclient *ESAClient) MarketSubscribe(msm MarketSubscriptionMessage) (StatusMessage, error) {
-	replyChan := make(chan ResponseMessage)
+	ctx, cancel := esaclient.requestContext()
+	defer cancel()
+	return esaclient.MarketSubscribeWithContext(ctx, msm)
+}
+
+// MarketSubscribeWithContext subscribes to markets, waiting for the response until the context is done
+func (esaclient *ESAClient) MarketSubscribeWithContext(ctx context.Context, msm MarketSubscriptionMessage) (StatusMessage, error) {
 	reqMsg := RequestMessage{Op: "marketSubscription", MarketSubscriptionMessage: &msm}
-	esaclient.reqMsgChan <- WorkUnit{req: reqMsg, respChan: replyChan}
 
-	select {
-	case resp := <-replyChan:
-		return *resp.StatusMessage, nil
-	case <-time.After(3 * time.Second):
-		return StatusMessage{}, errors.New("timeout before getting response")
+	sm, err := esaclient.request(ctx, reqMsg)
+	if err == nil && sm.StatusCode == StatusCode_Success {
+		esaclient.setState(ConnectionState_Subscribed, "market subscription successful", nil)
 	}
+	return sm, err
 }
 
-// OrderSubscribe subscribes to orders
+// OrderSubscribe subscribes to orders, waiting for the response up to the request timeout
 func (esaclient *ESAClient) OrderSubscribe(osm OrderSubscriptionMessage) (StatusMessage, error) {
-	replyChan := make(chan ResponseMessage)
+	ctx, cancel := esaclient.requestContext()
+	defer cancel()
+	return esaclient.OrderSubscribeWithContext(ctx, osm)
+}
+
+// OrderSubscribeWithContext subscribes to orders, waiting for the response until the context is done
+func (esaclient *ESAClient) OrderSubscribeWithContext(ctx context.Context, osm OrderSubscriptionMessage) (StatusMessage, error) {
 	reqMsg := RequestMessage{Op: "orderSubscription", OrderSubscriptionMessage: &osm}
-	esaclient.reqMsgChan <- WorkUnit{req: reqMsg, respChan: replyChan}
+
+	sm, err := esaclient.request(ctx, reqMsg)
+	if err == nil && sm.StatusCode == StatusCode_Success {
+		esaclient.setState(ConnectionState_Subscribed, "order subscription successful", nil)
+	}
+	return sm, err
+}
+
+// MarketSubscriptionID returns the ID of the request that created the current market subscription.
+// MarketChangeMessages belonging to that subscription carry the same ID, zero means there is no subscription.
+func (esaclient *ESAClient) MarketSubscriptionID() uint32 {
+	return esaclient.subscriptions.marketSubscriptionID()
+}
+
+// SetRequestTimeout sets how long the request methods without a context wait for a response (3 seconds by default).
+// It's also how long requests made with a context without deadline are kept waiting for a response.
+func (esaclient *ESAClient) SetRequestTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return fmt.Errorf("request timeout needs to be positive")
+	}
+	atomic.StoreInt64(&esaclient.requestTimeout, int64(timeout))
+	return nil
+}
+
+// requestContext returns a context that times out after the request timeout
+func (esaclient *ESAClient) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(atomic.LoadInt64(&esaclient.requestTimeout)))
+}
+
+// request sends the request to the controller and waits for the status message in response
+func (esaclient *ESAClient) request(ctx context.Context, reqMsg RequestMessage) (StatusMessage, error) {
+	switch esaclient.State() {
+	case ConnectionState_Connected, ConnectionState_Authenticated, ConnectionState_Subscribed:
+	default:
+		return StatusMessage{}, RequestError{Op: reqMsg.Op, Err: ErrNotConnected}
+	}
+
+	expiry, ok := ctx.Deadline()
+	if !ok {
+		expiry = time.Now().Add(time.Duration(atomic.LoadInt64(&esaclient.requestTimeout)))
+	}
+
+	// Buffered, so the controller never blocks on a caller that already gave up
+	replyChan := make(chan workResult, 1)
+	workUnit := WorkUnit{req: reqMsg, respChan: replyChan, expiry: expiry}
+
+	select {
+	case esaclient.reqMsgChan <- workUnit:
+	case <-ctx.Done():
+		return StatusMessage{}, RequestError{Op: reqMsg.Op, Err: ctx.Err()}
+	}
 
 	select {
-	case resp := <-replyChan:
-		return *resp.StatusMessage, nil
-	case <-time.After(3 * time.Second):
-		return StatusMessage{}, errors.New("timeout before getting response")
+	case result := <-replyChan:
+		if result.err != nil {
+			return StatusMessage{}, RequestError{Op: reqMsg.Op, Err: result.err}
+		}
+		return *result.resp.StatusMessage, nil
+	case <-ctx.Done():
+		return StatusMessage{}, RequestError{Op: reqMsg.Op, Err: ctx.Err()}
 	}
 }
 
@@ -359,6 +604,12 @@ func (esaclient *ESAClient) TurnOnMetrics() error {
 		return err
 	}
 
+	for _, collector := range esaclient.backpressureCollectors() {
+		if err = prometheus.Register(collector); err != nil {
+			return err
+		}
+	}
+
 	atomic.StoreUint32(&esaclient.metricsFlag, 1)
 	return nil
 }
@@ -0,0 +1,321 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReconnectResubscribes(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	connConfig := ts.connConfig()
+	connConfig.Reconnect = true
+
+	err := esaclient.Connect(context.Background(), connConfig)
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	if _, err = esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	msm := MarketSubscriptionMessage{MarketFilter: MarketFilter{MarketIDs: []string{"1.170000000"}}}
+	if _, err = esaclient.MarketSubscribe(msm); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	// Discard the requests made on the first connection
+	ts.waitRequest("authentication")
+	ts.waitRequest("marketSubscription")
+
+	ts.send(`{"op":"mcm","id":2,"initialClk":"initial","clk":"clk1","pt":1594999999999,"ct":"SUB_IMAGE","mc":[]}`)
+	ts.send(`{"op":"mcm","id":2,"clk":"clk2","pt":1594999999999,"mc":[]}`)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-esaclient.MCMChan:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for market change message")
+		}
+	}
+
+	waitState(t, &esaclient, ConnectionState_Subscribed)
+	ts.dropConnection()
+
+	event := waitState(t, &esaclient, ConnectionState_Reconnecting)
+	if event.From != ConnectionState_Subscribed || event.Err == nil {
+		t.Errorf("got event %+v, want transition from SUBSCRIBED with the connection error", event)
+	}
+
+	auth := ts.waitRequest("authentication")
+	if auth["session"] != "session_token" {
+		t.Errorf("got session %v after reconnecting, want session_token", auth["session"])
+	}
+
+	sub := ts.waitRequest("marketSubscription")
+	if sub["initialClk"] != "initial" || sub["clk"] != "clk2" {
+		t.Errorf("got clocks (%v, %v) when resubscribing, want (initial, clk2)", sub["initialClk"], sub["clk"])
+	}
+
+	ts.send(`{"op":"mcm","id":3,"clk":"clk3","pt":1594999999999,"mc":[]}`)
+
+	select {
+	case mcm := <-esaclient.MCMChan:
+		if mcm.Clk != "clk3" {
+			t.Errorf("got clk %s after reconnecting, want clk3", mcm.Clk)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for market change message after reconnecting")
+	}
+
+	waitState(t, &esaclient, ConnectionState_Subscribed)
+
+	if err = esaclient.Disconnect(); err != nil {
+		t.Errorf("error disconnecting - error: %s", err)
+	}
+
+	waitState(t, &esaclient, ConnectionState_Closed)
+}
+
+func TestNoReconnect(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	err := esaclient.Connect(context.Background(), ts.connConfig())
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	waitState(t, &esaclient, ConnectionState_Connected)
+	ts.dropConnection()
+
+	event := waitState(t, &esaclient, ConnectionState_Disconnected)
+	if event.Err == nil {
+		t.Errorf("got event %+v, want the connection error", event)
+	}
+
+	if _, _, connID, _ := esaclient.GetSessionInfo(); connID != "" {
+		t.Errorf("client still connected after the server dropped the connection")
+	}
+
+	if err = esaclient.Disconnect(); err == nil {
+		t.Errorf("expected error disconnecting a client that is no longer connected")
+	}
+}
+
+func TestReconnectRetryBudget(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	if err := esaclient.SetRequestTimeout(100 * time.Millisecond); err != nil {
+		t.Fatalf("error setting request timeout - error: %s", err)
+	}
+
+	connConfig := ts.connConfig()
+	connConfig.Reconnect = true
+	connConfig.Retries = 2
+	connConfig.MaximumBackoff = 0
+
+	err := esaclient.Connect(context.Background(), connConfig)
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	if _, err = esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	// Every attempt to restore the session times out, the retries of the outage run out
+	ts.ignore("authentication")
+	ts.dropConnection()
+
+	event := waitState(t, &esaclient, ConnectionState_Disconnected)
+	if event.Reason != "no retries left" {
+		t.Errorf("got event %+v, want giving up once the retries run out", event)
+	}
+
+	ts.mu.Lock()
+	connections := ts.connections
+	ts.mu.Unlock()
+	if connections != 1+connConfig.Retries {
+		t.Errorf("got %d connections, want the first one and %d retries", connections, connConfig.Retries)
+	}
+}
+
+// waitState waits for the client to transition into the given state, returning the transition event
+func waitState(t *testing.T, esaclient *ESAClient, state ConnectionState) ConnectionEvent {
+	t.Helper()
+
+	for {
+		select {
+		case event := <-esaclient.EventChan:
+			if event.To == state {
+				return event
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for state %s, current state: %s", state, esaclient.State())
+			return ConnectionEvent{}
+		}
+	}
+}
+
+func TestStatusErrorRelogin(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	esaclient.SetTokenProvider(func(ctx context.Context) (string, error) {
+		return "new_session_token", nil
+	})
+
+	connConfig := ts.connConfig()
+	connConfig.Reconnect = true
+
+	err := esaclient.Connect(context.Background(), connConfig)
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	if _, err = esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+	ts.waitRequest("authentication")
+
+	ts.send(`{"op":"status","statusCode":"FAILURE","errorCode":"INVALID_SESSION_INFORMATION","errorMessage":"session expired","connectionClosed":true}`)
+	ts.dropConnection()
+
+	select {
+	case err := <-esaclient.ErrorChan:
+		var statusErr StatusError
+		if !errors.As(err, &statusErr) || statusErr.ErrorCode != ErrorCode_InvalidSessionInformation || !statusErr.ConnectionClosed {
+			t.Errorf("got error %v, want INVALID_SESSION_INFORMATION StatusError", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for status error")
+	}
+
+	auth := ts.waitRequest("authentication")
+	if auth["session"] != "new_session_token" {
+		t.Errorf("got session %v after relogin, want new_session_token", auth["session"])
+	}
+
+	waitState(t, &esaclient, ConnectionState_Authenticated)
+
+	if err = esaclient.Disconnect(); err != nil {
+		t.Errorf("error disconnecting - error: %s", err)
+	}
+}
+
+func TestStatusErrorStop(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	connConfig := ts.connConfig()
+	connConfig.Reconnect = true
+
+	err := esaclient.Connect(context.Background(), connConfig)
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	ts.send(`{"op":"status","statusCode":"FAILURE","errorCode":"NOT_AUTHORIZED","connectionClosed":true}`)
+	ts.dropConnection()
+
+	event := waitState(t, &esaclient, ConnectionState_Disconnected)
+	var statusErr StatusError
+	if !errors.As(event.Err, &statusErr) || statusErr.Action() != ErrorAction_Stop {
+		t.Errorf("got event %+v, want the NOT_AUTHORIZED StatusError", event)
+	}
+}
+
+func TestStatusErrorAction(t *testing.T) {
+	tests := map[ErrorCode]ErrorAction{
+		ErrorCode_InvalidSessionInformation:  ErrorAction_Relogin,
+		ErrorCode_NoSession:                  ErrorAction_Relogin,
+		ErrorCode_InvalidClock:               ErrorAction_ResetClocks,
+		ErrorCode_Timeout:                    ErrorAction_Retry,
+		ErrorCode_MaxConnectionLimitExceeded: ErrorAction_Retry,
+		ErrorCode_SubscriptionLimitExceeded:  ErrorAction_Stop,
+		ErrorCode_InvalidAppKey:              ErrorAction_Stop,
+	}
+
+	for code, action := range tests {
+		if got := (StatusError{ErrorCode: code}).Action(); got != action {
+			t.Errorf("got action %s for %s, want %s", got, code, action)
+		}
+	}
+}
+
+func TestRequestErrors(t *testing.T) {
+	esaclient := NewESAClient("app_key", "session_token")
+
+	_, err := esaclient.Heartbeat()
+	if !errors.Is(err, ErrNotConnected) {
+		t.Errorf("got error %v before connecting, want %v", err, ErrNotConnected)
+	}
+
+	ts := newTestServer(t)
+	defer ts.close()
+	ts.ignore("heartbeat")
+
+	err = esaclient.Connect(context.Background(), ts.connConfig())
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	// The context deadline is honoured
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = esaclient.HeartbeatWithContext(ctx)
+	var requestErr RequestError
+	if !errors.As(err, &requestErr) || requestErr.Op != "heartbeat" || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want heartbeat RequestError with %v", err, context.DeadlineExceeded)
+	}
+
+	// Requests made with a context without deadline expire after the request timeout
+	if err = esaclient.SetRequestTimeout(200 * time.Millisecond); err != nil {
+		t.Fatalf("error setting request timeout - error: %s", err)
+	}
+	_, err = esaclient.HeartbeatWithContext(context.Background())
+	if !errors.Is(err, ErrRequestExpired) {
+		t.Errorf("got error %v, want %v", err, ErrRequestExpired)
+	}
+
+	// Discard the requests that expired
+	ts.waitRequest("heartbeat")
+	ts.waitRequest("heartbeat")
+
+	// Pending requests fail as soon as the connection is lost
+	if err = esaclient.SetRequestTimeout(10 * time.Second); err != nil {
+		t.Fatalf("error setting request timeout - error: %s", err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		_, err := esaclient.HeartbeatWithContext(context.Background())
+		errChan <- err
+	}()
+
+	ts.waitRequest("heartbeat")
+	ts.dropConnection()
+
+	select {
+	case err = <-errChan:
+		if !errors.Is(err, ErrConnectionLost) {
+			t.Errorf("got error %v, want %v", err, ErrConnectionLost)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pending request didn't fail after the connection was lost")
+	}
+}
@@ -0,0 +1,224 @@
+package exchangestream
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// StreamHandler gets the messages received by the ESAClient, as an alternative to consuming the public channels.
+// Each stream (market changes, order changes, status messages and connection events) is dispatched from its own
+// goroutine, so a slow handler for one stream doesn't hold back the others nor the request/response traffic.
+// Calls for the same stream are made one at a time and in the order the messages were received.
+// Up to 1000 messages are queued per stream, beyond that the BackpressureConfig policies apply to the market and
+// order changes (BackpressurePolicy_Block holds back everything, like it does with the channels), while the oldest
+// status messages and connection events are dropped.
+type StreamHandler interface {
+	OnMarketChange(mcm MarketChangeM)
+	OnOrderChange(ocm OrderChangeM)
+	// OnStatus gets every status message, both responses to requests and unsolicited errors
+	OnStatus(sm StatusM)
+	OnConnectionEvent(event ConnectionEvent)
+}
+
+// BaseStreamHandler implements StreamHandler doing nothing.
+// Embed it to only implement the callbacks needed.
+type BaseStreamHandler struct{}
+
+func (BaseStreamHandler) OnMarketChange(mcm MarketChangeM)        {}
+func (BaseStreamHandler) OnOrderChange(ocm OrderChangeM)          {}
+func (BaseStreamHandler) OnStatus(sm StatusM)                     {}
+func (BaseStreamHandler) OnConnectionEvent(event ConnectionEvent) {}
+
+// MarketChanges returns MCMChan
+func (esaclient *ESAClient) MarketChanges() <-chan MarketChangeM {
+	return esaclient.MCMChan
+}
+
+// OrderChanges returns OCMChan
+func (esaclient *ESAClient) OrderChanges() <-chan OrderChangeM {
+	return esaclient.OCMChan
+}
+
+// Errors returns ErrorChan
+func (esaclient *ESAClient) Errors() <-chan error {
+	return esaclient.ErrorChan
+}
+
+// SetHandler registers the handler that gets the messages received.
+// While a handler is registered, market and order changes are no longer sent to MCMChan and OCMChan,
+// and connection events are no longer sent to EventChan.
+// Passing nil unregisters the handler, messages still queued for the previous handler are dropped.
+func (esaclient *ESAClient) SetHandler(handler StreamHandler) {
+	var d *dispatcher
+	if handler != nil {
+		d = newDispatcher(handler, esaclient.backpressureCounters)
+	}
+
+	old := esaclient.dispatcher.Load().(*dispatcher)
+	esaclient.dispatcher.Store(d)
+
+	if old != nil {
+		old.stop()
+	}
+}
+
+// getDispatcher returns the dispatcher of the registered handler, nil if there is none
+func (esaclient *ESAClient) getDispatcher() *dispatcher {
+	return esaclient.dispatcher.Load().(*dispatcher)
+}
+
+// Largest number of messages queued for the handler per stream, the backpressure policy applies beyond it
+const dispatchQueueSize = 1000
+
+// dispatcher calls the handler from a goroutine per stream.
+// The goroutines only run while there are messages to dispatch, so none are left behind once the client disconnects
+// or the handler is replaced (other than one stuck in a handler call, until the call returns).
+type dispatcher struct {
+	market *dispatchQueue
+	order  *dispatchQueue
+	status *dispatchQueue
+	event  *dispatchQueue
+}
+
+func newDispatcher(handler StreamHandler, counters *backpressureCounters) *dispatcher {
+	d := &dispatcher{
+		market: newDispatchQueue(func(item interface{}) { handler.OnMarketChange(item.(MarketChangeM)) }),
+		order:  newDispatchQueue(func(item interface{}) { handler.OnOrderChange(item.(OrderChangeM)) }),
+		status: newDispatchQueue(func(item interface{}) { handler.OnStatus(item.(StatusM)) }),
+		event:  newDispatchQueue(func(item interface{}) { handler.OnConnectionEvent(item.(ConnectionEvent)) }),
+	}
+
+	d.market.op, d.market.dropped, d.market.coalesced = "mcm", &counters.marketDropped, &counters.marketCoalesced
+	d.market.merge = func(older interface{}, newer interface{}) interface{} {
+		c := marketCoalescer{}
+		c.add(older.(MarketChangeM))
+		c.add(newer.(MarketChangeM))
+		return *c.pending
+	}
+	d.order.op, d.order.dropped = "ocm", &counters.orderDropped
+
+	return d
+}
+
+func (d *dispatcher) stop() {
+	d.market.close()
+	d.order.close()
+	d.status.close()
+	d.event.close()
+}
+
+// dispatchQueue is a bounded FIFO queue consumed by a single goroutine, started when there is something to consume.
+// It's thread safe!
+type dispatchQueue struct {
+	mu      sync.Mutex
+	items   []interface{}
+	running bool
+	closed  bool
+	// Signalled (without blocking) every time an item is taken off the queue, so blocked pushes check again
+	space  chan struct{}
+	handle func(item interface{})
+
+	// Used when the queue is full: op goes in the BackpressureError, dropped and coalesced count the messages
+	// dropped and merged (nil if not counted) and merge merges two items (nil if they can't be merged)
+	op        string
+	dropped   *uint64
+	coalesced *uint64
+	merge     func(older interface{}, newer interface{}) interface{}
+}
+
+func newDispatchQueue(handle func(item interface{})) *dispatchQueue {
+	return &dispatchQueue{space: make(chan struct{}, 1), handle: handle}
+}
+
+// push adds an item to the queue, it's dropped if the queue is closed.
+// When the queue is full the policy applies: Block waits until there is room or ctx is done, DropOldest drops the
+// oldest item, Coalesce merges the item into the newest one and Disconnect returns a BackpressureError.
+func (q *dispatchQueue) push(ctx context.Context, item interface{}, policy BackpressurePolicy) error {
+	for {
+		q.mu.Lock()
+		if q.closed {
+			q.mu.Unlock()
+			return nil
+		}
+
+		if len(q.items) >= dispatchQueueSize {
+			switch {
+			case policy == BackpressurePolicy_DropOldest:
+				q.items[0] = nil
+				q.items = q.items[1:]
+				q.count(q.dropped)
+			case policy == BackpressurePolicy_Coalesce && q.merge != nil:
+				q.items[len(q.items)-1] = q.merge(q.items[len(q.items)-1], item)
+				q.count(q.coalesced)
+				q.mu.Unlock()
+				return nil
+			case policy == BackpressurePolicy_Disconnect:
+				q.mu.Unlock()
+				return BackpressureError{Op: q.op}
+			default:
+				q.mu.Unlock()
+				select {
+				case <-q.space:
+					continue
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+
+		q.items = append(q.items, item)
+		if !q.running {
+			q.running = true
+			go q.run()
+		}
+		q.mu.Unlock()
+		return nil
+	}
+}
+
+// count increments the counter, if there is one
+func (q *dispatchQueue) count(counter *uint64) {
+	if counter != nil {
+		atomic.AddUint64(counter, 1)
+	}
+}
+
+// len returns the number of items waiting in the queue
+func (q *dispatchQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// run calls handle for each item in the queue, until the queue is empty or closed
+func (q *dispatchQueue) run() {
+	for {
+		q.mu.Lock()
+		if len(q.items) == 0 || q.closed {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+
+		item := q.items[0]
+		q.items[0] = nil
+		q.items = q.items[1:]
+		q.mu.Unlock()
+
+		select {
+		case q.space <- struct{}{}:
+		default:
+		}
+
+		q.handle(item)
+	}
+}
+
+func (q *dispatchQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+	q.items = nil
+}
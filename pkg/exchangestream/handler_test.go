@@ -0,0 +1,256 @@
+package exchangestream
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHandler blocks on market changes until released and records everything else
+type testHandler struct {
+	BaseStreamHandler
+	release    chan struct{}
+	marketChan chan MarketChangeM
+	orderChan  chan OrderChangeM
+	statusChan chan StatusM
+	eventChan  chan ConnectionEvent
+}
+
+func newTestHandler() *testHandler {
+	return &testHandler{release: make(chan struct{}), marketChan: make(chan MarketChangeM, 2000),
+		orderChan: make(chan OrderChangeM, 10), statusChan: make(chan StatusM, 10), eventChan: make(chan ConnectionEvent, 10)}
+}
+
+func (h *testHandler) OnMarketChange(mcm MarketChangeM) {
+	<-h.release
+	h.marketChan <- mcm
+}
+
+func (h *testHandler) OnOrderChange(ocm OrderChangeM)          { h.orderChan <- ocm }
+func (h *testHandler) OnStatus(sm StatusM)                     { h.statusChan <- sm }
+func (h *testHandler) OnConnectionEvent(event ConnectionEvent) { h.eventChan <- event }
+
+func TestStreamHandler(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	handler := newTestHandler()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	esaclient.SetHandler(handler)
+	defer esaclient.SetHandler(nil)
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	select {
+	case event := <-handler.eventChan:
+		if event.To != ConnectionState_Connecting {
+			t.Errorf("got event %+v, want transition to CONNECTING", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for connection event")
+	}
+
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	select {
+	case <-handler.statusChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for authentication status message")
+	}
+
+	// As many market changes as the handler queue holds, while the market handler is stuck
+	for i := 0; i < dispatchQueueSize; i++ {
+		ts.send(fmt.Sprintf(`{"op":"mcm","id":1,"clk":"%d","pt":1594999999999}`, i))
+	}
+	ts.send(`{"op":"ocm","id":1,"clk":"clk1","pt":1594999999999}`)
+
+	select {
+	case ocm := <-handler.orderChan:
+		if ocm.Clk != "clk1" {
+			t.Errorf("got clk %s, want clk1", ocm.Clk)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("order change held back by the market handler")
+	}
+
+	sm, err := esaclient.Heartbeat()
+	if err != nil || sm.StatusCode != StatusCode_Success {
+		t.Fatalf("got (%+v, %v) for heartbeat, want success", sm, err)
+	}
+
+	select {
+	case status := <-handler.statusChan:
+		if status.ID == nil || status.StatusCode != StatusCode_Success {
+			t.Errorf("got status %+v, want heartbeat response", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for status message")
+	}
+
+	close(handler.release)
+
+	for i := 0; i < dispatchQueueSize; i++ {
+		select {
+		case mcm := <-handler.marketChan:
+			if mcm.Clk != fmt.Sprintf("%d", i) {
+				t.Fatalf("got clk %s, want %d", mcm.Clk, i)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for market change message %d", i)
+		}
+	}
+
+	if len(esaclient.MCMChan) != 0 || len(esaclient.OCMChan) != 0 {
+		t.Errorf("messages sent to the channels while a handler is registered")
+	}
+}
+
+func TestStreamHandlerBackpressure(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	handler := newTestHandler()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	if err := esaclient.SetBackpressure(BackpressureConfig{Market: BackpressurePolicy_DropOldest, Order: BackpressurePolicy_Block}); err != nil {
+		t.Fatalf("error setting backpressure - error: %s", err)
+	}
+	esaclient.SetHandler(handler)
+	defer esaclient.SetHandler(nil)
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	// The first message gets stuck in the handler
+	ts.send(`{"op":"mcm","id":1,"clk":"0","pt":1594999999999}`)
+	taken := func() bool {
+		q := esaclient.getDispatcher().market
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		return q.running && len(q.items) == 0
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !taken() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for the handler to get the first message")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The queue holds the latest ones and the others are dropped
+	total := dispatchQueueSize + 500
+	for i := 1; i < total; i++ {
+		ts.send(fmt.Sprintf(`{"op":"mcm","id":1,"clk":"%d","pt":1594999999999}`, i))
+	}
+	ts.send(`{"op":"ocm","id":1,"clk":"clk1","pt":1594999999999}`)
+
+	select {
+	case <-handler.orderChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("order change held back by the market handler")
+	}
+
+	stats := esaclient.BackpressureStats()
+	if stats.MarketQueueDepth != dispatchQueueSize || stats.MarketDropped != uint64(total-dispatchQueueSize-1) {
+		t.Errorf("got stats %+v, want a full queue and %d messages dropped", stats, total-dispatchQueueSize-1)
+	}
+
+	close(handler.release)
+
+	want := []string{"0"}
+	for i := total - dispatchQueueSize; i < total; i++ {
+		want = append(want, fmt.Sprintf("%d", i))
+	}
+	for _, clk := range want {
+		select {
+		case mcm := <-handler.marketChan:
+			if mcm.Clk != clk {
+				t.Fatalf("got clk %s, want %s", mcm.Clk, clk)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for market change message %s", clk)
+		}
+	}
+}
+
+// runningDispatchers returns the number of dispatcher goroutines still running, after giving them a moment to exit
+func runningDispatchers() int {
+	var running int
+	for i := 0; i < 20; i++ {
+		buf := make([]byte, 1<<20)
+		running = strings.Count(string(buf[:runtime.Stack(buf, true)]), "exchangestream.(*dispatchQueue).run(")
+		if running == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return running
+}
+
+func TestStreamHandlerNoLeaks(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	handler := newTestHandler()
+	close(handler.release)
+
+	esaclient := NewESAClient("app_key", "session_token")
+	esaclient.SetHandler(handler)
+	defer esaclient.SetHandler(nil)
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+	ts.send(`{"op":"mcm","id":1,"clk":"clk1","pt":1594999999999}`)
+	ts.send(`{"op":"ocm","id":1,"clk":"clk1","pt":1594999999999}`)
+	<-handler.marketChan
+	<-handler.orderChan
+
+	if err := esaclient.Disconnect(); err != nil {
+		t.Fatalf("error disconnecting - error: %s", err)
+	}
+	if running := runningDispatchers(); running != 0 {
+		t.Errorf("got %d dispatcher goroutines running after disconnecting, want 0", running)
+	}
+
+	// Replacing a handler stuck on a message, its goroutine exits once the call returns
+	stuck := newTestHandler()
+	esaclient.SetHandler(stuck)
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	ts.send(`{"op":"mcm","id":1,"clk":"clk1","pt":1594999999999}`)
+	ts.send(`{"op":"mcm","id":1,"clk":"clk2","pt":1594999999999}`)
+	for esaclient.BackpressureStats().MarketQueueDepth == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	esaclient.SetHandler(BaseStreamHandler{})
+	close(stuck.release)
+
+	if mcm := <-stuck.marketChan; mcm.Clk != "clk1" {
+		t.Errorf("got clk %s, want clk1", mcm.Clk)
+	}
+	if running := runningDispatchers(); running != 0 {
+		t.Errorf("got %d dispatcher goroutines running after replacing the handler, want 0", running)
+	}
+	if len(stuck.marketChan) != 0 {
+		t.Errorf("message queued for the replaced handler was dispatched")
+	}
+}
@@ -0,0 +1,125 @@
+// Package streammerge has the runner changes of the stream and merges them.
+// It's shared by exchangestream and the emulator, so both merge changes the same way, without it being part of
+// the public API. exchangestream exports the types under the same names.
+package streammerge
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+)
+
+// RunnerChange is the change to a runner of a market change message
+type RunnerChange struct {
+	TotalVolume *decimal.Money   `json:"tv,omitempty"`
+	BATB        []LevelPriceSize `json:"batb,omitempty"`
+	SPB         []PriceSize      `json:"spb,omitempty"`
+	BDATL       []LevelPriceSize `json:"bdatl,omitempty"`
+	TRD         []PriceSize      `json:"trd,omitempty"`
+	SPF         *decimal.Price   `json:"spf,omitempty"`
+	LTP         *decimal.Price   `json:"ltp,omitempty"`
+	ATB         []PriceSize      `json:"atb,omitempty"`
+	SPL         []PriceSize      `json:"spl,omitempty"`
+	SPN         *decimal.Price   `json:"spn,omitempty"`
+	ATL         []PriceSize      `json:"atl,omitempty"`
+	BATL        []LevelPriceSize `json:"batl,omitempty"`
+	ID          uint             `json:"id,omitempty"`
+	Handicap    *float64         `json:"hc,omitempty"`
+	BDATB       []LevelPriceSize `json:"bdatb,omitempty"`
+}
+
+// PriceSize is a price-keyed ladder entry, sent by betfair as a [price, size] json array
+type PriceSize struct {
+	Price decimal.Price
+	Size  decimal.Money
+}
+
+// MarshalJSON marshals PriceSize as a [price, size] json array
+func (ps PriceSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{ps.Price, ps.Size})
+}
+
+// UnmarshalJSON unmarshals a [price, size] json array
+func (ps *PriceSize) UnmarshalJSON(data []byte) error {
+	var elems [2][]byte
+	if err := splitNumberArray(data, elems[:]); err != nil {
+		return fmt.Errorf("expecting [price, size]: %w", err)
+	}
+
+	if err := ps.Price.UnmarshalJSON(elems[0]); err != nil {
+		return err
+	}
+
+	return ps.Size.UnmarshalJSON(elems[1])
+}
+
+// LevelPriceSize is a level-based ladder entry, sent by betfair as a [level, price, size] json array
+type LevelPriceSize struct {
+	Level uint
+	Price decimal.Price
+	Size  decimal.Money
+}
+
+// MarshalJSON marshals LevelPriceSize as a [level, price, size] json array
+func (lps LevelPriceSize) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{lps.Level, lps.Price, lps.Size})
+}
+
+// UnmarshalJSON unmarshals a [level, price, size] json array
+func (lps *LevelPriceSize) UnmarshalJSON(data []byte) error {
+	var elems [3][]byte
+	if err := splitNumberArray(data, elems[:]); err != nil {
+		return fmt.Errorf("expecting [level, price, size]: %w", err)
+	}
+
+	level, err := strconv.ParseUint(string(elems[0]), 10, 0)
+	if err != nil {
+		return err
+	}
+	lps.Level = uint(level)
+
+	if err := lps.Price.UnmarshalJSON(elems[1]); err != nil {
+		return err
+	}
+
+	return lps.Size.UnmarshalJSON(elems[2])
+}
+
+// splitNumberArray splits a json array of numbers (or strings without commas) into elems, without allocating.
+// Ladder entries are by far the most common values in the stream, so they skip the json decoder.
+func splitNumberArray(data []byte, elems [][]byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '[' || data[len(data)-1] != ']' {
+		return fmt.Errorf("not an array: %s", data)
+	}
+	data = data[1 : len(data)-1]
+
+	n := 0
+	for {
+		i := bytes.IndexByte(data, ',')
+		elem := data
+		if i >= 0 {
+			elem = data[:i]
+		}
+
+		elem = bytes.TrimSpace(elem)
+		if len(elem) == 0 || bytes.ContainsAny(elem, "[]{}") || n == len(elems) {
+			return fmt.Errorf("expecting %d numbers", len(elems))
+		}
+		elems[n] = elem
+		n++
+
+		if i < 0 {
+			break
+		}
+		data = data[i+1:]
+	}
+
+	if n != len(elems) {
+		return fmt.Errorf("expecting %d numbers, got %d", len(elems), n)
+	}
+	return nil
+}
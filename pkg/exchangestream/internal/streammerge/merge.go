@@ -0,0 +1,152 @@
+package streammerge
+
+import "github.com/gustavooferreira/betfair/pkg/decimal"
+
+// MergeRunnerChanges applies the newer changes on top of the older ones, runner by runner.
+// Ladder entries with size zero are kept, as they remove the price from the consumer's view.
+func MergeRunnerChanges(older []RunnerChange, newer []RunnerChange) []RunnerChange {
+	result := append([]RunnerChange{}, older...)
+	for _, rc := range newer {
+		merged := false
+		for i := range result {
+			if sameRunner(result[i], rc) {
+				result[i] = mergeRunnerChange(result[i], rc)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			result = append(result, rc)
+		}
+	}
+	return result
+}
+
+// sameRunner tells if both changes are to the same runner.
+// Runners are identified by selection and handicap, a missing handicap is zero (as in the market cache).
+func sameRunner(a RunnerChange, b RunnerChange) bool {
+	return a.ID == b.ID && handicap(a) == handicap(b)
+}
+
+func handicap(rc RunnerChange) float64 {
+	if rc.Handicap == nil {
+		return 0
+	}
+	return *rc.Handicap
+}
+
+// CompactRunnerChanges drops the ladder entries with size zero, for images: an image only lists the prices
+// available, as betfair sends it
+func CompactRunnerChanges(rcs []RunnerChange) []RunnerChange {
+	result := make([]RunnerChange, len(rcs))
+	for i, rc := range rcs {
+		rc.ATB = CompactPriceSizes(rc.ATB)
+		rc.ATL = CompactPriceSizes(rc.ATL)
+		rc.TRD = CompactPriceSizes(rc.TRD)
+		rc.SPB = CompactPriceSizes(rc.SPB)
+		rc.SPL = CompactPriceSizes(rc.SPL)
+		rc.BATB = compactLevelPriceSizes(rc.BATB)
+		rc.BATL = compactLevelPriceSizes(rc.BATL)
+		rc.BDATB = compactLevelPriceSizes(rc.BDATB)
+		rc.BDATL = compactLevelPriceSizes(rc.BDATL)
+		result[i] = rc
+	}
+	return result
+}
+
+// CompactPriceSizes drops the entries with size zero
+func CompactPriceSizes(pss []PriceSize) []PriceSize {
+	var result []PriceSize
+	for _, ps := range pss {
+		if !ps.Size.IsZero() {
+			result = append(result, ps)
+		}
+	}
+	return result
+}
+
+func compactLevelPriceSizes(lpss []LevelPriceSize) []LevelPriceSize {
+	var result []LevelPriceSize
+	for _, lps := range lpss {
+		if !lps.Size.IsZero() {
+			result = append(result, lps)
+		}
+	}
+	return result
+}
+
+// mergeRunnerChange applies the newer change on top of the older one.
+// Ladder entries with size zero are kept, as they remove the price from the consumer's view.
+func mergeRunnerChange(older RunnerChange, newer RunnerChange) RunnerChange {
+	result := older
+	if newer.TotalVolume != nil {
+		result.TotalVolume = newer.TotalVolume
+	}
+	if newer.LTP != nil {
+		result.LTP = newer.LTP
+	}
+	if newer.SPN != nil {
+		result.SPN = newer.SPN
+	}
+	if newer.SPF != nil {
+		result.SPF = newer.SPF
+	}
+
+	result.ATB = MergePriceSizes(older.ATB, newer.ATB)
+	result.ATL = MergePriceSizes(older.ATL, newer.ATL)
+	result.TRD = MergePriceSizes(older.TRD, newer.TRD)
+	result.SPB = MergePriceSizes(older.SPB, newer.SPB)
+	result.SPL = MergePriceSizes(older.SPL, newer.SPL)
+	result.BATB = mergeLevelPriceSizes(older.BATB, newer.BATB)
+	result.BATL = mergeLevelPriceSizes(older.BATL, newer.BATL)
+	result.BDATB = mergeLevelPriceSizes(older.BDATB, newer.BDATB)
+	result.BDATL = mergeLevelPriceSizes(older.BDATL, newer.BDATL)
+
+	return result
+}
+
+// MergePriceSizes replaces the sizes by price
+func MergePriceSizes(older []PriceSize, newer []PriceSize) []PriceSize {
+	if len(newer) == 0 {
+		return older
+	}
+
+	result := append([]PriceSize{}, older...)
+	index := make(map[decimal.Price]int, len(result))
+	for i, ps := range result {
+		index[ps.Price] = i
+	}
+
+	for _, ps := range newer {
+		if i, ok := index[ps.Price]; ok {
+			result[i] = ps
+		} else {
+			index[ps.Price] = len(result)
+			result = append(result, ps)
+		}
+	}
+	return result
+}
+
+// mergeLevelPriceSizes replaces the prices and sizes by level
+func mergeLevelPriceSizes(older []LevelPriceSize, newer []LevelPriceSize) []LevelPriceSize {
+	if len(newer) == 0 {
+		return older
+	}
+
+	result := append([]LevelPriceSize{}, older...)
+	index := make(map[uint]int, len(result))
+	for i, lps := range result {
+		index[lps.Level] = i
+	}
+
+	for _, lps := range newer {
+		if i, ok := index[lps.Level]; ok {
+			result[i] = lps
+		} else {
+			index[lps.Level] = len(result)
+			result = append(result, lps)
+		}
+	}
+	return result
+}
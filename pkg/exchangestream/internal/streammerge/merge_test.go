@@ -0,0 +1,50 @@
+package streammerge
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMergeRunnerChangesHandicap(t *testing.T) {
+	tests := map[string]struct {
+		older string
+		newer string
+		want  string
+	}{
+		"no handicap": {
+			older: `[{"id":1,"ltp":2}]`,
+			newer: `[{"id":1,"ltp":3}]`,
+			want:  `[{"ltp":3,"id":1}]`,
+		},
+		"missing handicap is zero": {
+			older: `[{"id":1,"ltp":2}]`,
+			newer: `[{"id":1,"hc":0,"ltp":3}]`,
+			want:  `[{"ltp":3,"id":1}]`,
+		},
+		"different handicaps": {
+			older: `[{"id":1,"ltp":2}]`,
+			newer: `[{"id":1,"hc":0.5,"ltp":3}]`,
+			want:  `[{"ltp":2,"id":1},{"ltp":3,"id":1,"hc":0.5}]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var older, newer []RunnerChange
+			if err := json.Unmarshal([]byte(test.older), &older); err != nil {
+				t.Fatalf("error unmarshalling older changes - error: %s", err)
+			}
+			if err := json.Unmarshal([]byte(test.newer), &newer); err != nil {
+				t.Fatalf("error unmarshalling newer changes - error: %s", err)
+			}
+
+			got, err := json.Marshal(MergeRunnerChanges(older, newer))
+			if err != nil {
+				t.Fatalf("error marshalling merged changes - error: %s", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
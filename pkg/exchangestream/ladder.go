@@ -0,0 +1,23 @@
+package exchangestream
+
+import (
+	"fmt"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/ladder"
+)
+
+// PriceLadder returns the price ladder defined by the market definition.
+// LINE_RANGE ladders are built from the lineMinUnit, lineMaxUnit and lineInterval fields.
+func (md MarketDefinition) PriceLadder() (ladder.Ladder, error) {
+	switch md.PriceLadderDefinition.Type {
+	case PriceLadderType_Classic:
+		return ladder.Classic(), nil
+	case PriceLadderType_Finest:
+		return ladder.Finest(), nil
+	case PriceLadderType_LineRange:
+		return ladder.LineRange(decimal.NewPrice(md.LineMinUnit), decimal.NewPrice(md.LineMaxUnit), decimal.NewPrice(md.LineInterval))
+	}
+
+	return ladder.Ladder{}, fmt.Errorf("unknown price ladder type %s", md.PriceLadderDefinition.Type)
+}
@@ -0,0 +1,576 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Betfair default limit of markets per subscription
+const defaultMarketsPerConnection = 200
+
+// ErrPoolFull is returned when the markets requested don't fit in the connections the pool is allowed to open
+var ErrPoolFull = errors.New("not enough connections available in the pool")
+
+// PoolConfig configures how the StreamPool spreads markets across connections
+type PoolConfig struct {
+	// MaxConnections is the maximum number of connections the pool opens (betfair limits connections per app key)
+	MaxConnections int
+	// MarketsPerConnection is the maximum number of markets subscribed on each connection (200 by default)
+	MarketsPerConnection int
+}
+
+// ConnectionHealth describes one of the connections of the pool
+type ConnectionHealth struct {
+	ConnectionID   string
+	State          ConnectionState
+	SubscriptionID uint32
+	MarketIDs      []string
+}
+
+// poolMember is one of the connections of the pool
+type poolMember struct {
+	client  *ESAClient
+	manager *SubscriptionManager
+	markets map[string]bool
+	// Copy of markets read by forward, changes of the markets moved to another connection are dropped
+	// (holds a map[string]bool)
+	owned atomic.Value
+	// Stops the goroutine forwarding the change messages into the pool channels
+	stopChan chan struct{}
+}
+
+// addMarkets assigns the markets to the connection (must hold the pool mu)
+func (member *poolMember) addMarkets(ids ...string) {
+	for _, id := range ids {
+		member.markets[id] = true
+	}
+	member.publish()
+}
+
+// removeMarkets takes the markets off the connection (must hold the pool mu)
+func (member *poolMember) removeMarkets(ids ...string) {
+	for _, id := range ids {
+		delete(member.markets, id)
+	}
+	member.publish()
+}
+
+// publish makes the markets of the connection visible to forward (must hold the pool mu)
+func (member *poolMember) publish() {
+	owned := make(map[string]bool, len(member.markets))
+	for id := range member.markets {
+		owned[id] = true
+	}
+	member.owned.Store(owned)
+}
+
+// filter drops the changes of the markets the connection doesn't hold, returns false if nothing is left.
+// Once a market is moved, its changes still coming from the old connection would follow the image sent by the new one.
+func (member *poolMember) filter(mcm MarketChangeM) (MarketChangeM, bool) {
+	if len(mcm.MarketChanges) == 0 {
+		return mcm, true
+	}
+
+	owned := member.owned.Load().(map[string]bool)
+	changes := make([]MarketChange, 0, len(mcm.MarketChanges))
+	for _, mc := range mcm.MarketChanges {
+		if owned[mc.ID] {
+			changes = append(changes, mc)
+		}
+	}
+
+	if len(changes) == 0 {
+		return mcm, false
+	} else if len(changes) != len(mcm.MarketChanges) {
+		mcm.MarketChanges = changes
+	}
+	return mcm, true
+}
+
+// StreamPool manages several ESAClient connections to go over the number of markets betfair allows per subscription.
+// Markets are sharded across the connections: new markets go to the least loaded connection and a new connection
+// is only opened when all the others are full. Removing markets rebalances the pool: connections that are no longer
+// needed are emptied into the others and closed, and markets are moved off the connections holding more than their
+// share.
+// Change messages from every connection are merged into MCMChan and OCMChan. Message IDs are only unique within
+// a connection, the messages are tagged with the connection they came from (MarketChangeM.ConnectionID).
+// It's thread safe!
+type StreamPool struct {
+	appKey       string
+	sessionToken string
+	connConfig   ConnectionConfig
+	poolConfig   PoolConfig
+	// Subscription settings used on every connection
+	template      MarketSubscriptionMessage
+	tokenProvider TokenProvider
+
+	// Guards members and serializes subscription changes
+	mu      sync.Mutex
+	members []*poolMember
+	// Connection holding the order subscription (nil if there is none)
+	orderMember *poolMember
+	wg          sync.WaitGroup
+
+	// Change Streams (merged from all connections)
+	// Public channel
+	MCMChan chan MarketChangeM
+	OCMChan chan OrderChangeM
+}
+
+// NewStreamPool creates a new StreamPool. Connections are opened as markets are added.
+// msm holds the subscription settings used on every connection, its market IDs are ignored.
+func NewStreamPool(appKey string, sessionToken string, connConfig ConnectionConfig, poolConfig PoolConfig,
+	msm MarketSubscriptionMessage) *StreamPool {
+
+	if poolConfig.MarketsPerConnection <= 0 {
+		poolConfig.MarketsPerConnection = defaultMarketsPerConnection
+	}
+	if poolConfig.MaxConnections <= 0 {
+		poolConfig.MaxConnections = 1
+	}
+
+	msm.MarketFilter.MarketIDs = nil
+
+	return &StreamPool{appKey: appKey, sessionToken: sessionToken, connConfig: connConfig, poolConfig: poolConfig,
+		template: msm, MCMChan: make(chan MarketChangeM, 1000), OCMChan: make(chan OrderChangeM, 1000)}
+}
+
+// SetTokenProvider sets the token provider used by every connection of the pool (see ESAClient.SetTokenProvider)
+func (pool *StreamPool) SetTokenProvider(tp TokenProvider) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.tokenProvider = tp
+	for _, member := range pool.members {
+		member.client.SetTokenProvider(tp)
+	}
+}
+
+// AddMarkets subscribes to the markets, opening new connections if needed.
+// Returns ErrPoolFull, without subscribing to any market, if they don't fit in the pool.
+func (pool *StreamPool) AddMarkets(ctx context.Context, marketIDs ...string) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	newIDs := []string{}
+	for _, id := range mergeIDs(nil, marketIDs) {
+		if pool.memberOf(id) == nil {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(newIDs) == 0 {
+		return nil
+	}
+
+	capacity := pool.poolConfig.MarketsPerConnection * (pool.poolConfig.MaxConnections - len(pool.members))
+	for _, member := range pool.members {
+		capacity += pool.poolConfig.MarketsPerConnection - len(member.markets)
+	}
+	if len(newIDs) > capacity {
+		return ErrPoolFull
+	}
+
+	// Assign the markets to the least loaded connections, opening new ones when all are full
+	assignment := map[*poolMember][]string{}
+	load := map[*poolMember]int{}
+	for _, member := range pool.members {
+		load[member] = len(member.markets)
+	}
+
+	for _, id := range newIDs {
+		member := pool.leastLoaded(load)
+		if member == nil {
+			var err error
+			if member, err = pool.connect(ctx); err != nil {
+				pool.closeIdle()
+				return err
+			}
+			load[member] = 0
+		}
+		assignment[member] = append(assignment[member], id)
+		load[member]++
+	}
+
+	for _, member := range pool.members {
+		ids, ok := assignment[member]
+		if !ok {
+			continue
+		}
+
+		// Assigned before subscribing, so the images aren't filtered out
+		member.addMarkets(ids...)
+		if _, err := member.manager.AddMarkets(ctx, ids...); err != nil {
+			member.removeMarkets(ids...)
+			pool.closeIdle()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeIdle closes the connections without markets nor order subscription (must hold mu)
+func (pool *StreamPool) closeIdle() {
+	for _, member := range append([]*poolMember{}, pool.members...) {
+		if len(member.markets) == 0 && member != pool.orderMember {
+			pool.close(member)
+		}
+	}
+}
+
+// RemoveMarkets unsubscribes from the markets, closing the connections left without markets, and rebalances the
+// markets left across the connections.
+func (pool *StreamPool) RemoveMarkets(ctx context.Context, marketIDs ...string) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	removal := map[*poolMember][]string{}
+	for _, id := range marketIDs {
+		if member := pool.memberOf(id); member != nil {
+			removal[member] = append(removal[member], id)
+		}
+	}
+
+	var firstErr error
+	for _, member := range append([]*poolMember{}, pool.members...) {
+		ids, ok := removal[member]
+		if !ok {
+			continue
+		}
+
+		if len(ids) == len(member.markets) && member != pool.orderMember {
+			pool.close(member)
+			continue
+		}
+
+		if _, err := member.manager.RemoveMarkets(ctx, ids...); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		member.removeMarkets(ids...)
+	}
+
+	if err := pool.rebalance(ctx); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	return firstErr
+}
+
+// rebalance moves markets between the connections (must hold mu).
+// While the markets fit in fewer connections, the least loaded connection is emptied into the others and closed
+// (the one holding the order subscription is kept open). Then markets are moved off the connections holding more
+// than their share, so every connection ends up with about the same number of markets.
+// A market is subscribed on its new connection before being removed from the old one.
+func (pool *StreamPool) rebalance(ctx context.Context) error {
+	total := 0
+	load := map[*poolMember]int{}
+	// Markets of each connection once the moves are done, sorted
+	markets := map[*poolMember][]string{}
+	for _, member := range pool.members {
+		load[member] = len(member.markets)
+		total += len(member.markets)
+		for id := range member.markets {
+			markets[member] = append(markets[member], id)
+		}
+		sort.Strings(markets[member])
+	}
+	if total == 0 {
+		return nil
+	}
+
+	// Connection each market is moved to
+	moves := map[string]*poolMember{}
+	move := func(from *poolMember, to *poolMember) {
+		ids := markets[from]
+		id := ids[len(ids)-1]
+		markets[from] = ids[:len(ids)-1]
+		markets[to] = append(markets[to], id)
+		load[from]--
+		load[to]++
+		moves[id] = to
+	}
+
+	active := append([]*poolMember{}, pool.members...)
+	needed := (total + pool.poolConfig.MarketsPerConnection - 1) / pool.poolConfig.MarketsPerConnection
+	for len(active) > needed {
+		// The newest of the least loaded connections is emptied
+		var drained *poolMember
+		for _, member := range active {
+			if member != pool.orderMember && (drained == nil || load[member] <= load[drained]) {
+				drained = member
+			}
+		}
+		if drained == nil {
+			break
+		}
+
+		remaining := []*poolMember{}
+		for _, member := range active {
+			if member != drained {
+				remaining = append(remaining, member)
+			}
+		}
+		for load[drained] > 0 {
+			move(drained, leastLoadedOf(remaining, load))
+		}
+		active = remaining
+	}
+
+	share := (total + len(active) - 1) / len(active)
+	for _, member := range active {
+		for load[member] > share {
+			move(member, leastLoadedOf(active, load))
+		}
+	}
+
+	// Subscribe the markets on their new connections, the old ones stop forwarding their changes right away
+	sources := map[*poolMember][]string{}
+	var firstErr error
+	for _, to := range pool.members {
+		ids := []string{}
+		for id, member := range moves {
+			// A market can end up back where it was
+			if member == to && pool.memberOf(id) != to {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		sort.Strings(ids)
+
+		from := map[string]*poolMember{}
+		for _, id := range ids {
+			from[id] = pool.memberOf(id)
+			from[id].removeMarkets(id)
+		}
+		to.addMarkets(ids...)
+
+		if _, err := to.manager.AddMarkets(ctx, ids...); err != nil {
+			to.removeMarkets(ids...)
+			for _, id := range ids {
+				from[id].addMarkets(id)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		for _, id := range ids {
+			sources[from[id]] = append(sources[from[id]], id)
+		}
+	}
+
+	// Then remove them from the old connections, closing the ones left empty.
+	// If the removal fails the market stays subscribed there, but its changes are no longer forwarded.
+	for _, member := range append([]*poolMember{}, pool.members...) {
+		ids, ok := sources[member]
+		if !ok {
+			continue
+		}
+
+		if len(member.markets) == 0 && member != pool.orderMember {
+			pool.close(member)
+			continue
+		}
+
+		if _, err := member.manager.RemoveMarkets(ctx, ids...); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// OrderSubscribe subscribes to orders on one of the connections (the order stream isn't sharded)
+func (pool *StreamPool) OrderSubscribe(ctx context.Context, osm OrderSubscriptionMessage) (StatusMessage, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	member := pool.orderMember
+	if member == nil {
+		if len(pool.members) != 0 {
+			member = pool.members[0]
+		} else {
+			var err error
+			if member, err = pool.connect(ctx); err != nil {
+				return StatusMessage{}, err
+			}
+		}
+	}
+
+	sm, err := member.client.OrderSubscribeWithContext(ctx, osm)
+	if err == nil && sm.StatusCode != StatusCode_Success {
+		err = NewStatusError(sm)
+	}
+	if err != nil {
+		pool.closeIdle()
+		return sm, err
+	}
+
+	pool.orderMember = member
+	return sm, nil
+}
+
+// Health returns the state of every connection of the pool
+func (pool *StreamPool) Health() []ConnectionHealth {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	result := []ConnectionHealth{}
+	for _, member := range pool.members {
+		_, _, connID, _ := member.client.GetSessionInfo()
+
+		ids := []string{}
+		for id := range member.markets {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		result = append(result, ConnectionHealth{ConnectionID: connID, State: member.client.State(),
+			SubscriptionID: member.manager.SubscriptionID(), MarketIDs: ids})
+	}
+	return result
+}
+
+// Close disconnects every connection of the pool
+func (pool *StreamPool) Close() {
+	pool.mu.Lock()
+	for _, member := range append([]*poolMember{}, pool.members...) {
+		pool.close(member)
+	}
+	pool.mu.Unlock()
+
+	pool.wg.Wait()
+}
+
+// connect opens and authenticates a new connection and adds it to the pool (must hold mu)
+func (pool *StreamPool) connect(ctx context.Context) (*poolMember, error) {
+	client := NewESAClient(pool.appKey, pool.sessionToken)
+	client.SetTokenProvider(pool.tokenProvider)
+
+	if err := client.Connect(ctx, pool.connConfig); err != nil {
+		return nil, err
+	}
+
+	sm, err := client.AuthenticateWithContext(ctx)
+	if err == nil && sm.StatusCode != StatusCode_Success {
+		err = NewStatusError(sm)
+	}
+	if err != nil {
+		client.Disconnect()
+		return nil, err
+	}
+
+	member := &poolMember{client: &client, manager: NewSubscriptionManager(&client, pool.template),
+		markets: map[string]bool{}, stopChan: make(chan struct{})}
+	member.publish()
+	pool.members = append(pool.members, member)
+
+	pool.wg.Add(1)
+	go pool.forward(member)
+
+	return member, nil
+}
+
+// close disconnects the connection and removes it from the pool (must hold mu)
+func (pool *StreamPool) close(member *poolMember) {
+	close(member.stopChan)
+
+	// forward is gone, discard the messages still coming in so the connection doesn't block on its full channels
+	// while disconnecting
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-member.client.MCMChan:
+			case <-member.client.OCMChan:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	member.client.Disconnect()
+	close(done)
+
+	for i, m := range pool.members {
+		if m == member {
+			pool.members = append(pool.members[:i], pool.members[i+1:]...)
+			break
+		}
+	}
+	if pool.orderMember == member {
+		pool.orderMember = nil
+	}
+}
+
+// forward merges the change messages of a connection into the pool channels
+func (pool *StreamPool) forward(member *poolMember) {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case <-member.stopChan:
+			return
+		case mcm := <-member.client.MCMChan:
+			mcm, ok := member.filter(mcm)
+			if !ok {
+				continue
+			}
+			select {
+			case pool.MCMChan <- mcm:
+			case <-member.stopChan:
+				return
+			}
+		case ocm := <-member.client.OCMChan:
+			select {
+			case pool.OCMChan <- ocm:
+			case <-member.stopChan:
+				return
+			}
+		}
+	}
+}
+
+// memberOf returns the connection subscribed to the market, nil if none (must hold mu)
+func (pool *StreamPool) memberOf(marketID string) *poolMember {
+	for _, member := range pool.members {
+		if member.markets[marketID] {
+			return member
+		}
+	}
+	return nil
+}
+
+// leastLoadedOf returns the connection with less markets
+func leastLoadedOf(members []*poolMember, load map[*poolMember]int) *poolMember {
+	var result *poolMember
+	for _, member := range members {
+		if result == nil || load[member] < load[result] {
+			result = member
+		}
+	}
+	return result
+}
+
+// leastLoaded returns the connection with less markets that still has room, nil if all are full (must hold mu)
+func (pool *StreamPool) leastLoaded(load map[*poolMember]int) *poolMember {
+	var result *poolMember
+	for _, member := range pool.members {
+		if load[member] >= pool.poolConfig.MarketsPerConnection {
+			continue
+		}
+		if result == nil || load[member] < load[result] {
+			result = member
+		}
+	}
+	return result
+}
@@ -0,0 +1,197 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamPool(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	pool := NewStreamPool("app_key", "session_token", ts.connConfig(), PoolConfig{MaxConnections: 2, MarketsPerConnection: 2},
+		MarketSubscriptionMessage{})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	if err := pool.AddMarkets(ctx, "1.1", "1.2", "1.3"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+
+	health := pool.Health()
+	if len(health) != 2 {
+		t.Fatalf("got %d connections, want 2", len(health))
+	}
+	if !reflect.DeepEqual(health[0].MarketIDs, []string{"1.1", "1.2"}) || !reflect.DeepEqual(health[1].MarketIDs, []string{"1.3"}) {
+		t.Errorf("got markets %v and %v, want [1.1 1.2] and [1.3]", health[0].MarketIDs, health[1].MarketIDs)
+	}
+	for _, h := range health {
+		if h.State != ConnectionState_Subscribed || h.ConnectionID == "" || h.SubscriptionID == 0 {
+			t.Errorf("got connection %+v, want subscribed", h)
+		}
+	}
+
+	if err := pool.AddMarkets(ctx, "1.4", "1.5"); !errors.Is(err, ErrPoolFull) {
+		t.Errorf("got error %v adding markets over the limit, want %v", err, ErrPoolFull)
+	}
+	if len(pool.Health()[1].MarketIDs) != 1 {
+		t.Errorf("markets subscribed even though they didn't fit in the pool")
+	}
+
+	// Messages from every connection are merged
+	ts.send(`{"op":"mcm","id":2,"clk":"clk1","pt":1594999999999,"mc":[]}`)
+
+	select {
+	case mcm := <-pool.MCMChan:
+		if mcm.Clk != "clk1" {
+			t.Errorf("got clk %s, want clk1", mcm.Clk)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for market change message")
+	}
+
+	// Connections left without markets are closed
+	if err := pool.RemoveMarkets(ctx, "1.3"); err != nil {
+		t.Fatalf("error removing markets - error: %s", err)
+	}
+	if health = pool.Health(); len(health) != 1 {
+		t.Fatalf("got %d connections after removing markets, want 1", len(health))
+	}
+
+	if err := pool.RemoveMarkets(ctx, "1.1"); err != nil {
+		t.Fatalf("error removing markets - error: %s", err)
+	}
+	if err := pool.AddMarkets(ctx, "1.4", "1.5"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+
+	health = pool.Health()
+	if len(health) != 2 || !reflect.DeepEqual(health[0].MarketIDs, []string{"1.2", "1.4"}) ||
+		!reflect.DeepEqual(health[1].MarketIDs, []string{"1.5"}) {
+		t.Errorf("got connections %+v, want markets [1.2 1.4] and [1.5]", health)
+	}
+}
+
+func TestStreamPoolRebalance(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	pool := NewStreamPool("app_key", "session_token", ts.connConfig(), PoolConfig{MaxConnections: 2, MarketsPerConnection: 2},
+		MarketSubscriptionMessage{})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	if err := pool.AddMarkets(ctx, "1.1", "1.2", "1.3", "1.4"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+	if err := pool.RemoveMarkets(ctx, "1.1", "1.3"); err != nil {
+		t.Fatalf("error removing markets - error: %s", err)
+	}
+
+	// The markets left fit in one connection, the other one is emptied and closed
+	health := pool.Health()
+	if len(health) != 1 || !reflect.DeepEqual(health[0].MarketIDs, []string{"1.2", "1.4"}) {
+		t.Errorf("got connections %+v, want markets [1.2 1.4] on one connection", health)
+	}
+}
+
+func TestStreamPoolRebalanceShare(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	pool := NewStreamPool("app_key", "session_token", ts.connConfig(), PoolConfig{MaxConnections: 2, MarketsPerConnection: 4},
+		MarketSubscriptionMessage{})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	if err := pool.AddMarkets(ctx, "1.1", "1.2", "1.3", "1.4", "1.5", "1.6", "1.7", "1.8"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+	if err := pool.RemoveMarkets(ctx, "1.5", "1.6", "1.7"); err != nil {
+		t.Fatalf("error removing markets - error: %s", err)
+	}
+
+	// A market is moved off the overloaded connection
+	health := pool.Health()
+	if len(health) != 2 || !reflect.DeepEqual(health[0].MarketIDs, []string{"1.1", "1.2", "1.3"}) ||
+		!reflect.DeepEqual(health[1].MarketIDs, []string{"1.4", "1.8"}) {
+		t.Fatalf("got connections %+v, want markets [1.1 1.2 1.3] and [1.4 1.8]", health)
+	}
+
+	// The changes of the market moved are only taken from its new connection, tagged with the connection
+	ts.sendTo(0, `{"op":"mcm","id":3,"clk":"clk1","pt":1594999999999,"mc":[{"id":"1.4"},{"id":"1.1"}]}`)
+	ts.sendTo(0, `{"op":"mcm","id":3,"clk":"clk2","pt":1594999999999,"mc":[{"id":"1.4"}]}`)
+	ts.sendTo(1, `{"op":"mcm","id":3,"clk":"clk3","pt":1594999999999,"mc":[{"id":"1.4"}]}`)
+
+	got := map[string]string{}
+	for len(got) < 2 {
+		select {
+		case mcm := <-pool.MCMChan:
+			var ids []string
+			for _, mc := range mcm.MarketChanges {
+				ids = append(ids, mc.ID)
+			}
+			got[mcm.Clk] = mcm.ConnectionID + " " + strings.Join(ids, ",")
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for market change messages, got %v", got)
+		}
+	}
+
+	want := map[string]string{"clk1": "001-1 1.1", "clk3": "001-2 1.4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got messages %v, want %v", got, want)
+	}
+}
+
+func TestStreamPoolCloseUndrained(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	pool := NewStreamPool("app_key", "session_token", ts.connConfig(), PoolConfig{MaxConnections: 1},
+		MarketSubscriptionMessage{})
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	if err := pool.AddMarkets(ctx, "1.1"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+
+	// Nobody reads the pool channel, the connection ends up blocked on its own full channel
+	for i := 0; i < cap(pool.MCMChan)+1100; i++ {
+		ts.send(`{"op":"mcm","id":2,"clk":"clk1","pt":1594999999999,"mc":[]}`)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(pool.MCMChan) < cap(pool.MCMChan) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for the pool channel to fill up, got %d messages", len(pool.MCMChan))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- pool.RemoveMarkets(ctx, "1.1")
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("error removing markets - error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout closing a connection nobody drains")
+	}
+
+	if health := pool.Health(); len(health) != 0 {
+		t.Errorf("got %d connections, want 0", len(health))
+	}
+}
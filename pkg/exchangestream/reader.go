@@ -0,0 +1,230 @@
+package exchangestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Default sizes of the reader buffer
+const (
+	defaultReaderBufferSize = 64 * 1024
+	defaultMaxMessageSize   = 64 * 1024 * 1024
+)
+
+// ErrMessageTooLarge is reported when a message goes over the maximum message size, the message is skipped
+var ErrMessageTooLarge = errors.New("message too large")
+
+// SetMaxMessageSize sets the largest message accepted from the server (64MB by default).
+// Larger messages are skipped and ErrMessageTooLarge is reported on ErrorChan. Applied on the next connection.
+func (esaclient *ESAClient) SetMaxMessageSize(size uint32) error {
+	if size < defaultReaderBufferSize {
+		return fmt.Errorf("maximum message size needs to be at least %d bytes", defaultReaderBufferSize)
+	}
+	atomic.StoreUint32(&esaclient.maxMessageSize, size)
+	return nil
+}
+
+// frameReader splits the stream into messages delimited by CRLF.
+// The buffer starts small and grows up to the maximum message size, as needed.
+// The messages returned point into the buffer (no copies are made) and are only valid until the next call.
+type frameReader struct {
+	r   io.Reader
+	buf []byte
+	// Beginning of the next message
+	start int
+	// End of the data read
+	end int
+	// There is no newline between start and scanned, no need to look there again
+	scanned int
+	maxSize int
+	// Skipping an oversized message until its end
+	discarding bool
+	// Error returned by Read alongside data, reported once the data is consumed
+	err error
+	// Called with the number of bytes read
+	onRead func(n int)
+}
+
+func newFrameReader(r io.Reader, bufferSize int, maxSize int) *frameReader {
+	if bufferSize > maxSize {
+		bufferSize = maxSize
+	}
+	return &frameReader{r: r, buf: make([]byte, bufferSize), maxSize: maxSize}
+}
+
+// next returns the next message, without the delimiter.
+// Returns ErrMessageTooLarge (once per message) when a message goes over the maximum size, and any error from
+// the underlying reader, in which case calling next again resumes where it left off.
+func (fr *frameReader) next() ([]byte, error) {
+	for {
+		if i := bytes.IndexByte(fr.buf[fr.scanned:fr.end], '\n'); i >= 0 {
+			i += fr.scanned
+			frame := fr.buf[fr.start:i]
+			fr.start = i + 1
+			fr.scanned = fr.start
+
+			if fr.discarding {
+				fr.discarding = false
+				continue
+			}
+
+			if n := len(frame); n > 0 && frame[n-1] == '\r' {
+				frame = frame[:n-1]
+			}
+			if len(frame) == 0 {
+				continue
+			}
+			return frame, nil
+		}
+		fr.scanned = fr.end
+
+		// A message of maxSize might be waiting for the last byte of its delimiter
+		if fr.end-fr.start > fr.maxSize+1 || fr.discarding {
+			// Throw away what was read of the message so far, and keep going until its end
+			reported := fr.discarding
+			fr.discarding = true
+			fr.start, fr.end, fr.scanned = 0, 0, 0
+			if !reported {
+				return nil, ErrMessageTooLarge
+			}
+		}
+
+		if err := fr.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fill reads more data into the buffer, making room first
+func (fr *frameReader) fill() error {
+	if fr.err != nil {
+		err := fr.err
+		fr.err = nil
+		return err
+	}
+
+	if fr.start > 0 {
+		copy(fr.buf, fr.buf[fr.start:fr.end])
+		fr.end -= fr.start
+		fr.scanned -= fr.start
+		fr.start = 0
+	}
+
+	if fr.end == len(fr.buf) {
+		size := 2 * len(fr.buf)
+		// Room for the largest message plus its delimiter
+		if size > fr.maxSize+2 {
+			size = fr.maxSize + 2
+		}
+		buf := make([]byte, size)
+		copy(buf, fr.buf[:fr.end])
+		fr.buf = buf
+	}
+
+	n, err := fr.r.Read(fr.buf[fr.end:])
+	fr.end += n
+	if n > 0 && fr.onRead != nil {
+		fr.onRead(n)
+	}
+
+	if n > 0 && err != nil {
+		// Go through the data first
+		fr.err = err
+		return nil
+	}
+	return err
+}
+
+// opKey precedes the operation of a message, the server always sends it without spaces
+var opKey = []byte(`"op":"`)
+
+// peekOp returns the operation of a message without decoding it.
+// Returns false if it can't be found, the message then needs to be decoded to get it.
+func peekOp(data []byte) (string, bool) {
+	i := bytes.Index(data, opKey)
+	if i < 0 {
+		return "", false
+	}
+	op := data[i+len(opKey):]
+	j := bytes.IndexByte(op, '"')
+	if j < 0 {
+		return "", false
+	}
+
+	// Converting in the switch doesn't allocate
+	switch string(op[:j]) {
+	case "mcm":
+		return "mcm", true
+	case "ocm":
+		return "ocm", true
+	case "status":
+		return "status", true
+	case "connection":
+		return "connection", true
+	}
+	return "", false
+}
+
+// decode decodes a message sent by the server in a single pass.
+// The operation is peeked at first, so the message is decoded straight into its type.
+func (rm *ResponseMessage) decode(data []byte) error {
+	op, ok := peekOp(data)
+	if !ok {
+		temp := struct {
+			Op string `json:"op"`
+		}{}
+		if err := json.Unmarshal(data, &temp); err != nil {
+			return err
+		}
+		op = temp.Op
+	}
+
+	*rm = ResponseMessage{Op: op}
+
+	switch op {
+	case "connection":
+		msg := struct {
+			ID *uint32 `json:"id"`
+			*ConnectionMessage
+		}{ConnectionMessage: &ConnectionMessage{}}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		rm.ID, rm.ConnectionMessage = msg.ID, msg.ConnectionMessage
+	case "status":
+		msg := struct {
+			ID *uint32 `json:"id"`
+			*StatusMessage
+		}{StatusMessage: &StatusMessage{}}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		rm.ID, rm.StatusMessage = msg.ID, msg.StatusMessage
+	case "mcm":
+		msg := struct {
+			ID *uint32 `json:"id"`
+			*MarketChangeMessage
+		}{MarketChangeMessage: &MarketChangeMessage{}}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		rm.ID, rm.MarketChangeMessage = msg.ID, msg.MarketChangeMessage
+	case "ocm":
+		msg := struct {
+			ID *uint32 `json:"id"`
+			*OrderChangeMessage
+		}{OrderChangeMessage: &OrderChangeMessage{}}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return err
+		}
+		rm.ID, rm.OrderChangeMessage = msg.ID, msg.OrderChangeMessage
+	default:
+		return errors.New("Invalid object value")
+	}
+
+	return nil
+}
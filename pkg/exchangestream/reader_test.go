@@ -0,0 +1,372 @@
+package exchangestream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFrameReader(t *testing.T) {
+	large := strings.Repeat("x", 100)
+	input := "first\r\n\r\nsecond\r\n" + large + "\r\n" + strings.Repeat("y", 300) + "\r\nthird\r\nfourth\n" + large + "\r\nlast"
+
+	fr := newFrameReader(iotest.OneByteReader(strings.NewReader(input)), 8, 128)
+
+	expected := []string{"first", "second", large, "", "third", "fourth", large}
+	for _, want := range expected {
+		frame, err := fr.next()
+		if want == "" {
+			if !errors.Is(err, ErrMessageTooLarge) {
+				t.Fatalf("got (%q, %v) for the oversized message, want %v", frame, err, ErrMessageTooLarge)
+			}
+			continue
+		}
+		if err != nil || string(frame) != want {
+			t.Fatalf("got (%q, %v), want %q", frame, err, want)
+		}
+	}
+
+	// The incomplete message at the end is never returned
+	if frame, err := fr.next(); err != io.EOF {
+		t.Errorf("got (%q, %v) at the end, want %v", frame, err, io.EOF)
+	}
+}
+
+func TestFrameReaderResumes(t *testing.T) {
+	// Errors (e.g. read timeouts) don't lose the data read so far
+	fr := newFrameReader(iotest.TimeoutReader(strings.NewReader("first\r\nsec")), 64, 128)
+
+	if frame, err := fr.next(); err != nil || string(frame) != "first" {
+		t.Fatalf("got (%q, %v), want first", frame, err)
+	}
+	if _, err := fr.next(); !errors.Is(err, iotest.ErrTimeout) {
+		t.Fatalf("got error %v, want %v", err, iotest.ErrTimeout)
+	}
+
+	fr.r = strings.NewReader("ond\r\n")
+	if frame, err := fr.next(); err != nil || string(frame) != "second" {
+		t.Errorf("got (%q, %v) after the error, want second", frame, err)
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	messages := []string{
+		`{"op":"connection","connectionId":"002-051134157842-432409"}`,
+		// The operation can't be peeked at with spaces around the colon
+		`{"id":2, "op" : "connection", "connectionId":"002-1"}`,
+		`{"op":"status","id":1,"statusCode":"FAILURE","errorCode":"INVALID_SESSION_INFORMATION","errorMessage":"expired","connectionClosed":true,"connectionId":"002-1"}`,
+		`{"op":"ocm","id":3,"clk":"AAAAAAAA","pt":1594990000000,"heartbeatMs":5000,"conflateMs":50,"segmentType":"SEG_START","oc":[{"id":"1.1","accountId":1,"orc":[{"id":1,"uo":[{"id":"1","p":1.5,"s":2,"side":"B","status":"E","pt":"L","ot":"L","pd":1594990000000,"sm":0,"sr":2,"sl":0,"sc":0,"sv":0}]}]}]}`,
+	}
+	recorded, err := ioutil.ReadFile("testdata/mcm_recorded.txt")
+	if err != nil {
+		t.Fatalf("error reading recorded messages - error: %s", err)
+	}
+	messages = append(messages, strings.Split(strings.TrimSpace(string(recorded)), "\r\n")...)
+
+	for _, data := range messages {
+		got := ResponseMessage{}
+		if err := got.decode([]byte(data)); err != nil {
+			t.Fatalf("error decoding %s - error: %s", data, err)
+		}
+
+		want, err := decodeTwoPass([]byte(data))
+		if err != nil {
+			t.Fatalf("error decoding %s in two passes - error: %s", data, err)
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	rm := ResponseMessage{}
+	if err := rm.decode([]byte(`{"op":"unknown"}`)); err == nil {
+		t.Errorf("expected error decoding an unknown operation")
+	}
+}
+
+// TestDecodeAllFields makes sure decode keeps every field of the messages sent by the server
+func TestDecodeAllFields(t *testing.T) {
+	tests := map[string]interface{}{
+		"connection": &ConnectionMessage{},
+		"status":     &StatusMessage{},
+		"mcm":        &MarketChangeMessage{},
+		"ocm":        &OrderChangeMessage{},
+	}
+
+	for op, msg := range tests {
+		t.Run(op, func(t *testing.T) {
+			fillFields(reflect.ValueOf(msg).Elem())
+
+			want, err := json.Marshal(msg)
+			if err != nil {
+				t.Fatalf("error marshalling message - error: %s", err)
+			}
+			data := append([]byte(`{"op":"`+op+`","id":1,`), want[1:]...)
+
+			rm := ResponseMessage{}
+			if err := rm.decode(data); err != nil {
+				t.Fatalf("error decoding %s - error: %s", data, err)
+			}
+
+			var decoded interface{}
+			switch op {
+			case "connection":
+				decoded = rm.ConnectionMessage
+			case "status":
+				decoded = rm.StatusMessage
+			case "mcm":
+				decoded = rm.MarketChangeMessage
+			case "ocm":
+				decoded = rm.OrderChangeMessage
+			}
+
+			got, err := json.Marshal(decoded)
+			if err != nil {
+				t.Fatalf("error marshalling decoded message - error: %s", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+// fillFields sets every field of the struct to a value other than the zero value
+func fillFields(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		switch {
+		case field.Type() == reflect.TypeOf(EpochMillis{}):
+			field.Set(reflect.ValueOf(NewEpochMillis(1594999999999)))
+		case field.Kind() == reflect.Ptr:
+			field.Set(reflect.New(field.Type().Elem()))
+			fillValue(field.Elem())
+		case field.Kind() == reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		default:
+			fillValue(field)
+		}
+	}
+}
+
+func fillValue(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("1")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	}
+}
+
+// decodeTwoPass is how messages used to be decoded: once for the op and again for the message itself
+func decodeTwoPass(data []byte) (ResponseMessage, error) {
+	temp := struct {
+		Op string  `json:"op"`
+		ID *uint32 `json:"id,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return ResponseMessage{}, err
+	}
+
+	rm := ResponseMessage{Op: temp.Op, ID: temp.ID}
+	var err error
+
+	switch temp.Op {
+	case "connection":
+		rm.ConnectionMessage = &ConnectionMessage{}
+		err = json.Unmarshal(data, rm.ConnectionMessage)
+	case "status":
+		rm.StatusMessage = &StatusMessage{}
+		err = json.Unmarshal(data, rm.StatusMessage)
+	case "mcm":
+		rm.MarketChangeMessage = &MarketChangeMessage{}
+		err = json.Unmarshal(data, rm.MarketChangeMessage)
+	case "ocm":
+		rm.OrderChangeMessage = &OrderChangeMessage{}
+		err = json.Unmarshal(data, rm.OrderChangeMessage)
+	}
+
+	return rm, err
+}
+
+func loadRecorded(b *testing.B) ([]byte, [][]byte) {
+	b.Helper()
+
+	recorded, err := ioutil.ReadFile("testdata/mcm_recorded.txt")
+	if err != nil {
+		b.Fatalf("error reading recorded messages - error: %s", err)
+	}
+	return recorded, bytes.Split(bytes.TrimSpace(recorded), []byte("\r\n"))
+}
+
+func BenchmarkDecodeTwoPass(b *testing.B) {
+	recorded, messages := loadRecorded(b)
+	b.SetBytes(int64(len(recorded)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, data := range messages {
+			if _, err := decodeTwoPass(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDecodeUnmarshalJSON(b *testing.B) {
+	recorded, messages := loadRecorded(b)
+	b.SetBytes(int64(len(recorded)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, data := range messages {
+			rm := ResponseMessage{}
+			if err := json.Unmarshal(data, &rm); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	recorded, messages := loadRecorded(b)
+	b.SetBytes(int64(len(recorded)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for _, data := range messages {
+			rm := ResponseMessage{}
+			if err := rm.decode(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// recordedStream is a long stream of recorded messages, so buffer setup doesn't dominate the benchmarks
+func recordedStream(b *testing.B) []byte {
+	b.Helper()
+
+	recorded, _ := loadRecorded(b)
+	return bytes.Repeat(recorded, 100)
+}
+
+// readBaseline is how the stream used to be read: a fixed 8MB buffer, scanned byte by byte from the beginning
+// of the current message after every read, with every message decoded twice
+func readBaseline(r io.Reader, handle func(ResponseMessage)) error {
+	buf := make([]byte, 8*1024*1024)
+	indiceStart := 0
+	indiceStop := 0
+
+	for {
+		n, err := r.Read(buf[indiceStop : len(buf)-1])
+		if err != nil {
+			return err
+		}
+		indiceStop += n
+
+		progress := true
+		for progress {
+			progress = false
+
+			for i := indiceStart; i < indiceStop; i++ {
+				if buf[i] == '\n' {
+					if i-indiceStart > 1 && buf[i-1] == '\r' {
+						respMsg, err := decodeTwoPass(buf[indiceStart : i-1])
+						if err != nil {
+							return err
+						}
+						handle(respMsg)
+					}
+					indiceStart = i + 1
+					progress = true
+					break
+				}
+			}
+		}
+
+		if indiceStart != 0 {
+			copy(buf, buf[indiceStart:indiceStop])
+			indiceStop -= indiceStart
+			indiceStart = 0
+		}
+	}
+}
+
+func BenchmarkReaderBaseline(b *testing.B) {
+	stream := recordedStream(b)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := readBaseline(bytes.NewReader(stream), func(ResponseMessage) {}); err != io.EOF {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	stream := recordedStream(b)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fr := newFrameReader(bytes.NewReader(stream), defaultReaderBufferSize, defaultMaxMessageSize)
+		for {
+			frame, err := fr.next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+
+			respMsg := ResponseMessage{}
+			if err := respMsg.decode(frame); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkFrameReader(b *testing.B) {
+	stream := recordedStream(b)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		fr := newFrameReader(bytes.NewReader(stream), defaultReaderBufferSize, defaultMaxMessageSize)
+		for {
+			if _, err := fr.next(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkScanner(b *testing.B) {
+	stream := recordedStream(b)
+	b.SetBytes(int64(len(stream)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		scanner := bufio.NewScanner(bytes.NewReader(stream))
+		for scanner.Scan() {
+		}
+	}
+}
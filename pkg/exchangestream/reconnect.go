@@ -0,0 +1,299 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// connLost is sent to the supervisor when the connection is lost
+type connLost struct {
+	// generation identifies the connection that was lost
+	generation uint32
+	err        error
+}
+
+// subscriptions keeps track of the active subscriptions and the latest clocks received for each one,
+// so they can be resumed after reconnecting and betfair only sends the deltas.
+// It's thread safe!
+type subscriptions struct {
+	mu     sync.Mutex
+	market *MarketSubscriptionMessage
+	order  *OrderSubscriptionMessage
+	// ID of the request that created the market subscription, betfair tags the MarketChangeMessages with it
+	marketID uint32
+}
+
+// setMarket replaces the market subscription (betfair only keeps one market subscription per connection)
+func (s *subscriptions) setMarket(msm MarketSubscriptionMessage, id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.market = &msm
+	s.marketID = id
+}
+
+// marketSubscriptionID returns the ID of the request that created the market subscription
+func (s *subscriptions) marketSubscriptionID() uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marketID
+}
+
+// setOrder replaces the order subscription (betfair only keeps one order subscription per connection)
+func (s *subscriptions) setOrder(osm OrderSubscriptionMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.order = &osm
+}
+
+// updateMarketClocks records the clocks received on a MarketChangeMessage
+func (s *subscriptions) updateMarketClocks(initialClk string, clk string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.market == nil {
+		return
+	}
+
+	if initialClk != "" {
+		s.market.InitialClk = initialClk
+	}
+	if clk != "" {
+		s.market.Clk = clk
+	}
+}
+
+// updateOrderClocks records the clocks received on an OrderChangeMessage
+func (s *subscriptions) updateOrderClocks(initialClk string, clk string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.order == nil {
+		return
+	}
+
+	if initialClk != "" {
+		s.order.InitialClk = initialClk
+	}
+	if clk != "" {
+		s.order.Clk = clk
+	}
+}
+
+// marketSubscription returns the market subscription with the latest clocks, if there is one
+func (s *subscriptions) marketSubscription() (MarketSubscriptionMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.market == nil {
+		return MarketSubscriptionMessage{}, false
+	}
+	return *s.market, true
+}
+
+// orderSubscription returns the order subscription with the latest clocks, if there is one
+func (s *subscriptions) orderSubscription() (OrderSubscriptionMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.order == nil {
+		return OrderSubscriptionMessage{}, false
+	}
+	return *s.order, true
+}
+
+// resetClocks forgets the clocks received, so resubscribing starts from a new image
+func (s *subscriptions) resetClocks() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.market != nil {
+		s.market.InitialClk = ""
+		s.market.Clk = ""
+	}
+	if s.order != nil {
+		s.order.InitialClk = ""
+		s.order.Clk = ""
+	}
+}
+
+func (s *subscriptions) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.market = nil
+	s.order = nil
+	s.marketID = 0
+}
+
+func supervisor(esaclient *ESAClient, ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case lost := <-esaclient.connLostChan:
+			if lost.generation != atomic.LoadUint32(&esaclient.connGeneration) {
+				// Signal from a connection that has already been replaced
+				continue
+			}
+
+			log.Log(globals.Logger, log.ERROR, "connection lost", log.Fields{"error": fmt.Sprintf("%v", lost.err)})
+
+			if !esaclient.reconnect(ctx, lost.err) {
+				return
+			}
+		}
+	}
+}
+
+// reconnect replaces the lost connection with a new one and restores the session.
+// A single retry policy covers the whole outage: every attempt backs off first, failing to restore the session
+// counts as a failed attempt, and the client gives up once the retries run out.
+// Returns false if the client gave up and is now disconnected.
+func (esaclient *ESAClient) reconnect(ctx context.Context, cause error) bool {
+	esaclient.connMu.Lock()
+	defer esaclient.connMu.Unlock()
+
+	connConfig := esaclient.connConfig.Load().(ConnectionConfig)
+	rpe := NewPolicyExponential(connConfig.Retries, connConfig.MaximumBackoff, 0)
+
+	for {
+		if ctx.Err() != nil {
+			// Disconnect is in progress
+			return false
+		}
+
+		if esaclient.connected {
+			err := esaclient.teardown()
+			if err != nil {
+				log.Log(globals.Logger, log.DEBUG, "error while closing lost connection", log.Fields{"error": err.Error()})
+			}
+		}
+
+		if !connConfig.Reconnect {
+			log.Log(globals.Logger, log.INFO, "reconnection disabled, giving up", nil)
+			esaclient.disconnectHelper()
+			esaclient.setState(ConnectionState_Disconnected, "connection lost and reconnection is disabled", cause)
+			return false
+		}
+
+		if errors.Is(cause, ErrSlowConsumer) {
+			log.Log(globals.Logger, log.ERROR, "consumer not keeping up with the stream, giving up", log.Fields{"error": cause.Error()})
+			esaclient.disconnectHelper()
+			esaclient.setState(ConnectionState_Disconnected, "consumer not keeping up with the stream", cause)
+			return false
+		}
+
+		// Act on the errors reported by betfair before reconnecting
+		var statusErr StatusError
+		if errors.As(cause, &statusErr) {
+			switch statusErr.Action() {
+			case ErrorAction_Stop:
+				log.Log(globals.Logger, log.ERROR, "unrecoverable error reported by betfair, giving up", log.Fields{"error": cause.Error()})
+				esaclient.disconnectHelper()
+				esaclient.setState(ConnectionState_Disconnected, "unrecoverable error reported by betfair", cause)
+				return false
+			case ErrorAction_Relogin:
+				esaclient.setState(ConnectionState_Reconnecting, "session rejected by betfair", cause)
+				if err := esaclient.relogin(ctx); err != nil {
+					log.Log(globals.Logger, log.ERROR, "failed getting a new session token, giving up", log.Fields{"error": err.Error()})
+					esaclient.disconnectHelper()
+					esaclient.setState(ConnectionState_Disconnected, "failed getting a new session token", err)
+					return false
+				}
+			case ErrorAction_ResetClocks:
+				esaclient.subscriptions.resetClocks()
+			}
+		}
+
+		// Don't hammer the server
+		if cancelled, err := rpe.WaitBackOff(ctx); cancelled {
+			return false
+		} else if err != nil {
+			log.Log(globals.Logger, log.ERROR, "no retries left, giving up", log.Fields{"attempts": rpe.RetryCount(), "error": cause.Error()})
+			esaclient.disconnectHelper()
+			esaclient.setState(ConnectionState_Disconnected, "no retries left", cause)
+			return false
+		}
+
+		log.Log(globals.Logger, log.INFO, "reconnecting to server", nil)
+		esaclient.setState(ConnectionState_Reconnecting, "connection lost", cause)
+
+		err := esaclient.connectionHelper(ctx, &rpe)
+		if err != nil {
+			log.Log(globals.Logger, log.ERROR, "failed reconnecting to server, giving up", log.Fields{"error": err.Error()})
+			esaclient.disconnectHelper()
+			if ctx.Err() == nil {
+				esaclient.setState(ConnectionState_Disconnected, "failed to reconnect", err)
+			}
+			return false
+		}
+
+		err = esaclient.restoreSession()
+		if err == nil {
+			log.Log(globals.Logger, log.INFO, "successfully reconnected to server", nil)
+			return true
+		}
+
+		// Start again with the new cause, the next pass acts on it and gives up if it can't be recovered from
+		log.Log(globals.Logger, log.ERROR, "failed restoring session", log.Fields{"error": err.Error()})
+		cause = err
+	}
+}
+
+// relogin gets a new session token from the token provider
+func (esaclient *ESAClient) relogin(ctx context.Context) error {
+	tp := esaclient.tokenProvider.Load().(TokenProvider)
+	if tp == nil {
+		return errors.New("no token provider set")
+	}
+
+	sessionToken, err := tp(ctx)
+	if err != nil {
+		return err
+	}
+
+	esaclient.sessionToken.Store(sessionToken)
+	return nil
+}
+
+// restoreSession authenticates and resubscribes using the latest clocks received.
+// Failures reported by betfair are returned as StatusError.
+// reconnect counts every failure against the retries of the outage, so a resubscription that keeps failing
+// (e.g. INVALID_CLOCK or requests timing out) ends up disconnecting the client.
+func (esaclient *ESAClient) restoreSession() error {
+	if atomic.LoadUint32(&esaclient.authenticated) == 0 {
+		return nil
+	}
+
+	sm, err := esaclient.Authenticate()
+	if err != nil {
+		return err
+	} else if sm.StatusCode != StatusCode_Success {
+		return NewStatusError(sm)
+	}
+
+	if msm, ok := esaclient.subscriptions.marketSubscription(); ok {
+		sm, err = esaclient.MarketSubscribe(msm)
+		if err != nil {
+			return err
+		} else if sm.StatusCode != StatusCode_Success {
+			return NewStatusError(sm)
+		}
+	}
+
+	if osm, ok := esaclient.subscriptions.orderSubscription(); ok {
+		sm, err = esaclient.OrderSubscribe(osm)
+		if err != nil {
+			return err
+		} else if sm.StatusCode != StatusCode_Success {
+			return NewStatusError(sm)
+		}
+	}
+
+	return nil
+}
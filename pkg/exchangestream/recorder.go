@@ -0,0 +1,478 @@
+package exchangestream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// Defaults used when the RecorderConfig leaves them unset
+const (
+	defaultRecorderPrefix      = "stream"
+	defaultRecorderQueueSize   = 10000
+	defaultRecorderIdleTimeout = 10 * time.Minute
+	// How often the files are flushed and checked for rotation
+	recorderTick = time.Second
+	// Size of the buffer in front of each file
+	recorderBufferSize = 64 * 1024
+)
+
+// Layout of the date in the file names
+const recorderFileTimeLayout = "20060102T150405Z"
+
+// RecorderConfig holds the Recorder settings.
+type RecorderConfig struct {
+	// Dir is where the files are written, it's created if it doesn't exist
+	Dir string
+	// Prefix of the file names ("stream" by default)
+	Prefix string
+	// MaxFileSize rotates a file once it has this many bytes (before compression), 0 means no limit
+	MaxFileSize int64
+	// RotateInterval rotates a file once it has been open this long, 0 means no limit
+	RotateInterval time.Duration
+	// Compressor wraps the files, e.g. GzipCompressor. The files aren't compressed when nil.
+	// bz2 and zstd compressors can be plugged in from third party packages.
+	Compressor func(w io.Writer) (io.WriteCloser, error)
+	// Extension added to the file names when compressing (e.g. ".gz")
+	Extension string
+	// SplitByMarket writes the market and order changes to a file per market.
+	// Changes for several markets in the same message are split into a message per market,
+	// messages without changes (e.g. heartbeats and status messages) go to the shared file.
+	SplitByMarket bool
+	// IdleTimeout closes a market file after this long without messages (10 minutes by default)
+	IdleTimeout time.Duration
+	// QueueSize is how many messages can be waiting to be written (10000 by default).
+	// Messages are dropped when the queue is full, see Recorder.Dropped.
+	QueueSize int
+}
+
+// GzipCompressor compresses the files with gzip, use it with Extension ".gz"
+func GzipCompressor(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+// RecordedMessage is a message as written by the Recorder
+type RecordedMessage struct {
+	// ReceivedAt is the local time the message was read from the connection
+	ReceivedAt time.Time
+	// ConnectionID of the connection the message was received on
+	ConnectionID string
+	// Data is the raw message
+	Data []byte
+}
+
+// ErrInvalidRecord is returned when a line isn't in the format written by the Recorder
+var ErrInvalidRecord = errors.New("invalid recorded message")
+
+// ParseRecordedMessage parses a line written by the Recorder, without the trailing newline.
+// Data points into line.
+func ParseRecordedMessage(line []byte) (RecordedMessage, error) {
+	fields := bytes.SplitN(line, []byte{'\t'}, 3)
+	if len(fields) != 3 {
+		return RecordedMessage{}, ErrInvalidRecord
+	}
+
+	receivedAt, err := time.Parse(time.RFC3339Nano, string(fields[0]))
+	if err != nil {
+		return RecordedMessage{}, fmt.Errorf("%w: %s", ErrInvalidRecord, err)
+	}
+
+	return RecordedMessage{ReceivedAt: receivedAt, ConnectionID: string(fields[1]), Data: fields[2]}, nil
+}
+
+// appendRecord appends the line format: receive time (RFC3339, UTC), connection ID and raw message,
+// separated by tabs
+func appendRecord(buf []byte, receivedAt time.Time, connectionID string, data []byte) []byte {
+	buf = receivedAt.UTC().AppendFormat(buf, time.RFC3339Nano)
+	buf = append(buf, '\t')
+	buf = append(buf, connectionID...)
+	buf = append(buf, '\t')
+	buf = append(buf, data...)
+	return append(buf, '\n')
+}
+
+// Recorder writes every raw message received by an ESAClient to disk, see ESAClient.SetRecorder.
+// Messages are written from a goroutine of its own, the reader only copies the message and queues it.
+// It's thread safe!
+type Recorder struct {
+	// Atomic, kept as the first field so it's 64-bit aligned on 32-bit platforms
+	dropped uint64
+
+	// Held for reading while queueing, so nothing is queued once Close has stopped the writer
+	mu sync.RWMutex
+	// Set once Close is called (must hold mu)
+	closed bool
+
+	config RecorderConfig
+	queue  chan recordedItem
+	stop   chan struct{}
+	done   chan struct{}
+
+	// Only used by the writer goroutine
+	// Open files (key: market ID, empty for the shared file)
+	files map[string]*recordFile
+	// Number of files opened, used to keep the file names unique
+	fileCount uint64
+	// First error writing or closing the files
+	err error
+	buf []byte
+}
+
+type recordedItem struct {
+	receivedAt   time.Time
+	connectionID string
+	data         []byte
+}
+
+// NewRecorder creates the directory and starts the writer goroutine.
+// Call Close to flush and close the files.
+func NewRecorder(config RecorderConfig) (*Recorder, error) {
+	if config.Dir == "" {
+		return nil, errors.New("recorder directory not set")
+	}
+	if config.MaxFileSize < 0 || config.RotateInterval < 0 || config.IdleTimeout < 0 || config.QueueSize < 0 {
+		return nil, errors.New("recorder limits can't be negative")
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultRecorderPrefix
+	}
+	if config.IdleTimeout == 0 {
+		config.IdleTimeout = defaultRecorderIdleTimeout
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = defaultRecorderQueueSize
+	}
+
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating recorder directory: %w", err)
+	}
+
+	rec := &Recorder{
+		config: config,
+		queue:  make(chan recordedItem, config.QueueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		files:  make(map[string]*recordFile),
+	}
+	go rec.run()
+
+	return rec, nil
+}
+
+// Record queues a message to be written, it doesn't block.
+// data is copied, so it can be reused straight away.
+// Returns false if the message was dropped because the queue is full or the recorder is closed.
+func (rec *Recorder) Record(data []byte, connectionID string) bool {
+	rec.mu.RLock()
+	defer rec.mu.RUnlock()
+
+	if rec.closed {
+		atomic.AddUint64(&rec.dropped, 1)
+		return false
+	}
+
+	item := recordedItem{receivedAt: time.Now(), connectionID: connectionID, data: append([]byte(nil), data...)}
+
+	select {
+	case rec.queue <- item:
+		return true
+	default:
+		atomic.AddUint64(&rec.dropped, 1)
+		return false
+	}
+}
+
+// Dropped returns how many messages weren't recorded because the queue was full or the recorder was closed
+func (rec *Recorder) Dropped() uint64 {
+	return atomic.LoadUint64(&rec.dropped)
+}
+
+// Close writes the messages still queued, closes the files and stops the writer goroutine.
+// Returns the first error writing the files, if any.
+func (rec *Recorder) Close() error {
+	rec.mu.Lock()
+	if !rec.closed {
+		rec.closed = true
+		close(rec.stop)
+	}
+	rec.mu.Unlock()
+	<-rec.done
+
+	return rec.err
+}
+
+// run is the writer goroutine
+func (rec *Recorder) run() {
+	defer close(rec.done)
+
+	ticker := time.NewTicker(recorderTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case item := <-rec.queue:
+			rec.write(item)
+		case now := <-ticker.C:
+			rec.maintain(now)
+		case <-rec.stop:
+			for {
+				select {
+				case item := <-rec.queue:
+					rec.write(item)
+				default:
+					for key := range rec.files {
+						rec.closeFile(key)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// write writes the message to its file(s)
+func (rec *Recorder) write(item recordedItem) {
+	if !rec.config.SplitByMarket {
+		rec.writeLine("", item.receivedAt, item.connectionID, item.data)
+		return
+	}
+
+	for _, part := range splitByMarket(item.data) {
+		rec.writeLine(part.marketID, item.receivedAt, item.connectionID, part.data)
+	}
+}
+
+func (rec *Recorder) writeLine(key string, receivedAt time.Time, connectionID string, data []byte) {
+	rec.buf = appendRecord(rec.buf[:0], receivedAt, connectionID, data)
+
+	file := rec.files[key]
+	if file != nil && rec.shouldRotate(file, int64(len(rec.buf)), receivedAt) {
+		rec.closeFile(key)
+		file = nil
+	}
+
+	if file == nil {
+		var err error
+		file, err = rec.openFile(key, receivedAt)
+		if err != nil {
+			rec.fail("recorder failed to open file", err)
+			return
+		}
+		rec.files[key] = file
+	}
+
+	if err := file.write(rec.buf, receivedAt); err != nil {
+		rec.fail("recorder failed to write to file", err)
+		rec.closeFile(key)
+	}
+}
+
+// shouldRotate checks whether writing n more bytes at the given time rotates the file
+func (rec *Recorder) shouldRotate(file *recordFile, n int64, now time.Time) bool {
+	if rec.config.MaxFileSize > 0 && file.size > 0 && file.size+n > rec.config.MaxFileSize {
+		return true
+	}
+	return rec.config.RotateInterval > 0 && now.Sub(file.opened) >= rec.config.RotateInterval
+}
+
+// maintain flushes the files, closing the ones due for rotation and the idle market files
+func (rec *Recorder) maintain(now time.Time) {
+	for key, file := range rec.files {
+		if rec.config.RotateInterval > 0 && now.Sub(file.opened) >= rec.config.RotateInterval {
+			rec.closeFile(key)
+		} else if key != "" && now.Sub(file.lastWrite) >= rec.config.IdleTimeout {
+			rec.closeFile(key)
+		} else if err := file.flush(); err != nil {
+			rec.fail("recorder failed to flush file", err)
+			rec.closeFile(key)
+		}
+	}
+}
+
+func (rec *Recorder) openFile(key string, now time.Time) (*recordFile, error) {
+	rec.fileCount++
+
+	name := rec.config.Prefix
+	if key != "" {
+		name += "-" + key
+	}
+	name = fmt.Sprintf("%s-%s-%d.log%s", name, now.UTC().Format(recorderFileTimeLayout), rec.fileCount, rec.config.Extension)
+
+	return newRecordFile(filepath.Join(rec.config.Dir, name), rec.config.Compressor, now)
+}
+
+func (rec *Recorder) closeFile(key string) {
+	file := rec.files[key]
+	delete(rec.files, key)
+
+	if err := file.close(); err != nil {
+		rec.fail("recorder failed to close file", err)
+	}
+}
+
+// fail logs the error and keeps the first one to be returned by Close
+func (rec *Recorder) fail(msg string, err error) {
+	log.Log(globals.Logger, log.ERROR, msg, log.Fields{"error": err.Error()})
+	if rec.err == nil {
+		rec.err = err
+	}
+}
+
+// recordFile is a file being written: os.File <- compressor (optional) <- bufio.Writer
+type recordFile struct {
+	file       *os.File
+	compressor io.WriteCloser
+	writer     *bufio.Writer
+	// Bytes written, before compression
+	size      int64
+	opened    time.Time
+	lastWrite time.Time
+}
+
+func newRecordFile(path string, compressor func(w io.Writer) (io.WriteCloser, error), now time.Time) (*recordFile, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	rf := &recordFile{file: file, opened: now, lastWrite: now}
+
+	var w io.Writer = file
+	if compressor != nil {
+		rf.compressor, err = compressor(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error creating compressor: %w", err)
+		}
+		w = rf.compressor
+	}
+	rf.writer = bufio.NewWriterSize(w, recorderBufferSize)
+
+	return rf, nil
+}
+
+func (rf *recordFile) write(data []byte, now time.Time) error {
+	n, err := rf.writer.Write(data)
+	rf.size += int64(n)
+	rf.lastWrite = now
+	return err
+}
+
+// flush pushes the buffered data down to the file, through the compressor if it can be flushed
+func (rf *recordFile) flush() error {
+	if err := rf.writer.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := rf.compressor.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// close flushes and closes the file, returns the first error
+func (rf *recordFile) close() error {
+	err := rf.writer.Flush()
+	if rf.compressor != nil {
+		if err1 := rf.compressor.Close(); err == nil {
+			err = err1
+		}
+	}
+	if err1 := rf.file.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
+
+// marketPart is the part of a message for one market
+type marketPart struct {
+	// Empty for messages without market or order changes
+	marketID string
+	data     []byte
+}
+
+// splitByMarket splits a change message into a message per market, keeping every other field.
+// Messages that can't be split, including the ones that fail to decode, are returned whole with no market ID.
+func splitByMarket(data []byte) []marketPart {
+	whole := []marketPart{{data: data}}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return whole
+	}
+
+	var key string
+	switch string(fields["op"]) {
+	case `"mcm"`:
+		key = "mc"
+	case `"ocm"`:
+		key = "oc"
+	default:
+		return whole
+	}
+
+	var changes []json.RawMessage
+	if err := json.Unmarshal(fields[key], &changes); err != nil || len(changes) == 0 {
+		return whole
+	}
+
+	// Changes grouped by market, in the order the markets first show up
+	var marketIDs []string
+	groups := make(map[string][]json.RawMessage)
+	for _, change := range changes {
+		var id struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(change, &id); err != nil || id.ID == "" {
+			return whole
+		}
+
+		if _, ok := groups[id.ID]; !ok {
+			marketIDs = append(marketIDs, id.ID)
+		}
+		groups[id.ID] = append(groups[id.ID], change)
+	}
+
+	if len(marketIDs) == 1 {
+		return []marketPart{{marketID: marketIDs[0], data: data}}
+	}
+
+	parts := make([]marketPart, 0, len(marketIDs))
+	for _, marketID := range marketIDs {
+		group, err := json.Marshal(groups[marketID])
+		if err != nil {
+			return whole
+		}
+		fields[key] = group
+
+		part, err := json.Marshal(fields)
+		if err != nil {
+			return whole
+		}
+		parts = append(parts, marketPart{marketID: marketID, data: part})
+	}
+
+	return parts
+}
+
+// SetRecorder registers the recorder that gets every raw message received, along with the connection ID.
+// The recorder isn't closed by the ESAClient. Passing nil unregisters it.
+func (esaclient *ESAClient) SetRecorder(rec *Recorder) {
+	esaclient.recorder.Store(rec)
+}
+
+// getRecorder returns the registered recorder, nil if there is none
+func (esaclient *ESAClient) getRecorder() *Recorder {
+	return esaclient.recorder.Load().(*Recorder)
+}
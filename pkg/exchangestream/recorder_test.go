@@ -0,0 +1,277 @@
+package exchangestream
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readRecordings reads the messages of every file in dir, in file name order.
+// Returns the messages per file.
+func readRecordings(t *testing.T, dir string, gzipped bool) map[string][]RecordedMessage {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatalf("error listing files - error: %s", err)
+	}
+	sort.Strings(names)
+
+	recordings := make(map[string][]RecordedMessage)
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("error opening file - error: %s", err)
+		}
+
+		var r io.Reader = f
+		if gzipped {
+			gr, err := gzip.NewReader(f)
+			if err != nil {
+				t.Fatalf("error opening gzip file %s - error: %s", name, err)
+			}
+			r = gr
+		}
+
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			msg, err := ParseRecordedMessage(scanner.Bytes())
+			if err != nil {
+				t.Fatalf("error parsing line %q - error: %s", scanner.Text(), err)
+			}
+			msg.Data = append([]byte(nil), msg.Data...)
+			recordings[filepath.Base(name)] = append(recordings[filepath.Base(name)], msg)
+		}
+		if err := scanner.Err(); err != nil {
+			t.Fatalf("error reading file %s - error: %s", name, err)
+		}
+		f.Close()
+	}
+
+	return recordings
+}
+
+func TestRecorderESAClient(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(RecorderConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("error creating recorder - error: %s", err)
+	}
+
+	esaclient := NewESAClient("app_key", "session_token")
+	esaclient.SetRecorder(rec)
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	before := time.Now()
+	ts.send(`{"op":"mcm","id":1,"clk":"clk1","pt":1594999999999}`)
+	select {
+	case <-esaclient.MCMChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for market change message")
+	}
+
+	esaclient.Disconnect()
+	if err := rec.Close(); err != nil {
+		t.Fatalf("error closing recorder - error: %s", err)
+	}
+
+	recordings := readRecordings(t, dir, false)
+	if len(recordings) != 1 {
+		t.Fatalf("got %d files, want 1", len(recordings))
+	}
+
+	var messages []RecordedMessage
+	for name, msgs := range recordings {
+		if !strings.HasPrefix(name, "stream-") || !strings.HasSuffix(name, ".log") {
+			t.Errorf("got file name %s, want stream-*.log", name)
+		}
+		messages = msgs
+	}
+
+	wantData := []string{
+		`{"op":"connection","connectionId":"001-1"}`,
+		`{"op":"status","id":1,"statusCode":"SUCCESS","connectionClosed":false}`,
+		`{"op":"mcm","id":1,"clk":"clk1","pt":1594999999999}`,
+	}
+	if len(messages) != len(wantData) {
+		t.Fatalf("got %d messages, want %d", len(messages), len(wantData))
+	}
+	for i, msg := range messages {
+		if string(msg.Data) != wantData[i] {
+			t.Errorf("message %d: got %s, want %s", i, msg.Data, wantData[i])
+		}
+		if msg.ConnectionID != "001-1" {
+			t.Errorf("message %d: got connection ID %q, want 001-1", i, msg.ConnectionID)
+		}
+	}
+	if last := messages[len(messages)-1].ReceivedAt; last.Before(before) || last.After(time.Now()) {
+		t.Errorf("got receive time %s, want between %s and now", last, before)
+	}
+}
+
+func TestRecorderRotation(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(RecorderConfig{Dir: dir, Prefix: "test", MaxFileSize: 200})
+	if err != nil {
+		t.Fatalf("error creating recorder - error: %s", err)
+	}
+
+	// Each line is a bit over 100 bytes, so every file holds a single message
+	msg := `{"op":"mcm","id":1,"clk":"clk","pt":1594999999999,"mc":[{"id":"1.1"}]}`
+	for i := 0; i < 5; i++ {
+		if !rec.Record([]byte(msg), "conn") {
+			t.Fatalf("message %d dropped", i)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("error closing recorder - error: %s", err)
+	}
+
+	recordings := readRecordings(t, dir, false)
+	if len(recordings) != 5 {
+		t.Fatalf("got %d files, want 5", len(recordings))
+	}
+	for name, messages := range recordings {
+		if !strings.HasPrefix(name, "test-") {
+			t.Errorf("got file name %s, want test-*", name)
+		}
+		if len(messages) != 1 || string(messages[0].Data) != msg {
+			t.Errorf("file %s: got %d messages, want the message once", name, len(messages))
+		}
+	}
+
+	if rec.Record([]byte(msg), "conn") || rec.Dropped() != 1 {
+		t.Errorf("got %d dropped messages after closing, want 1", rec.Dropped())
+	}
+}
+
+func TestRecorderCloseWhileRecording(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(RecorderConfig{Dir: dir, Prefix: "test", QueueSize: 100000})
+	if err != nil {
+		t.Fatalf("error creating recorder - error: %s", err)
+	}
+
+	msg := `{"op":"mcm","id":1,"clk":"clk","pt":1594999999999,"mc":[{"id":"1.1"}]}`
+	const goroutines, messages = 4, 1000
+	recorded := make(chan int, goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			n := 0
+			for j := 0; j < messages; j++ {
+				if rec.Record([]byte(msg), "conn") {
+					n++
+				}
+			}
+			recorded <- n
+		}()
+	}
+
+	time.Sleep(time.Millisecond)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("error closing recorder - error: %s", err)
+	}
+
+	total := 0
+	for i := 0; i < goroutines; i++ {
+		total += <-recorded
+	}
+
+	written := 0
+	for _, msgs := range readRecordings(t, dir, false) {
+		written += len(msgs)
+	}
+	if written != total {
+		t.Errorf("got %d messages written, want the %d recorded", written, total)
+	}
+	if dropped := int(rec.Dropped()); total+dropped != goroutines*messages {
+		t.Errorf("got %d recorded and %d dropped, want %d in total", total, dropped, goroutines*messages)
+	}
+}
+
+func TestRecorderSplitByMarket(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(RecorderConfig{Dir: dir, SplitByMarket: true, Compressor: GzipCompressor, Extension: ".gz"})
+	if err != nil {
+		t.Fatalf("error creating recorder - error: %s", err)
+	}
+
+	messages := []string{
+		`{"op":"mcm","id":1,"clk":"c1","pt":1,"mc":[{"id":"1.1","tv":1},{"id":"1.2","tv":2},{"id":"1.1","tv":3}]}`,
+		`{"op":"mcm","id":1,"clk":"c2","pt":2,"ct":"HEARTBEAT"}`,
+		`{"op":"ocm","id":2,"clk":"c3","pt":3,"oc":[{"id":"1.2","orc":[]}]}`,
+		`not json`,
+	}
+	for _, msg := range messages {
+		rec.Record([]byte(msg), "conn")
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("error closing recorder - error: %s", err)
+	}
+
+	got := make(map[string][]string)
+	for name, msgs := range readRecordings(t, dir, true) {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("got file name %s, want *.log.gz", name)
+		}
+
+		key := "shared"
+		if strings.HasPrefix(name, "stream-1.1-") {
+			key = "1.1"
+		} else if strings.HasPrefix(name, "stream-1.2-") {
+			key = "1.2"
+		}
+		for _, msg := range msgs {
+			got[key] = append(got[key], string(msg.Data))
+		}
+	}
+
+	want := map[string][]string{
+		"1.1":    {`{"clk":"c1","id":1,"mc":[{"id":"1.1","tv":1},{"id":"1.1","tv":3}],"op":"mcm","pt":1}`},
+		"1.2":    {`{"clk":"c1","id":1,"mc":[{"id":"1.2","tv":2}],"op":"mcm","pt":1}`, messages[2]},
+		"shared": {messages[1], messages[3]},
+	}
+	for key, wantMsgs := range want {
+		if strings.Join(got[key], "\n") != strings.Join(wantMsgs, "\n") {
+			t.Errorf("%s: got messages\n%s\nwant\n%s", key, strings.Join(got[key], "\n"), strings.Join(wantMsgs, "\n"))
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d files, want %d", len(got), len(want))
+	}
+}
+
+func TestParseRecordedMessage(t *testing.T) {
+	receivedAt := time.Date(2020, 7, 17, 13, 30, 0, 123456789, time.UTC)
+	line := appendRecord(nil, receivedAt, "001-1", []byte("{\"op\":\"mcm\"}"))
+
+	msg, err := ParseRecordedMessage(line[:len(line)-1])
+	if err != nil {
+		t.Fatalf("error parsing line - error: %s", err)
+	}
+	if !msg.ReceivedAt.Equal(receivedAt) || msg.ConnectionID != "001-1" || string(msg.Data) != `{"op":"mcm"}` {
+		t.Errorf("got %+v", msg)
+	}
+
+	for _, line := range []string{"", "no tabs", "yesterday\t001-1\t{}"} {
+		if _, err := ParseRecordedMessage([]byte(line)); err == nil {
+			t.Errorf("got no error parsing %q", line)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package exchangestream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// ReplayMode sets the pace the messages are replayed at
+type ReplayMode int
+
+const (
+	// ReplayMode_AsFastAsPossible replays the messages without waiting in between (default)
+	ReplayMode_AsFastAsPossible ReplayMode = iota + 1
+	// ReplayMode_RealTime replays the messages as far apart as their publish times (pt)
+	ReplayMode_RealTime
+	// ReplayMode_Speed replays the messages as far apart as their publish times (pt) divided by ReplayConfig.Speed
+	ReplayMode_Speed
+)
+
+func (rm ReplayMode) String() string {
+	if elem, ok := replayModeToString[rm]; ok {
+		return elem
+	}
+	return ""
+}
+
+var replayModeToString = map[ReplayMode]string{
+	ReplayMode_AsFastAsPossible: "AS_FAST_AS_POSSIBLE",
+	ReplayMode_RealTime:         "REAL_TIME",
+	ReplayMode_Speed:            "SPEED",
+}
+
+// ReplayConfig holds the ReplaySource settings.
+type ReplayConfig struct {
+	Mode ReplayMode
+	// Speed multiplies the pace of ReplayMode_Speed, e.g. 10 replays an hour in 6 minutes
+	Speed float64
+	// Segmentation sets whether segmented change messages are reassembled, as in ESAClient.SetSegmentation
+	Segmentation SegmentationConfig
+}
+
+// Stream is what the ESAClient and the ReplaySource have in common, so code consuming the changes can run over
+// a live connection as well as over recorded data.
+// The ReplaySource closes the channels at the end of the replay, the ESAClient never closes them.
+type Stream interface {
+	// MarketChanges returns the channel the market changes are sent to (MCMChan)
+	MarketChanges() <-chan MarketChangeM
+	// OrderChanges returns the channel the order changes are sent to (OCMChan)
+	OrderChanges() <-chan OrderChangeM
+	// Errors returns the channel the errors are reported on (ErrorChan)
+	Errors() <-chan error
+	// SetHandler registers the handler that gets the changes instead of the channels
+	SetHandler(handler StreamHandler)
+}
+
+var (
+	_ Stream = (*ESAClient)(nil)
+	_ Stream = (*ReplaySource)(nil)
+)
+
+// ReplaySource replays a recorded stream through the same channels and StreamHandler as the ESAClient,
+// so code written against a live connection can run over recorded data.
+// It reads the lines written by the Recorder as well as the raw messages of betfair's historic data files
+// (one json message per line), the format of each line is detected on its own. Compressed files must be
+// decompressed by the caller, e.g. with gzip.NewReader or bzip2.NewReader.
+type ReplaySource struct {
+	config  ReplayConfig
+	scanner *bufio.Scanner
+	// Set once Run is called (0 - False | 1 - True)
+	running uint32
+	// The registered StreamHandler (holds a StreamHandler wrapped in replayHandler)
+	handler atomic.Value
+
+	// Change Streams, closed when Run returns
+	// Public channel
+	MCMChan chan MarketChangeM
+	OCMChan chan OrderChangeM
+	// Lines that couldn't be replayed, dropped segments and errors reported through status messages.
+	// Closed when Run returns
+	// Public channel
+	ErrorChan chan error
+}
+
+// replayHandler wraps the handler so a nil handler can be stored in an atomic.Value
+type replayHandler struct {
+	handler StreamHandler
+}
+
+// ReplayLineError is reported on ErrorChan when a line can't be replayed
+type ReplayLineError struct {
+	Line int
+	Err  error
+}
+
+func (e ReplayLineError) Error() string {
+	return fmt.Sprintf("error replaying line %d: %s", e.Line, e.Err)
+}
+
+func (e ReplayLineError) Unwrap() error {
+	return e.Err
+}
+
+// NewReplaySource creates a source that replays the messages read from r.
+func NewReplaySource(r io.Reader, config ReplayConfig) (*ReplaySource, error) {
+	if config.Mode == 0 {
+		config.Mode = ReplayMode_AsFastAsPossible
+	} else if _, ok := replayModeToString[config.Mode]; !ok {
+		return nil, fmt.Errorf("invalid replay mode: %d", config.Mode)
+	}
+
+	switch config.Mode {
+	case ReplayMode_RealTime:
+		config.Speed = 1
+	case ReplayMode_Speed:
+		if config.Speed <= 0 {
+			return nil, fmt.Errorf("replay speed needs to be greater than 0")
+		}
+	}
+
+	if config.Segmentation.MaxSegments < 0 || config.Segmentation.Timeout < 0 {
+		return nil, fmt.Errorf("segmentation limits can't be negative")
+	}
+	if config.Segmentation.MaxSegments == 0 {
+		config.Segmentation.MaxSegments = defaultMaxSegments
+	}
+	if config.Segmentation.Timeout == 0 {
+		config.Segmentation.Timeout = defaultSegmentTimeout
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, defaultReaderBufferSize), defaultMaxMessageSize)
+
+	rs := &ReplaySource{
+		config:    config,
+		scanner:   scanner,
+		MCMChan:   make(chan MarketChangeM, 1000),
+		OCMChan:   make(chan OrderChangeM, 1000),
+		ErrorChan: make(chan error, 100),
+	}
+	rs.handler.Store(replayHandler{})
+
+	return rs, nil
+}
+
+// MarketChanges returns MCMChan
+func (rs *ReplaySource) MarketChanges() <-chan MarketChangeM {
+	return rs.MCMChan
+}
+
+// OrderChanges returns OCMChan
+func (rs *ReplaySource) OrderChanges() <-chan OrderChangeM {
+	return rs.OCMChan
+}
+
+// Errors returns ErrorChan
+func (rs *ReplaySource) Errors() <-chan error {
+	return rs.ErrorChan
+}
+
+// SetHandler registers the handler that gets the messages replayed.
+// While a handler is registered, market and order changes are no longer sent to MCMChan and OCMChan.
+// The handler is called from the goroutine running Run, one message at a time.
+func (rs *ReplaySource) SetHandler(handler StreamHandler) {
+	rs.handler.Store(replayHandler{handler: handler})
+}
+
+// Run replays the messages until the end of the input, blocking while MCMChan or OCMChan are full.
+// MCMChan, OCMChan and ErrorChan are closed when it returns. It can only be called once.
+// A segmented message whose SEG_END isn't found before the end of the input is reported on ErrorChan.
+// Returns nil at the end of the input, the context error if ctx is done first or the error reading the input.
+func (rs *ReplaySource) Run(ctx context.Context) error {
+	if !atomic.CompareAndSwapUint32(&rs.running, 0, 1) {
+		return errors.New("replay already run")
+	}
+	defer close(rs.MCMChan)
+	defer close(rs.OCMChan)
+	defer close(rs.ErrorChan)
+
+	marketSegs := marketSegments{config: rs.config.Segmentation}
+	orderSegs := orderSegments{config: rs.config.Segmentation}
+
+	// Publish time of the first message and when it was replayed, the pace is set from them
+	var firstPT time.Time
+	var start time.Time
+
+	lineNumber := 0
+	for rs.scanner.Scan() {
+		lineNumber++
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		data, err := replayData(rs.scanner.Bytes())
+		if err != nil {
+			rs.reportError(ReplayLineError{Line: lineNumber, Err: err})
+			continue
+		} else if len(data) == 0 {
+			continue
+		}
+
+		respMsg := ResponseMessage{}
+		if err := respMsg.decode(data); err != nil {
+			rs.reportError(ReplayLineError{Line: lineNumber, Err: err})
+			continue
+		}
+
+		var pt time.Time
+		if respMsg.MarketChangeMessage != nil {
+			pt = respMsg.MarketChangeMessage.PublishTime.Time
+		} else if respMsg.OrderChangeMessage != nil {
+			pt = respMsg.OrderChangeMessage.PublishTime.Time
+		}
+
+		if !pt.IsZero() && rs.config.Mode != ReplayMode_AsFastAsPossible {
+			if firstPT.IsZero() {
+				firstPT = pt
+				start = time.Now()
+			} else if err := rs.wait(ctx, start.Add(time.Duration(float64(pt.Sub(firstPT))/rs.config.Speed))); err != nil {
+				return err
+			}
+		}
+
+		handler := rs.handler.Load().(replayHandler).handler
+
+		switch respMsg.Op {
+		case "mcm":
+			mcm := MarketChangeM{ID: respMsg.ID, MarketChangeMessage: *respMsg.MarketChangeMessage}
+			if rs.config.Segmentation.Reassemble {
+				var complete bool
+				if mcm, complete, err = marketSegs.add(mcm, pt); err != nil {
+					rs.reportError(err)
+				}
+				if !complete {
+					continue
+				}
+			}
+
+			if handler != nil {
+				handler.OnMarketChange(mcm)
+				continue
+			}
+			select {
+			case rs.MCMChan <- mcm:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case "ocm":
+			ocm := OrderChangeM{ID: respMsg.ID, OrderChangeMessage: *respMsg.OrderChangeMessage}
+			if rs.config.Segmentation.Reassemble {
+				var complete bool
+				if ocm, complete, err = orderSegs.add(ocm, pt); err != nil {
+					rs.reportError(err)
+				}
+				if !complete {
+					continue
+				}
+			}
+
+			if handler != nil {
+				handler.OnOrderChange(ocm)
+				continue
+			}
+			select {
+			case rs.OCMChan <- ocm:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case "status":
+			if handler != nil {
+				handler.OnStatus(StatusM{ID: respMsg.ID, StatusMessage: *respMsg.StatusMessage})
+			}
+			if respMsg.StatusMessage.StatusCode != StatusCode_Success {
+				rs.reportError(NewStatusError(*respMsg.StatusMessage))
+			}
+		}
+	}
+
+	if marketSegs.batch != nil {
+		rs.reportError(marketSegs.drop(ErrSegmentMissingEnd))
+	}
+	if orderSegs.batch != nil {
+		rs.reportError(orderSegs.drop(ErrSegmentMissingEnd))
+	}
+
+	return rs.scanner.Err()
+}
+
+// replayData returns the raw message in a line, either written by the Recorder or as found in betfair's
+// historic data files. Returns no data for blank lines.
+func replayData(line []byte) ([]byte, error) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] == '{' {
+		return line, nil
+	}
+
+	recorded, err := ParseRecordedMessage(line)
+	if err != nil {
+		return nil, err
+	}
+	return recorded.Data, nil
+}
+
+// wait waits until the given time or until ctx is done
+func (rs *ReplaySource) wait(ctx context.Context, until time.Time) error {
+	d := time.Until(until)
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reportError sends the error to ErrorChan without blocking
+func (rs *ReplaySource) reportError(err error) {
+	select {
+	case rs.ErrorChan <- err:
+	default:
+		log.Log(globals.Logger, log.WARN, "error channel full, dropping error", log.Fields{"error": err.Error()})
+	}
+}
@@ -0,0 +1,246 @@
+package exchangestream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replayAll runs the replay and collects everything sent on the channels
+func replayAll(t *testing.T, rs *ReplaySource) ([]MarketChangeM, []OrderChangeM, []error) {
+	t.Helper()
+
+	errChan := make(chan error, 1)
+	go func() { errChan <- rs.Run(context.Background()) }()
+
+	// Consumed as any Stream would be
+	var stream Stream = rs
+	var mcms []MarketChangeM
+	for mcm := range stream.MarketChanges() {
+		mcms = append(mcms, mcm)
+	}
+	var ocms []OrderChangeM
+	for ocm := range stream.OrderChanges() {
+		ocms = append(ocms, ocm)
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatalf("error replaying - error: %s", err)
+	}
+
+	var errs []error
+	for err := range stream.Errors() {
+		errs = append(errs, err)
+	}
+	return mcms, ocms, errs
+}
+
+func TestReplayHistoricFormat(t *testing.T) {
+	f, err := os.Open("testdata/mcm_recorded.txt")
+	if err != nil {
+		t.Fatalf("error opening recorded messages - error: %s", err)
+	}
+	defer f.Close()
+
+	rs, err := NewReplaySource(f, ReplayConfig{})
+	if err != nil {
+		t.Fatalf("error creating replay source - error: %s", err)
+	}
+
+	mcms, ocms, errs := replayAll(t, rs)
+	if len(mcms) != 61 || len(ocms) != 0 || len(errs) != 0 {
+		t.Fatalf("got %d mcms, %d ocms and errors %v, want 61 mcms", len(mcms), len(ocms), errs)
+	}
+	if mcms[0].ChangeType == nil || *mcms[0].ChangeType != ChangeType_SubImage || len(mcms[0].MarketChanges) == 0 {
+		t.Errorf("got first message %+v, want market subscription image", mcms[0])
+	}
+}
+
+func TestReplayRecorderFormat(t *testing.T) {
+	receivedAt := time.Date(2020, 7, 17, 13, 30, 0, 0, time.UTC)
+
+	var input []byte
+	for _, msg := range []string{
+		`{"op":"connection","connectionId":"001-1"}`,
+		`{"op":"status","id":1,"statusCode":"SUCCESS","connectionClosed":false}`,
+		`{"op":"mcm","id":2,"clk":"c1","pt":1594990000000,"mc":[{"id":"1.1","tv":1}]}`,
+		`{"op":"ocm","id":3,"clk":"c2","pt":1594990000100,"oc":[{"id":"1.1"}]}`,
+		`{"op":"status","statusCode":"FAILURE","errorCode":"SUBSCRIPTION_LIMIT_EXCEEDED","connectionClosed":true}`,
+	} {
+		input = appendRecord(input, receivedAt, "001-1", []byte(msg))
+	}
+	// Historic format lines can be mixed in, broken lines are reported and skipped
+	input = append(input, "\nnot a message\n{\"op\":\"mcm\",\"id\":2,\"clk\":\"c3\",\"pt\":1594990000200}\n"...)
+
+	rs, err := NewReplaySource(bytes.NewReader(input), ReplayConfig{})
+	if err != nil {
+		t.Fatalf("error creating replay source - error: %s", err)
+	}
+
+	mcms, ocms, errs := replayAll(t, rs)
+
+	if len(mcms) != 2 || mcms[0].Clk != "c1" || mcms[1].Clk != "c3" || *mcms[0].ID != 2 {
+		t.Errorf("got mcms %+v, want clocks c1 and c3", mcms)
+	}
+	if len(ocms) != 1 || ocms[0].Clk != "c2" || len(ocms[0].OrderMarketChanges) != 1 {
+		t.Errorf("got ocms %+v, want clock c2", ocms)
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("got errors %v, want status error and line error", errs)
+	}
+	var statusErr StatusError
+	if !errors.As(errs[0], &statusErr) || statusErr.ErrorCode != ErrorCode_SubscriptionLimitExceeded {
+		t.Errorf("got error %v, want SUBSCRIPTION_LIMIT_EXCEEDED status error", errs[0])
+	}
+	var lineErr ReplayLineError
+	if !errors.As(errs[1], &lineErr) || lineErr.Line != 7 || !errors.Is(lineErr, ErrInvalidRecord) {
+		t.Errorf("got error %v, want invalid record on line 7", errs[1])
+	}
+}
+
+// replayMessages builds historic format messages, delay apart
+func replayMessages(n int, delay time.Duration) string {
+	var sb strings.Builder
+	pt := int64(1594990000000)
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "{\"op\":\"mcm\",\"id\":1,\"clk\":\"%d\",\"pt\":%d}\n", i, pt+int64(i)*int64(delay/time.Millisecond))
+	}
+	return sb.String()
+}
+
+func TestReplayPace(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ReplayConfig
+		delay  time.Duration
+		min    time.Duration
+		max    time.Duration
+	}{
+		{name: "as fast as possible", config: ReplayConfig{Mode: ReplayMode_AsFastAsPossible}, delay: time.Hour,
+			max: time.Second},
+		{name: "real time", config: ReplayConfig{Mode: ReplayMode_RealTime}, delay: 50 * time.Millisecond,
+			min: 150 * time.Millisecond, max: 2 * time.Second},
+		{name: "speed", config: ReplayConfig{Mode: ReplayMode_Speed, Speed: 20}, delay: time.Second,
+			min: 150 * time.Millisecond, max: 2 * time.Second},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rs, err := NewReplaySource(strings.NewReader(replayMessages(4, test.delay)), test.config)
+			if err != nil {
+				t.Fatalf("error creating replay source - error: %s", err)
+			}
+
+			start := time.Now()
+			mcms, _, _ := replayAll(t, rs)
+			elapsed := time.Since(start)
+
+			if len(mcms) != 4 {
+				t.Errorf("got %d mcms, want 4", len(mcms))
+			}
+			if elapsed < test.min || elapsed > test.max {
+				t.Errorf("replay took %s, want between %s and %s", elapsed, test.min, test.max)
+			}
+		})
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	rs, err := NewReplaySource(strings.NewReader(replayMessages(2, time.Hour)), ReplayConfig{Mode: ReplayMode_RealTime})
+	if err != nil {
+		t.Fatalf("error creating replay source - error: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- rs.Run(ctx) }()
+
+	<-rs.MCMChan
+	cancel()
+
+	select {
+	case err := <-errChan:
+		if err != context.Canceled {
+			t.Errorf("got error %v, want context canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("replay not stopped")
+	}
+
+	if err := rs.Run(context.Background()); err == nil {
+		t.Errorf("got no error running the replay twice")
+	}
+}
+
+func TestReplayHandlerAndSegments(t *testing.T) {
+	input := `{"op":"mcm","id":1,"clk":"c1","pt":1,"segmentType":"SEG_START","mc":[{"id":"1.1"}]}
+{"op":"mcm","id":1,"clk":"c2","pt":2,"segmentType":"SEG","mc":[{"id":"1.2"}]}
+{"op":"mcm","id":1,"clk":"c3","pt":3,"segmentType":"SEG_END","mc":[{"id":"1.3"}]}
+{"op":"ocm","id":2,"clk":"c4","pt":4}
+{"op":"status","id":3,"statusCode":"SUCCESS","connectionClosed":false}
+`
+	rs, err := NewReplaySource(strings.NewReader(input), ReplayConfig{Segmentation: SegmentationConfig{Reassemble: true}})
+	if err != nil {
+		t.Fatalf("error creating replay source - error: %s", err)
+	}
+
+	handler := newTestHandler()
+	close(handler.release)
+	rs.SetHandler(handler)
+
+	mcms, ocms, errs := replayAll(t, rs)
+	if len(mcms) != 0 || len(ocms) != 0 || len(errs) != 0 {
+		t.Fatalf("got %d mcms, %d ocms and errors %v on the channels, want everything on the handler", len(mcms), len(ocms), errs)
+	}
+
+	if len(handler.marketChan) != 1 {
+		t.Fatalf("got %d market changes, want 1", len(handler.marketChan))
+	}
+	mcm := <-handler.marketChan
+	if mcm.Clk != "c3" || len(mcm.MarketChanges) != 3 || mcm.SegmentType != nil {
+		t.Errorf("got market change %+v, want the 3 segments reassembled", mcm)
+	}
+	if len(handler.orderChan) != 1 || len(handler.statusChan) != 1 {
+		t.Errorf("got %d order changes and %d status messages, want 1 of each", len(handler.orderChan), len(handler.statusChan))
+	}
+}
+
+func TestReplayUnterminatedSegment(t *testing.T) {
+	input := `{"op":"mcm","id":1,"clk":"c1","pt":1,"mc":[{"id":"1.1"}]}
+{"op":"mcm","id":1,"clk":"c2","pt":2,"segmentType":"SEG_START","mc":[{"id":"1.2"}]}
+{"op":"mcm","id":1,"clk":"c3","pt":3,"segmentType":"SEG","mc":[{"id":"1.3"}]}
+`
+	rs, err := NewReplaySource(strings.NewReader(input), ReplayConfig{Segmentation: SegmentationConfig{Reassemble: true}})
+	if err != nil {
+		t.Fatalf("error creating replay source - error: %s", err)
+	}
+
+	mcms, _, errs := replayAll(t, rs)
+	if len(mcms) != 1 || mcms[0].Clk != "c1" {
+		t.Fatalf("got mcms %+v, want only the unsegmented message", mcms)
+	}
+
+	var segErr SegmentError
+	if len(errs) != 1 || !errors.As(errs[0], &segErr) || segErr.Segments != 2 || !errors.Is(segErr, ErrSegmentMissingEnd) {
+		t.Errorf("got errors %v, want the 2 segments dropped for the missing SEG_END", errs)
+	}
+}
+
+func TestReplayConfig(t *testing.T) {
+	for _, config := range []ReplayConfig{
+		{Mode: 99},
+		{Mode: ReplayMode_Speed},
+		{Mode: ReplayMode_Speed, Speed: -1},
+		{Segmentation: SegmentationConfig{MaxSegments: -1}},
+	} {
+		if _, err := NewReplaySource(strings.NewReader(""), config); err == nil {
+			t.Errorf("got no error for config %+v", config)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package exchangestream
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Defaults used when reassembling segmented change messages
+const (
+	defaultMaxSegments    = 1000
+	defaultSegmentTimeout = 30 * time.Second
+)
+
+// SegmentationConfig configures the reassembly of segmented change messages.
+// Betfair only segments messages when the subscription has SegmentationEnabled set.
+type SegmentationConfig struct {
+	// Reassemble turns on the reassembly, when off each segment is sent to MCMChan/OCMChan as received
+	Reassemble bool
+	// MaxSegments is the maximum number of segments buffered for a single message (1000 by default)
+	MaxSegments int
+	// Timeout is how long to wait for the SEG_END segment after SEG_START (30 seconds by default)
+	Timeout time.Duration
+}
+
+// SetSegmentation sets how segmented change messages are handled.
+// The new settings are applied on the next connection.
+func (esaclient *ESAClient) SetSegmentation(config SegmentationConfig) error {
+	if config.MaxSegments < 0 || config.Timeout < 0 {
+		return fmt.Errorf("segmentation limits can't be negative")
+	}
+	if config.MaxSegments == 0 {
+		config.MaxSegments = defaultMaxSegments
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultSegmentTimeout
+	}
+
+	esaclient.segmentationConfig.Store(config)
+	return nil
+}
+
+// ErrSegmentMissingStart is reported when a segment arrives without a SEG_START segment before it
+var ErrSegmentMissingStart = errors.New("segment received without SEG_START")
+
+// ErrSegmentMissingEnd is reported when a new SEG_START segment arrives before the SEG_END of the previous message,
+// or when a replay reaches the end of the input
+var ErrSegmentMissingEnd = errors.New("SEG_END not received")
+
+// ErrSegmentLimit is reported when a message has more segments than SegmentationConfig.MaxSegments
+var ErrSegmentLimit = errors.New("too many segments")
+
+// ErrSegmentTimeout is reported when SEG_END isn't received within SegmentationConfig.Timeout
+var ErrSegmentTimeout = errors.New("timeout waiting for SEG_END")
+
+// SegmentError is reported on ErrorChan when a segmented message is dropped
+type SegmentError struct {
+	// Op is either mcm or ocm
+	Op string
+	// Segments is the number of segments dropped
+	Segments int
+	Err      error
+}
+
+func (e SegmentError) Error() string {
+	return fmt.Sprintf("dropped %d %s segments: %s", e.Segments, e.Op, e.Err)
+}
+
+func (e SegmentError) Unwrap() error {
+	return e.Err
+}
+
+// marketSegments buffers the segments of a MarketChangeMessage until SEG_END is received.
+// It's not thread safe, it's only used by the controller.
+type marketSegments struct {
+	config   SegmentationConfig
+	batch    *MarketChangeM
+	segments int
+	started  time.Time
+}
+
+// add processes a message, returning the message to send on when the batch is complete (or the message isn't segmented)
+func (s *marketSegments) add(mcm MarketChangeM, now time.Time) (MarketChangeM, bool, error) {
+	if mcm.SegmentType == nil {
+		return mcm, true, nil
+	}
+
+	var err error
+
+	switch *mcm.SegmentType {
+	case SegmentType_SegStart:
+		if s.batch != nil {
+			err = s.drop(ErrSegmentMissingEnd)
+		}
+		batch := mcm
+		batch.MarketChanges = append([]MarketChange{}, mcm.MarketChanges...)
+		batch.SegmentType = nil
+		s.batch = &batch
+		s.segments = 1
+		s.started = now
+		return MarketChangeM{}, false, err
+	default:
+		if s.batch == nil {
+			return MarketChangeM{}, false, SegmentError{Op: "mcm", Segments: 1, Err: ErrSegmentMissingStart}
+		}
+	}
+
+	s.segments++
+	if s.segments > s.config.MaxSegments {
+		return MarketChangeM{}, false, s.drop(ErrSegmentLimit)
+	}
+
+	s.batch.MarketChanges = append(s.batch.MarketChanges, mcm.MarketChanges...)
+	s.batch.PublishTime = mcm.PublishTime
+	if mcm.Clk != "" {
+		s.batch.Clk = mcm.Clk
+	}
+	if mcm.InitialClk != "" {
+		s.batch.InitialClk = mcm.InitialClk
+	}
+	if mcm.ChangeType != nil {
+		s.batch.ChangeType = mcm.ChangeType
+	}
+	if mcm.Status != nil {
+		s.batch.Status = mcm.Status
+	}
+
+	if *mcm.SegmentType != SegmentType_SegEnd {
+		return MarketChangeM{}, false, nil
+	}
+
+	batch := *s.batch
+	s.batch = nil
+	s.segments = 0
+	return batch, true, nil
+}
+
+// expire drops the batch if SEG_END didn't arrive in time
+func (s *marketSegments) expire(now time.Time) error {
+	if s.batch == nil || now.Sub(s.started) < s.config.Timeout {
+		return nil
+	}
+	return s.drop(ErrSegmentTimeout)
+}
+
+func (s *marketSegments) drop(err error) error {
+	segErr := SegmentError{Op: "mcm", Segments: s.segments, Err: err}
+	s.batch = nil
+	s.segments = 0
+	return segErr
+}
+
+// orderSegments buffers the segments of an OrderChangeMessage until SEG_END is received.
+// It's not thread safe, it's only used by the controller.
+type orderSegments struct {
+	config   SegmentationConfig
+	batch    *OrderChangeM
+	segments int
+	started  time.Time
+}
+
+// add processes a message, returning the message to send on when the batch is complete (or the message isn't segmented)
+func (s *orderSegments) add(ocm OrderChangeM, now time.Time) (OrderChangeM, bool, error) {
+	if ocm.SegmentType == nil {
+		return ocm, true, nil
+	}
+
+	var err error
+
+	switch *ocm.SegmentType {
+	case SegmentType_SegStart:
+		if s.batch != nil {
+			err = s.drop(ErrSegmentMissingEnd)
+		}
+		batch := ocm
+		batch.OrderMarketChanges = append([]OrderMarketChange{}, ocm.OrderMarketChanges...)
+		batch.SegmentType = nil
+		s.batch = &batch
+		s.segments = 1
+		s.started = now
+		return OrderChangeM{}, false, err
+	default:
+		if s.batch == nil {
+			return OrderChangeM{}, false, SegmentError{Op: "ocm", Segments: 1, Err: ErrSegmentMissingStart}
+		}
+	}
+
+	s.segments++
+	if s.segments > s.config.MaxSegments {
+		return OrderChangeM{}, false, s.drop(ErrSegmentLimit)
+	}
+
+	s.batch.OrderMarketChanges = append(s.batch.OrderMarketChanges, ocm.OrderMarketChanges...)
+	s.batch.PublishTime = ocm.PublishTime
+	if ocm.Clk != "" {
+		s.batch.Clk = ocm.Clk
+	}
+	if ocm.InitialClk != "" {
+		s.batch.InitialClk = ocm.InitialClk
+	}
+	if ocm.ChangeType != nil {
+		s.batch.ChangeType = ocm.ChangeType
+	}
+	if ocm.Status != nil {
+		s.batch.Status = ocm.Status
+	}
+
+	if *ocm.SegmentType != SegmentType_SegEnd {
+		return OrderChangeM{}, false, nil
+	}
+
+	batch := *s.batch
+	s.batch = nil
+	s.segments = 0
+	return batch, true, nil
+}
+
+// expire drops the batch if SEG_END didn't arrive in time
+func (s *orderSegments) expire(now time.Time) error {
+	if s.batch == nil || now.Sub(s.started) < s.config.Timeout {
+		return nil
+	}
+	return s.drop(ErrSegmentTimeout)
+}
+
+func (s *orderSegments) drop(err error) error {
+	segErr := SegmentError{Op: "ocm", Segments: s.segments, Err: err}
+	s.batch = nil
+	s.segments = 0
+	return segErr
+}
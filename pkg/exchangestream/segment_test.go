@@ -0,0 +1,118 @@
+package exchangestream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func segmentedMCM(t *testing.T, data string) MarketChangeM {
+	t.Helper()
+
+	mcm := MarketChangeM{}
+	if err := json.Unmarshal([]byte(data), &mcm.MarketChangeMessage); err != nil {
+		t.Fatalf("error unmarshalling message - error: %s", err)
+	}
+	return mcm
+}
+
+func TestMarketSegments(t *testing.T) {
+	segs := marketSegments{config: SegmentationConfig{Reassemble: true, MaxSegments: 3, Timeout: time.Second}}
+	now := time.Now()
+
+	start := segmentedMCM(t, `{"op":"mcm","initialClk":"initial","clk":"clk1","pt":1,"ct":"SUB_IMAGE","segmentType":"SEG_START","mc":[{"id":"1.1"}]}`)
+	seg := segmentedMCM(t, `{"op":"mcm","pt":2,"segmentType":"SEG","mc":[{"id":"1.2"}]}`)
+	end := segmentedMCM(t, `{"op":"mcm","clk":"clk2","pt":3,"segmentType":"SEG_END","mc":[{"id":"1.3"}]}`)
+
+	// Messages without segment type go through untouched
+	if _, complete, err := segs.add(segmentedMCM(t, `{"op":"mcm","clk":"clk0","pt":1}`), now); !complete || err != nil {
+		t.Errorf("got (%t, %v) for a message without segments, want (true, nil)", complete, err)
+	}
+
+	for _, mcm := range []MarketChangeM{start, seg} {
+		if _, complete, err := segs.add(mcm, now); complete || err != nil {
+			t.Errorf("got (%t, %v) for %s, want (false, nil)", complete, err, mcm.SegmentType)
+		}
+	}
+
+	mcm, complete, err := segs.add(end, now)
+	if !complete || err != nil {
+		t.Fatalf("got (%t, %v) for SEG_END, want (true, nil)", complete, err)
+	}
+	if len(mcm.MarketChanges) != 3 || mcm.MarketChanges[2].ID != "1.3" {
+		t.Errorf("got market changes %+v, want the 3 segments merged in order", mcm.MarketChanges)
+	}
+	if mcm.InitialClk != "initial" || mcm.Clk != "clk2" || mcm.PublishTime.Millis() != 3 || mcm.SegmentType != nil ||
+		mcm.ChangeType == nil || *mcm.ChangeType != ChangeType_SubImage {
+		t.Errorf("got message %+v, want clocks (initial, clk2), pt 3 and SUB_IMAGE without segment type", mcm)
+	}
+
+	// Missing SEG_START
+	if _, complete, err = segs.add(seg, now); complete || !errors.Is(err, ErrSegmentMissingStart) {
+		t.Errorf("got (%t, %v) for SEG without SEG_START, want (false, %v)", complete, err, ErrSegmentMissingStart)
+	}
+
+	// Missing SEG_END
+	segs.add(start, now)
+	var segErr SegmentError
+	if _, complete, err = segs.add(start, now); complete || !errors.As(err, &segErr) || segErr.Segments != 1 ||
+		!errors.Is(err, ErrSegmentMissingEnd) {
+		t.Errorf("got (%t, %v) for SEG_START twice, want (false, %v)", complete, err, ErrSegmentMissingEnd)
+	}
+
+	// Too many segments
+	segs.add(seg, now)
+	segs.add(seg, now)
+	if _, complete, err = segs.add(end, now); complete || !errors.Is(err, ErrSegmentLimit) {
+		t.Errorf("got (%t, %v) over the segment limit, want (false, %v)", complete, err, ErrSegmentLimit)
+	}
+
+	// Timeout
+	segs.add(start, now)
+	if err = segs.expire(now.Add(500 * time.Millisecond)); err != nil {
+		t.Errorf("got error %v before the timeout, want nil", err)
+	}
+	if err = segs.expire(now.Add(time.Second)); !errors.Is(err, ErrSegmentTimeout) {
+		t.Errorf("got error %v after the timeout, want %v", err, ErrSegmentTimeout)
+	}
+	if _, complete, err = segs.add(end, now); complete || !errors.Is(err, ErrSegmentMissingStart) {
+		t.Errorf("got (%t, %v) for SEG_END after the timeout, want (false, %v)", complete, err, ErrSegmentMissingStart)
+	}
+}
+
+func TestSegmentReassembly(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	if err := esaclient.SetSegmentation(SegmentationConfig{Reassemble: true}); err != nil {
+		t.Fatalf("error setting segmentation - error: %s", err)
+	}
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	waitState(t, &esaclient, ConnectionState_Connected)
+
+	ts.send(`{"op":"ocm","id":1,"initialClk":"initial","clk":"clk1","pt":1,"segmentType":"SEG_START","oc":[{"id":"1.1"}]}`)
+	ts.send(`{"op":"ocm","id":1,"clk":"clk2","pt":2,"segmentType":"SEG_END","oc":[{"id":"1.2"}]}`)
+
+	select {
+	case ocm := <-esaclient.OCMChan:
+		if len(ocm.OrderMarketChanges) != 2 || ocm.Clk != "clk2" {
+			t.Errorf("got message %+v, want both segments merged", ocm)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for order change message")
+	}
+
+	select {
+	case ocm := <-esaclient.OCMChan:
+		t.Errorf("got unexpected message %+v", ocm)
+	default:
+	}
+}
@@ -0,0 +1,189 @@
+package exchangestream
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer is a minimal betfair stream server, listening on localhost, used to test the ESAClient.
+// It replies SUCCESS to every request and records the requests received.
+type testServer struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu          sync.Mutex
+	conns       []net.Conn
+	requests    []map[string]interface{}
+	connections int
+
+	// requestChan gets every request received (after it has been replied to)
+	requestChan chan map[string]interface{}
+	// ignoreOps lists the request operations the server doesn't reply to
+	ignoreOps map[string]bool
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	cert, err := selfSignedCert()
+	if err != nil {
+		t.Fatalf("error creating certificate - error: %s", err)
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatalf("error listening - error: %s", err)
+	}
+
+	ts := &testServer{t: t, listener: listener, requestChan: make(chan map[string]interface{}, 100), ignoreOps: map[string]bool{}}
+	go ts.serve()
+
+	return ts
+}
+
+// connConfig returns the ConnectionConfig needed to connect to the server
+func (ts *testServer) connConfig() ConnectionConfig {
+	addr := ts.listener.Addr().(*net.TCPAddr)
+	return ConnectionConfig{ServerHost: "127.0.0.1", ServerPort: uint(addr.Port), InsecureSkipVerify: true,
+		ConnectionTimeout: 1000, Retries: 3, MaximumBackoff: 1}
+}
+
+func (ts *testServer) serve() {
+	for {
+		conn, err := ts.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		ts.mu.Lock()
+		ts.conns = append(ts.conns, conn)
+		ts.connections++
+		connID := "001-" + strconv.Itoa(ts.connections)
+		ts.mu.Unlock()
+
+		go ts.handle(conn, connID)
+	}
+}
+
+func (ts *testServer) handle(conn net.Conn, connID string) {
+	defer conn.Close()
+
+	fmt.Fprintf(conn, "{\"op\":\"connection\",\"connectionId\":\"%s\"}\r\n", connID)
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		req := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			continue
+		}
+
+		ts.mu.Lock()
+		ignore := ts.ignoreOps[fmt.Sprintf("%v", req["op"])]
+		ts.requests = append(ts.requests, req)
+		ts.mu.Unlock()
+
+		if !ignore {
+			fmt.Fprintf(conn, "{\"op\":\"status\",\"id\":%v,\"statusCode\":\"SUCCESS\",\"connectionClosed\":false}\r\n", req["id"])
+		}
+
+		ts.requestChan <- req
+	}
+}
+
+// ignore makes the server stop replying to the given request operation
+func (ts *testServer) ignore(op string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.ignoreOps[op] = true
+}
+
+// send writes a message to the latest connection
+func (ts *testServer) send(msg string) {
+	ts.mu.Lock()
+	n := len(ts.conns)
+	ts.mu.Unlock()
+
+	ts.sendTo(n-1, msg)
+}
+
+// sendTo writes a message to the i-th connection accepted (starting at 0)
+func (ts *testServer) sendTo(i int, msg string) {
+	ts.mu.Lock()
+	conn := ts.conns[i]
+	ts.mu.Unlock()
+
+	if _, err := conn.Write([]byte(msg + "\r\n")); err != nil {
+		ts.t.Errorf("error writing to connection - error: %s", err)
+	}
+}
+
+// dropConnection closes the latest connection on the server side
+func (ts *testServer) dropConnection() {
+	ts.mu.Lock()
+	conn := ts.conns[len(ts.conns)-1]
+	ts.mu.Unlock()
+
+	conn.Close()
+}
+
+// waitRequest waits for a request with the given op, skipping heartbeats
+func (ts *testServer) waitRequest(op string) map[string]interface{} {
+	ts.t.Helper()
+
+	for {
+		select {
+		case req := <-ts.requestChan:
+			if req["op"] == op {
+				return req
+			}
+		case <-time.After(5 * time.Second):
+			ts.t.Fatalf("timeout waiting for %s request", op)
+			return nil
+		}
+	}
+}
+
+func (ts *testServer) close() {
+	ts.listener.Close()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, conn := range ts.conns {
+		conn.Close()
+	}
+}
+
+func selfSignedCert() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{"betfair test server"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,102 @@
+package exchangestream
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// NegotiatedSettings are the heartbeat and conflation intervals confirmed by the server on the change messages.
+// Zero means the server hasn't confirmed them yet (no change message received for that subscription).
+type NegotiatedSettings struct {
+	MarketHeartbeatMs uint
+	MarketConflateMs  uint
+	OrderHeartbeatMs  uint
+	OrderConflateMs   uint
+}
+
+// negotiatedSettings is updated atomically by the controller
+type negotiatedSettings struct {
+	marketHeartbeatMS uint32
+	marketConflateMS  uint32
+	orderHeartbeatMS  uint32
+	orderConflateMS   uint32
+}
+
+func (ns *negotiatedSettings) reset() {
+	atomic.StoreUint32(&ns.marketHeartbeatMS, 0)
+	atomic.StoreUint32(&ns.marketConflateMS, 0)
+	atomic.StoreUint32(&ns.orderHeartbeatMS, 0)
+	atomic.StoreUint32(&ns.orderConflateMS, 0)
+}
+
+// SetConflateMS sets the conflation interval requested on subscriptions that don't set one (0 means no conflation)
+func (esaclient *ESAClient) SetConflateMS(conflateMS uint32) error {
+	if conflateMS > 120000 {
+		return fmt.Errorf("conflateMS needs to be at most 120000ms")
+	}
+	atomic.StoreUint32(&esaclient.conflateMS, conflateMS)
+	return nil
+}
+
+// NegotiatedSettings returns the heartbeat and conflation intervals the server is using on this connection
+func (esaclient *ESAClient) NegotiatedSettings() NegotiatedSettings {
+	return NegotiatedSettings{
+		MarketHeartbeatMs: uint(atomic.LoadUint32(&esaclient.negotiated.marketHeartbeatMS)),
+		MarketConflateMs:  uint(atomic.LoadUint32(&esaclient.negotiated.marketConflateMS)),
+		OrderHeartbeatMs:  uint(atomic.LoadUint32(&esaclient.negotiated.orderHeartbeatMS)),
+		OrderConflateMs:   uint(atomic.LoadUint32(&esaclient.negotiated.orderConflateMS)),
+	}
+}
+
+// applySettings sets the client heartbeat and conflation intervals on subscriptions that don't set their own
+func (esaclient *ESAClient) applySettings(req *RequestMessage) {
+	heartbeatMS := uint(atomic.LoadUint32(&esaclient.heartbeatMS))
+	conflateMS := uint(atomic.LoadUint32(&esaclient.conflateMS))
+
+	if msm := req.MarketSubscriptionMessage; msm != nil {
+		if msm.HeartbeatMs == 0 {
+			msm.HeartbeatMs = heartbeatMS
+		}
+		if msm.ConflateMs == 0 {
+			msm.ConflateMs = conflateMS
+		}
+	} else if osm := req.OrderSubscriptionMessage; osm != nil {
+		if osm.HeartbeatMs == 0 {
+			osm.HeartbeatMs = heartbeatMS
+		}
+		if osm.ConflateMs == 0 {
+			osm.ConflateMs = conflateMS
+		}
+	}
+}
+
+// livenessTimeout returns how long connTracker waits for a message before checking the connection with a heartbeat.
+// Once the server confirms the intervals, the shortest heartbeat (plus its conflation delay) is used,
+// otherwise the client heartbeatMS. Either way it's scaled by heartbeatMultiplier.
+func (esaclient *ESAClient) livenessTimeout() time.Duration {
+	intervalMS := atomic.LoadUint32(&esaclient.heartbeatMS)
+
+	negotiated := esaclient.NegotiatedSettings()
+	candidates := [][2]uint{
+		{negotiated.MarketHeartbeatMs, negotiated.MarketConflateMs},
+		{negotiated.OrderHeartbeatMs, negotiated.OrderConflateMs},
+	}
+
+	var shortest uint32
+	for _, candidate := range candidates {
+		if candidate[0] == 0 {
+			continue
+		}
+		ms := uint32(candidate[0] + candidate[1])
+		if shortest == 0 || ms < shortest {
+			shortest = ms
+		}
+	}
+	if shortest != 0 {
+		intervalMS = shortest
+	}
+
+	multiplier := atomic.LoadUint32(&esaclient.heartbeatMultiplier)
+	return time.Duration(uint64(intervalMS)*uint64(multiplier)/100) * time.Millisecond
+}
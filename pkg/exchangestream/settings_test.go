@@ -0,0 +1,77 @@
+package exchangestream
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionSettings(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+	if err := esaclient.ChangeSettings(3, 600, 150); err != nil {
+		t.Fatalf("error changing settings - error: %s", err)
+	}
+	if err := esaclient.SetConflateMS(100); err != nil {
+		t.Fatalf("error setting conflateMS - error: %s", err)
+	}
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	// Messages received before authenticating must not hold back the reader
+	for i := 0; i < 50; i++ {
+		ts.send(`{"op":"ocm","id":1,"clk":"clk0","pt":1594999999999}`)
+	}
+	for i := 0; i < 50; i++ {
+		select {
+		case <-esaclient.OCMChan:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for order change message %d", i)
+		}
+	}
+
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	if _, err := esaclient.MarketSubscribe(MarketSubscriptionMessage{}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+	if _, err := esaclient.OrderSubscribe(OrderSubscriptionMessage{HeartbeatMs: 2000, ConflateMs: 50}); err != nil {
+		t.Fatalf("error subscribing - error: %s", err)
+	}
+
+	sub := ts.waitRequest("marketSubscription")
+	if sub["heartbeatMs"] != float64(600) || sub["conflateMs"] != float64(100) {
+		t.Errorf("got (%v, %v) on the market subscription, want the client settings (600, 100)", sub["heartbeatMs"], sub["conflateMs"])
+	}
+
+	sub = ts.waitRequest("orderSubscription")
+	if sub["heartbeatMs"] != float64(2000) || sub["conflateMs"] != float64(50) {
+		t.Errorf("got (%v, %v) on the order subscription, want its own settings (2000, 50)", sub["heartbeatMs"], sub["conflateMs"])
+	}
+
+	if timeout := esaclient.livenessTimeout(); timeout != 900*time.Millisecond {
+		t.Errorf("got liveness timeout %s before the server confirmed the settings, want 900ms", timeout)
+	}
+
+	ts.send(`{"op":"mcm","id":2,"initialClk":"initial","clk":"clk1","pt":1594999999999,"ct":"SUB_IMAGE","heartbeatMs":500,"conflateMs":200}`)
+	ts.send(`{"op":"ocm","id":3,"initialClk":"initial","clk":"clk1","pt":1594999999999,"ct":"SUB_IMAGE","heartbeatMs":2000,"conflateMs":50}`)
+	<-esaclient.MCMChan
+	<-esaclient.OCMChan
+
+	expected := NegotiatedSettings{MarketHeartbeatMs: 500, MarketConflateMs: 200, OrderHeartbeatMs: 2000, OrderConflateMs: 50}
+	if negotiated := esaclient.NegotiatedSettings(); negotiated != expected {
+		t.Errorf("got negotiated settings %+v, want %+v", negotiated, expected)
+	}
+
+	// The shortest interval is used: (500 + 200) * 150%
+	if timeout := esaclient.livenessTimeout(); timeout != 1050*time.Millisecond {
+		t.Errorf("got liveness timeout %s, want 1050ms", timeout)
+	}
+}
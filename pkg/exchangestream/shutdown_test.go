@@ -0,0 +1,193 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientGoroutines are the goroutines started by Connect
+var clientGoroutines = []string{
+	"exchangestream.(*ESAClient).controller(",
+	"exchangestream.(*ESAClient).reader(",
+	"exchangestream.(*ESAClient).writer(",
+	"exchangestream.(*ESAClient).connTracker(",
+	"exchangestream.(*ESAClient).supervisor(",
+}
+
+// runningClientGoroutines returns the stacks of the client goroutines still running
+func runningClientGoroutines() []string {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	var running []string
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		for _, name := range clientGoroutines {
+			if strings.Contains(stack, name) {
+				running = append(running, stack)
+				break
+			}
+		}
+	}
+	return running
+}
+
+// checkNoLeaks fails the test if any client goroutine is still running.
+// A goroutine that called Done might take a moment to go away, so give it a few tries.
+func checkNoLeaks(t *testing.T) {
+	t.Helper()
+
+	var running []string
+	for i := 0; i < 20; i++ {
+		if running = runningClientGoroutines(); len(running) == 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%d goroutines still running:\n\n%s", len(running), strings.Join(running, "\n\n"))
+}
+
+func TestDisconnectNoLeaks(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	connConfig := ts.connConfig()
+	connConfig.Reconnect = true
+
+	for i := 0; i < 5; i++ {
+		if err := esaclient.Connect(context.Background(), connConfig); err != nil {
+			t.Fatalf("error connecting on cycle %d - error: %s", i, err)
+		}
+		if _, err := esaclient.Authenticate(); err != nil {
+			t.Fatalf("error authenticating on cycle %d - error: %s", i, err)
+		}
+		if _, err := esaclient.MarketSubscribe(MarketSubscriptionMessage{}); err != nil {
+			t.Fatalf("error subscribing on cycle %d - error: %s", i, err)
+		}
+		ts.send(`{"op":"mcm","id":3,"initialClk":"initial","clk":"clk1","pt":1594999999999,"ct":"SUB_IMAGE"}`)
+
+		if err := esaclient.Disconnect(); err != nil {
+			t.Fatalf("error disconnecting on cycle %d - error: %s", i, err)
+		}
+		checkNoLeaks(t)
+
+		if id := esaclient.MarketSubscriptionID(); id != 0 {
+			t.Errorf("got subscription ID %d after disconnecting, want 0", id)
+		}
+		if _, _, connID, _ := esaclient.GetSessionInfo(); connID != "" {
+			t.Errorf("got connection ID %s after disconnecting, want none", connID)
+		}
+
+		for len(esaclient.MCMChan) > 0 {
+			<-esaclient.MCMChan
+		}
+	}
+}
+
+func TestDisconnectUndrained(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	if _, err := esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+
+	// Nobody reads MCMChan, the controller ends up blocked on it
+	for i := 0; i < cap(esaclient.MCMChan)+100; i++ {
+		ts.send(`{"op":"mcm","id":1,"clk":"clk1","pt":1594999999999}`)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(esaclient.MCMChan) < cap(esaclient.MCMChan) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for MCMChan to fill up, got %d messages", len(esaclient.MCMChan))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- esaclient.Disconnect()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("error disconnecting - error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout disconnecting with a full MCMChan")
+	}
+	checkNoLeaks(t)
+}
+
+func TestDisconnectFailsPending(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+
+	ts.ignore("authentication")
+
+	errChan := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		_, err := esaclient.AuthenticateWithContext(ctx)
+		errChan <- err
+	}()
+	ts.waitRequest("authentication")
+
+	start := time.Now()
+	if err := esaclient.Disconnect(); err != nil {
+		t.Fatalf("error disconnecting - error: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("disconnecting took %s", elapsed)
+	}
+	checkNoLeaks(t)
+
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, ErrConnectionLost) {
+			t.Errorf("got error %v for the pending request, want %v", err, ErrConnectionLost)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pending request didn't fail after disconnecting")
+	}
+}
+
+func TestConnectionLostNoLeaks(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	for i := 0; i < 3; i++ {
+		if err := esaclient.Connect(context.Background(), ts.connConfig()); err != nil {
+			t.Fatalf("error connecting on cycle %d - error: %s", i, err)
+		}
+		if _, err := esaclient.Authenticate(); err != nil {
+			t.Fatalf("error authenticating on cycle %d - error: %s", i, err)
+		}
+
+		// Reconnection is disabled, so the client gives up and cleans up after itself
+		ts.dropConnection()
+		waitState(t, &esaclient, ConnectionState_Disconnected)
+		checkNoLeaks(t)
+	}
+}
@@ -0,0 +1,95 @@
+package exchangestream
+
+import (
+	"context"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/globals"
+	"github.com/gustavooferreira/betfair/pkg/utils/log"
+)
+
+// ConnectionState is the state of the ESAClient connection
+type ConnectionState int
+
+const (
+	// ConnectionState_Disconnected means there is no connection (initial state, or the client gave up reconnecting)
+	ConnectionState_Disconnected ConnectionState = iota + 1
+	// ConnectionState_Connecting means the client is trying to connect to the server
+	ConnectionState_Connecting
+	// ConnectionState_Connected means the connection is established and the connection message was received
+	ConnectionState_Connected
+	// ConnectionState_Authenticated means the session was successfully authenticated
+	ConnectionState_Authenticated
+	// ConnectionState_Subscribed means there is at least one active market or order subscription
+	ConnectionState_Subscribed
+	// ConnectionState_Reconnecting means the connection was lost and the client is trying to restore it
+	ConnectionState_Reconnecting
+	// ConnectionState_Closed means the connection was closed by calling Disconnect
+	ConnectionState_Closed
+)
+
+func (cs ConnectionState) String() string {
+	if elem, ok := connectionStateToString[cs]; ok {
+		return elem
+	}
+	return ""
+}
+
+var connectionStateToString = map[ConnectionState]string{
+	ConnectionState_Disconnected:  "DISCONNECTED",
+	ConnectionState_Connecting:    "CONNECTING",
+	ConnectionState_Connected:     "CONNECTED",
+	ConnectionState_Authenticated: "AUTHENTICATED",
+	ConnectionState_Subscribed:    "SUBSCRIBED",
+	ConnectionState_Reconnecting:  "RECONNECTING",
+	ConnectionState_Closed:        "CLOSED",
+}
+
+// ConnectionEvent reports a transition of the connection state
+type ConnectionEvent struct {
+	From ConnectionState
+	To   ConnectionState
+	// Reason is a human readable description of why the transition happened
+	Reason string
+	// Err is the error that caused the transition, if any
+	Err  error
+	Time time.Time
+}
+
+// State returns the current connection state.
+func (esaclient *ESAClient) State() ConnectionState {
+	esaclient.stateMu.Lock()
+	defer esaclient.stateMu.Unlock()
+	return esaclient.state
+}
+
+// setState transitions to a new state and publishes the event on EventChan (or to the registered handler).
+// If nobody is consuming EventChan and it's full, the event is dropped rather than blocking the client.
+func (esaclient *ESAClient) setState(to ConnectionState, reason string, err error) {
+	esaclient.stateMu.Lock()
+	defer esaclient.stateMu.Unlock()
+
+	if esaclient.state == to && err == nil {
+		return
+	}
+
+	event := ConnectionEvent{From: esaclient.state, To: to, Reason: reason, Err: err, Time: time.Now()}
+	esaclient.state = to
+
+	fields := log.Fields{"from": event.From.String(), "to": event.To.String(), "reason": reason}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	log.Log(globals.Logger, log.INFO, "connection state changed", fields)
+
+	if d := esaclient.getDispatcher(); d != nil {
+		d.event.push(context.Background(), event, BackpressurePolicy_DropOldest)
+		return
+	}
+
+	select {
+	case esaclient.EventChan <- event:
+	default:
+		log.Log(globals.Logger, log.WARN, "event channel full, dropping connection event", fields)
+	}
+}
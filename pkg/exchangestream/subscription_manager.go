@@ -0,0 +1,173 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrEmptyFilter is returned when removing markets would leave the subscription without any market,
+// which betfair would take as a subscription to every market.
+var ErrEmptyFilter = errors.New("market filter would be empty")
+
+// SubscriptionManager keeps track of the market filter subscribed on an ESAClient.
+// Betfair replaces the previous market subscription whenever a new one is made, so adding or removing markets
+// means resending the whole filter. The manager keeps the merged filter and resubscribes with the latest clocks
+// received, so betfair only sends full images for the markets that weren't subscribed already.
+// It's thread safe!
+type SubscriptionManager struct {
+	mu     sync.Mutex
+	client *ESAClient
+	// Subscription settings (data filter, conflation, heartbeat, etc) used on every subscription
+	template MarketSubscriptionMessage
+	filter   MarketFilter
+}
+
+// NewSubscriptionManager creates a new SubscriptionManager on top of the client.
+// msm holds the settings used on every subscription, its MarketFilter is the initial filter.
+func NewSubscriptionManager(client *ESAClient, msm MarketSubscriptionMessage) *SubscriptionManager {
+	sm := &SubscriptionManager{client: client, template: msm, filter: copyMarketFilter(msm.MarketFilter)}
+	sm.template.MarketFilter = MarketFilter{}
+	sm.template.InitialClk = ""
+	sm.template.Clk = ""
+	return sm
+}
+
+// AddMarkets adds markets to the filter and resubscribes
+func (sm *SubscriptionManager) AddMarkets(ctx context.Context, marketIDs ...string) (StatusMessage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	filter := copyMarketFilter(sm.filter)
+	filter.MarketIDs = mergeIDs(filter.MarketIDs, marketIDs)
+
+	return sm.subscribe(ctx, filter)
+}
+
+// RemoveMarkets removes markets from the filter and resubscribes.
+// Returns ErrEmptyFilter if no markets would be left, use ReplaceFilter to change the filter completely.
+func (sm *SubscriptionManager) RemoveMarkets(ctx context.Context, marketIDs ...string) (StatusMessage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	filter := copyMarketFilter(sm.filter)
+	filter.MarketIDs = removeIDs(filter.MarketIDs, marketIDs)
+
+	if len(filter.MarketIDs) == 0 && len(sm.filter.MarketIDs) != 0 && isMarketIDsOnly(filter) {
+		return StatusMessage{}, ErrEmptyFilter
+	}
+
+	return sm.subscribe(ctx, filter)
+}
+
+// ReplaceFilter replaces the whole filter and resubscribes
+func (sm *SubscriptionManager) ReplaceFilter(ctx context.Context, filter MarketFilter) (StatusMessage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return sm.subscribe(ctx, copyMarketFilter(filter))
+}
+
+// MarketFilter returns the filter of the current subscription
+func (sm *SubscriptionManager) MarketFilter() MarketFilter {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return copyMarketFilter(sm.filter)
+}
+
+// SubscriptionID returns the ID of the current market subscription.
+// MarketChangeMessages with a different ID belong to a subscription that has been replaced.
+func (sm *SubscriptionManager) SubscriptionID() uint32 {
+	return sm.client.MarketSubscriptionID()
+}
+
+// IsCurrent tells whether the MarketChangeMessage belongs to the current subscription
+func (sm *SubscriptionManager) IsCurrent(mcm MarketChangeM) bool {
+	id := sm.SubscriptionID()
+	return id != 0 && mcm.ID != nil && *mcm.ID == id
+}
+
+// subscribe sends the subscription with the latest clocks and records the filter if successful.
+// Must be called with the lock held.
+func (sm *SubscriptionManager) subscribe(ctx context.Context, filter MarketFilter) (StatusMessage, error) {
+	msm := sm.template
+	msm.MarketFilter = filter
+
+	if current, ok := sm.client.subscriptions.marketSubscription(); ok {
+		msm.InitialClk = current.InitialClk
+		msm.Clk = current.Clk
+	}
+
+	status, err := sm.client.MarketSubscribeWithContext(ctx, msm)
+	if err != nil {
+		return status, err
+	}
+	if status.StatusCode != StatusCode_Success {
+		return status, NewStatusError(status)
+	}
+
+	sm.filter = filter
+	return status, nil
+}
+
+// isMarketIDsOnly tells whether the filter has no criteria other than the market IDs
+func isMarketIDsOnly(filter MarketFilter) bool {
+	return len(filter.CountryCodes) == 0 && len(filter.BettingTypes) == 0 && filter.TurnInPlayEnabled == nil &&
+		len(filter.MarketTypes) == 0 && len(filter.Venues) == 0 && len(filter.EventTypeIDs) == 0 &&
+		len(filter.EventIDs) == 0 && filter.BSPMarket == nil && len(filter.RaceTypes) == 0
+}
+
+// mergeIDs appends the IDs not present yet, keeping the order
+func mergeIDs(ids []string, add []string) []string {
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+
+	for _, id := range add {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// removeIDs returns the IDs not present in remove, keeping the order
+func removeIDs(ids []string, remove []string) []string {
+	drop := make(map[string]bool, len(remove))
+	for _, id := range remove {
+		drop[id] = true
+	}
+
+	result := []string{}
+	for _, id := range ids {
+		if !drop[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// copyMarketFilter deep copies the slices, so the filter can't be changed from outside the manager
+func copyMarketFilter(filter MarketFilter) MarketFilter {
+	result := filter
+	result.CountryCodes = copyStrings(filter.CountryCodes)
+	result.MarketTypes = copyStrings(filter.MarketTypes)
+	result.Venues = copyStrings(filter.Venues)
+	result.MarketIDs = copyStrings(filter.MarketIDs)
+	result.EventTypeIDs = copyStrings(filter.EventTypeIDs)
+	result.EventIDs = copyStrings(filter.EventIDs)
+	result.RaceTypes = copyStrings(filter.RaceTypes)
+	if filter.BettingTypes != nil {
+		result.BettingTypes = append([]BettingType{}, filter.BettingTypes...)
+	}
+	return result
+}
+
+func copyStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	return append([]string{}, s...)
+}
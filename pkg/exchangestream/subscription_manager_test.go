@@ -0,0 +1,101 @@
+package exchangestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubscriptionManager(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.close()
+
+	esaclient := NewESAClient("app_key", "session_token")
+
+	err := esaclient.Connect(context.Background(), ts.connConfig())
+	if err != nil {
+		t.Fatalf("error connecting - error: %s", err)
+	}
+	defer esaclient.Disconnect()
+
+	if _, err = esaclient.Authenticate(); err != nil {
+		t.Fatalf("error authenticating - error: %s", err)
+	}
+	ts.waitRequest("authentication")
+
+	manager := NewSubscriptionManager(&esaclient, MarketSubscriptionMessage{ConflateMs: 500})
+	ctx := context.Background()
+
+	if _, err = manager.AddMarkets(ctx, "1.1", "1.2"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+
+	sub := ts.waitRequest("marketSubscription")
+	if sub["clk"] != nil || sub["initialClk"] != nil {
+		t.Errorf("got clocks (%v, %v) on the first subscription, want none", sub["initialClk"], sub["clk"])
+	}
+
+	firstID := manager.SubscriptionID()
+	if firstID == 0 || fmt.Sprintf("%v", sub["id"]) != fmt.Sprintf("%d", firstID) {
+		t.Errorf("got subscription ID %d, want the request ID %v", firstID, sub["id"])
+	}
+
+	ts.send(fmt.Sprintf(`{"op":"mcm","id":%d,"initialClk":"initial","clk":"clk1","pt":1594999999999,"ct":"SUB_IMAGE","mc":[]}`, firstID))
+
+	select {
+	case mcm := <-esaclient.MCMChan:
+		if !manager.IsCurrent(mcm) {
+			t.Errorf("got message with ID %d not current, want current", *mcm.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for market change message")
+	}
+
+	if _, err = manager.AddMarkets(ctx, "1.2", "1.3"); err != nil {
+		t.Fatalf("error adding markets - error: %s", err)
+	}
+
+	sub = ts.waitRequest("marketSubscription")
+	if sub["initialClk"] != "initial" || sub["clk"] != "clk1" {
+		t.Errorf("got clocks (%v, %v) when adding markets, want (initial, clk1)", sub["initialClk"], sub["clk"])
+	}
+	if sub["conflateMs"] != float64(500) {
+		t.Errorf("got conflateMs %v, want 500", sub["conflateMs"])
+	}
+
+	filter := sub["marketFilter"].(map[string]interface{})
+	if ids := fmt.Sprintf("%v", filter["marketIds"]); ids != "[1.1 1.2 1.3]" {
+		t.Errorf("got market IDs %s, want [1.1 1.2 1.3]", ids)
+	}
+
+	old := MarketChangeM{ID: &firstID}
+	if manager.IsCurrent(old) {
+		t.Errorf("message from the replaced subscription reported as current")
+	}
+
+	if _, err = manager.RemoveMarkets(ctx, "1.1", "1.3"); err != nil {
+		t.Fatalf("error removing markets - error: %s", err)
+	}
+	ts.waitRequest("marketSubscription")
+
+	if got := manager.MarketFilter(); !reflect.DeepEqual(got.MarketIDs, []string{"1.2"}) {
+		t.Errorf("got market IDs %v, want [1.2]", got.MarketIDs)
+	}
+
+	if _, err = manager.RemoveMarkets(ctx, "1.2"); !errors.Is(err, ErrEmptyFilter) {
+		t.Errorf("got error %v removing every market, want %v", err, ErrEmptyFilter)
+	}
+
+	if _, err = manager.ReplaceFilter(ctx, MarketFilter{EventTypeIDs: []string{"7"}}); err != nil {
+		t.Fatalf("error replacing filter - error: %s", err)
+	}
+
+	sub = ts.waitRequest("marketSubscription")
+	filter = sub["marketFilter"].(map[string]interface{})
+	if filter["marketIds"] != nil || fmt.Sprintf("%v", filter["eventTypeIds"]) != "[7]" {
+		t.Errorf("got filter %v, want only event type 7", filter)
+	}
+}
@@ -0,0 +1,57 @@
+package exchangestream
+
+import (
+	"strconv"
+	"time"
+)
+
+// EpochMillis is a point in time sent by betfair as the number of milliseconds since the unix epoch.
+type EpochMillis struct {
+	time.Time
+}
+
+// NewEpochMillis creates an EpochMillis from milliseconds since the unix epoch.
+func NewEpochMillis(ms int64) EpochMillis {
+	return EpochMillis{time.Unix(0, ms*int64(time.Millisecond)).UTC()}
+}
+
+// Millis returns the number of milliseconds since the unix epoch.
+func (em EpochMillis) Millis() int64 {
+	return em.UnixNano() / int64(time.Millisecond)
+}
+
+// MarshalJSON marshals the time as a json number of milliseconds since the unix epoch
+func (em EpochMillis) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(em.Millis(), 10)), nil
+}
+
+// UnmarshalJSON unmarshals a json number of milliseconds since the unix epoch
+func (em *EpochMillis) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	*em = NewEpochMillis(ms)
+	return nil
+}
+
+// Latency returns how long ago the message was published by betfair.
+func (mcm MarketChangeMessage) Latency(now time.Time) time.Duration {
+	return now.Sub(mcm.PublishTime.Time)
+}
+
+// Latency returns how long ago the message was published by betfair.
+func (ocm OrderChangeMessage) Latency(now time.Time) time.Duration {
+	return now.Sub(ocm.PublishTime.Time)
+}
+
+// TimeToOff returns the time left until the market's scheduled start (negative once the start time has passed).
+func (md MarketDefinition) TimeToOff(now time.Time) time.Duration {
+	return md.MarketTime.Sub(now)
+}
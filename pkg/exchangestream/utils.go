@@ -2,10 +2,21 @@ package exchangestream
 
 type MarketChangeM struct {
 	ID *uint32
+	// ConnectionID is the connection the message came from, empty when unknown (e.g. replayed messages).
+	// Message IDs are only unique within a connection.
+	ConnectionID string
 	MarketChangeMessage
 }
 
 type OrderChangeM struct {
 	ID *uint32
+	// ConnectionID is the connection the message came from, empty when unknown (e.g. replayed messages).
+	// Message IDs are only unique within a connection.
+	ConnectionID string
 	OrderChangeMessage
 }
+
+type StatusM struct {
+	ID *uint32
+	StatusMessage
+}
@@ -0,0 +1,99 @@
+package exchangestream
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// testWriter writes at most maxWrite bytes per call and fails after failAfter bytes
+type testWriter struct {
+	buf       bytes.Buffer
+	maxWrite  int
+	failAfter int
+}
+
+var errTestWrite = errors.New("broken pipe")
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	if w.buf.Len() >= w.failAfter {
+		return 0, errTestWrite
+	}
+	if len(p) > w.maxWrite {
+		p = p[:w.maxWrite]
+	}
+	return w.buf.Write(p)
+}
+
+func (w *testWriter) SetWriteDeadline(t time.Time) error { return nil }
+
+func TestWriteFull(t *testing.T) {
+	w := &testWriter{maxWrite: 3, failAfter: 100}
+	if err := writeFull(w, []byte("{\"op\":\"heartbeat\"}\r\n")); err != nil || w.buf.String() != "{\"op\":\"heartbeat\"}\r\n" {
+		t.Errorf("got (%q, %v), want the whole frame written", w.buf.String(), err)
+	}
+
+	w = &testWriter{maxWrite: 3, failAfter: 6}
+	if err := writeFull(w, []byte("{\"op\":\"heartbeat\"}\r\n")); !errors.Is(err, errTestWrite) {
+		t.Errorf("got error %v, want %v", err, errTestWrite)
+	}
+
+	if err := writeFull(writerFunc(func(p []byte) (int, error) { return 0, nil }), []byte("x")); err != io.ErrShortWrite {
+		t.Errorf("got error %v for a writer making no progress, want %v", err, io.ErrShortWrite)
+	}
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func TestWriteLoopFailure(t *testing.T) {
+	w := &testWriter{maxWrite: 4, failAfter: 20}
+
+	reqMsgChan := make(chan requestFrame, 10)
+	writeErrChan := make(chan writeFailure, 1)
+	connLostChan := make(chan error, 3)
+
+	done := make(chan struct{})
+	go func() {
+		writeLoop(w, reqMsgChan, writeErrChan, connLostChan)
+		close(done)
+	}()
+
+	reqMsgChan <- requestFrame{id: 1, data: []byte("{\"op\":\"heartbeat\",\"id\":1}\r\n")}
+
+	select {
+	case failure := <-writeErrChan:
+		var writeErr WriteError
+		if failure.id != 1 || !errors.As(failure.err, &writeErr) || !errors.Is(failure.err, errTestWrite) {
+			t.Errorf("got failure %+v, want WriteError for request 1", failure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the write failure")
+	}
+
+	select {
+	case err := <-connLostChan:
+		if !errors.Is(err, errTestWrite) {
+			t.Errorf("got connection lost error %v, want %v", err, errTestWrite)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout waiting for the connection lost signal")
+	}
+
+	// Requests queued after the failure are not written and the writer exits when stopped
+	reqMsgChan <- requestFrame{id: 2, data: []byte("{\"op\":\"heartbeat\",\"id\":2}\r\n")}
+	close(reqMsgChan)
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("writer didn't stop")
+	}
+
+	if w.buf.Len() != 20 {
+		t.Errorf("got %d bytes written, want 20", w.buf.Len())
+	}
+}
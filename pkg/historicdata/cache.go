@@ -0,0 +1,33 @@
+package historicdata
+
+import (
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/cache/market"
+)
+
+// BuildCaches reads a historic data file (see ReadFile) and returns the final cache of each market selected.
+func BuildCaches(name string, filter Filter) (map[string]market.MarketCache, error) {
+	cm := market.NewCacheManager()
+	marketIDs := make(map[string]bool)
+
+	err := ReadFile(name, filter, func(file string, mcm exchangestream.MarketChangeMessage) error {
+		updated, err := cm.Process(exchangestream.MarketChangeM{MarketChangeMessage: mcm})
+		if err != nil {
+			return err
+		}
+		for _, marketID := range updated {
+			marketIDs[marketID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	caches := make(map[string]market.MarketCache, len(marketIDs))
+	for marketID := range marketIDs {
+		caches[marketID], _ = cm.GetCache(marketID)
+	}
+
+	return caches, nil
+}
@@ -0,0 +1,103 @@
+package historicdata
+
+import (
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// Filter selects the markets read, the zero value selects everything.
+type Filter struct {
+	MarketIDs    []string
+	EventTypeIDs []string
+	// From and To limit the markets by their start time (MarketDefinition.MarketTime), From included and To excluded.
+	// A zero time means no limit.
+	From time.Time
+	To   time.Time
+}
+
+// filterState applies a filter, keeping track of the markets selected by their definitions
+type filterState struct {
+	marketIDs    map[string]bool
+	eventTypeIDs map[string]bool
+	from         time.Time
+	to           time.Time
+	// Whether a market is selected, according to its latest definition (key: market ID)
+	selected map[string]bool
+}
+
+func newFilterState(filter Filter) *filterState {
+	fs := &filterState{from: filter.From, to: filter.To, selected: make(map[string]bool)}
+
+	if len(filter.MarketIDs) > 0 {
+		fs.marketIDs = make(map[string]bool)
+		for _, marketID := range filter.MarketIDs {
+			fs.marketIDs[marketID] = true
+		}
+	}
+	if len(filter.EventTypeIDs) > 0 {
+		fs.eventTypeIDs = make(map[string]bool)
+		for _, eventTypeID := range filter.EventTypeIDs {
+			fs.eventTypeIDs[eventTypeID] = true
+		}
+	}
+
+	return fs
+}
+
+// needsDefinition is set when markets are selected by their definition
+func (fs *filterState) needsDefinition() bool {
+	return fs.eventTypeIDs != nil || !fs.from.IsZero() || !fs.to.IsZero()
+}
+
+// wantFile checks whether a file can hold selected markets, going by its name
+func (fs *filterState) wantFile(name string) bool {
+	if fs.marketIDs == nil {
+		return true
+	}
+
+	marketID := marketIDFromFile(name)
+	return marketID == "" || fs.marketIDs[marketID]
+}
+
+// apply keeps the changes of the selected markets, returns false if none are left.
+// Changes of markets whose definition hasn't been seen yet are dropped when selecting by definition.
+func (fs *filterState) apply(mcm exchangestream.MarketChangeMessage) (exchangestream.MarketChangeMessage, bool) {
+	if fs.marketIDs == nil && !fs.needsDefinition() {
+		return mcm, len(mcm.MarketChanges) > 0
+	}
+
+	changes := make([]exchangestream.MarketChange, 0, len(mcm.MarketChanges))
+	for _, change := range mcm.MarketChanges {
+		if fs.marketIDs != nil && !fs.marketIDs[change.ID] {
+			continue
+		}
+
+		if fs.needsDefinition() {
+			if change.MarketDefinition != nil {
+				fs.selected[change.ID] = fs.wantDefinition(*change.MarketDefinition)
+			}
+			if !fs.selected[change.ID] {
+				continue
+			}
+		}
+
+		changes = append(changes, change)
+	}
+
+	mcm.MarketChanges = changes
+	return mcm, len(changes) > 0
+}
+
+func (fs *filterState) wantDefinition(md exchangestream.MarketDefinition) bool {
+	if fs.eventTypeIDs != nil && !fs.eventTypeIDs[md.EventTypeID] {
+		return false
+	}
+	if !fs.from.IsZero() && md.MarketTime.Before(fs.from) {
+		return false
+	}
+	if !fs.to.IsZero() && !md.MarketTime.Before(fs.to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,180 @@
+// Package historicdata reads betfair historic stream data (BASIC, ADVANCED and PRO).
+// Betfair sells it as bz2 files with one market change message per line, in the same format the stream sends,
+// usually bundled in a tar archive with a file per market or per event.
+// The messages are decoded into the exchangestream types, so the code written for the live stream can be used on
+// the historic data as well.
+package historicdata
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+// Largest line accepted, PRO data images of big markets can take a few MB
+const maxLineSize = 64 * 1024 * 1024
+
+// Header of bz2 streams
+var bz2Magic = []byte("BZh")
+
+// LineError is returned when a line can't be decoded
+type LineError struct {
+	// File is the name of the file the line belongs to, empty when reading with a Decoder
+	File string
+	Line int
+	Err  error
+}
+
+func (e LineError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s line %d: %s", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder decodes the market change messages of a single historic data file, already decompressed.
+type Decoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewDecoder creates a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return &Decoder{scanner: scanner}
+}
+
+// Decode returns the next market change message, skipping blank lines and other operations.
+// Returns io.EOF at the end of the file and a LineError when a line can't be decoded.
+// A LineError can be skipped by calling Decode again.
+func (d *Decoder) Decode() (exchangestream.MarketChangeMessage, error) {
+	for d.scanner.Scan() {
+		d.line++
+
+		data := bytes.TrimSpace(d.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		respMsg := exchangestream.ResponseMessage{}
+		if err := json.Unmarshal(data, &respMsg); err != nil {
+			return exchangestream.MarketChangeMessage{}, LineError{Line: d.line, Err: err}
+		}
+		if respMsg.MarketChangeMessage == nil {
+			continue
+		}
+
+		return *respMsg.MarketChangeMessage, nil
+	}
+
+	if err := d.scanner.Err(); err != nil {
+		return exchangestream.MarketChangeMessage{}, err
+	}
+	return exchangestream.MarketChangeMessage{}, io.EOF
+}
+
+// HandlerFunc gets each message read, along with the name of the file it came from.
+// Returning an error stops the reading, the error is returned by ReadFile.
+type HandlerFunc func(file string, mcm exchangestream.MarketChangeMessage) error
+
+// ReadFile reads a historic data file, either a single file or a tar archive (ending in .tar) of files.
+// Files are decompressed if they are bz2 compressed, whatever their extension.
+// Only the market changes passing the filter are handed to fn, messages left without changes are skipped.
+func ReadFile(name string, filter Filter, fn HandlerFunc) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fs := newFilterState(filter)
+
+	if strings.HasSuffix(name, ".tar") {
+		return readTar(f, fs, fn)
+	}
+	return read(f, name, fs, fn)
+}
+
+// Read reads a single historic data file (not a tar archive), see ReadFile.
+// name is only used in errors and handed to fn.
+func Read(r io.Reader, name string, filter Filter, fn HandlerFunc) error {
+	return read(r, name, newFilterState(filter), fn)
+}
+
+// readTar reads every regular file in the archive
+func readTar(r io.Reader, fs *filterState, fn HandlerFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg || !fs.wantFile(header.Name) {
+			continue
+		}
+
+		if err := read(tr, header.Name, fs, fn); err != nil {
+			return err
+		}
+	}
+}
+
+func read(r io.Reader, name string, fs *filterState, fn HandlerFunc) error {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(bz2Magic)); bytes.Equal(magic, bz2Magic) {
+		r = bzip2.NewReader(br)
+	} else {
+		r = br
+	}
+
+	decoder := NewDecoder(r)
+	for {
+		mcm, err := decoder.Decode()
+		if err == io.EOF {
+			return nil
+		} else if lineErr, ok := err.(LineError); ok {
+			lineErr.File = name
+			return lineErr
+		} else if err != nil {
+			return fmt.Errorf("error reading %s: %w", name, err)
+		}
+
+		if mcm, ok := fs.apply(mcm); ok {
+			if err := fn(name, mcm); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// marketIDFromFile returns the market ID of files named after the market (e.g. 1.171234567.bz2), empty otherwise
+func marketIDFromFile(name string) string {
+	base := strings.TrimSuffix(path.Base(name), ".bz2")
+
+	parts := strings.Split(base, ".")
+	if len(parts) != 2 || !isDigits(parts[0]) || !isDigits(parts[1]) {
+		return ""
+	}
+	return base
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
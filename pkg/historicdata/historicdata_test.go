@@ -0,0 +1,211 @@
+package historicdata
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+	"github.com/gustavooferreira/betfair/pkg/exchangestream"
+)
+
+const (
+	raceMarketID     = "1.100000001"
+	footballMarketID = "1.200000002"
+)
+
+// writeTar bundles the test files the way betfair does, returns the archive path
+func writeTar(t *testing.T) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "data.tar")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("error creating tar archive - error: %s", err)
+	}
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	for _, marketPath := range []string{"BASIC/2020/Jul/17/30000001/" + raceMarketID, "BASIC/2021/Jan/2/30000002/" + footballMarketID} {
+		data, err := ioutil.ReadFile("testdata/" + path.Base(marketPath) + ".bz2")
+		if err != nil {
+			t.Fatalf("error reading test file - error: %s", err)
+		}
+
+		if err := tw.WriteHeader(&tar.Header{Name: path.Dir(marketPath) + "/", Typeflag: tar.TypeDir, Mode: 0755}); err != nil {
+			t.Fatalf("error writing tar header - error: %s", err)
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: marketPath + ".bz2", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("error writing tar header - error: %s", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("error writing tar file - error: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("error closing tar archive - error: %s", err)
+	}
+
+	return name
+}
+
+// readClocks reads the file and returns the clocks of the messages read per market
+func readClocks(t *testing.T, name string, filter Filter) map[string][]string {
+	t.Helper()
+
+	clocks := make(map[string][]string)
+	err := ReadFile(name, filter, func(file string, mcm exchangestream.MarketChangeMessage) error {
+		for _, change := range mcm.MarketChanges {
+			clocks[change.ID] = append(clocks[change.ID], mcm.Clk)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error reading %s - error: %s", name, err)
+	}
+	return clocks
+}
+
+func TestReadFile(t *testing.T) {
+	clocks := readClocks(t, "testdata/"+raceMarketID+".bz2", Filter{})
+
+	want := "1001 1002 1003 1004 1005"
+	if got := strings.Join(clocks[raceMarketID], " "); got != want || len(clocks) != 1 {
+		t.Errorf("got clocks %v, want %s", clocks, want)
+	}
+}
+
+func TestReadFileTarFilters(t *testing.T) {
+	archive := writeTar(t)
+
+	race := "1001 1002 1003 1004 1005"
+	football := "2001 2002 2003"
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   map[string]string
+	}{
+		{name: "everything", filter: Filter{}, want: map[string]string{raceMarketID: race, footballMarketID: football}},
+		{name: "market", filter: Filter{MarketIDs: []string{footballMarketID}}, want: map[string]string{footballMarketID: football}},
+		{name: "event type", filter: Filter{EventTypeIDs: []string{"7"}}, want: map[string]string{raceMarketID: race}},
+		{name: "from", filter: Filter{From: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+			want: map[string]string{footballMarketID: football}},
+		{name: "to", filter: Filter{To: time.Date(2021, 1, 2, 15, 0, 0, 0, time.UTC)},
+			want: map[string]string{raceMarketID: race}},
+		{name: "nothing", filter: Filter{MarketIDs: []string{raceMarketID}, EventTypeIDs: []string{"1"}}, want: map[string]string{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clocks := readClocks(t, archive, test.filter)
+
+			if len(clocks) != len(test.want) {
+				t.Errorf("got clocks %v, want %v", clocks, test.want)
+			}
+			for marketID, want := range test.want {
+				if got := strings.Join(clocks[marketID], " "); got != want {
+					t.Errorf("market %s: got clocks %s, want %s", marketID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	input := `{"op":"mcm","clk":"1","pt":1594990000000,"mc":[{"id":"1.1"}]}
+
+{"op":"mcm","clk":
+`
+
+	var files []string
+	err := Read(strings.NewReader(input), "plain", Filter{}, func(file string, mcm exchangestream.MarketChangeMessage) error {
+		files = append(files, file)
+		return nil
+	})
+
+	var lineErr LineError
+	if !errors.As(err, &lineErr) || lineErr.File != "plain" || lineErr.Line != 3 {
+		t.Errorf("got error %v, want error on line 3 of plain", err)
+	}
+	if len(files) != 1 || files[0] != "plain" {
+		t.Errorf("got messages from %v, want one message from plain", files)
+	}
+
+	stop := errors.New("stop")
+	err = ReadFile("testdata/"+raceMarketID+".bz2", Filter{}, func(file string, mcm exchangestream.MarketChangeMessage) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("got error %v, want the handler error", err)
+	}
+
+	if err := ReadFile("testdata/missing.bz2", Filter{}, nil); !os.IsNotExist(err) {
+		t.Errorf("got error %v, want file not found", err)
+	}
+}
+
+func TestDecoder(t *testing.T) {
+	input := `{"op":"status","statusCode":"SUCCESS"}
+not json
+{"op":"mcm","clk":"2","pt":1594990000000,"mc":[{"id":"1.1","tv":12.5}]}
+`
+	decoder := NewDecoder(strings.NewReader(input))
+
+	if _, err := decoder.Decode(); err == nil {
+		t.Fatalf("got no error decoding line 2")
+	}
+
+	mcm, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("error decoding line 3 - error: %s", err)
+	}
+	if mcm.Clk != "2" || mcm.PublishTime.Millis() != 1594990000000 || len(mcm.MarketChanges) != 1 ||
+		*mcm.MarketChanges[0].TotalVolume != decimal.NewMoneyFromCents(1250) {
+		t.Errorf("got message %+v", mcm)
+	}
+
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("got error %v, want EOF", err)
+	}
+}
+
+func TestBuildCaches(t *testing.T) {
+	caches, err := BuildCaches(writeTar(t), Filter{})
+	if err != nil {
+		t.Fatalf("error building caches - error: %s", err)
+	}
+	if len(caches) != 2 {
+		t.Fatalf("got %d caches, want 2", len(caches))
+	}
+
+	tests := []struct {
+		marketID     string
+		clk          string
+		publishTime  int64
+		tradedVolume int64
+	}{
+		{marketID: raceMarketID, clk: "1005", publishTime: 1594991600000, tradedVolume: 13500},
+		{marketID: footballMarketID, clk: "2003", publishTime: 1609602000000, tradedVolume: 15000},
+	}
+
+	for _, test := range tests {
+		mc := caches[test.marketID]
+		if mc.MarketID != test.marketID || mc.Clk != test.clk {
+			t.Errorf("market %s: got market ID %s and clock %s, want clock %s", test.marketID, mc.MarketID, mc.Clk, test.clk)
+		}
+		if mc.PublishTime == nil || !exchangestream.NewEpochMillis(test.publishTime).Equal(*mc.PublishTime) {
+			t.Errorf("market %s: got publish time %v, want %d", test.marketID, mc.PublishTime, test.publishTime)
+		}
+		if mc.TradedVolume == nil || *mc.TradedVolume != decimal.NewMoneyFromCents(test.tradedVolume) {
+			t.Errorf("market %s: got traded volume %v, want %d cents", test.marketID, mc.TradedVolume, test.tradedVolume)
+		}
+	}
+}
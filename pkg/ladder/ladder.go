@@ -0,0 +1,365 @@
+// Package ladder provides tick utilities for the betfair price ladders (CLASSIC, FINEST and LINE_RANGE).
+package ladder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+)
+
+// ErrOutOfRange is returned when a price (or tick) falls outside the ladder.
+var ErrOutOfRange = errors.New("ladder: price out of range")
+
+// ErrInvalidPrice is returned when a price is not a valid ladder price (e.g. it sits between ticks, or it's NaN).
+var ErrInvalidPrice = errors.New("ladder: invalid price")
+
+// Type is the type of a betfair price ladder.
+type Type int
+
+const (
+	Type_Classic Type = iota + 1
+	Type_Finest
+	Type_LineRange
+)
+
+func (t Type) String() string {
+	switch t {
+	case Type_Classic:
+		return "CLASSIC"
+	case Type_Finest:
+		return "FINEST"
+	case Type_LineRange:
+		return "LINE_RANGE"
+	}
+	return ""
+}
+
+// band is a contiguous section of the ladder with a constant increment.
+// Ticks are start, start+step, ..., end (both inclusive).
+type band struct {
+	start int64
+	end   int64
+	step  int64
+	// first is the index of the band's first tick in the whole ladder
+	first int
+}
+
+func (b band) ticks() int {
+	return int((b.end-b.start)/b.step) + 1
+}
+
+func (b band) price(index int) decimal.Price {
+	return decimal.Price(b.start + int64(index-b.first)*b.step)
+}
+
+// Ladder is a price ladder.
+// The zero value is an empty ladder, use Classic, Finest or LineRange to get a usable one.
+type Ladder struct {
+	ladderType Type
+	bands      []band
+	ticks      int
+}
+
+// classicBands holds the CLASSIC ladder increments, as (start, end, step) in decimal.Price units.
+var classicBands = newBands([][3]decimal.Price{
+	{p("1.01"), p("2"), p("0.01")},
+	{p("2.02"), p("3"), p("0.02")},
+	{p("3.05"), p("4"), p("0.05")},
+	{p("4.1"), p("6"), p("0.1")},
+	{p("6.2"), p("10"), p("0.2")},
+	{p("10.5"), p("20"), p("0.5")},
+	{p("21"), p("30"), p("1")},
+	{p("32"), p("50"), p("2")},
+	{p("55"), p("100"), p("5")},
+	{p("110"), p("1000"), p("10")},
+})
+
+// finestBands holds the FINEST ladder increments.
+var finestBands = newBands([][3]decimal.Price{
+	{p("1.01"), p("1000"), p("0.01")},
+})
+
+// Classic returns the CLASSIC ladder (1.01 to 1000 with the standard betfair increments).
+func Classic() Ladder {
+	return Ladder{ladderType: Type_Classic, bands: classicBands, ticks: countTicks(classicBands)}
+}
+
+// Finest returns the FINEST ladder (1.01 to 1000 in 0.01 increments).
+func Finest() Ladder {
+	return Ladder{ladderType: Type_Finest, bands: finestBands, ticks: countTicks(finestBands)}
+}
+
+// LineRange returns the LINE_RANGE ladder of a line market, going from min to max in interval increments.
+// If max is not reachable from min in whole intervals, the ladder stops at the last tick below max.
+func LineRange(min decimal.Price, max decimal.Price, interval decimal.Price) (Ladder, error) {
+	if min.IsNaN() || min.IsInf() || max.IsNaN() || max.IsInf() || interval.IsNaN() || interval.IsInf() {
+		return Ladder{}, ErrInvalidPrice
+	} else if interval <= 0 {
+		return Ladder{}, fmt.Errorf("ladder: interval must be positive, got %s", interval)
+	} else if max < min {
+		return Ladder{}, fmt.Errorf("ladder: max (%s) must not be lower than min (%s)", max, min)
+	}
+
+	end := int64(min) + (int64(max)-int64(min))/int64(interval)*int64(interval)
+	bands := newBands([][3]decimal.Price{{min, decimal.Price(end), interval}})
+
+	return Ladder{ladderType: Type_LineRange, bands: bands, ticks: countTicks(bands)}, nil
+}
+
+// ForType returns the ladder for the given type.
+// The line range arguments are only used by Type_LineRange.
+func ForType(t Type, lineMin decimal.Price, lineMax decimal.Price, lineInterval decimal.Price) (Ladder, error) {
+	switch t {
+	case Type_Classic:
+		return Classic(), nil
+	case Type_Finest:
+		return Finest(), nil
+	case Type_LineRange:
+		return LineRange(lineMin, lineMax, lineInterval)
+	}
+	return Ladder{}, fmt.Errorf("ladder: unknown ladder type %d", t)
+}
+
+// Type returns the type of the ladder.
+func (l Ladder) Type() Type {
+	return l.ladderType
+}
+
+// Len returns the number of ticks in the ladder.
+func (l Ladder) Len() int {
+	return l.ticks
+}
+
+// Min returns the lowest price in the ladder.
+func (l Ladder) Min() decimal.Price {
+	if l.ticks == 0 {
+		return decimal.PriceNaN
+	}
+	return decimal.Price(l.bands[0].start)
+}
+
+// Max returns the highest price in the ladder.
+func (l Ladder) Max() decimal.Price {
+	if l.ticks == 0 {
+		return decimal.PriceNaN
+	}
+	return decimal.Price(l.bands[len(l.bands)-1].end)
+}
+
+// Prices returns every price in the ladder, in ascending order.
+func (l Ladder) Prices() []decimal.Price {
+	result := make([]decimal.Price, 0, l.ticks)
+	for _, b := range l.bands {
+		for v := b.start; v <= b.end; v += b.step {
+			result = append(result, decimal.Price(v))
+		}
+	}
+	return result
+}
+
+// IsValid reports whether the price is one of the ladder's ticks.
+func (l Ladder) IsValid(price decimal.Price) bool {
+	_, err := l.Index(price)
+	return err == nil
+}
+
+// Index returns the position of the price in the ladder (the lowest price is at index 0).
+func (l Ladder) Index(price decimal.Price) (int, error) {
+	b, ok := l.find(price)
+	if !ok {
+		return 0, ErrOutOfRange
+	}
+
+	offset := int64(price) - b.start
+	if offset%b.step != 0 {
+		return 0, ErrInvalidPrice
+	}
+
+	return b.first + int(offset/b.step), nil
+}
+
+// Price returns the price at the given position in the ladder.
+func (l Ladder) Price(index int) (decimal.Price, error) {
+	if index < 0 || index >= l.ticks {
+		return 0, ErrOutOfRange
+	}
+
+	for _, b := range l.bands {
+		if index < b.first+b.ticks() {
+			return b.price(index), nil
+		}
+	}
+
+	return 0, ErrOutOfRange
+}
+
+// RoundDown returns the highest ladder price lower than or equal to the price.
+// Prices above the ladder round down to Max.
+func (l Ladder) RoundDown(price decimal.Price) (decimal.Price, error) {
+	index, err := l.floorIndex(price)
+	if err != nil {
+		return 0, err
+	}
+	return l.Price(index)
+}
+
+// RoundUp returns the lowest ladder price higher than or equal to the price.
+// Prices below the ladder round up to Min.
+func (l Ladder) RoundUp(price decimal.Price) (decimal.Price, error) {
+	index, err := l.ceilIndex(price)
+	if err != nil {
+		return 0, err
+	}
+	return l.Price(index)
+}
+
+// Round returns the ladder price nearest to the price, ties are rounded up.
+// Prices outside the ladder are clamped to Min or Max.
+func (l Ladder) Round(price decimal.Price) (decimal.Price, error) {
+	if price.IsNaN() || l.ticks == 0 {
+		return 0, ErrInvalidPrice
+	}
+
+	if price <= l.Min() {
+		return l.Min(), nil
+	} else if price >= l.Max() {
+		return l.Max(), nil
+	}
+
+	down, err := l.RoundDown(price)
+	if err != nil {
+		return 0, err
+	}
+
+	up, err := l.RoundUp(price)
+	if err != nil {
+		return 0, err
+	}
+
+	if price-down < up-price {
+		return down, nil
+	}
+	return up, nil
+}
+
+// Ticks returns the number of ticks between the prices (to - from).
+// The result is negative when to is lower than from.
+// Both prices must be valid ladder prices.
+func (l Ladder) Ticks(from decimal.Price, to decimal.Price) (int, error) {
+	fromIndex, err := l.Index(from)
+	if err != nil {
+		return 0, err
+	}
+
+	toIndex, err := l.Index(to)
+	if err != nil {
+		return 0, err
+	}
+
+	return toIndex - fromIndex, nil
+}
+
+// Add returns the price n ticks away from the price (n can be negative).
+// The price must be a valid ladder price.
+func (l Ladder) Add(price decimal.Price, n int) (decimal.Price, error) {
+	index, err := l.Index(price)
+	if err != nil {
+		return 0, err
+	}
+	return l.Price(index + n)
+}
+
+// find returns the band the price falls in.
+func (l Ladder) find(price decimal.Price) (band, bool) {
+	if price.IsNaN() || price.IsInf() {
+		return band{}, false
+	}
+
+	v := int64(price)
+	for i, b := range l.bands {
+		if v > b.end {
+			continue
+		}
+
+		// Prices between the end of a band and the start of the next belong to the next band,
+		// as they sit between ticks of the next band's step
+		if v >= b.start || (i > 0 && v > l.bands[i-1].end) {
+			return b, true
+		}
+		break
+	}
+
+	return band{}, false
+}
+
+// floorIndex returns the index of the highest tick lower than or equal to the price.
+func (l Ladder) floorIndex(price decimal.Price) (int, error) {
+	if price.IsNaN() || l.ticks == 0 {
+		return 0, ErrInvalidPrice
+	} else if price < l.Min() {
+		return 0, ErrOutOfRange
+	} else if price >= l.Max() {
+		return l.ticks - 1, nil
+	}
+
+	b, _ := l.find(price)
+	if int64(price) < b.start {
+		// price sits in the gap before this band, so the previous tick is the end of the previous band
+		return b.first - 1, nil
+	}
+
+	return b.first + int((int64(price)-b.start)/b.step), nil
+}
+
+// ceilIndex returns the index of the lowest tick higher than or equal to the price.
+func (l Ladder) ceilIndex(price decimal.Price) (int, error) {
+	if price.IsNaN() || l.ticks == 0 {
+		return 0, ErrInvalidPrice
+	} else if price > l.Max() {
+		return 0, ErrOutOfRange
+	} else if price <= l.Min() {
+		return 0, nil
+	}
+
+	b, _ := l.find(price)
+	if int64(price) < b.start {
+		return b.first, nil
+	}
+
+	offset := int64(price) - b.start
+	index := b.first + int(offset/b.step)
+	if offset%b.step != 0 {
+		index++
+	}
+
+	return index, nil
+}
+
+func newBands(defs [][3]decimal.Price) []band {
+	bands := make([]band, 0, len(defs))
+	first := 0
+	for _, def := range defs {
+		b := band{start: int64(def[0]), end: int64(def[1]), step: int64(def[2]), first: first}
+		bands = append(bands, b)
+		first += b.ticks()
+	}
+	return bands
+}
+
+func countTicks(bands []band) int {
+	if len(bands) == 0 {
+		return 0
+	}
+	last := bands[len(bands)-1]
+	return last.first + last.ticks()
+}
+
+// p parses a constant price, panicking if it's malformed.
+func p(s string) decimal.Price {
+	result, err := decimal.ParsePrice(s)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
@@ -0,0 +1,188 @@
+package ladder
+
+import (
+	"testing"
+
+	"github.com/gustavooferreira/betfair/pkg/decimal"
+)
+
+func mustPrice(t *testing.T, s string) decimal.Price {
+	t.Helper()
+	result, err := decimal.ParsePrice(s)
+	if err != nil {
+		t.Fatalf("error parsing price %s - error: %s", s, err)
+	}
+	return result
+}
+
+func TestLadderLen(t *testing.T) {
+	lineRange, err := LineRange(mustPrice(t, "0.5"), mustPrice(t, "10"), mustPrice(t, "1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	tests := map[string]struct {
+		ladder Ladder
+		len    int
+		min    string
+		max    string
+	}{
+		"classic":    {ladder: Classic(), len: 350, min: "1.01", max: "1000"},
+		"finest":     {ladder: Finest(), len: 99900, min: "1.01", max: "1000"},
+		"line range": {ladder: lineRange, len: 10, min: "0.5", max: "9.5"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.ladder.Len() != test.len {
+				t.Errorf("got len %d, want %d", test.ladder.Len(), test.len)
+			}
+
+			prices := test.ladder.Prices()
+			if len(prices) != test.len {
+				t.Errorf("got %d prices, want %d", len(prices), test.len)
+			}
+
+			if test.ladder.Min().String() != test.min || test.ladder.Max().String() != test.max {
+				t.Errorf("got range [%s, %s], want [%s, %s]", test.ladder.Min(), test.ladder.Max(), test.min, test.max)
+			}
+
+			for i, price := range prices {
+				if i > 0 && price <= prices[i-1] {
+					t.Fatalf("prices not ascending at index %d: %s <= %s", i, price, prices[i-1])
+				}
+
+				index, err := test.ladder.Index(price)
+				if err != nil || index != i {
+					t.Fatalf("got index %d (error: %v) for price %s, want %d", index, err, price, i)
+				}
+
+				got, err := test.ladder.Price(i)
+				if err != nil || got != price {
+					t.Fatalf("got price %s (error: %v) at index %d, want %s", got, err, i, price)
+				}
+			}
+		})
+	}
+}
+
+func TestClassicIsValid(t *testing.T) {
+	tests := map[string]bool{
+		"1.01": true, "1.5": true, "2": true, "2.01": false, "2.02": true, "3.03": false, "3.05": true,
+		"4.15": false, "4.1": true, "6.1": false, "6.2": true, "10.2": false, "10.5": true, "20.5": false,
+		"21": true, "31": false, "32": true, "52": false, "55": true, "105": false, "110": true, "1000": true,
+		"1": false, "1010": false, "0": false, "-2": false,
+	}
+
+	ladder := Classic()
+	for price, valid := range tests {
+		if got := ladder.IsValid(mustPrice(t, price)); got != valid {
+			t.Errorf("IsValid(%s) = %t, want %t", price, got, valid)
+		}
+	}
+
+	if ladder.IsValid(decimal.PriceNaN) || ladder.IsValid(decimal.PriceInfinity) {
+		t.Errorf("NaN and Infinity must not be valid ladder prices")
+	}
+}
+
+func TestClassicRounding(t *testing.T) {
+	tests := []struct {
+		price   string
+		down    string
+		up      string
+		nearest string
+	}{
+		{price: "1.01", down: "1.01", up: "1.01", nearest: "1.01"},
+		{price: "1.015", down: "1.01", up: "1.02", nearest: "1.02"},
+		{price: "2.01", down: "2", up: "2.02", nearest: "2.02"},
+		{price: "2.995", down: "2.98", up: "3", nearest: "3"},
+		{price: "3.01", down: "3", up: "3.05", nearest: "3"},
+		{price: "3.03", down: "3", up: "3.05", nearest: "3.05"},
+		{price: "4.14", down: "4.1", up: "4.2", nearest: "4.1"},
+		{price: "20.4", down: "20", up: "21", nearest: "20"},
+		{price: "51", down: "50", up: "55", nearest: "50"},
+		{price: "999", down: "990", up: "1000", nearest: "1000"},
+		{price: "1500", down: "1000", up: "", nearest: "1000"},
+		{price: "1", down: "", up: "1.01", nearest: "1.01"},
+	}
+
+	ladder := Classic()
+	for _, test := range tests {
+		price := mustPrice(t, test.price)
+
+		down, err := ladder.RoundDown(price)
+		if test.down == "" {
+			if err != ErrOutOfRange {
+				t.Errorf("RoundDown(%s): got error %v, want %v", test.price, err, ErrOutOfRange)
+			}
+		} else if err != nil || down.String() != test.down {
+			t.Errorf("RoundDown(%s) = %s (error: %v), want %s", test.price, down, err, test.down)
+		}
+
+		up, err := ladder.RoundUp(price)
+		if test.up == "" {
+			if err != ErrOutOfRange {
+				t.Errorf("RoundUp(%s): got error %v, want %v", test.price, err, ErrOutOfRange)
+			}
+		} else if err != nil || up.String() != test.up {
+			t.Errorf("RoundUp(%s) = %s (error: %v), want %s", test.price, up, err, test.up)
+		}
+
+		nearest, err := ladder.Round(price)
+		if err != nil || nearest.String() != test.nearest {
+			t.Errorf("Round(%s) = %s (error: %v), want %s", test.price, nearest, err, test.nearest)
+		}
+	}
+}
+
+func TestClassicTicks(t *testing.T) {
+	tests := []struct {
+		from  string
+		to    string
+		ticks int
+	}{
+		{from: "1.01", to: "1000", ticks: 349},
+		{from: "1.99", to: "2.02", ticks: 2},
+		{from: "3", to: "2.98", ticks: -1},
+		{from: "10", to: "21", ticks: 21},
+	}
+
+	ladder := Classic()
+	for _, test := range tests {
+		from := mustPrice(t, test.from)
+		to := mustPrice(t, test.to)
+
+		ticks, err := ladder.Ticks(from, to)
+		if err != nil || ticks != test.ticks {
+			t.Errorf("Ticks(%s, %s) = %d (error: %v), want %d", test.from, test.to, ticks, err, test.ticks)
+		}
+
+		got, err := ladder.Add(from, test.ticks)
+		if err != nil || got != to {
+			t.Errorf("Add(%s, %d) = %s (error: %v), want %s", test.from, test.ticks, got, err, test.to)
+		}
+	}
+
+	if _, err := ladder.Add(mustPrice(t, "1000"), 1); err != ErrOutOfRange {
+		t.Errorf("Add past the end of the ladder: got error %v, want %v", err, ErrOutOfRange)
+	}
+
+	if _, err := ladder.Ticks(mustPrice(t, "2.01"), mustPrice(t, "3")); err != ErrInvalidPrice {
+		t.Errorf("Ticks from an invalid price: got error %v, want %v", err, ErrInvalidPrice)
+	}
+}
+
+func TestLineRangeErrors(t *testing.T) {
+	if _, err := LineRange(mustPrice(t, "0"), mustPrice(t, "10"), mustPrice(t, "0")); err == nil {
+		t.Errorf("expected error for a zero interval")
+	}
+
+	if _, err := LineRange(mustPrice(t, "10"), mustPrice(t, "0"), mustPrice(t, "1")); err == nil {
+		t.Errorf("expected error for max lower than min")
+	}
+
+	if _, err := ForType(Type(0), 0, 0, 0); err == nil {
+		t.Errorf("expected error for an unknown ladder type")
+	}
+}
@@ -0,0 +1,361 @@
+// Generate betfair APING entities, containers and endpoints from the API definitions.
+// run (from the repository root): go run ./scripts/gen_aping
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+const definitionsDir = "assets/api_definitions"
+const templatesDir = "assets/templates"
+const outputDir = "pkg/aping"
+
+// packages lists the APING packages generated from a definition file.
+var packages = []string{"betting", "accounts", "heartbeat", "racestatus"}
+
+// initialisms maps lowercase words to the form they take in Go identifiers.
+var initialisms = map[string]string{
+	"api":  "API",
+	"bsp":  "BSP",
+	"id":   "ID",
+	"ids":  "IDs",
+	"sp":   "SP",
+	"url":  "URL",
+	"uuid": "UUID",
+}
+
+type Definition struct {
+	Package      string      `json:"package"`
+	API          string      `json:"api"`
+	Receiver     string      `json:"receiver"`
+	Protocol     string      `json:"protocol"`
+	Endpoint     string      `json:"endpoint"`
+	MethodPrefix string      `json:"methodPrefix"`
+	Operations   []Operation `json:"operations"`
+	Types        []Type      `json:"types"`
+	Enums        []Enum      `json:"enums"`
+}
+
+type Operation struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Params      []Field `json:"params"`
+	Returns     string  `json:"returns"`
+	// Validate makes the endpoint call the hand-written validate<GoName> method before sending the request
+	Validate bool `json:"validate"`
+
+	GoName       string // example: ListMarketCatalogue
+	ReturnGoType string // example: []MarketCatalogue
+}
+
+type Type struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Fields      []Field `json:"fields"`
+}
+
+type Field struct {
+	Name        string `json:"name"`
+	GoName      string `json:"goName"`
+	Type        string `json:"type"`
+	GoType      string `json:"goType"`
+	Mandatory   bool   `json:"mandatory"`
+	Description string `json:"description"`
+}
+
+type Enum struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// EnumsInfo mirrors the data expected by the enums template.
+type EnumsInfo struct {
+	Type      string // example: MarketProjection
+	TypeCamel string // example: marketProjection
+	VarName   string // example: mp
+
+	Enums           []string // example: EVENT_TYPE
+	EnumsPascalCase []string // example: EventType
+}
+
+type EnumsData struct {
+	Package string
+	Enums   []EnumsInfo
+}
+
+type FileData struct {
+	Definition
+	Imports []string
+}
+
+// knownImports maps the qualifier used in Go types to the package import path.
+var knownImports = map[string]string{
+	"time.":    "time",
+	"decimal.": "github.com/gustavooferreira/betfair/pkg/decimal",
+}
+
+func main() {
+	for _, pkg := range packages {
+		def := readDefinition(filepath.Join(definitionsDir, pkg+".json"))
+
+		addExtraTransformations(&def)
+
+		dir := filepath.Join(outputDir, pkg)
+
+		writeCode(filepath.Join(dir, "entities.go"), "aping_entities.go.tmpl",
+			FileData{Definition: def, Imports: typesImports(def.Types)})
+		writeCode(filepath.Join(dir, "containers.go"), "aping_containers.go.tmpl",
+			FileData{Definition: def, Imports: operationsImports(def.Operations)})
+		writeCode(filepath.Join(dir, "endpoints.go"), "aping_endpoints.go.tmpl", FileData{Definition: def})
+
+		// Betting enums are generated from the CSV file by gen_betting_enums.go
+		if len(def.Enums) != 0 {
+			writeCode(filepath.Join(dir, "enums.go"), "enums.go.tmpl", EnumsData{Package: def.Package, Enums: enumsInfo(def.Enums)})
+		}
+	}
+}
+
+func readDefinition(filePath string) Definition {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		log.Fatalf("error: %s\n", err)
+	}
+
+	def := Definition{}
+	err = json.Unmarshal(data, &def)
+	if err != nil {
+		log.Fatalf("error while parsing %s: %s\n", filePath, err)
+	}
+
+	if def.Protocol != "rest" && def.Protocol != "jsonrpc" {
+		log.Fatalf("error: unknown protocol '%s' in %s\n", def.Protocol, filePath)
+	}
+
+	return def
+}
+
+func addExtraTransformations(def *Definition) {
+	for i, op := range def.Operations {
+		def.Operations[i].GoName = goName(op.Name)
+		def.Operations[i].ReturnGoType = baseGoType(op.Returns)
+		fillFields(def.Operations[i].Params)
+	}
+
+	for i := range def.Types {
+		fillFields(def.Types[i].Fields)
+	}
+}
+
+func fillFields(fields []Field) {
+	for i, field := range fields {
+		if field.GoName == "" {
+			fields[i].GoName = goName(field.Name)
+		}
+		fields[i].GoType = fieldGoType(field)
+	}
+}
+
+// goName converts a camelCase API name into an exported Go identifier.
+// example: eventTypeIds -> EventTypeIDs
+func goName(name string) string {
+	words := []string{}
+	current := []rune{}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && !unicode.IsUpper(runes[i-1]) {
+			words = append(words, string(current))
+			current = []rune{}
+		}
+		current = append(current, r)
+	}
+	words = append(words, string(current))
+
+	result := ""
+	for _, word := range words {
+		if init, ok := initialisms[strings.ToLower(word)]; ok {
+			result += init
+			continue
+		}
+		wordRunes := []rune(word)
+		result += string(unicode.ToUpper(wordRunes[0])) + string(wordRunes[1:])
+	}
+
+	return result
+}
+
+// baseGoType maps an API type into the corresponding Go type.
+func baseGoType(apiType string) string {
+	switch apiType {
+	case "string":
+		return "string"
+	case "int":
+		return "int"
+	case "long":
+		return "int64"
+	case "double":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "dateTime":
+		return "time.Time"
+	}
+
+	if inner, ok := unwrap(apiType, "list("); ok {
+		return "[]" + baseGoType(inner)
+	}
+	if inner, ok := unwrap(apiType, "set("); ok {
+		return "[]" + baseGoType(inner)
+	}
+	if inner, ok := unwrap(apiType, "map("); ok {
+		kv := strings.SplitN(inner, ",", 2)
+		if len(kv) != 2 {
+			log.Fatalf("error: invalid map type '%s'\n", apiType)
+		}
+		return "map[" + baseGoType(strings.TrimSpace(kv[0])) + "]" + baseGoType(strings.TrimSpace(kv[1]))
+	}
+
+	// Enums and structs keep their names
+	return apiType
+}
+
+// fieldGoType returns the Go type of a field.
+// The base type can be overridden in the definition with goType (e.g. decimal.Price for a double).
+// Optional fields are pointers, except for strings, slices and maps which rely on their zero value.
+func fieldGoType(field Field) string {
+	result := baseGoType(field.Type)
+	if field.GoType != "" {
+		result = field.GoType
+	}
+
+	if field.Mandatory || result == "string" || strings.HasPrefix(result, "[]") || strings.HasPrefix(result, "map[") {
+		return result
+	}
+
+	return "*" + result
+}
+
+func unwrap(apiType string, prefix string) (string, bool) {
+	if strings.HasPrefix(apiType, prefix) && strings.HasSuffix(apiType, ")") {
+		return apiType[len(prefix) : len(apiType)-1], true
+	}
+	return "", false
+}
+
+func typesImports(types []Type) []string {
+	fields := []Field{}
+	for _, t := range types {
+		fields = append(fields, t.Fields...)
+	}
+	return fieldsImports(fields)
+}
+
+func operationsImports(operations []Operation) []string {
+	fields := []Field{}
+	for _, op := range operations {
+		fields = append(fields, op.Params...)
+	}
+	return fieldsImports(fields)
+}
+
+// fieldsImports returns the (sorted) list of packages needed by the fields types.
+func fieldsImports(fields []Field) []string {
+	imports := []string{}
+	for qualifier, path := range knownImports {
+		for _, f := range fields {
+			if strings.Contains(f.GoType, qualifier) {
+				imports = append(imports, path)
+				break
+			}
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+func enumsInfo(enums []Enum) []EnumsInfo {
+	results := []EnumsInfo{}
+
+	for _, enum := range enums {
+		info := EnumsInfo{Type: enum.Name, Enums: enum.Values}
+
+		typeRune := []rune(enum.Name)
+		info.TypeCamel = string(append([]rune{unicode.ToLower(typeRune[0])}, typeRune[1:]...))
+
+		varName := []rune{}
+		for _, elemType := range typeRune {
+			if unicode.IsUpper(elemType) {
+				varName = append(varName, elemType)
+			}
+		}
+		info.VarName = strings.ToLower(string(varName))
+
+		for _, value := range enum.Values {
+			// convert _ to space and apply string.title then remove spaces
+			temp := strings.Replace(value, "_", " ", -1)
+			temp = strings.ToLower(temp)
+			temp = strings.Title(temp)
+			temp = strings.Replace(temp, " ", "", -1)
+
+			info.EnumsPascalCase = append(info.EnumsPascalCase, temp)
+		}
+
+		results = append(results, info)
+	}
+
+	return results
+}
+
+// comment turns a (possibly multi-line) description into a Go comment.
+// The first line is prefixed with the identifier being documented.
+func comment(ident string, description string) string {
+	lines := strings.Split(strings.TrimSpace(description), "\n")
+
+	if ident != "" && !strings.HasPrefix(lines[0], ident+" ") {
+		first := []rune(lines[0])
+		lines[0] = ident + " " + string(unicode.ToLower(first[0])) + string(first[1:])
+	}
+
+	return "// " + strings.Join(lines, "\n// ")
+}
+
+func writeCode(filePath string, templateName string, data interface{}) {
+	tmpl := template.New(templateName).Funcs(template.FuncMap{
+		"comment": comment,
+		"lowerFirst": func(str string) string {
+			r := []rune(str)
+			return string(unicode.ToLower(r[0])) + string(r[1:])
+		},
+	})
+
+	tmpl, err := tmpl.ParseFiles(filepath.Join(templatesDir, templateName))
+	if err != nil {
+		log.Fatalf("error: %s\n", err)
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+
+	err = tmpl.Execute(buf, data)
+	if err != nil {
+		log.Fatalf("error: %s\n", err)
+	}
+
+	result, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatalf("error while formatting %s: %s\n", filePath, err)
+	}
+
+	err = ioutil.WriteFile(filePath, result, 0644)
+	if err != nil {
+		log.Fatalf("error: %s\n", err)
+	}
+}
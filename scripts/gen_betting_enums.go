@@ -24,6 +24,11 @@ type EnumsInfo struct {
 
 type EnumsInfoArray []EnumsInfo
 
+type EnumsData struct {
+	Package string
+	Enums   EnumsInfoArray
+}
+
 func main() {
 	// Revise this
 	// filePath := os.Args[1]
@@ -151,7 +156,7 @@ func genCode(data EnumsInfoArray) *bytes.Buffer {
 
 	// tmpl := template.Must(template.ParseFiles("assets/templates/enums.go.tmpl"))
 
-	tmpl := template.New("betting_enums.go.tmpl")
+	tmpl := template.New("enums.go.tmpl")
 
 	// tmpl = tmpl.Funcs(template.FuncMap{
 	// 	"gfTitle": func(str string) string {
@@ -161,7 +166,7 @@ func genCode(data EnumsInfoArray) *bytes.Buffer {
 	// 	},
 	// })
 
-	tmpl, err := tmpl.ParseFiles("assets/templates/betting_enums.go.tmpl")
+	tmpl, err := tmpl.ParseFiles("assets/templates/enums.go.tmpl")
 	if err != nil {
 		log.Fatalf("error: %s\n", err)
 	}
@@ -169,7 +174,7 @@ func genCode(data EnumsInfoArray) *bytes.Buffer {
 	// var b bytes.Buffer
 	buf := bytes.NewBuffer([]byte{})
 
-	err = tmpl.Execute(buf, data)
+	err = tmpl.Execute(buf, EnumsData{Package: "betting", Enums: data})
 	if err != nil {
 		log.Fatalf("error: %s\n", err)
 	}
@@ -1,89 +1,59 @@
 package main
 
 import (
-	"crypto/rand"
 	"crypto/tls"
 	"fmt"
 	"log"
-	"net"
 	"os"
-	"time"
+	"os/signal"
+
+	"github.com/gustavooferreira/betfair/pkg/exchangestream/emulator"
 )
 
+// Runs the exchange stream emulator until interrupted, publishing the recording given as argument (if any)
 func main() {
 	fmt.Println("Server start")
 
-	certFile, keyFile := config()
+	config := emulator.Config{Addr: "0.0.0.0:8080"}
 
-	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
-	if err != nil {
-		log.Fatalf("server: loadkeys: %s", err)
+	// Uses a self-signed certificate unless one is provided
+	if certFile, keyFile, ok := certConfig(); ok {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			log.Fatalf("server: loadkeys: %s", err)
+		}
+		config.Certificate = &cert
 	}
-	config := tls.Config{Certificates: []tls.Certificate{cert}}
-	config.Rand = rand.Reader
-	service := "0.0.0.0:8080"
-	listener, err := tls.Listen("tcp", service, &config)
+
+	server, err := emulator.NewServer(config)
 	if err != nil {
-		log.Fatalf("server: listen: %s", err)
+		log.Fatalf("server: %s", err)
 	}
-	log.Print("server: listening")
-	for {
-		conn, err := listener.Accept()
+	defer server.Close()
+	log.Printf("server: listening on %s", server.Addr())
+
+	if len(os.Args) > 1 {
+		f, err := os.Open(os.Args[1])
 		if err != nil {
-			log.Printf("server: accept: %s", err)
-			break
+			log.Fatalf("server: opening recording: %s", err)
 		}
-		defer conn.Close()
-		log.Printf("server: accepted from %s", conn.RemoteAddr())
-		tlscon, ok := conn.(*tls.Conn)
-		if ok {
-			err = tlscon.Handshake()
-			if err != nil {
-				log.Printf("server: TLS handshake: %s", err)
-				break
-			}
+		err = server.PublishRecorded(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("server: publishing recording: %s", err)
 		}
-		go handleClient(conn)
 	}
-}
-
-func handleClient(conn net.Conn) {
-	// defer closeConn(conn)
-
-	msg := "{\"op\":\"connection\",\"connectionId\":\"002-230915140112-174\"}\r\n"
-	msgBytes := []byte(msg)
-
-	time.Sleep(500 * time.Millisecond)
-
-	n, err := conn.Write(msgBytes[:20])
-	log.Printf("Wrote %d bytes - Error: %+v\n", n, err)
-
-	time.Sleep(1500 * time.Millisecond)
-
-	n, err = conn.Write(msgBytes[20:])
-	log.Printf("Wrote %d bytes - Error: %+v\n", n, err)
-
-	// n, err = conn.Write(msgBytes[:])
-	// log.Printf("Wrote %d bytes - Error: %+v\n", n, err)
-
-	// n, err := conn.Write([]byte("{\"op\":\"connection\",\"connectionId\":\"002-230915140112-174\"}\r\n{\"op\":\"connect"))
-	// log.Printf("Wrote %d bytes - Error: %+v\n", n, err)
-
-	// time.Sleep(1500 * time.Millisecond)
-
-	// n, err = conn.Write([]byte("ion\",\"connectionId\":\"002-230915140112-174\"}\r\n"))
-	// log.Printf("Wrote %d bytes - Error: %+v\n", n, err)
-}
 
-func closeConn(conn net.Conn) {
-	log.Println("server: conn: closed")
-	conn.Close()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	<-sigChan
+	log.Print("server: stopping")
 }
 
-func config() (string, string) {
+func certConfig() (string, string, bool) {
 	certFile, ok := os.LookupEnv("SERVER_CERTFILE")
 	if !ok {
-		log.Fatalln("Env var SERVER_CERTFILE missing")
+		return "", "", false
 	}
 
 	keyFile, ok := os.LookupEnv("SERVER_KEYFILE")
@@ -91,5 +61,5 @@ func config() (string, string) {
 		log.Fatalln("Env var SERVER_KEYFILE missing")
 	}
 
-	return certFile, keyFile
+	return certFile, keyFile, true
 }